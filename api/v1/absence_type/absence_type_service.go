@@ -0,0 +1,187 @@
+package absencetype
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/MninaTB/vacadm/api/v1/util"
+	"github.com/MninaTB/vacadm/pkg/database"
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+// NewAbsenceTypeService returns a AbsenceTypeService.
+func NewAbsenceTypeService(
+	store database.Database,
+	logger logrus.FieldLogger,
+) *AbsenceTypeService {
+	return &AbsenceTypeService{
+		store:  store,
+		logger: logger.WithField("component", "absence-type-service"),
+	}
+}
+
+// AbsenceTypeService implements http.HandlerFunc's to operate on the
+// absence-type catalogue.
+type AbsenceTypeService struct {
+	store  database.Database
+	logger logrus.FieldLogger
+}
+
+// Create reads the given payload and creates a store representation accordingly.
+func (a *AbsenceTypeService) Create(w http.ResponseWriter, r *http.Request) {
+	logger := a.logger.WithField("method", "create")
+	logger.Info("create new absence-type")
+	var at model.AbsenceType
+	err := json.NewDecoder(r.Body).Decode(&at)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.Error(err)
+		return
+	}
+	newAT, err := a.store.CreateAbsenceType(r.Context(), &at)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.Error(err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(newAT)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+	a.logger.Info("create absence-type with ID: ", newAT.ID)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// GetByID extracts a absenceTypeID from URL and writes the absence-type into
+// the given response writer.
+func (a *AbsenceTypeService) GetByID(w http.ResponseWriter, r *http.Request) {
+	logger := a.logger.WithField("method", "read")
+	logger.Info("get absence-type by id")
+	atID, err := extractAbsenceTypeID(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	opts, err := util.QueryOptionsFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	at, err := a.store.GetAbsenceTypeByID(r.Context(), atID, opts...)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	util.SetETag(w, at.Version)
+	err = json.NewEncoder(w).Encode(at)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// List retuns a list of all absence-types available on the internal store.
+func (a *AbsenceTypeService) List(w http.ResponseWriter, r *http.Request) {
+	logger := a.logger.WithField("method", "list")
+	logger.Info("retrieve absence-type list")
+	opts, err := util.QueryOptionsFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	list, err := a.store.ListAbsenceTypes(r.Context(), opts...)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	err = json.NewEncoder(w).Encode(&list)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// Update reads new absence-type information from the request body and
+// updates the store representation accordingly.
+func (a *AbsenceTypeService) Update(w http.ResponseWriter, r *http.Request) {
+	logger := a.logger.WithField("method", "update")
+	logger.Info("update absence-type")
+	atID, err := extractAbsenceTypeID(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var at model.AbsenceType
+	err = json.NewDecoder(r.Body).Decode(&at)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	at.ID = atID
+	at.Version, err = util.VersionFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(util.IfMatchStatusCode(err))
+		return
+	}
+	newAT, err := a.store.UpdateAbsenceType(r.Context(), &at)
+	if errors.Is(err, model.ErrVersionConflict) {
+		logger.Error(err)
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	util.SetETag(w, newAT.Version)
+	err = json.NewEncoder(w).Encode(&newAT)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	a.logger.Info("update absence-type with id: ", newAT.ID)
+}
+
+// Delete a absence-type associated to the given absenceTypeID in the URL.
+func (a *AbsenceTypeService) Delete(w http.ResponseWriter, r *http.Request) {
+	logger := a.logger.WithField("method", "delete")
+	logger.Info("delete absence-type")
+	atID, err := extractAbsenceTypeID(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	err = a.store.DeleteAbsenceType(r.Context(), atID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	a.logger.Info("delete absence-type with id: ", atID)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func extractAbsenceTypeID(r *http.Request) (string, error) {
+	vars := mux.Vars(r)
+	absenceTypeID, ok := vars["absenceTypeID"]
+	if !ok {
+		return "", errors.New("could not extract absenceTypeID")
+	}
+	return absenceTypeID, nil
+}
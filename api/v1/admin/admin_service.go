@@ -0,0 +1,118 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/MninaTB/vacadm/pkg/blob"
+	"github.com/MninaTB/vacadm/pkg/database"
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+// NewAdminService returns an AdminService. Deleted entries can be purged,
+// once they are deleted longer than the given retention period.
+func NewAdminService(store database.Database, blobs blob.Store, retention time.Duration, logger logrus.FieldLogger) *AdminService {
+	return &AdminService{
+		store:     store,
+		blobs:     blobs,
+		retention: retention,
+		logger:    logger.WithField("component", "admin-service"),
+	}
+}
+
+// AdminService implements http.HandlerFunc's to restore and purge soft deleted
+// entries.
+type AdminService struct {
+	store     database.Database
+	blobs     blob.Store
+	retention time.Duration
+	logger    logrus.FieldLogger
+}
+
+// Restore extracts entity and id from URL and removes the deletion mark of the
+// entry.
+// Example request:
+// PUT /v1/admin/team-rule/1ff63524-156f-466d-b287-4258811444dd/restore
+func (a *AdminService) Restore(w http.ResponseWriter, r *http.Request) {
+	logger := a.logger.WithField("method", "restore")
+	logger.Info("restore entity")
+	entity, id, err := entityFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	err = a.store.Restore(r.Context(), entity, id)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(statusCode(err, http.StatusInternalServerError))
+		return
+	}
+	a.logger.Info("restore ", entity, " with id: ", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Purge extracts entity and id from URL and permanently removes the entry, if
+// it was deleted before the retention period. The content of attachments is
+// removed as well.
+func (a *AdminService) Purge(w http.ResponseWriter, r *http.Request) {
+	logger := a.logger.WithField("method", "purge")
+	logger.Info("purge entity")
+	entity, id, err := entityFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	err = a.store.Purge(r.Context(), entity, id, time.Now().Add(-a.retention))
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(statusCode(err, http.StatusInternalServerError))
+		return
+	}
+	if entity == model.EntityAttachment {
+		err = a.blobs.Delete(r.Context(), id)
+		if err != nil && !errors.Is(err, blob.ErrNotFound) {
+			logger.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+	a.logger.Info("purge ", entity, " with id: ", id)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// entityFromRequest reads entity and id of an entry from the given request.
+func entityFromRequest(r *http.Request) (model.Entity, string, error) {
+	vars := mux.Vars(r)
+	entity, err := model.ParseEntity(vars["entity"])
+	if err != nil {
+		return "", "", err
+	}
+	id, ok := vars["entityID"]
+	if !ok || len(id) == 0 {
+		return "", "", errors.New("could not extract entityID")
+	}
+	return entity, id, nil
+}
+
+// statusCode maps errors of restore and purge to http status codes, other
+// errors are mapped to fallback.
+func statusCode(err error, fallback int) int {
+	switch {
+	case errors.Is(err, model.ErrUnknownEntity):
+		return http.StatusBadRequest
+	case errors.Is(err, model.ErrEntityNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrNotDeleted),
+		errors.Is(err, model.ErrRetentionPeriod),
+		errors.Is(err, model.ErrEntityReferenced),
+		errors.Is(err, model.ErrOverlappingAbsence):
+		return http.StatusConflict
+	}
+	return fallback
+}
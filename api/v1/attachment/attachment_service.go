@@ -0,0 +1,304 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/MninaTB/vacadm/api/v1/util"
+	"github.com/MninaTB/vacadm/pkg/blob"
+	"github.com/MninaTB/vacadm/pkg/database"
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+// ChecksumHeader carries the hex encoded SHA-256 sum of a downloaded
+// attachment.
+const ChecksumHeader = "X-Checksum-Sha256"
+
+// NewAttachmentService returns an AttachmentService. The content of
+// attachments is kept in the given blob store, uploads have to match the
+// given policy.
+func NewAttachmentService(
+	store database.Database,
+	blobs blob.Store,
+	policy model.AttachmentPolicy,
+	logger logrus.FieldLogger,
+) *AttachmentService {
+	return &AttachmentService{
+		store:  store,
+		blobs:  blobs,
+		policy: policy,
+		logger: logger.WithField("component", "attachment-service"),
+	}
+}
+
+// AttachmentService implements http.HandlerFunc's to operate on the
+// attachments of vacation requests and vacations. The resource is taken from
+// the URL, either a vacationRequestID or a vacationID. Access to the user of
+// the URL is restricted by the auth middleware, therefore only the user and
+// its parents can read and write attachments.
+type AttachmentService struct {
+	store  database.Database
+	blobs  blob.Store
+	policy model.AttachmentPolicy
+	logger logrus.FieldLogger
+}
+
+// Create reads the request body as content of a new attachment of the
+// resource in the URL. The content type is detected from the content itself
+// and has to be part of the allowlist, the file name is given by the filename
+// query parameter.
+// Example request:
+// PUT /v1/user/{userID}/vacation/request/{vacationRequestID}/attachments?filename=certificate.pdf
+func (a *AttachmentService) Create(w http.ResponseWriter, r *http.Request) {
+	logger := a.logger.WithField("method", "create")
+	logger.Info("create new attachment")
+	kind, resourceID, userID, ok := a.resource(w, r, logger)
+	if !ok {
+		return
+	}
+	fileName := filepath.Base(r.URL.Query().Get("filename"))
+	if fileName == "." || fileName == string(filepath.Separator) {
+		logger.Error("missing query parameter filename")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	// NOTE: one byte more than allowed is read to detect oversized content.
+	content, err := io.ReadAll(io.LimitReader(r.Body, a.policy.MaxSize+1))
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	contentType := http.DetectContentType(content)
+	err = a.policy.Check(int64(len(content)), contentType)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(statusCode(err))
+		return
+	}
+	checksum := sha256.Sum256(content)
+	newAttachment, err := a.store.CreateAttachment(r.Context(), &model.Attachment{
+		ResourceKind: kind,
+		ResourceID:   resourceID,
+		UserID:       userID,
+		FileName:     fileName,
+		ContentType:  contentType,
+		Size:         int64(len(content)),
+		Checksum:     hex.EncodeToString(checksum[:]),
+	})
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(statusCode(err))
+		return
+	}
+	err = a.blobs.Put(r.Context(), newAttachment.ID, bytes.NewReader(content))
+	if err != nil {
+		logger.Error(err)
+		if errDel := a.store.DeleteAttachment(r.Context(), newAttachment.ID); errDel != nil {
+			logger.Error(errDel)
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(newAttachment)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	a.logger.Info("create attachment with ID: ", newAttachment.ID)
+}
+
+// List writes the metadata of all attachments of the resource in the URL into
+// the given response writer.
+func (a *AttachmentService) List(w http.ResponseWriter, r *http.Request) {
+	logger := a.logger.WithField("method", "list")
+	logger.Info("retrieve attachment list")
+	kind, resourceID, _, ok := a.resource(w, r, logger)
+	if !ok {
+		return
+	}
+	opts, err := util.QueryOptionsFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	list, err := a.store.ListAttachments(r.Context(), kind, resourceID, opts...)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(&list)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// Download writes the content of the attachment in the URL into the given
+// response writer. The checksum is passed in the ChecksumHeader.
+func (a *AttachmentService) Download(w http.ResponseWriter, r *http.Request) {
+	logger := a.logger.WithField("method", "download")
+	logger.Info("download attachment")
+	attachment, ok := a.attachment(w, r, logger)
+	if !ok {
+		return
+	}
+	content, err := a.blobs.Get(r.Context(), attachment.ID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	defer content.Close()
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": attachment.FileName,
+	}))
+	w.Header().Set(ChecksumHeader, attachment.Checksum)
+	_, err = io.Copy(w, content)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	a.logger.Info("download attachment with id: ", attachment.ID)
+}
+
+// Delete marks the attachment in the URL as deleted. The content is kept until
+// the attachment is purged.
+func (a *AttachmentService) Delete(w http.ResponseWriter, r *http.Request) {
+	logger := a.logger.WithField("method", "delete")
+	logger.Info("delete attachment")
+	attachment, ok := a.attachment(w, r, logger)
+	if !ok {
+		return
+	}
+	err := a.store.DeleteAttachment(r.Context(), attachment.ID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	a.logger.Info("delete attachment with id: ", attachment.ID)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// resource resolves the vacation-request or vacation of the URL and verifies
+// that it belongs to the user of the URL. If this is not the case, an error
+// code is written to the response writer and false is returned.
+func (a *AttachmentService) resource(
+	w http.ResponseWriter,
+	r *http.Request,
+	logger logrus.FieldLogger,
+) (model.AttachmentResource, string, string, bool) {
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return "", "", "", false
+	}
+	kind, resourceID, err := extractResource(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return "", "", "", false
+	}
+	ownerID, err := a.resourceOwner(r.Context(), kind, resourceID)
+	if err != nil || ownerID != userID {
+		logger.Errorf("no %s found: %v", kind, err)
+		w.WriteHeader(http.StatusNotFound)
+		return "", "", "", false
+	}
+	return kind, resourceID, userID, true
+}
+
+func (a *AttachmentService) resourceOwner(ctx context.Context, kind model.AttachmentResource, resourceID string) (string, error) {
+	if kind == model.AttachmentVacation {
+		vac, err := a.store.GetVacationByID(ctx, resourceID)
+		if err != nil {
+			return "", err
+		}
+		return vac.UserID, nil
+	}
+	vR, err := a.store.GetVacationRequestByID(ctx, resourceID)
+	if err != nil {
+		return "", err
+	}
+	return vR.UserID, nil
+}
+
+// attachment loads the attachment of the URL and verifies that it belongs to
+// the resource of the URL. If this is not the case, an error code is written
+// to the response writer and false is returned.
+func (a *AttachmentService) attachment(
+	w http.ResponseWriter,
+	r *http.Request,
+	logger logrus.FieldLogger,
+) (*model.Attachment, bool) {
+	kind, resourceID, _, ok := a.resource(w, r, logger)
+	if !ok {
+		return nil, false
+	}
+	attachmentID, err := extractAttachmentID(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, false
+	}
+	attachment, err := a.store.GetAttachmentByID(r.Context(), attachmentID)
+	if err != nil || attachment.ResourceKind != kind || attachment.ResourceID != resourceID {
+		logger.Error("no attachment found: ", err)
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+	return attachment, true
+}
+
+// statusCode maps policy and store errors to http status codes.
+func statusCode(err error) int {
+	if errors.Is(err, model.ErrAttachmentTooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	if errors.Is(err, model.ErrUnsupportedContentType) {
+		return http.StatusUnsupportedMediaType
+	}
+	if errors.Is(err, model.ErrInvalidAttachment) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func extractResource(r *http.Request) (model.AttachmentResource, string, error) {
+	vars := mux.Vars(r)
+	if vacationRequestID, ok := vars["vacationRequestID"]; ok {
+		return model.AttachmentVacationRequest, vacationRequestID, nil
+	}
+	if vacationID, ok := vars["vacationID"]; ok {
+		return model.AttachmentVacation, vacationID, nil
+	}
+	return "", "", errors.New("could not extract vacationRequestID or vacationID")
+}
+
+func extractAttachmentID(r *http.Request) (string, error) {
+	vars := mux.Vars(r)
+	attachmentID, ok := vars["attachmentID"]
+	if !ok {
+		return "", errors.New("could not extract attachmentID")
+	}
+	return attachmentID, nil
+}
@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/MninaTB/vacadm/pkg/database"
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+// NewAuditService returns an AuditService.
+func NewAuditService(store database.AuditStore, logger logrus.FieldLogger) *AuditService {
+	return &AuditService{
+		store:  store,
+		logger: logger.WithField("component", "audit-service"),
+	}
+}
+
+// AuditService implements http.HandlerFunc's to read the audit log.
+type AuditService struct {
+	store  database.AuditStore
+	logger logrus.FieldLogger
+}
+
+// List writes all audit entries matching the query parameters actor_id,
+// action, entity, entity_id, request_id, from and to into the given response
+// writer. from and to are RFC 3339 timestamps.
+// Example request:
+// GET /v1/audit?entity=user&entity_id=1ff63524-156f-466d-b287-4258811444dd&action=update
+func (a *AuditService) List(w http.ResponseWriter, r *http.Request) {
+	logger := a.logger.WithField("method", "list")
+	logger.Info("retrieve audit list")
+	filter, err := filterFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	list, err := a.store.ListAuditEntries(r.Context(), filter)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(&list)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	a.logger.Info("retrieve ", len(list), " audit entries")
+}
+
+// filterFromRequest reads the audit filter from the query parameters of the
+// given request.
+func filterFromRequest(r *http.Request) (model.AuditFilter, error) {
+	q := r.URL.Query()
+	filter := model.AuditFilter{
+		ActorID:   q.Get("actor_id"),
+		EntityID:  q.Get("entity_id"),
+		RequestID: q.Get("request_id"),
+	}
+	var err error
+	if raw := q.Get("action"); raw != "" {
+		filter.Action, err = model.ParseAuditAction(raw)
+		if err != nil {
+			return filter, err
+		}
+	}
+	if raw := q.Get("entity"); raw != "" {
+		filter.Entity, err = model.ParseEntity(raw)
+		if err != nil {
+			return filter, err
+		}
+	}
+	filter.From, err = timeFromQuery(r, "from")
+	if err != nil {
+		return filter, err
+	}
+	filter.To, err = timeFromQuery(r, "to")
+	return filter, err
+}
+
+// timeFromQuery reads the RFC 3339 timestamp of the given query parameter,
+// nil if the parameter is missing.
+func timeFromQuery(r *http.Request, key string) (*time.Time, error) {
+	raw := r.URL.Query().Get(key)
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", key, err)
+	}
+	return &t, nil
+}
@@ -0,0 +1,194 @@
+package delegation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/MninaTB/vacadm/api/v1/util"
+	"github.com/MninaTB/vacadm/pkg/database"
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+// NewDelegationService returns a DelegationService.
+func NewDelegationService(
+	store database.Database,
+	logger logrus.FieldLogger,
+) *DelegationService {
+	return &DelegationService{
+		store:  store,
+		logger: logger.WithField("component", "delegation-service"),
+	}
+}
+
+// DelegationService implements http.HandlerFunc's to operate on the
+// delegations of approval rights.
+type DelegationService struct {
+	store  database.Database
+	logger logrus.FieldLogger
+}
+
+// Create reads the given payload and delegates the approval rights of the user
+// in the URL to the given delegate for the given period.
+// Example request:
+// PUT /v1/user/{userID}/delegation
+// {"delegate_id": "...", "from": "2022-08-01T00:00:00Z", "to": "2022-08-14T00:00:00Z"}
+func (d *DelegationService) Create(w http.ResponseWriter, r *http.Request) {
+	logger := d.logger.WithField("method", "create")
+	logger.Info("create new delegation")
+	var del model.Delegation
+	err := json.NewDecoder(r.Body).Decode(&del)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.Error(err)
+		return
+	}
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.Error(err)
+		return
+	}
+	del.DelegatorID = userID
+	_, err = d.store.GetUserByID(r.Context(), del.DelegateID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.Error(err)
+		return
+	}
+	newDel, err := d.store.CreateDelegation(r.Context(), &del)
+	if errors.Is(err, model.ErrInvalidDelegation) {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.Error(err)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(newDel)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	d.logger.Info("create delegation with ID: ", newDel.ID)
+}
+
+// GetByID extracts a delegationID from URL and writes the delegation into the
+// given response writer. Only delegations of the user in the URL are returned.
+func (d *DelegationService) GetByID(w http.ResponseWriter, r *http.Request) {
+	logger := d.logger.WithField("method", "read")
+	logger.Info("get delegation by id")
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	delID, err := extractDelegationID(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	opts, err := util.QueryOptionsFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	del, err := d.store.GetDelegationByID(r.Context(), delID, opts...)
+	if err != nil || (del.DelegatorID != userID && del.DelegateID != userID) {
+		logger.Error("no delegation found: ", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	err = json.NewEncoder(w).Encode(del)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// List writes all delegations, the user in the URL delegated or received,
+// into the given response writer.
+func (d *DelegationService) List(w http.ResponseWriter, r *http.Request) {
+	logger := d.logger.WithField("method", "list")
+	logger.Info("retrieve delegation list")
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	opts, err := util.QueryOptionsFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	list, err := d.store.ListDelegations(r.Context(), opts...)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	filtered := make([]*model.Delegation, 0, len(list))
+	for _, del := range list {
+		if del.DelegatorID == userID || del.DelegateID == userID {
+			filtered = append(filtered, del)
+		}
+	}
+	err = json.NewEncoder(w).Encode(&filtered)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// Delete revokes the delegation associated to the given delegationID in the
+// URL. Only the delegating user can revoke a delegation.
+func (d *DelegationService) Delete(w http.ResponseWriter, r *http.Request) {
+	logger := d.logger.WithField("method", "delete")
+	logger.Info("delete delegation")
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	delID, err := extractDelegationID(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	del, err := d.store.GetDelegationByID(r.Context(), delID)
+	if err != nil || del.DelegatorID != userID {
+		logger.Error("no delegation found: ", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	err = d.store.DeleteDelegation(r.Context(), delID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	d.logger.Info("delete delegation with id: ", delID)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func extractDelegationID(r *http.Request) (string, error) {
+	vars := mux.Vars(r)
+	delegationID, ok := vars["delegationID"]
+	if !ok {
+		return "", errors.New("could not extract delegationID")
+	}
+	return delegationID, nil
+}
@@ -0,0 +1,80 @@
+package holiday
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/MninaTB/vacadm/api/v1/util"
+	"github.com/MninaTB/vacadm/pkg/holiday"
+)
+
+// NewHolidayService returns a HolidayService.
+func NewHolidayService(logger logrus.FieldLogger) *HolidayService {
+	return &HolidayService{
+		logger: logger.WithField("component", "holiday-service"),
+	}
+}
+
+// HolidayService implements http.HandlerFunc's to read holiday calendars.
+type HolidayService struct {
+	logger logrus.FieldLogger
+}
+
+type calendarResponse struct {
+	ID       string        `json:"id"`
+	Name     string        `json:"name"`
+	Holidays []holiday.Day `json:"holidays,omitempty"`
+}
+
+// List returns all available holiday calendars. The id of a calendar can be
+// attached to users and teams.
+func (h *HolidayService) List(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithField("method", "list")
+	logger.Info("retrieve holiday calendar list")
+	list := []*calendarResponse{}
+	for _, c := range holiday.Calendars() {
+		list = append(list, &calendarResponse{ID: c.ID, Name: c.Name})
+	}
+	err := json.NewEncoder(w).Encode(&list)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// GetByID extracts a calendarID from URL and writes all holidays of the
+// requested year into the given response writer. If no year is provided, the
+// current year is used.
+func (h *HolidayService) GetByID(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger.WithField("method", "read")
+	logger.Info("get holiday calendar by id")
+	calendarID, err := util.CalendarIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	year, err := util.YearFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c, err := holiday.Lookup(calendarID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	err = json.NewEncoder(w).Encode(&calendarResponse{
+		ID:       c.ID,
+		Name:     c.Name,
+		Holidays: c.Days(year),
+	})
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
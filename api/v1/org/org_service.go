@@ -0,0 +1,102 @@
+package org
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/MninaTB/vacadm/api/v1/util"
+	"github.com/MninaTB/vacadm/pkg/database"
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+// ContentTypeDOT is the content type of org charts in the DOT language of
+// graphviz.
+const ContentTypeDOT = "text/vnd.graphviz"
+
+// NewOrgService returns an OrgService.
+func NewOrgService(store database.Database, logger logrus.FieldLogger) *OrgService {
+	return &OrgService{
+		store:  store,
+		logger: logger.WithField("component", "org-service"),
+	}
+}
+
+// OrgService implements http.HandlerFunc's to read the reporting lines of all
+// users.
+type OrgService struct {
+	store  database.Database
+	logger logrus.FieldLogger
+}
+
+// Get writes the reporting tree of all users as nested JSON into the given
+// response writer. If a userID is provided in the URL, only the subtree of the
+// user is written. Requests accepting text/vnd.graphviz receive the tree as
+// DOT graph.
+// Example response:
+// [{"id":"1ff63524-156f-466d-b287-4258811444dd", "first_name":"Max", ...,
+// "reports":[{"id":"f5742f08-55ae-41f9-bca0-3600b466106c", ..., "reports":[]}]}]
+func (o *OrgService) Get(w http.ResponseWriter, r *http.Request) {
+	logger := o.logger.WithField("method", "read")
+	logger.Info("get org chart")
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil && err != util.ErrDoesNotExistUserID {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	users, err := o.store.ListUsers(r.Context())
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	chart := model.OrgChart(users, userID)
+	if userID != "" && len(chart) == 0 {
+		logger.Error("no user found with id: ", userID)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if strings.Contains(r.Header.Get("Accept"), ContentTypeDOT) {
+		w.Header().Set("Content-Type", ContentTypeDOT)
+		if err := writeDOT(w, chart); err != nil {
+			logger.Error(err)
+		}
+		return
+	}
+	err = json.NewEncoder(w).Encode(&chart)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// writeDOT writes the given chart as directed graph, edges point from managers
+// to their reports.
+func writeDOT(w io.Writer, chart []*model.OrgNode) error {
+	var sb strings.Builder
+	sb.WriteString("digraph org {\n")
+	var walk func(nodes []*model.OrgNode)
+	walk = func(nodes []*model.OrgNode) {
+		for _, n := range nodes {
+			label := strings.TrimSpace(n.FirstName + " " + n.LastName)
+			if label == "" {
+				label = n.Email
+			}
+			fmt.Fprintf(&sb, "\t%s [label=%s];\n", strconv.Quote(n.ID), strconv.Quote(label))
+			for _, report := range n.Reports {
+				fmt.Fprintf(&sb, "\t%s -> %s;\n", strconv.Quote(n.ID), strconv.Quote(report.ID))
+			}
+			walk(n.Reports)
+		}
+	}
+	walk(chart)
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -2,16 +2,28 @@ package v1
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 
+	absencetype "github.com/MninaTB/vacadm/api/v1/absence_type"
+	"github.com/MninaTB/vacadm/api/v1/admin"
+	"github.com/MninaTB/vacadm/api/v1/attachment"
+	"github.com/MninaTB/vacadm/api/v1/audit"
+	"github.com/MninaTB/vacadm/api/v1/delegation"
+	"github.com/MninaTB/vacadm/api/v1/holiday"
+	"github.com/MninaTB/vacadm/api/v1/org"
 	"github.com/MninaTB/vacadm/api/v1/team"
+	teammembership "github.com/MninaTB/vacadm/api/v1/team_membership"
+	teamrule "github.com/MninaTB/vacadm/api/v1/team_rule"
 	"github.com/MninaTB/vacadm/api/v1/user"
 	"github.com/MninaTB/vacadm/api/v1/vacation"
 	vacationrequest "github.com/MninaTB/vacadm/api/v1/vacation_request"
 	vacationresources "github.com/MninaTB/vacadm/api/v1/vacation_resource"
+	"github.com/MninaTB/vacadm/pkg/blob"
 	"github.com/MninaTB/vacadm/pkg/database"
+	"github.com/MninaTB/vacadm/pkg/model"
 	"github.com/MninaTB/vacadm/pkg/notify"
 )
 
@@ -22,19 +34,45 @@ type TokenValidator interface {
 	Valid(token string) (userID string, teamID string, err error)
 }
 
+// Config contains the business rules of the v1 api.
+type Config struct {
+	// Rounding is applied on prorated vacation entitlements.
+	Rounding database.Rounding
+	// CarryOver defines cap and expiry of days carried over into the next
+	// year.
+	CarryOver database.CarryOverPolicy
+	// ApprovalPolicy defines the approval chain of vacation requests.
+	ApprovalPolicy model.ApprovalPolicy
+	// AutoApproval defines low-risk vacation requests, which are approved
+	// without approval chain.
+	AutoApproval model.AutoApprovalPolicy
+	// Attachments limits the size and content types of uploaded documents.
+	Attachments model.AttachmentPolicy
+	// Retention is the period, soft deleted entries are kept before they can
+	// be purged.
+	Retention time.Duration
+}
+
 type server struct {
 	logger   logrus.FieldLogger
 	db       database.Database
 	mw       []mux.MiddlewareFunc
 	tv       TokenValidator
 	notifier notify.Notifier
+	blobs    blob.Store
+	audits   database.AuditStore
+	cfg      Config
 }
 
-// NewServer returns a new http.Handler.
+// NewServer returns a new http.Handler. Every mutation of db is recorded in
+// audits.
 func NewServer(
 	db database.Database,
 	notifier notify.Notifier,
+	blobs blob.Store,
+	audits database.AuditStore,
 	tokenValidator TokenValidator,
+	cfg Config,
 	middleware ...mux.MiddlewareFunc,
 ) http.Handler {
 	return &server{
@@ -42,7 +80,10 @@ func NewServer(
 		mw:       middleware,
 		db:       db,
 		notifier: notifier,
+		blobs:    blobs,
+		audits:   audits,
 		tv:       tokenValidator,
+		cfg:      cfg,
 	}
 }
 
@@ -51,15 +92,40 @@ func NewServer(
 // When there is a match, the route variables can be retrieved calling
 // mux.Vars(request).
 func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	usrSvc := user.NewUserService(s.db, s.logger)
+	s.router().ServeHTTP(w, r)
+}
+
+// router returns a mux.Router with all routes of the v1 api.
+func (s *server) router() *mux.Router {
+	db := database.NewAuditDB(s.db, s.audits)
+
+	usrSvc := user.NewUserService(db, s.logger, s.tv)
+
+	orgSvc := org.NewOrgService(db, s.logger)
+
+	teamSvc := team.NewTeamService(db, s.logger, s.tv)
+
+	teamRuleSvc := teamrule.NewTeamRuleService(db, s.logger, s.tv)
 
-	teamSvc := team.NewTeamService(s.db, s.logger, s.tv)
+	teamMembershipSvc := teammembership.NewTeamMembershipService(db, s.logger, s.tv)
 
-	vacSvc := vacation.NewVacationService(s.db, s.logger)
+	vacSvc := vacation.NewVacationService(db, s.cfg.Rounding, s.cfg.CarryOver, s.logger)
 
-	vacReqSvc := vacationrequest.NewVacationRequestService(s.db, s.notifier, s.logger)
+	vacReqSvc := vacationrequest.NewVacationRequestService(db, s.notifier, s.cfg.ApprovalPolicy, s.cfg.AutoApproval, s.logger, s.tv)
 
-	vacResSvc := vacationresources.NewVacationResourceService(s.db, s.logger)
+	vacResSvc := vacationresources.NewVacationResourceService(db, s.logger)
+
+	holidaySvc := holiday.NewHolidayService(s.logger)
+
+	absenceTypeSvc := absencetype.NewAbsenceTypeService(db, s.logger)
+
+	delegationSvc := delegation.NewDelegationService(db, s.logger)
+
+	attachmentSvc := attachment.NewAttachmentService(db, s.blobs, s.cfg.Attachments, s.logger)
+
+	adminSvc := admin.NewAdminService(db, s.blobs, s.cfg.Retention, s.logger)
+
+	auditSvc := audit.NewAuditService(s.audits, s.logger)
 
 	router := mux.NewRouter()
 	router.Path("/user").Methods(http.MethodPut).HandlerFunc(usrSvc.Create)
@@ -67,6 +133,10 @@ func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	router.Path("/user").Methods(http.MethodGet).HandlerFunc(usrSvc.List)
 	router.Path("/user/{userID}").Methods(http.MethodPatch).HandlerFunc(usrSvc.Update)
 	router.Path("/user/{userID}").Methods(http.MethodDelete).HandlerFunc(usrSvc.Delete)
+	router.Path("/user/{userID}/role").Methods(http.MethodPut).HandlerFunc(usrSvc.UpdateRole)
+
+	router.Path("/org").Methods(http.MethodGet).HandlerFunc(orgSvc.Get)
+	router.Path("/org/{userID}").Methods(http.MethodGet).HandlerFunc(orgSvc.Get)
 
 	router.Path("/team").Methods(http.MethodPut).HandlerFunc(teamSvc.Create)
 	router.Path("/team/{teamID}").Methods(http.MethodGet).HandlerFunc(teamSvc.GetByID)
@@ -77,25 +147,77 @@ func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	router.Path("/team/{teamID}").Methods(http.MethodPatch).HandlerFunc(teamSvc.Update)
 	router.Path("/team/{teamID}").Methods(http.MethodDelete).HandlerFunc(teamSvc.Delete)
 
-	router.Path("/user/{userID}/vacation/{vacationID}").Methods(http.MethodGet).HandlerFunc(vacSvc.GetByID)
+	router.Path("/team/{teamID}/rules").Methods(http.MethodPut).HandlerFunc(teamRuleSvc.Create)
+	router.Path("/team/{teamID}/rules").Methods(http.MethodGet).HandlerFunc(teamRuleSvc.List)
+	router.Path("/team/{teamID}/rules/{teamRuleID}").Methods(http.MethodGet).HandlerFunc(teamRuleSvc.GetByID)
+	router.Path("/team/{teamID}/rules/{teamRuleID}").Methods(http.MethodPatch).HandlerFunc(teamRuleSvc.Update)
+	router.Path("/team/{teamID}/rules/{teamRuleID}").Methods(http.MethodDelete).HandlerFunc(teamRuleSvc.Delete)
+
+	router.Path("/team/{teamID}/members").Methods(http.MethodPut).HandlerFunc(teamMembershipSvc.Create)
+	router.Path("/team/{teamID}/members").Methods(http.MethodGet).HandlerFunc(teamMembershipSvc.List)
+	router.Path("/team/{teamID}/members/{teamMembershipID}").Methods(http.MethodPatch).HandlerFunc(teamMembershipSvc.Update)
+	router.Path("/team/{teamID}/members/{teamMembershipID}").Methods(http.MethodDelete).HandlerFunc(teamMembershipSvc.Delete)
+
+	router.Path("/user/{userID}/delegation").Methods(http.MethodPut).HandlerFunc(delegationSvc.Create)
+	router.Path("/user/{userID}/delegation").Methods(http.MethodGet).HandlerFunc(delegationSvc.List)
+	router.Path("/user/{userID}/delegation/{delegationID}").Methods(http.MethodGet).HandlerFunc(delegationSvc.GetByID)
+	router.Path("/user/{userID}/delegation/{delegationID}").Methods(http.MethodDelete).HandlerFunc(delegationSvc.Delete)
+
+	router.Path("/user/{userID}/deputy").Methods(http.MethodGet).HandlerFunc(vacReqSvc.ListDeputyRequests)
+	router.Path("/user/{userID}/deputy/{vacationRequestID}/accept").Methods(http.MethodPut).HandlerFunc(vacReqSvc.AcceptDeputy)
+	router.Path("/user/{userID}/deputy/{vacationRequestID}/decline").Methods(http.MethodPut).HandlerFunc(vacReqSvc.DeclineDeputy)
+
+	router.Path("/user/{userID}/vacation/balance").Methods(http.MethodGet).HandlerFunc(vacSvc.Balance)
 	router.Path("/user/{userID}/vacation").Methods(http.MethodGet).HandlerFunc(vacSvc.List)
-	router.Path("/user/{userID}/vacation/{vacationID}").Methods(http.MethodDelete).HandlerFunc(vacSvc.Delete)
+	router.Path("/user/{userID}/vacation/carry-over").Methods(http.MethodPut).HandlerFunc(vacSvc.CarryOver)
 
 	router.Path("/user/{userID}/vacation/request").Methods(http.MethodPut).HandlerFunc(vacReqSvc.Create)
-	router.Path("/user/{userID}/vacation/request/{vacation-requestID}").Methods(http.MethodGet).HandlerFunc(vacReqSvc.GetByID)
-	router.Path("/user/{userID}/vacation/request/{vacation-requestID}/approve/{parentID}").Methods(http.MethodPut).HandlerFunc(vacReqSvc.Approve)
+	router.Path("/user/{userID}/vacation/request/{vacationRequestID}").Methods(http.MethodGet).HandlerFunc(vacReqSvc.GetByID)
+	router.Path("/user/{userID}/vacation/request/{vacationRequestID}/approve/{parentID}").Methods(http.MethodPut).HandlerFunc(vacReqSvc.Approve)
+	router.Path("/user/{userID}/vacation/request/{vacationRequestID}/reject/{parentID}").Methods(http.MethodPut).HandlerFunc(vacReqSvc.Reject)
 	router.Path("/user/{userID}/vacation/request").Methods(http.MethodGet).HandlerFunc(vacReqSvc.List)
-	router.Path("/user/{userID}/vacation/request/{vacation-requestID}").Methods(http.MethodPatch).HandlerFunc(vacReqSvc.Update)
-	router.Path("/user/{userID}/vacation/request/{vacation-requestID}").Methods(http.MethodDelete).HandlerFunc(vacReqSvc.Delete)
+	router.Path("/user/{userID}/vacation/request/{vacationRequestID}").Methods(http.MethodPatch).HandlerFunc(vacReqSvc.Update)
+	router.Path("/user/{userID}/vacation/request/{vacationRequestID}").Methods(http.MethodDelete).HandlerFunc(vacReqSvc.Delete)
+	router.Path("/user/{userID}/vacation/request/{vacationRequestID}/submit").Methods(http.MethodPut).HandlerFunc(vacReqSvc.Submit)
+	router.Path("/user/{userID}/vacation/request/{vacationRequestID}/withdraw").Methods(http.MethodPut).HandlerFunc(vacReqSvc.Withdraw)
+	router.Path("/user/{userID}/vacation/request/{vacationRequestID}/cancel").Methods(http.MethodPut).HandlerFunc(vacReqSvc.Cancel)
+	router.Path("/user/{userID}/vacation/request/{vacationRequestID}/comments").Methods(http.MethodPut).HandlerFunc(vacReqSvc.CreateComment)
+	router.Path("/user/{userID}/vacation/request/{vacationRequestID}/comments").Methods(http.MethodGet).HandlerFunc(vacReqSvc.ListComments)
+	router.Path("/user/{userID}/vacation/request/{vacationRequestID}/attachments").Methods(http.MethodPut).HandlerFunc(attachmentSvc.Create)
+	router.Path("/user/{userID}/vacation/request/{vacationRequestID}/attachments").Methods(http.MethodGet).HandlerFunc(attachmentSvc.List)
+	router.Path("/user/{userID}/vacation/request/{vacationRequestID}/attachments/{attachmentID}").Methods(http.MethodGet).HandlerFunc(attachmentSvc.Download)
+	router.Path("/user/{userID}/vacation/request/{vacationRequestID}/attachments/{attachmentID}").Methods(http.MethodDelete).HandlerFunc(attachmentSvc.Delete)
 
 	router.Path("/user/{userID}/vacation/resource").Methods(http.MethodPut).HandlerFunc(vacResSvc.Create)
-	router.Path("/user/{userID}/vacation/resource/{vacation-resourceID}").Methods(http.MethodGet).HandlerFunc(vacResSvc.GetByID)
+	router.Path("/user/{userID}/vacation/resource/{vacationResourceID}").Methods(http.MethodGet).HandlerFunc(vacResSvc.GetByID)
 	router.Path("/user/{userID}/vacation/resource").Methods(http.MethodGet).HandlerFunc(vacResSvc.List)
-	router.Path("/user/{userID}/vacation/resource/{vacation-resourceID}").Methods(http.MethodPatch).HandlerFunc(vacResSvc.Update)
-	router.Path("/user/{userID}/vacation/resource/{vacation-resourceID}").Methods(http.MethodDelete).HandlerFunc(vacResSvc.Delete)
+	router.Path("/user/{userID}/vacation/resource/{vacationResourceID}").Methods(http.MethodPatch).HandlerFunc(vacResSvc.Update)
+	router.Path("/user/{userID}/vacation/resource/{vacationResourceID}").Methods(http.MethodDelete).HandlerFunc(vacResSvc.Delete)
+
+	// NOTE: must be registered after request and resource routes, otherwise
+	// "request" and "resource" would be matched as vacationID.
+	router.Path("/user/{userID}/vacation/{vacationID}").Methods(http.MethodGet).HandlerFunc(vacSvc.GetByID)
+	router.Path("/user/{userID}/vacation/{vacationID}").Methods(http.MethodDelete).HandlerFunc(vacSvc.Delete)
+	router.Path("/user/{userID}/vacation/{vacationID}/attachments").Methods(http.MethodPut).HandlerFunc(attachmentSvc.Create)
+	router.Path("/user/{userID}/vacation/{vacationID}/attachments").Methods(http.MethodGet).HandlerFunc(attachmentSvc.List)
+	router.Path("/user/{userID}/vacation/{vacationID}/attachments/{attachmentID}").Methods(http.MethodGet).HandlerFunc(attachmentSvc.Download)
+	router.Path("/user/{userID}/vacation/{vacationID}/attachments/{attachmentID}").Methods(http.MethodDelete).HandlerFunc(attachmentSvc.Delete)
+
+	router.Path("/holiday-calendar").Methods(http.MethodGet).HandlerFunc(holidaySvc.List)
+	router.Path("/holiday-calendar/{calendarID}").Methods(http.MethodGet).HandlerFunc(holidaySvc.GetByID)
+
+	router.Path("/absence-type").Methods(http.MethodPut).HandlerFunc(absenceTypeSvc.Create)
+	router.Path("/absence-type/{absenceTypeID}").Methods(http.MethodGet).HandlerFunc(absenceTypeSvc.GetByID)
+	router.Path("/absence-type").Methods(http.MethodGet).HandlerFunc(absenceTypeSvc.List)
+	router.Path("/absence-type/{absenceTypeID}").Methods(http.MethodPatch).HandlerFunc(absenceTypeSvc.Update)
+	router.Path("/absence-type/{absenceTypeID}").Methods(http.MethodDelete).HandlerFunc(absenceTypeSvc.Delete)
+
+	router.Path("/admin/{entity}/{entityID}/restore").Methods(http.MethodPut).HandlerFunc(adminSvc.Restore)
+	router.Path("/admin/{entity}/{entityID}").Methods(http.MethodDelete).HandlerFunc(adminSvc.Purge)
+
+	router.Path("/audit").Methods(http.MethodGet).HandlerFunc(auditSvc.List)
 	if s.mw != nil {
 		router.Use(s.mw...)
 	}
-
-	router.ServeHTTP(w, r)
+	return router
 }
@@ -0,0 +1,278 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/MninaTB/vacadm/pkg/database/inmemory"
+	"github.com/MninaTB/vacadm/pkg/jwt"
+	"github.com/MninaTB/vacadm/pkg/middleware"
+	"github.com/MninaTB/vacadm/pkg/model"
+	"github.com/MninaTB/vacadm/pkg/notify"
+	"github.com/MninaTB/vacadm/pkg/policy"
+)
+
+func TestServer_RoutePolicies(t *testing.T) {
+	s := NewServer(inmemory.NewInmemoryDB(), nil, nil, nil, nil, Config{}).(*server)
+	var count int
+	err := s.router().Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+		for _, method := range methods {
+			count++
+			if _, ok := policy.Lookup(method, path); !ok {
+				t.Errorf("missing policy for route %s %s", method, path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != len(policy.Routes) {
+		t.Fatalf("policies of unknown routes, want: %d, got: %d", count, len(policy.Routes))
+	}
+}
+
+// testServer returns a server protected by the auth middleware and a function
+// to send authorized requests as the given user.
+func testServer(t *testing.T, db *inmemory.InmemoryDB) func(u *model.User, method, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	tok := jwt.NewTokenizer([]byte("secret"), time.Hour)
+	s := NewServer(db, notify.NewNoopNotifier(), nil, db, tok, Config{}, middleware.Auth(tok, policy.NewEngine(db)))
+	return func(u *model.User, method, path string, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		var buf bytes.Buffer
+		if body != nil {
+			if err := json.NewEncoder(&buf).Encode(body); err != nil {
+				t.Fatal(err)
+			}
+		}
+		req, err := http.NewRequest(method, path, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, err := tok.Generate(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("If-Match", "*")
+		rr := httptest.NewRecorder()
+		s.ServeHTTP(rr, req)
+		return rr
+	}
+}
+
+func TestServer_UpdateUser(t *testing.T) {
+	ctx := context.Background()
+	db := inmemory.NewInmemoryDB()
+	mustUser := func(u *model.User) *model.User {
+		t.Helper()
+		u, err := db.CreateUser(ctx, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+	manager := mustUser(&model.User{Email: "manager@inform.de"})
+	owner := mustUser(&model.User{Email: "owner@inform.de"})
+	team, err := db.CreateTeam(ctx, &model.Team{Name: "backend", OwnerID: manager.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	employee := mustUser(&model.User{Email: "employee@inform.de", FirstName: "employee", ParentID: &manager.ID})
+	colleague := mustUser(&model.User{Email: "colleague@inform.de", FirstName: "colleague", ParentID: &manager.ID})
+	delegate := mustUser(&model.User{Email: "delegate@inform.de", ParentID: &owner.ID})
+	now := time.Now()
+	_, err = db.CreateDelegation(ctx, &model.Delegation{
+		DelegatorID: manager.ID,
+		DelegateID:  delegate.ID,
+		From:        now.AddDate(0, 0, -1),
+		To:          now.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	do := testServer(t, db)
+
+	// NOTE: the user of the URL is updated, the id of the payload is ignored.
+	rr := do(employee, http.MethodPatch, "/user/"+employee.ID, &model.User{ID: colleague.ID, FirstName: "forged"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	got, err := db.GetUserByID(ctx, colleague.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.FirstName != "colleague" {
+		t.Fatalf("expected foreign user to be unchanged, got: %s", got.FirstName)
+	}
+	got, err = db.GetUserByID(ctx, employee.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.FirstName != "forged" {
+		t.Fatalf("expected user of the URL to be updated, got: %s", got.FirstName)
+	}
+
+	for name, u := range map[string]*model.User{
+		"change own parent": {ParentID: &owner.ID},
+		"change own team":   {TeamID: &team.ID},
+	} {
+		rr = do(employee, http.MethodPatch, "/user/"+employee.ID, u)
+		if rr.Code != http.StatusForbidden {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v", name, rr.Code, http.StatusForbidden)
+		}
+	}
+
+	rr = do(manager, http.MethodPatch, "/user/"+employee.ID, &model.User{TeamID: &team.ID})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	// NOTE: delegates take over approvals, they do not manage the users.
+	rr = do(delegate, http.MethodPatch, "/user/"+colleague.ID, &model.User{FirstName: "forged"})
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusForbidden)
+	}
+	rr = do(delegate, http.MethodDelete, "/user/"+colleague.ID, nil)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusForbidden)
+	}
+	rr = do(delegate, http.MethodGet, "/user/"+colleague.ID, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+}
+
+func TestServer_ForeignEntries(t *testing.T) {
+	ctx := context.Background()
+	db := inmemory.NewInmemoryDB()
+	mustUser := func(u *model.User) *model.User {
+		t.Helper()
+		u, err := db.CreateUser(ctx, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+	manager := mustUser(&model.User{Email: "manager@inform.de"})
+	owner := mustUser(&model.User{Email: "owner@inform.de"})
+	employee := mustUser(&model.User{Email: "employee@inform.de", ParentID: &manager.ID})
+	stranger := mustUser(&model.User{Email: "stranger@inform.de", ParentID: &owner.ID})
+	monday := time.Date(2022, time.April, 4, 0, 0, 0, 0, time.UTC)
+	vR, err := db.CreateVacationRequest(ctx, &model.VacationRequest{UserID: stranger.ID, From: monday, To: monday})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vacation, err := db.CreateVacation(ctx, &model.Vacation{UserID: stranger.ID, ApprovedBy: &owner.ID, From: monday, To: monday})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resource, err := db.CreateVacationResource(ctx, &model.VacationResource{UserID: stranger.ID, YearlyDays: 30, From: monday})
+	if err != nil {
+		t.Fatal(err)
+	}
+	do := testServer(t, db)
+
+	// NOTE: the users are authorized for the user of the URL, the entries of
+	// other users must not be reachable through it.
+	tt := []struct {
+		name   string
+		user   *model.User
+		method string
+		path   string
+		body   interface{}
+	}{
+		{name: "read request", user: employee, method: http.MethodGet, path: "/user/" + employee.ID + "/vacation/request/" + vR.ID},
+		{name: "update request", user: employee, method: http.MethodPatch, path: "/user/" + employee.ID + "/vacation/request/" + vR.ID, body: &model.VacationRequest{ID: vR.ID, To: monday.AddDate(0, 0, 1)}},
+		{name: "delete request", user: employee, method: http.MethodDelete, path: "/user/" + employee.ID + "/vacation/request/" + vR.ID},
+		{name: "read vacation", user: manager, method: http.MethodGet, path: "/user/" + employee.ID + "/vacation/" + vacation.ID},
+		{name: "delete vacation", user: manager, method: http.MethodDelete, path: "/user/" + employee.ID + "/vacation/" + vacation.ID},
+		{name: "read resource", user: manager, method: http.MethodGet, path: "/user/" + employee.ID + "/vacation/resource/" + resource.ID},
+		{name: "update resource", user: manager, method: http.MethodPatch, path: "/user/" + employee.ID + "/vacation/resource/" + resource.ID, body: &model.VacationResource{ID: resource.ID, YearlyDays: 1, From: monday}},
+		{name: "delete resource", user: manager, method: http.MethodDelete, path: "/user/" + employee.ID + "/vacation/resource/" + resource.ID},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := do(tc.user, tc.method, tc.path, tc.body)
+			if rr.Code != http.StatusNotFound {
+				t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+			}
+		})
+	}
+
+	gotVR, err := db.GetVacationRequestByID(ctx, vR.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gotVR.To.Equal(monday) || gotVR.Version != vR.Version {
+		t.Fatalf("expected foreign vacation-request to be unchanged, got: %+v", gotVR)
+	}
+	if _, err = db.GetVacationByID(ctx, vacation.ID); err != nil {
+		t.Fatalf("expected foreign vacation to be kept, got: %v", err)
+	}
+	gotResource, err := db.GetVacationResourceByID(ctx, resource.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotResource.YearlyDays != 30 || gotResource.Version != resource.Version {
+		t.Fatalf("expected foreign vacation-resource to be unchanged, got: %+v", gotResource)
+	}
+}
+
+func TestServer_UpdateTeam(t *testing.T) {
+	ctx := context.Background()
+	db := inmemory.NewInmemoryDB()
+	owner, err := db.CreateUser(ctx, &model.User{Email: "owner@inform.de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stranger, err := db.CreateUser(ctx, &model.User{Email: "stranger@inform.de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	own, err := db.CreateTeam(ctx, &model.Team{Name: "backend", OwnerID: owner.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign, err := db.CreateTeam(ctx, &model.Team{Name: "sales", OwnerID: stranger.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	do := testServer(t, db)
+
+	// NOTE: the team of the URL is updated, the id of the payload is ignored.
+	rr := do(owner, http.MethodPatch, "/team/"+own.ID, &model.Team{ID: foreign.ID, Name: "hijacked"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	got, err := db.GetTeamByID(ctx, foreign.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "sales" || got.Version != foreign.Version {
+		t.Fatalf("expected foreign team to be unchanged, got: %+v", got)
+	}
+	got, err = db.GetTeamByID(ctx, own.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "hijacked" {
+		t.Fatalf("expected team of the URL to be updated, got: %s", got.Name)
+	}
+}
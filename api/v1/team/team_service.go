@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -14,6 +16,7 @@ import (
 	"github.com/MninaTB/vacadm/pkg/database"
 	"github.com/MninaTB/vacadm/pkg/jwt"
 	"github.com/MninaTB/vacadm/pkg/model"
+	"github.com/MninaTB/vacadm/pkg/policy"
 )
 
 // Tokenizer implements methods to verify auth tokens.
@@ -28,6 +31,8 @@ func NewTeamService(store database.Database, logger logrus.FieldLogger, t Tokeni
 	return &TeamService{
 		store:         store,
 		relationStore: database.NewRelationDB(store),
+		calendarStore: database.NewCalendarDB(store),
+		engine:        policy.NewEngine(store),
 		tokenizer:     t,
 		logger:        logger.WithField("component", "team-service"),
 	}
@@ -37,11 +42,14 @@ func NewTeamService(store database.Database, logger logrus.FieldLogger, t Tokeni
 type TeamService struct {
 	store         database.Database
 	relationStore database.RelationDB
+	calendarStore database.CalendarDB
+	engine        policy.Engine
 	logger        logrus.FieldLogger
 	tokenizer     Tokenizer
 }
 
 // Create reads the given payload and creates a store representation accordingly.
+// The optional parent_id places the team into a department.
 func (t *TeamService) Create(w http.ResponseWriter, r *http.Request) {
 	logger := t.logger.WithField("method", "create")
 	logger.Info("create new team")
@@ -52,10 +60,16 @@ func (t *TeamService) Create(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	err = util.ValidHolidayCalendar(team.HolidayCalendar)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 	tm, err := t.store.CreateTeam(r.Context(), &team)
 	if err != nil {
 		logger.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(statusCode(err, http.StatusInternalServerError))
 		return
 	}
 	err = json.NewEncoder(w).Encode(tm)
@@ -79,12 +93,19 @@ func (t *TeamService) GetByID(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	team, err := t.store.GetTeamByID(r.Context(), teamID)
+	opts, err := util.QueryOptionsFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	team, err := t.store.GetTeamByID(r.Context(), teamID, opts...)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
+	util.SetETag(w, team.Version)
 	err = json.NewEncoder(w).Encode(team)
 	if err != nil {
 		logger.Error(err)
@@ -93,16 +114,46 @@ func (t *TeamService) GetByID(w http.ResponseWriter, r *http.Request) {
 	t.logger.Info("get team with id: ", teamID)
 }
 
-// List retuns a list of all teams available on the internal store.
+// List retuns a list of all teams available on the internal store. The optional
+// root query parameter limits the list to the given team and its sub-teams,
+// otherwise the list can be sorted and paged, see util.ListOptionsFromRequest.
+// Example request:
+// GET /v1/team?root={teamID}
 func (t *TeamService) List(w http.ResponseWriter, r *http.Request) {
 	logger := t.logger.WithField("method", "list")
 	logger.Info("retrieve team list")
-	list, err := t.store.ListTeams(r.Context())
+	opts, err := util.ListOptionsFromRequest(r, model.EntityTeam)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	var list []*model.Team
+	if rootID := r.URL.Query().Get("root"); rootID != "" {
+		// NOTE: sub-team lists are neither sorted nor paged.
+		opts = nil
+		list, err = t.relationStore.SubTeams(r.Context(), rootID)
+		if err == nil && len(list) == 0 {
+			logger.Error("no team found: ", rootID)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	} else {
+		list, err = t.store.ListTeams(r.Context(), opts...)
+	}
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if n := len(list); n > 0 {
+		err = util.SetNextCursor(w, opts, n, list[n-1])
+		if err != nil {
+			logger.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
 	err = json.NewEncoder(w).Encode(&list)
 	if err != nil {
 		logger.Error(err)
@@ -121,7 +172,13 @@ func (t *TeamService) ListTeamUsers(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	teamUser, err := t.store.ListTeamUsers(r.Context(), teamID)
+	opts, err := util.QueryOptionsFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	teamUser, err := t.store.ListTeamUsers(r.Context(), teamID, opts...)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -135,24 +192,48 @@ func (t *TeamService) ListTeamUsers(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// Update reads new team settings from the request body and updates the store
-// representation accordingly.
+// Update reads new team settings from the request body and updates the team
+// associated to the teamID in the URL accordingly, the id of the payload is
+// ignored. An empty parent_id moves the team to the top level, a team can not
+// be moved into one of its own sub-teams.
 func (t *TeamService) Update(w http.ResponseWriter, r *http.Request) {
 	logger := t.logger.WithField("method", "update")
 	logger.Info("update team")
+	teamID, err := util.TeamIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 	var team model.Team
-	err := json.NewDecoder(r.Body).Decode(&team)
+	err = json.NewDecoder(r.Body).Decode(&team)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	uTeam, err := t.store.UpdateTeam(r.Context(), &team)
+	// NOTE: the team is always taken from the URL, which is authorized by the
+	// middleware, never from the payload.
+	team.ID = teamID
+	team.Version, err = util.VersionFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(util.IfMatchStatusCode(err))
+		return
+	}
+	err = util.ValidHolidayCalendar(team.HolidayCalendar)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	uTeam, err := t.store.UpdateTeam(r.Context(), &team)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(statusCode(err, http.StatusBadRequest))
+		return
+	}
+	util.SetETag(w, uTeam.Version)
 	err = json.NewEncoder(w).Encode(&uTeam)
 	if err != nil {
 		logger.Error(err)
@@ -161,7 +242,8 @@ func (t *TeamService) Update(w http.ResponseWriter, r *http.Request) {
 	t.logger.Info("update team with id: ", team.ID)
 }
 
-// Delete a team associated to the given teamID in the URL.
+// Delete a team associated to the given teamID in the URL. Teams with
+// sub-teams can not be deleted.
 func (t *TeamService) Delete(w http.ResponseWriter, r *http.Request) {
 	logger := t.logger.WithField("method", "delete")
 	logger.Info("delete team")
@@ -174,7 +256,7 @@ func (t *TeamService) Delete(w http.ResponseWriter, r *http.Request) {
 	err = t.store.DeleteTeam(r.Context(), teamID)
 	if err != nil {
 		logger.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(statusCode(err, http.StatusInternalServerError))
 		return
 	}
 	t.logger.Info("delete team with id: ", teamID)
@@ -182,6 +264,10 @@ func (t *TeamService) Delete(w http.ResponseWriter, r *http.Request) {
 }
 
 // ListCapacity lists teams and their availability for the requested period.
+// The availability of a team rolls up all of its sub-teams, e.g. a department
+// covers the members and vacations of all of its teams.
+// Vacations are only listed to users, who may read the absences of the team,
+// readers of the team see an anonymized list.
 // Example request:
 // {
 //   "from":"2009-11-10T23:00:00Z",
@@ -199,24 +285,28 @@ func (t *TeamService) Delete(w http.ResponseWriter, r *http.Request) {
 //   # NOTE: Depending on the availability ratio this value can be:
 //   # "HIGH", "MEDIUM" or "LOW".
 //   "availability":"HIGH",
-//   # NOTE: vacations are only displayed if the requesting user is a team owner.
-//   # Or the requesting user is the parent of a team owner.
+//   # NOTE: vacations are only displayed if the requesting user is a team owner,
+//   # the owner of a parent team or the parent of a team owner.
 //   # Parent is recursive in this case. This means that the parent of the
-//   # parent is also valid.
+//   # parent is also valid. Team members only see absences of types, which are
+//   # visible to the team.
 //   "vacations":[
 //     {
 //       "id":"",
 //       "user_id":"",
 //       "approved_by":null,
+//       "deputy_id":null,
 //       "from":"0001-01-01T00:00:00Z",
 //       "to":"0001-01-01T00:00:00Z",
+//       "portion":"full_day",
+//       "hours":0,
 //       "created_at":null
 //     }
 //   ]
 // }
 //
 // Example response requesting Content-Type csv/application:
-// from,to,teamID,availability,vacation-id,vacation-user_id,vacation-approved_by,vacation-from,vacation-to,vacation-created_at,vacation-deleted_at
+// from,to,teamID,availability,vacation-id,vacation-user_id,vacation-approved_by,vacation-deputy_id,vacation-from,vacation-to,vacation-portion,vacation-hours,vacation-created_at,vacation-deleted_at
 // 2022-04-19 22:23:40.886412444 +0200 CEST m=-258901.921920057,2022-04-25 22:23:40.886412586 +0200 CEST m=+259498.078080085,a7da8eb8-410f-4f6a-8324-1db65a289a13,HIGH,,,,,,,
 //	2022-04-19 22:23:40.886412677 +0200 CEST m=-258901.921919824,2022-04-25 22:23:40.886412747 +0200 CEST m=+259498.078080246,e22b2a12-cf42-44c6-a2ed-c3630ba9583a,HIGH,,,,,,,
 //	2022-04-19 22:23:40.886412822 +0200 CEST m=-258901.921919683,2022-04-25 22:23:40.886412887 +0200 CEST m=+259498.078080386,e22b2a12-cf42-44c6-a2ed-c3630ba9583a,HIGH,,,,,,,
@@ -250,7 +340,7 @@ func (t *TeamService) ListCapacity(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// NOTE: ignore teamID, team members have only access to a anonymized list
+	// NOTE: ignore teamID, the engine decides per team about the vacations.
 	userID, _, err := t.tokenizer.Valid(token)
 	if err != nil {
 		logger.Error(err)
@@ -258,6 +348,13 @@ func (t *TeamService) ListCapacity(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	absenceTypes, err := t.store.ListAbsenceTypes(r.Context())
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
 	var resp []*capacityResponse
 	var teamsBundle []*teamBundle
 
@@ -269,8 +366,7 @@ func (t *TeamService) ListCapacity(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		teamsBundle = append(teamsBundle, &teamBundle{
-			teamOwnerID: team.OwnerID,
-			teamID:      request.TeamID,
+			teamID: team.ID,
 		})
 	} else {
 		teams, err := t.store.ListTeams(r.Context())
@@ -281,57 +377,44 @@ func (t *TeamService) ListCapacity(w http.ResponseWriter, r *http.Request) {
 		}
 		for _, team := range teams {
 			teamsBundle = append(teamsBundle, &teamBundle{
-				teamOwnerID: team.OwnerID,
-				teamID:      team.ID,
+				teamID: team.ID,
 			})
 		}
 	}
 
 	for _, tb := range teamsBundle {
-		vacs, err := t.vaccationByTeam(r.Context(), tb.teamID, request.From, request.To)
+		vacs, ratio, err := t.capacity(r.Context(), tb.teamID, request.From, request.To)
 		if err != nil {
 			logger.Error(err)
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 		window := &capacityResponse{
-			TeamID: request.TeamID,
+			TeamID: tb.teamID,
 			From:   request.From,
 			To:     request.To,
 		}
 
-		isOwner, err := t.relationStore.IsTeamOwner(r.Context(), tb.teamID, userID)
+		readAbsences, err := t.engine.Allowed(r.Context(), userID, policy.ReadAbsences, tb.teamID)
 		if err != nil {
 			logger.Error(err)
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
-		isParentOfOwner, err := t.relationStore.IsParentUser(r.Context(), tb.teamOwnerID, userID)
+		readTeam, err := t.engine.Allowed(r.Context(), userID, policy.ReadTeam, tb.teamID)
 		if err != nil {
 			logger.Error(err)
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
-		if isOwner || isParentOfOwner {
+		if readAbsences {
 			window.Vacation = vacs
+		} else if readTeam {
+			window.Vacation = visibleToTeam(vacs, absenceTypes)
 		}
 
-		users, err := t.store.ListTeamUsers(r.Context(), tb.teamID)
-		if err != nil {
-			logger.Error(err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-		// NOTE: we dont need to consider pure workdays, since vacation resources
-		// also do not consider them.
-		workDays := daysBetween(request.From, request.To) * float64(len(users))
-		var daysOfVacation float64
-		for _, vac := range vacs {
-			daysOfVacation += daysBetween(vac.From, vac.To)
-		}
-		ratio := workDays / daysOfVacation
 		if ratio > 0.8 {
 			window.Availability = "HIGH"
 		} else if ratio <= 0.8 && ratio > 0.25 {
@@ -361,6 +444,49 @@ func (t *TeamService) ListCapacity(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// capacity returns the vacations within the given period and the ratio of
+// available working days of the team and all of its sub-teams. Working days
+// are counted by the holiday calendar of each sub-team and weighted by the
+// allocation of each member, e.g. a member split across two teams counts half
+// in both of them.
+func (t *TeamService) capacity(ctx context.Context, teamID string, from, to time.Time) ([]*model.Vacation, float64, error) {
+	subTeams, err := t.relationStore.SubTeams(ctx, teamID)
+	if err != nil {
+		return nil, 0, err
+	}
+	var vacations []*model.Vacation
+	var workDays, daysOfVacation float64
+	for _, team := range subTeams {
+		vacs, err := t.vaccationByTeam(ctx, team.ID, from, to)
+		if err != nil {
+			return nil, 0, err
+		}
+		memberships, err := t.store.ListTeamMemberships(ctx, team.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		cal, err := t.calendarStore.TeamCalendar(ctx, team.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		shares := map[string]float64{}
+		for _, m := range memberships {
+			shares[m.UserID] = m.Share()
+			workDays += cal.WorkingDays(from, to) * m.Share()
+		}
+		for _, vac := range vacs {
+			daysOfVacation += cal.WorkingDays(vac.From, vac.To) * vac.Portion.Fraction(vac.Hours) * shares[vac.UserID]
+		}
+		vacations = append(vacations, vacs...)
+	}
+	// NOTE: without any working days, nobody is missing.
+	ratio := 1.0
+	if workDays > 0 {
+		ratio = (workDays - daysOfVacation) / workDays
+	}
+	return vacations, ratio, nil
+}
+
 func (t *TeamService) vaccationByTeam(ctx context.Context, teamID string, from, to time.Time) ([]*model.Vacation, error) {
 	vacations, err := t.store.GetVacationsByTeamID(ctx, teamID)
 	if err != nil {
@@ -369,7 +495,7 @@ func (t *TeamService) vaccationByTeam(ctx context.Context, teamID string, from,
 
 	var response []*model.Vacation
 	for _, v := range vacations {
-		if !(v.From.After(from) && from.Before(to)) {
+		if v.From.After(to) || v.To.Before(from) {
 			continue
 		}
 		// NOTE: trim start time
@@ -386,20 +512,32 @@ func (t *TeamService) vaccationByTeam(ctx context.Context, teamID string, from,
 	return response, nil
 }
 
-func daysBetween(from, to time.Time) float64 {
-	return to.Sub(from).Hours() / 24
+// visibleToTeam returns all vacations, which can be shown to teammates.
+func visibleToTeam(vacations []*model.Vacation, absenceTypes []*model.AbsenceType) []*model.Vacation {
+	hidden := map[string]bool{}
+	for _, a := range absenceTypes {
+		hidden[a.ID] = !a.VisibleToTeam
+	}
+	var visible []*model.Vacation
+	for _, v := range vacations {
+		if v.AbsenceTypeID != nil && hidden[*v.AbsenceTypeID] {
+			continue
+		}
+		visible = append(visible, v)
+	}
+	return visible
 }
 
 type capacityRequest struct {
-	From time.Time `json:"to"`
-	To   time.Time `json:"from"`
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
 	// NOTE: optional
 	TeamID string `json:"team_id"`
 }
 
 type capacityResponse struct {
-	From   time.Time `json:"to"`
-	To     time.Time `json:"from"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
 	TeamID string    `json:"team_id"`
 
 	// NOTE: HIGH, MEDIUM, LOW
@@ -414,8 +552,9 @@ func (c *capacityResponse) WriteCSVHeader(w io.Writer) error {
 	defer wr.Flush()
 	wr.Write([]string{
 		"from", "to", "teamID", "availability",
-		"vacation-id", "vacation-user_id", "vacation-approved_by",
-		"vacation-from", "vacation-to", "vacation-created_at", "vacation-deleted_at",
+		"vacation-id", "vacation-user_id", "vacation-approved_by", "vacation-deputy_id",
+		"vacation-from", "vacation-to", "vacation-portion", "vacation-hours",
+		"vacation-created_at", "vacation-deleted_at",
 	})
 	return nil
 }
@@ -426,7 +565,7 @@ func (c *capacityResponse) WriteCSV(w io.Writer) error {
 	if len(c.Vacation) == 0 {
 		wr.Write([]string{
 			c.From.String(), c.To.String(), c.TeamID, c.Availability,
-			"", "", "", "", "", "", "",
+			"", "", "", "", "", "", "", "", "", "",
 		})
 		return nil
 	}
@@ -435,6 +574,10 @@ func (c *capacityResponse) WriteCSV(w io.Writer) error {
 		if vac.ApprovedBy != nil {
 			approvedBy = *vac.ApprovedBy
 		}
+		var deputyID string
+		if vac.DeputyID != nil {
+			deputyID = *vac.DeputyID
+		}
 		var createdAt, deletedAt string
 		if vac.CreatedAt != nil {
 			createdAt = vac.CreatedAt.String()
@@ -444,14 +587,30 @@ func (c *capacityResponse) WriteCSV(w io.Writer) error {
 		}
 		wr.Write([]string{
 			c.From.String(), c.To.String(), c.TeamID, c.Availability,
-			vac.ID, vac.UserID, approvedBy,
-			vac.From.String(), vac.To.String(), createdAt, deletedAt,
+			vac.ID, vac.UserID, approvedBy, deputyID,
+			vac.From.String(), vac.To.String(),
+			string(vac.Portion), strconv.FormatFloat(vac.Hours, 'f', -1, 64),
+			createdAt, deletedAt,
 		})
 	}
 	return nil
 }
 
 type teamBundle struct {
-	teamOwnerID string
-	teamID      string
+	teamID string
+}
+
+// statusCode maps team hierarchy and version errors to http status codes, other errors are
+// mapped to the given fallback.
+func statusCode(err error, fallback int) int {
+	if errors.Is(err, model.ErrVersionConflict) {
+		return http.StatusPreconditionFailed
+	}
+	if errors.Is(err, model.ErrTeamCycle) || errors.Is(err, model.ErrTeamHasSubTeams) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, model.ErrInvalidTeamParent) {
+		return http.StatusBadRequest
+	}
+	return fallback
 }
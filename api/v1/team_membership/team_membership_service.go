@@ -0,0 +1,260 @@
+package teammembership
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/MninaTB/vacadm/api/v1/util"
+	"github.com/MninaTB/vacadm/pkg/database"
+	"github.com/MninaTB/vacadm/pkg/jwt"
+	"github.com/MninaTB/vacadm/pkg/model"
+	"github.com/MninaTB/vacadm/pkg/policy"
+)
+
+// Tokenizer implements methods to verify auth tokens.
+type Tokenizer interface {
+	// Valid if a token is valid, userID and teamID are returned.
+	// if a token is invalid, an error is returned.
+	Valid(token string) (userID string, teamID string, err error)
+}
+
+// NewTeamMembershipService returns a TeamMembershipService.
+func NewTeamMembershipService(
+	store database.Database,
+	logger logrus.FieldLogger,
+	t Tokenizer,
+) *TeamMembershipService {
+	return &TeamMembershipService{
+		store:     store,
+		engine:    policy.NewEngine(store),
+		tokenizer: t,
+		logger:    logger.WithField("component", "team-membership-service"),
+	}
+}
+
+// TeamMembershipService implements http.HandlerFunc's to operate on the
+// memberships of a team. Team members can read the memberships, only users
+// holding policy.ManageTeam, e.g. the team owner, can manage them.
+type TeamMembershipService struct {
+	store     database.Database
+	engine    policy.Engine
+	tokenizer Tokenizer
+	logger    logrus.FieldLogger
+}
+
+// Create reads the given payload and adds the user to the team in the URL.
+// Example request:
+// PUT /v1/team/{teamID}/members
+// {"user_id": "1ff63524-156f-466d-b287-4258811444dd", "role": "lead", "allocation": 50}
+func (t *TeamMembershipService) Create(w http.ResponseWriter, r *http.Request) {
+	logger := t.logger.WithField("method", "create")
+	logger.Info("create new team-membership")
+	teamID, ok := t.authorizeOwner(w, r, logger)
+	if !ok {
+		return
+	}
+	var membership model.TeamMembership
+	err := json.NewDecoder(r.Body).Decode(&membership)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	membership.TeamID = teamID
+	newMembership, err := t.store.CreateTeamMembership(r.Context(), &membership)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(statusCode(err))
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(newMembership)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	t.logger.Info("create team-membership with ID: ", newMembership.ID)
+}
+
+// List writes all memberships of the team in the URL into the given response
+// writer.
+func (t *TeamMembershipService) List(w http.ResponseWriter, r *http.Request) {
+	logger := t.logger.WithField("method", "list")
+	logger.Info("retrieve team-membership list")
+	teamID, err := util.TeamIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	opts, err := util.QueryOptionsFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	list, err := t.store.ListTeamMemberships(r.Context(), teamID, opts...)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(&list)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// Update reads the given payload and changes role and allocation of the
+// membership associated to the teamMembershipID in the URL.
+func (t *TeamMembershipService) Update(w http.ResponseWriter, r *http.Request) {
+	logger := t.logger.WithField("method", "update")
+	logger.Info("update team-membership")
+	teamID, ok := t.authorizeOwner(w, r, logger)
+	if !ok {
+		return
+	}
+	membershipID, err := extractTeamMembershipID(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	current, err := t.store.GetTeamMembershipByID(r.Context(), membershipID)
+	if err != nil || current.TeamID != teamID {
+		logger.Error("no team-membership found: ", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var membership model.TeamMembership
+	err = json.NewDecoder(r.Body).Decode(&membership)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	membership.ID = membershipID
+	membership.Version, err = util.VersionFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(util.IfMatchStatusCode(err))
+		return
+	}
+	updated, err := t.store.UpdateTeamMembership(r.Context(), &membership)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(statusCode(err))
+		return
+	}
+	util.SetETag(w, updated.Version)
+	err = json.NewEncoder(w).Encode(updated)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	t.logger.Info("update team-membership with id: ", updated.ID)
+}
+
+// Delete removes the membership associated to the teamMembershipID in the URL.
+func (t *TeamMembershipService) Delete(w http.ResponseWriter, r *http.Request) {
+	logger := t.logger.WithField("method", "delete")
+	logger.Info("delete team-membership")
+	teamID, ok := t.authorizeOwner(w, r, logger)
+	if !ok {
+		return
+	}
+	membershipID, err := extractTeamMembershipID(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	membership, err := t.store.GetTeamMembershipByID(r.Context(), membershipID)
+	if err != nil || membership.TeamID != teamID {
+		logger.Error("no team-membership found: ", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	err = t.store.DeleteTeamMembership(r.Context(), membershipID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	t.logger.Info("delete team-membership with id: ", membershipID)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// authorizeOwner asks the policy engine, whether the user of the token can
+// manage the team in the URL. If this is not the case, an error code is
+// written to the response writer and false is returned.
+func (t *TeamMembershipService) authorizeOwner(
+	w http.ResponseWriter,
+	r *http.Request,
+	logger logrus.FieldLogger,
+) (string, bool) {
+	teamID, err := util.TeamIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return "", false
+	}
+	token, err := jwt.ExtractToken(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return "", false
+	}
+	userID, _, err := t.tokenizer.Valid(token)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return "", false
+	}
+	team, err := t.store.GetTeamByID(r.Context(), teamID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusNotFound)
+		return "", false
+	}
+	allowed, err := t.engine.Allowed(r.Context(), userID, policy.ManageTeam, team.ID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return "", false
+	}
+	if !allowed {
+		logger.Error("missing permission - only the team owner can manage team-memberships")
+		w.WriteHeader(http.StatusForbidden)
+		return "", false
+	}
+	return teamID, true
+}
+
+// statusCode maps membership errors to http status codes.
+func statusCode(err error) int {
+	if errors.Is(err, model.ErrVersionConflict) {
+		return http.StatusPreconditionFailed
+	}
+	if errors.Is(err, model.ErrOverAllocated) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, model.ErrInvalidMembership) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func extractTeamMembershipID(r *http.Request) (string, error) {
+	vars := mux.Vars(r)
+	membershipID, ok := vars["teamMembershipID"]
+	if !ok {
+		return "", errors.New("could not extract teamMembershipID")
+	}
+	return membershipID, nil
+}
@@ -0,0 +1,298 @@
+package teamrule
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/MninaTB/vacadm/api/v1/util"
+	"github.com/MninaTB/vacadm/pkg/database"
+	"github.com/MninaTB/vacadm/pkg/jwt"
+	"github.com/MninaTB/vacadm/pkg/model"
+	"github.com/MninaTB/vacadm/pkg/policy"
+)
+
+// Tokenizer implements methods to verify auth tokens.
+type Tokenizer interface {
+	// Valid if a token is valid, userID and teamID are returned.
+	// if a token is invalid, an error is returned.
+	Valid(token string) (userID string, teamID string, err error)
+}
+
+// NewTeamRuleService returns a TeamRuleService.
+func NewTeamRuleService(
+	store database.Database,
+	logger logrus.FieldLogger,
+	t Tokenizer,
+) *TeamRuleService {
+	return &TeamRuleService{
+		store:     store,
+		engine:    policy.NewEngine(store),
+		tokenizer: t,
+		logger:    logger.WithField("component", "team-rule-service"),
+	}
+}
+
+// TeamRuleService implements http.HandlerFunc's to operate on the staffing
+// rules and blackout periods of a team. Team members can read the rules, only
+// users holding policy.ManageTeam, e.g. the team owner, can manage them.
+type TeamRuleService struct {
+	store     database.Database
+	engine    policy.Engine
+	tokenizer Tokenizer
+	logger    logrus.FieldLogger
+}
+
+// Create reads the given payload and creates a rule for the team in the URL.
+// Example request:
+// PUT /v1/team/{teamID}/rules
+// {"name": "release freeze", "kind": "blackout", "blocking": true,
+// "from": "2022-12-01T00:00:00Z", "to": "2022-12-15T00:00:00Z", "yearly": true}
+func (t *TeamRuleService) Create(w http.ResponseWriter, r *http.Request) {
+	logger := t.logger.WithField("method", "create")
+	logger.Info("create new team-rule")
+	teamID, ok := t.authorizeOwner(w, r, logger)
+	if !ok {
+		return
+	}
+	var rule model.TeamRule
+	err := json.NewDecoder(r.Body).Decode(&rule)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.Error(err)
+		return
+	}
+	rule.TeamID = teamID
+	newRule, err := t.store.CreateTeamRule(r.Context(), &rule)
+	if errors.Is(err, model.ErrInvalidTeamRule) {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.Error(err)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(newRule)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	t.logger.Info("create team-rule with ID: ", newRule.ID)
+}
+
+// GetByID extracts a teamRuleID from URL and writes the rule into the given
+// response writer. Only rules of the team in the URL are returned.
+func (t *TeamRuleService) GetByID(w http.ResponseWriter, r *http.Request) {
+	logger := t.logger.WithField("method", "read")
+	logger.Info("get team-rule by id")
+	teamID, err := util.TeamIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	ruleID, err := extractTeamRuleID(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	opts, err := util.QueryOptionsFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	rule, err := t.store.GetTeamRuleByID(r.Context(), ruleID, opts...)
+	if err != nil || rule.TeamID != teamID {
+		logger.Error("no team-rule found: ", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	util.SetETag(w, rule.Version)
+	err = json.NewEncoder(w).Encode(rule)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// List writes all rules of the team in the URL into the given response writer.
+func (t *TeamRuleService) List(w http.ResponseWriter, r *http.Request) {
+	logger := t.logger.WithField("method", "list")
+	logger.Info("retrieve team-rule list")
+	teamID, err := util.TeamIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	opts, err := util.QueryOptionsFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	list, err := t.store.ListTeamRules(r.Context(), teamID, opts...)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(&list)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// Update reads the given payload and replaces the rule associated to the
+// teamRuleID in the URL.
+func (t *TeamRuleService) Update(w http.ResponseWriter, r *http.Request) {
+	logger := t.logger.WithField("method", "update")
+	logger.Info("update team-rule")
+	teamID, ok := t.authorizeOwner(w, r, logger)
+	if !ok {
+		return
+	}
+	ruleID, err := extractTeamRuleID(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	current, err := t.store.GetTeamRuleByID(r.Context(), ruleID)
+	if err != nil || current.TeamID != teamID {
+		logger.Error("no team-rule found: ", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var rule model.TeamRule
+	err = json.NewDecoder(r.Body).Decode(&rule)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	rule.ID = ruleID
+	rule.Version, err = util.VersionFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(util.IfMatchStatusCode(err))
+		return
+	}
+	updated, err := t.store.UpdateTeamRule(r.Context(), &rule)
+	if errors.Is(err, model.ErrInvalidTeamRule) {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, model.ErrVersionConflict) {
+		logger.Error(err)
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	util.SetETag(w, updated.Version)
+	err = json.NewEncoder(w).Encode(updated)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	t.logger.Info("update team-rule with id: ", updated.ID)
+}
+
+// Delete removes the rule associated to the teamRuleID in the URL.
+func (t *TeamRuleService) Delete(w http.ResponseWriter, r *http.Request) {
+	logger := t.logger.WithField("method", "delete")
+	logger.Info("delete team-rule")
+	teamID, ok := t.authorizeOwner(w, r, logger)
+	if !ok {
+		return
+	}
+	ruleID, err := extractTeamRuleID(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	rule, err := t.store.GetTeamRuleByID(r.Context(), ruleID)
+	if err != nil || rule.TeamID != teamID {
+		logger.Error("no team-rule found: ", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	err = t.store.DeleteTeamRule(r.Context(), ruleID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	t.logger.Info("delete team-rule with id: ", ruleID)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// authorizeOwner asks the policy engine, whether the user of the token can
+// manage the team in the URL. If this is not the case, an error code is
+// written to the response writer and false is returned.
+func (t *TeamRuleService) authorizeOwner(
+	w http.ResponseWriter,
+	r *http.Request,
+	logger logrus.FieldLogger,
+) (string, bool) {
+	teamID, err := util.TeamIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return "", false
+	}
+	token, err := jwt.ExtractToken(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return "", false
+	}
+	userID, _, err := t.tokenizer.Valid(token)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return "", false
+	}
+	team, err := t.store.GetTeamByID(r.Context(), teamID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusNotFound)
+		return "", false
+	}
+	allowed, err := t.engine.Allowed(r.Context(), userID, policy.ManageTeam, team.ID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return "", false
+	}
+	if !allowed {
+		logger.Error("missing permission - only the team owner can manage team-rules")
+		w.WriteHeader(http.StatusForbidden)
+		return "", false
+	}
+	return teamID, true
+}
+
+func extractTeamRuleID(r *http.Request) (string, error) {
+	vars := mux.Vars(r)
+	teamRuleID, ok := vars["teamRuleID"]
+	if !ok {
+		return "", errors.New("could not extract teamRuleID")
+	}
+	return teamRuleID, nil
+}
@@ -2,30 +2,45 @@ package user
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/MninaTB/vacadm/api/v1/util"
 	"github.com/MninaTB/vacadm/pkg/database"
+	"github.com/MninaTB/vacadm/pkg/jwt"
 	"github.com/MninaTB/vacadm/pkg/model"
+	"github.com/MninaTB/vacadm/pkg/policy"
 )
 
+// Tokenizer implements methods to verify auth tokens.
+type Tokenizer interface {
+	// Valid if a token is valid, userID and teamID are returned.
+	// if a token is invalid, an error is returned.
+	Valid(token string) (userID string, teamID string, err error)
+}
+
 // NewUserService returns a UserService.
-func NewUserService(store database.Database, logger logrus.FieldLogger) *UserService {
+func NewUserService(store database.Database, logger logrus.FieldLogger, t Tokenizer) *UserService {
 	return &UserService{
-		store:  store,
-		logger: logger.WithField("component", "user-service"),
+		store:     store,
+		engine:    policy.NewEngine(store),
+		tokenizer: t,
+		logger:    logger.WithField("component", "user-service"),
 	}
 }
 
 // UserService implements http.HandlerFunc's to operate on user resources.
 type UserService struct {
-	store  database.Database
-	logger logrus.FieldLogger
+	store     database.Database
+	engine    policy.Engine
+	tokenizer Tokenizer
+	logger    logrus.FieldLogger
 }
 
 // Create reads the given payload and creates a store representation accordingly.
+// New users are employees, see UpdateRole.
 func (u *UserService) Create(w http.ResponseWriter, r *http.Request) {
 	logger := u.logger.WithField("method", "create")
 	logger.Info("create new user")
@@ -36,10 +51,17 @@ func (u *UserService) Create(w http.ResponseWriter, r *http.Request) {
 		logger.Error(err)
 		return
 	}
+	usr.Role = ""
+	err = util.ValidHolidayCalendar(usr.HolidayCalendar)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.Error(err)
+		return
+	}
 	user, err := u.store.CreateUser(r.Context(), &usr)
 	if err != nil {
 		logger.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(statusCode(err, http.StatusInternalServerError))
 		return
 	}
 	err = json.NewEncoder(w).Encode(user)
@@ -63,12 +85,19 @@ func (u *UserService) GetByID(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	usr, err := u.store.GetUserByID(r.Context(), userID)
+	opts, err := util.QueryOptionsFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	usr, err := u.store.GetUserByID(r.Context(), userID, opts...)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
+	util.SetETag(w, usr.Version)
 	err = json.NewEncoder(w).Encode(usr)
 	if err != nil {
 		logger.Error(err)
@@ -77,16 +106,33 @@ func (u *UserService) GetByID(w http.ResponseWriter, r *http.Request) {
 	u.logger.Info("get user with id: ", userID)
 }
 
-// List retuns a list of all users available on the internal store.
+// List retuns a list of all users available on the internal store. The list
+// can be filtered by team, sorted and paged, see util.ListOptionsFromRequest.
+// Example request:
+// GET /v1/user?team_id={teamID}&sort=last_name&limit=50
 func (u *UserService) List(w http.ResponseWriter, r *http.Request) {
 	logger := u.logger.WithField("method", "list")
 	logger.Info("retrieve user list")
-	list, err := u.store.ListUsers(r.Context())
+	opts, err := util.ListOptionsFromRequest(r, model.EntityUser)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	list, err := u.store.ListUsers(r.Context(), opts...)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+	if n := len(list); n > 0 {
+		err = util.SetNextCursor(w, opts, n, list[n-1])
+		if err != nil {
+			logger.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
 	err = json.NewEncoder(w).Encode(&list)
 	if err != nil {
 		logger.Error(err)
@@ -95,24 +141,67 @@ func (u *UserService) List(w http.ResponseWriter, r *http.Request) {
 	u.logger.Info("get list of users")
 }
 
-// Update reads new user information from the request body and updates the store
-// representation accordingly.
+// Update reads new user information from the request body and updates the user
+// associated to the userID in the URL accordingly. The role is kept, see
+// UpdateRole. Changing the parent or the team requires policy.ManageUser.
 func (u *UserService) Update(w http.ResponseWriter, r *http.Request) {
 	logger := u.logger.WithField("method", "update")
 	logger.Info("update user")
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 	var usr model.User
-	err := json.NewDecoder(r.Body).Decode(&usr)
+	err = json.NewDecoder(r.Body).Decode(&usr)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	user, err := u.store.UpdateUser(r.Context(), &usr)
+	// NOTE: the user is always taken from the URL, which is authorized by the
+	// middleware, never from the payload.
+	usr.ID = userID
+	usr.Role = ""
+	usr.Version, err = util.VersionFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(util.IfMatchStatusCode(err))
+		return
+	}
+	err = util.ValidHolidayCalendar(usr.HolidayCalendar)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	current, err := u.store.GetUserByID(r.Context(), userID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if reportingLineChanged(current, &usr) {
+		ok, err := u.allowed(r, policy.ManageUser, userID)
+		if err != nil {
+			logger.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			logger.Warn("missing permission to change parent or team of user: ", userID)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+	}
+	user, err := u.store.UpdateUser(r.Context(), &usr)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(statusCode(err, http.StatusBadRequest))
+		return
+	}
+	util.SetETag(w, user.Version)
 	err = json.NewEncoder(w).Encode(&user)
 	if err != nil {
 		logger.Error(err)
@@ -121,6 +210,56 @@ func (u *UserService) Update(w http.ResponseWriter, r *http.Request) {
 	u.logger.Info("update user with id: ", usr.ID)
 }
 
+// UpdateRole reads the given payload and changes the role of the user
+// associated to the userID in the URL.
+// Example request:
+// PUT /v1/user/{userID}/role
+// {"role": "hr"}
+func (u *UserService) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	logger := u.logger.WithField("method", "update-role")
+	logger.Info("update user role")
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var payload struct {
+		Role string `json:"role"`
+	}
+	err = json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	role, err := model.ParseRole(payload.Role)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	usr, err := u.store.GetUserByID(r.Context(), userID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	usr.Role = role
+	usr, err = u.store.UpdateUser(r.Context(), usr)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(usr)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	u.logger.Info("update role of user with id: ", userID)
+}
+
 // Delete a user associated to the given userID in the URL.
 func (u *UserService) Delete(w http.ResponseWriter, r *http.Request) {
 	logger := u.logger.WithField("method", "delete")
@@ -140,3 +279,41 @@ func (u *UserService) Delete(w http.ResponseWriter, r *http.Request) {
 	u.logger.Info("delete user with id: ", userID)
 	w.WriteHeader(http.StatusAccepted)
 }
+
+// allowed verifies, if the user of the auth token holds permission p on the
+// resource.
+func (u *UserService) allowed(r *http.Request, p policy.Permission, resourceID string) (bool, error) {
+	token, err := jwt.ExtractToken(r)
+	if err != nil {
+		return false, err
+	}
+	actorID, _, err := u.tokenizer.Valid(token)
+	if err != nil {
+		return false, err
+	}
+	return u.engine.Allowed(r.Context(), actorID, p, resourceID)
+}
+
+// reportingLineChanged reports, if the update moves the user to another parent
+// or team. Missing fields are kept by the store and do not change anything.
+func reportingLineChanged(current, update *model.User) bool {
+	changed := func(a, b *string) bool {
+		return b != nil && (a == nil || *a != *b)
+	}
+	return changed(current.ParentID, update.ParentID) || changed(current.TeamID, update.TeamID)
+}
+
+// statusCode maps reporting line and version errors to http status codes, other errors are
+// mapped to the given fallback.
+func statusCode(err error, fallback int) int {
+	if errors.Is(err, model.ErrVersionConflict) {
+		return http.StatusPreconditionFailed
+	}
+	if errors.Is(err, model.ErrUserCycle) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, model.ErrInvalidUserParent) {
+		return http.StatusBadRequest
+	}
+	return fallback
+}
@@ -31,7 +31,7 @@ func TestUserService_Create(t *testing.T) {
 
 	// We create a ResponseRecorder (which satisfies http.ResponseWriter) to record the response.
 	rr := httptest.NewRecorder()
-	svc := NewUserService(inmemory.NewInmemoryDB(), logrus.New())
+	svc := NewUserService(inmemory.NewInmemoryDB(), logrus.New(), nil)
 
 	handler := http.HandlerFunc(svc.Create)
 
@@ -46,6 +46,8 @@ func TestUserService_Create(t *testing.T) {
 	usr.Email = "test@test.com"
 	usr.FirstName = "abc"
 	usr.LastName = "def"
+	// NOTE: the role is ignored, new users are employees.
+	usr.Role = model.RoleAdmin
 	buf.Reset()
 	if err := json.NewEncoder(&buf).Encode(usr); err != nil {
 		t.Fatal(err)
@@ -86,6 +88,8 @@ func TestUserService_Create(t *testing.T) {
 	}, cmp.Ignore())
 
 	// NOTE: compare original struct, ignore ID and CreatedAt (should be different)
+	usr.Role = model.RoleEmployee
+	usr.Version = 1
 	if !cmp.Equal(usr, got, ignoreFields) {
 		t.Fatal(cmp.Diff(usr, got, ignoreFields))
 	}
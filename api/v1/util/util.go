@@ -3,8 +3,23 @@ package util
 import (
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+
+	"github.com/MninaTB/vacadm/pkg/database/query"
+	"github.com/MninaTB/vacadm/pkg/holiday"
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+const (
+	// NextCursorHeader contains the cursor of the next page of a list, it is
+	// only set if the page is full.
+	NextCursorHeader = "X-Next-Cursor"
+	// MaxLimit is the largest page size accepted by lists.
+	MaxLimit = 500
 )
 
 var (
@@ -17,6 +32,27 @@ var (
 	// ErrDoesNotExistParentID is an error returned when a parentID does not exist
 	// in a URL.
 	ErrDoesNotExistParentID = errors.New("could not extract parentID")
+	// ErrInvalidYear is an error returned when the year query parameter
+	// is not a number.
+	ErrInvalidYear = errors.New("could not parse year")
+	// ErrDoesNotExistCalendarID is an error returned when a calendarID does
+	// not exist in a URL.
+	ErrDoesNotExistCalendarID = errors.New("could not extract calendarID")
+	// ErrInvalidIncludeDeleted is an error returned when the include_deleted
+	// query parameter is not a boolean.
+	ErrInvalidIncludeDeleted = errors.New("could not parse include_deleted")
+	// ErrInvalidLimit is an error returned when the limit query parameter is
+	// not a number between 1 and MaxLimit.
+	ErrInvalidLimit = errors.New("could not parse limit")
+	// ErrInvalidPeriod is an error returned when the from or to query
+	// parameter is not a date.
+	ErrInvalidPeriod = errors.New("could not parse from or to")
+	// ErrMissingIfMatch is an error returned when an update request has no
+	// If-Match header.
+	ErrMissingIfMatch = errors.New("missing If-Match header")
+	// ErrInvalidIfMatch is an error returned when the If-Match header is not
+	// an ETag returned by this service.
+	ErrInvalidIfMatch = errors.New("could not parse If-Match header")
 )
 
 // TeamIDFromRequest reads a teamID from the given request.
@@ -51,3 +87,171 @@ func ParentIDFromRequest(r *http.Request) (string, error) {
 	}
 	return usrID, nil
 }
+
+// CalendarIDFromRequest reads a calendarID from the given request.
+// if no calendarID can be found, an error is returned.
+func CalendarIDFromRequest(r *http.Request) (string, error) {
+	vars := mux.Vars(r)
+	calendarID, ok := vars["calendarID"]
+	if !ok || len(calendarID) == 0 {
+		return "", ErrDoesNotExistCalendarID
+	}
+	return calendarID, nil
+}
+
+// ValidHolidayCalendar verifies that the given holiday calendar id refers to
+// a known calendar. A nil id is valid.
+func ValidHolidayCalendar(calendarID *string) error {
+	if calendarID == nil {
+		return nil
+	}
+	_, err := holiday.Lookup(*calendarID)
+	return err
+}
+
+// YearFromRequest reads the year query parameter from the given request.
+// if no year is provided, the current year is returned.
+func YearFromRequest(r *http.Request) (int, error) {
+	raw := r.URL.Query().Get("year")
+	if raw == "" {
+		return time.Now().Year(), nil
+	}
+	year, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, ErrInvalidYear
+	}
+	return year, nil
+}
+
+// QueryOptionsFromRequest reads the query options of a read from the given
+// request. Soft deleted entries are included, if include_deleted is true.
+func QueryOptionsFromRequest(r *http.Request) ([]query.Option, error) {
+	raw := r.URL.Query().Get("include_deleted")
+	if raw == "" {
+		return nil, nil
+	}
+	include, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, ErrInvalidIncludeDeleted
+	}
+	return []query.Option{query.IncludeDeleted(include)}, nil
+}
+
+// SetETag sets the ETag header of the given response writer to the given
+// entity version.
+func SetETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// ListOptionsFromRequest reads the query options of a list of the given entity
+// from the given request. Next to include_deleted, lists accept the filters
+// user_id, team_id, from and to, the sort field, e.g. sort=-created_at for a
+// descending order, and the page parameters limit and cursor.
+func ListOptionsFromRequest(r *http.Request, entity model.Entity) ([]query.Option, error) {
+	opts, err := QueryOptionsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	q := r.URL.Query()
+	if userID := q.Get("user_id"); userID != "" {
+		opts = append(opts, query.UserID(userID))
+	}
+	if teamID := q.Get("team_id"); teamID != "" {
+		opts = append(opts, query.TeamID(teamID))
+	}
+	from, err := dateFromQuery(r, "from")
+	if err != nil {
+		return nil, err
+	}
+	to, err := dateFromQuery(r, "to")
+	if err != nil {
+		return nil, err
+	}
+	if from != nil || to != nil {
+		opts = append(opts, query.Period(from, to))
+	}
+	if raw := q.Get("sort"); raw != "" {
+		field, desc, err := query.ParseSort(entity, raw)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, query.SortBy(field, desc))
+	}
+	var limit int
+	if raw := q.Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			return nil, ErrInvalidLimit
+		}
+	}
+	var after *query.Cursor
+	if raw := q.Get("cursor"); raw != "" {
+		after, err = query.ParseCursor(raw)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if limit > 0 || after != nil {
+		opts = append(opts, query.Page(limit, after))
+	}
+	return opts, nil
+}
+
+// dateFromQuery reads the date of the given query parameter, nil if the
+// parameter is missing.
+func dateFromQuery(r *http.Request, key string) (*time.Time, error) {
+	raw := r.URL.Query().Get(key)
+	if raw == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return nil, ErrInvalidPeriod
+	}
+	return &date, nil
+}
+
+// SetNextCursor sets the NextCursorHeader of the given response writer, if the
+// page of n entries read with the given options is full. last is the last
+// entry of the page.
+func SetNextCursor(w http.ResponseWriter, opts []query.Option, n int, last interface{}) error {
+	o := query.New(opts...)
+	if o.Limit == 0 || n < o.Limit {
+		return nil
+	}
+	cursor, err := query.NextCursor(last, o.SortField())
+	if err != nil {
+		return err
+	}
+	w.Header().Set(NextCursorHeader, cursor.String())
+	return nil
+}
+
+// IfMatchStatusCode maps errors of VersionFromRequest to http status codes.
+func IfMatchStatusCode(err error) int {
+	if errors.Is(err, ErrMissingIfMatch) {
+		return http.StatusPreconditionRequired
+	}
+	return http.StatusBadRequest
+}
+
+// VersionFromRequest reads the entity version from the If-Match header of the
+// given request. The wildcard "*" matches every version and is returned as 0.
+func VersionFromRequest(r *http.Request) (int, error) {
+	raw := strings.TrimSpace(r.Header.Get("If-Match"))
+	if raw == "" {
+		return 0, ErrMissingIfMatch
+	}
+	if raw == "*" {
+		return 0, nil
+	}
+	raw, err := strconv.Unquote(strings.TrimPrefix(raw, "W/"))
+	if err != nil {
+		return 0, ErrInvalidIfMatch
+	}
+	version, err := strconv.Atoi(raw)
+	if err != nil || version < 1 {
+		return 0, ErrInvalidIfMatch
+	}
+	return version, nil
+}
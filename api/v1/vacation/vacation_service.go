@@ -9,21 +9,31 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 
+	"github.com/MninaTB/vacadm/api/v1/util"
 	"github.com/MninaTB/vacadm/pkg/database"
+	"github.com/MninaTB/vacadm/pkg/model"
 )
 
-// NewVacation returns a VacationService.
-func NewVacationService(store database.Database, logger logrus.FieldLogger) *VacationService {
+// NewVacation returns a VacationService. The given rounding rule is applied
+// on prorated entitlements, unused days are carried over by the given policy.
+func NewVacationService(
+	store database.Database,
+	rounding database.Rounding,
+	carryOver database.CarryOverPolicy,
+	logger logrus.FieldLogger,
+) *VacationService {
 	return &VacationService{
-		store:  store,
-		logger: logger.WithField("component", "vacation-service"),
+		store:        store,
+		balanceStore: database.NewBalanceDB(store, rounding, carryOver),
+		logger:       logger.WithField("component", "vacation-service"),
 	}
 }
 
 // VacationService implements http.HandlerFunc's to operate on user resources.
 type VacationService struct {
-	store  database.Database
-	logger logrus.FieldLogger
+	store        database.Database
+	balanceStore database.BalanceDB
+	logger       logrus.FieldLogger
 }
 
 // GetByID extracts a vacationID from URL and writes all user information into the
@@ -31,15 +41,27 @@ type VacationService struct {
 func (v *VacationService) GetByID(w http.ResponseWriter, r *http.Request) {
 	logger := v.logger.WithField("method", "read")
 	logger.Info("get vacation by id")
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 	vacID, err := extractVacationID(r)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	vacation, err := v.store.GetVacationByID(r.Context(), vacID)
+	opts, err := util.QueryOptionsFromRequest(r)
 	if err != nil {
 		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	vacation, err := v.store.GetVacationByID(r.Context(), vacID, opts...)
+	if err != nil || vacation.UserID != userID {
+		logger.Error("no vacation found: ", err)
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
@@ -51,16 +73,40 @@ func (v *VacationService) GetByID(w http.ResponseWriter, r *http.Request) {
 	v.logger.Info("get vacation with id: ", vacID)
 }
 
-// List retuns a list of all vacations available on the internal store.
+// List retuns a list of all vacations of the user associated to the userID in
+// the URL. The list can be filtered by period, sorted and paged, see
+// util.ListOptionsFromRequest.
+// Example request:
+// GET /v1/user/{userID}/vacation?from=2022-01-01&to=2022-12-31
 func (v *VacationService) List(w http.ResponseWriter, r *http.Request) {
 	logger := v.logger.WithField("method", "list")
 	logger.Info("get vacation list")
-	list, err := v.store.ListVacations(r.Context())
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	opts, err := util.ListOptionsFromRequest(r, model.EntityVacation)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	list, err := v.store.GetVacationsByUserID(r.Context(), userID, opts...)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	if n := len(list); n > 0 {
+		err = util.SetNextCursor(w, opts, n, list[n-1])
+		if err != nil {
+			logger.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
 	if r.Header.Get("Content-Type") == "application/csv" {
 		logger.Info("csv requested")
 		csvWriter := csv.NewWriter(w)
@@ -94,16 +140,102 @@ func (v *VacationService) List(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// Delete a vacation associated to the given vacationID in the URL.
+// Balance extracts a userID from URL and writes the vacation balance of the
+// requested year into the given response writer. If no year is provided, the
+// current year is used.
+// Example request:
+// GET /v1/user/{userID}/vacation/balance?year=2022
+func (v *VacationService) Balance(w http.ResponseWriter, r *http.Request) {
+	logger := v.logger.WithField("method", "balance")
+	logger.Info("get vacation balance")
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	year, err := util.YearFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	balance, err := v.balanceStore.Balance(r.Context(), userID, year)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	err = json.NewEncoder(w).Encode(balance)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	v.logger.Info("get vacation balance of user with id: ", userID)
+}
+
+// CarryOver extracts a userID from URL and carries the remaining days of the
+// previous year over into the requested year. If no year is provided, the
+// current year is used. The carry-over resource is written into the given
+// response writer, if no days are left 204 is returned.
+// Example request:
+// PUT /v1/user/{userID}/vacation/carry-over?year=2023
+func (v *VacationService) CarryOver(w http.ResponseWriter, r *http.Request) {
+	logger := v.logger.WithField("method", "carry-over")
+	logger.Info("carry over vacation days")
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	year, err := util.YearFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	carried, err := v.balanceStore.CarryOver(r.Context(), userID, year)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if carried == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	err = json.NewEncoder(w).Encode(carried)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	v.logger.Infof("carried %v days of user with id %s over", carried.CarriedDays, userID)
+}
+
+// Delete a vacation associated to the given vacationID in the URL. Vacations
+// of other users than the user of the URL are not found.
 func (v *VacationService) Delete(w http.ResponseWriter, r *http.Request) {
 	logger := v.logger.WithField("method", "delete")
 	logger.Info("delete vacation")
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 	vacID, err := extractVacationID(r)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	vacation, err := v.store.GetVacationByID(r.Context(), vacID)
+	if err != nil || vacation.UserID != userID {
+		logger.Error("no vacation found: ", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
 	err = v.store.DeleteVacation(r.Context(), vacID)
 	if err != nil {
 		logger.Error(err)
@@ -1,44 +1,83 @@
 package vacationrequest
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 
 	"github.com/MninaTB/vacadm/api/v1/util"
 	"github.com/MninaTB/vacadm/pkg/database"
+	"github.com/MninaTB/vacadm/pkg/database/query"
+	"github.com/MninaTB/vacadm/pkg/jwt"
 	"github.com/MninaTB/vacadm/pkg/model"
 	"github.com/MninaTB/vacadm/pkg/notify"
 )
 
-// NewVacationRequestService returns a VacationRequestService.
+// Tokenizer implements methods to verify auth tokens.
+type Tokenizer interface {
+	// Valid if a token is valid, userID and teamID are returned.
+	// if a token is invalid, an error is returned.
+	Valid(token string) (userID string, teamID string, err error)
+}
+
+// NewVacationRequestService returns a VacationRequestService. Submitted
+// requests have to pass the approval chain of the given policy, if no policy
+// is given any parent of the requesting user approves. Requests matching the
+// auto-approval policy are approved by the system right away.
 func NewVacationRequestService(
 	store database.Database,
 	notifier notify.Notifier,
+	approvalPolicy model.ApprovalPolicy,
+	autoApproval model.AutoApprovalPolicy,
 	logger logrus.FieldLogger,
+	t Tokenizer,
 ) *VacationRequestService {
+	if len(approvalPolicy) == 0 {
+		approvalPolicy = model.DefaultApprovalPolicy()
+	}
 	return &VacationRequestService{
-		store:         store,
-		relationStore: database.NewRelationDB(store),
-		notifier:      notifier,
-		logger:        logger.WithField("component", "vacation-request-service"),
+		store:          store,
+		relationStore:  database.NewRelationDB(store),
+		calendarStore:  database.NewCalendarDB(store),
+		ruleStore:      database.NewRuleDB(store),
+		notifier:       notifier,
+		approvalPolicy: approvalPolicy,
+		autoApproval:   autoApproval,
+		tokenizer:      t,
+		logger:         logger.WithField("component", "vacation-request-service"),
 	}
 }
 
 // VacationRequestService implements http.HandlerFunc's to operate on VacationRequest
 // resources.
 type VacationRequestService struct {
-	store         database.Database
-	relationStore database.RelationDB
-	notifier      notify.Notifier
-	logger        logrus.FieldLogger
+	store          database.Database
+	relationStore  database.RelationDB
+	calendarStore  database.CalendarDB
+	ruleStore      database.RuleDB
+	notifier       notify.Notifier
+	approvalPolicy model.ApprovalPolicy
+	autoApproval   model.AutoApprovalPolicy
+	tokenizer      Tokenizer
+	logger         logrus.FieldLogger
 }
 
 // Create reads the given payload and creates a store representation accordingly.
+// Requests, which do not cover a single working day of the users holiday
+// calendar, are rejected. Requests overlapping with other requests or
+// vacations of the user are rejected with 409, the clashing records are
+// returned. Requests violating a blocking rule of the team of the user are
+// rejected with 409 as well. Requests of an absence type without approval, e.g.
+// sick leave, are approved by the requesting user right away, low-risk
+// requests matching the auto-approval policy by the system. An assigned deputy
+// gets informed and has to accept, before the request can be approved.
 func (v *VacationRequestService) Create(w http.ResponseWriter, r *http.Request) {
 	logger := v.logger.WithField("method", "create")
 	logger.Info("create new vacation-request")
@@ -49,27 +88,71 @@ func (v *VacationRequestService) Create(w http.ResponseWriter, r *http.Request)
 		logger.Error(err)
 		return
 	}
-	newVR, err := v.store.CreateVacationRequest(r.Context(), &vr)
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.Error(err)
+		return
+	}
+	vr.UserID = userID
+	// NOTE: the approval chain is recorded on submission.
+	vr.ApprovalSteps = nil
+	user, err := v.store.GetUserByID(r.Context(), userID)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		logger.Error(err)
 		return
 	}
-	userID, err := util.UserIDFromRequest(r)
+	err = v.validateDeputy(r.Context(), user, vr.DeputyID)
+	if err != nil {
+		w.WriteHeader(statusCode(err))
+		logger.Error(err)
+		return
+	}
+	cal, err := v.calendarStore.UserCalendar(r.Context(), userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+	if cal.WorkingDays(vr.From, vr.To) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.Error("vacation-request does not contain any working day")
+		return
+	}
+	requiresApproval, err := v.requiresApproval(r.Context(), &vr)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		logger.Error(err)
 		return
 	}
-	user, err := v.store.GetUserByID(r.Context(), userID)
+	if requiresApproval && !v.checkRules(w, r, logger, &vr) {
+		return
+	}
+	newVR, err := v.store.CreateVacationRequest(r.Context(), &vr)
+	if v.overlap(w, logger, err) {
+		return
+	}
+	if errors.Is(err, model.ErrInvalidStatusTransition) || errors.Is(err, model.ErrInvalidPortion) ||
+		errors.Is(err, model.ErrInvalidDeputy) {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.Error(err)
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		logger.Error(err)
 		return
 	}
-	if user.ParentID != nil {
-		action := fmt.Sprintf("new vacation request from %s %s, id: %s", user.FirstName, user.LastName, user.ID)
-		err = v.notifier.NotifyUser(r.Context(), *user.ParentID, action)
+	err = v.notifyDeputy(r.Context(), user, newVR)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		logger.Error(err)
+		return
+	}
+	// NOTE: drafts are not visible for approvers until they get submitted.
+	if newVR.Status == model.StatusPending {
+		err = v.submitted(r.Context(), user, newVR)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			logger.Error(err)
@@ -91,18 +174,31 @@ func (v *VacationRequestService) Create(w http.ResponseWriter, r *http.Request)
 func (v *VacationRequestService) GetByID(w http.ResponseWriter, r *http.Request) {
 	logger := v.logger.WithField("method", "read")
 	logger.Info("get vacation-request by id")
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 	vrID, err := extractVacationRequestID(r)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	vR, err := v.store.GetVacationRequestByID(r.Context(), vrID)
+	opts, err := util.QueryOptionsFromRequest(r)
 	if err != nil {
 		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	vR, err := v.store.GetVacationRequestByID(r.Context(), vrID, opts...)
+	if err != nil || vR.UserID != userID {
+		logger.Error("no vacation-request found: ", err)
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
+	util.SetETag(w, vR.Version)
 	err = json.NewEncoder(w).Encode(&vR)
 	if err != nil {
 		logger.Error(err)
@@ -111,81 +207,71 @@ func (v *VacationRequestService) GetByID(w http.ResponseWriter, r *http.Request)
 	v.logger.Info("get vacation-request with id: ", vR)
 }
 
-// Approve checks if a user has the necessary permissions to approve a request.
-// If this is the case, a confirmed Vacation entry is created in the store.
+// Approve checks if a user has the necessary permissions to approve the
+// current step of the approval chain of a request. The approver completes the
+// current step and all following steps, they are allowed to approve. Once the
+// last step is completed, a confirmed Vacation entry is created in the store
+// and returned. Otherwise the request is returned with 202.
+// Only pending requests can be approved, an assigned deputy has to accept
+// first.
 func (v *VacationRequestService) Approve(w http.ResponseWriter, r *http.Request) {
 	logger := v.logger.WithField("method", "approve")
-	vrID, err := extractVacationRequestID(r)
-	if err != nil {
-		logger.Error(err)
-		w.WriteHeader(http.StatusBadRequest)
-		return
-	}
-
-	vR, err := v.store.GetVacationRequestByID(r.Context(), vrID)
-	if err != nil {
-		logger.Error(err)
-		w.WriteHeader(http.StatusNotFound)
-		return
-	}
-
-	userID, err := util.UserIDFromRequest(r)
-	if err != nil {
-		logger.Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+	vR, parent, onBehalfOf, ok := v.authorizeApprover(w, r, logger)
+	if !ok {
 		return
 	}
-
-	parentID, err := util.ParentIDFromRequest(r)
-	if err != nil {
-		logger.Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+	if !vR.DeputyConfirmed() {
+		logger.Error(model.ErrDeputyNotAccepted)
+		w.WriteHeader(statusCode(model.ErrDeputyNotAccepted))
 		return
 	}
-
+	vrID, userID, parentID := vR.ID, vR.UserID, parent.ID
 	logger = logger.WithFields(logrus.Fields{
 		"vac-request": vrID,
 		"parentID":    parentID,
 		"userID":      userID,
 	})
 
-	ok, err := v.relationStore.IsParentUser(r.Context(), userID, parentID)
+	logger.Info("approve vacation-request")
+	completed, err := v.approveSteps(r.Context(), vR, parentID, onBehalfOf)
 	if err != nil {
 		logger.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	if !ok {
-		logger.Error("missing permission - can not approve")
-		w.WriteHeader(http.StatusUnauthorized)
+		w.WriteHeader(statusCode(err))
 		return
 	}
-
-	logger.Info("approve vacation-request")
-	vacation := &model.Vacation{
-		UserID:     vR.UserID,
-		ApprovedBy: &parentID,
-		From:       vR.From,
-		To:         vR.To,
-	}
-
-	parent, err := v.store.GetUserByID(r.Context(), parentID)
+	approver, err := v.approverName(r.Context(), parent, onBehalfOf)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+	if !completed {
+		msg := fmt.Sprintf(
+			"your vacation request '%s', from: %s, to: %s got approved by: %s, waiting for approval step %d of %d",
+			vrID, vR.From.String(), vR.To.String(), approver,
+			vR.NextApprovalStep()+1, len(vR.ApprovalSteps),
+		)
+		err = v.notifier.NotifyUser(r.Context(), userID, msg)
+		if err != nil {
+			logger.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		v.encode(w, logger, vR)
+		return
+	}
 
-	vac, err := v.store.CreateVacation(r.Context(), vacation)
+	vac, err := v.approve(r.Context(), vR, parentID, onBehalfOf)
 	if err != nil {
 		logger.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(statusCode(err))
 		return
 	}
 
 	msg := fmt.Sprintf(
-		"your vacation request '%s', from: %s, to: %s got approved by: %s %s",
-		vrID, vR.From.String(), vR.To.String(), parent.FirstName, parent.LastName,
+		"your vacation request '%s', from: %s, to: %s got approved by: %s",
+		vrID, vR.From.String(), vR.To.String(), approver,
 	)
 	err = v.notifier.NotifyUser(r.Context(), userID, msg)
 	if err != nil {
@@ -201,16 +287,121 @@ func (v *VacationRequestService) Approve(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-// List retuns a list of all VacationRequests available on the internal store.
+// approveSteps completes the current approval step of the given request and
+// all following steps, the approver is allowed to approve. The approver acts
+// on behalf of the given user for the current step, if set. Returns true, if
+// the approval chain is completed.
+func (v *VacationRequestService) approveSteps(
+	ctx context.Context,
+	vR *model.VacationRequest,
+	approverID string,
+	onBehalfOf *string,
+) (bool, error) {
+	first := vR.NextApprovalStep()
+	if first < 0 {
+		return true, nil
+	}
+	steps := vR.Copy().ApprovalSteps
+	now := time.Now()
+	for i := first; i < len(steps); i++ {
+		if i != first {
+			var ok bool
+			var err error
+			onBehalfOf, ok, err = v.canApprove(ctx, vR.UserID, approverID, steps[i])
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				break
+			}
+		}
+		steps[i].ApprovedBy = &approverID
+		steps[i].OnBehalfOf = onBehalfOf
+		steps[i].ApprovedAt = &now
+	}
+	updated, err := v.store.UpdateVacationRequest(ctx, &model.VacationRequest{
+		ID:            vR.ID,
+		ApprovalSteps: steps,
+	})
+	if err != nil {
+		return false, err
+	}
+	vR.ApprovalSteps = updated.ApprovalSteps
+	return vR.NextApprovalStep() < 0, nil
+}
+
+// approve moves the given request to approved and creates the according
+// Vacation in one step, this way a request can not be approved twice and stays
+// pending, if the Vacation can not be created. The approver acts on behalf of
+// the given user, if set.
+func (v *VacationRequestService) approve(
+	ctx context.Context,
+	vR *model.VacationRequest,
+	approverID string,
+	onBehalfOf *string,
+) (*model.Vacation, error) {
+	updated, vac, err := v.store.ApproveVacationRequest(ctx, vR.ID, &model.Vacation{
+		UserID:             vR.UserID,
+		ApprovedBy:         &approverID,
+		ApprovedOnBehalfOf: onBehalfOf,
+		AbsenceTypeID:      vR.AbsenceTypeID,
+		DeputyID:           vR.DeputyID,
+		From:               vR.From,
+		To:                 vR.To,
+		Portion:            vR.Portion,
+		Hours:              vR.Hours,
+	})
+	if err != nil {
+		return nil, err
+	}
+	vR.Status = updated.Status
+	vR.VacationID = updated.VacationID
+	return vac, nil
+}
+
+// List retuns a list of all VacationRequests of the user associated to the
+// userID in the URL. The list can be filtered by one or more comma separated
+// states and by period, sorted and paged, see util.ListOptionsFromRequest.
+// Example request:
+// GET /v1/user/{userID}/vacation/request?status=pending,approved&sort=-from&limit=20
 func (v *VacationRequestService) List(w http.ResponseWriter, r *http.Request) {
 	logger := v.logger.WithField("method", "list")
 	logger.Info("retrieve vacation-request list")
-	list, err := v.store.ListVacationRequests(r.Context())
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	states, err := statusFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	opts, err := util.ListOptionsFromRequest(r, model.EntityVacationRequest)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if len(states) > 0 {
+		opts = append(opts, query.Status(states...))
+	}
+	list, err := v.store.GetVacationRequestsByUserID(r.Context(), userID, opts...)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
+	if n := len(list); n > 0 {
+		err = util.SetNextCursor(w, opts, n, list[n-1])
+		if err != nil {
+			logger.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
 	err = json.NewEncoder(w).Encode(&list)
 	if err != nil {
 		logger.Error(err)
@@ -220,7 +411,13 @@ func (v *VacationRequestService) List(w http.ResponseWriter, r *http.Request) {
 }
 
 // Update reads new VacationRequest information from the request body and
-// updates the store representation accordingly.
+// updates the request of the URL accordingly, the id of the payload is ignored.
+// The status can not be changed
+// by an update, use the dedicated lifecycle endpoints instead. The same applies
+// to the answer of the deputy, the rejection and the rule violations,
+// assigning another deputy restarts the assignment. Changing the period is
+// checked like a new request and restarts the approval chain and the deputy
+// assignment.
 func (v *VacationRequestService) Update(w http.ResponseWriter, r *http.Request) {
 	logger := v.logger.WithField("method", "update")
 	logger.Info("update vacation-request")
@@ -231,10 +428,18 @@ func (v *VacationRequestService) Update(w http.ResponseWriter, r *http.Request)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	newVR, err := v.store.UpdateVacationRequest(r.Context(), &vr)
+	vr.Status = ""
+	vr.VacationID = nil
+	vr.ApprovalSteps = nil
+	vr.RuleViolations = nil
+	vr.DeputyStatus = ""
+	vr.RejectedBy = nil
+	vr.RejectedOnBehalfOf = nil
+	vr.RejectionReason = nil
+	vr.Version, err = util.VersionFromRequest(r)
 	if err != nil {
 		logger.Error(err)
-		w.WriteHeader(http.StatusBadRequest)
+		w.WriteHeader(util.IfMatchStatusCode(err))
 		return
 	}
 	userID, err := util.UserIDFromRequest(r)
@@ -243,21 +448,70 @@ func (v *VacationRequestService) Update(w http.ResponseWriter, r *http.Request)
 		logger.Error(err)
 		return
 	}
+	vr.ID, err = extractVacationRequestID(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	current, err := v.store.GetVacationRequestByID(r.Context(), vr.ID)
+	if err != nil || current.UserID != userID {
+		logger.Error("no vacation-request found: ", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
 	user, err := v.store.GetUserByID(r.Context(), userID)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		logger.Error(err)
 		return
 	}
+	// NOTE: the update is previewed, this way the checks of Create run on the
+	// resulting request.
+	updated := current.Copy()
+	err = updated.Update(&vr)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(statusCode(err))
+		return
+	}
+	err = v.validateDeputy(r.Context(), user, updated.DeputyID)
+	if err != nil {
+		w.WriteHeader(statusCode(err))
+		logger.Error(err)
+		return
+	}
+	periodChanged := current.PeriodChanged(&vr)
+	if periodChanged && !v.recheckPeriod(w, r, logger, user, &vr, updated) {
+		return
+	}
+	newVR, err := v.store.UpdateVacationRequest(r.Context(), &vr)
+	if v.overlap(w, logger, err) {
+		return
+	}
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(statusCode(err))
+		return
+	}
+	if vr.DeputyID != nil || periodChanged {
+		err = v.notifyDeputy(r.Context(), user, newVR)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			logger.Error(err)
+			return
+		}
+	}
 	if user.ParentID != nil {
 		action := fmt.Sprintf("updated vacation request from %s %s, id: %s", user.FirstName, user.LastName, user.ID)
-		err = v.notifier.NotifyUser(r.Context(), *user.ParentID, action)
+		err = v.notifyApprover(r.Context(), *user.ParentID, action)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			logger.Error(err)
 			return
 		}
 	}
+	util.SetETag(w, newVR.Version)
 	err = json.NewEncoder(w).Encode(&newVR)
 	if err != nil {
 		logger.Error(err)
@@ -266,16 +520,85 @@ func (v *VacationRequestService) Update(w http.ResponseWriter, r *http.Request)
 	v.logger.Info("update vacation-request with id: ", newVR.ID)
 }
 
+// recheckPeriod verifies the changed period of the given updated request like
+// Create does. For pending requests the approval chain and the violated team
+// rules of the new period are added to the given update vr. If the period is
+// not accepted, an error code is written to the response writer and false is
+// returned.
+func (v *VacationRequestService) recheckPeriod(
+	w http.ResponseWriter,
+	r *http.Request,
+	logger logrus.FieldLogger,
+	user *model.User,
+	vr, updated *model.VacationRequest,
+) bool {
+	cal, err := v.calendarStore.UserCalendar(r.Context(), user.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		logger.Error(err)
+		return false
+	}
+	if cal.WorkingDays(updated.From, updated.To) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.Error("vacation-request does not contain any working day")
+		return false
+	}
+	requiresApproval, err := v.requiresApproval(r.Context(), updated)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		logger.Error(err)
+		return false
+	}
+	if !requiresApproval {
+		return true
+	}
+	if !v.checkRules(w, r, logger, updated) {
+		return false
+	}
+	// NOTE: drafts record their approval chain on submission.
+	if updated.Status != model.StatusPending {
+		return true
+	}
+	vr.ApprovalSteps, err = v.approvalSteps(r.Context(), user, updated)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		logger.Error(err)
+		return false
+	}
+	violations, err := v.ruleStore.Violations(r.Context(), updated)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		logger.Error(err)
+		return false
+	}
+	// NOTE: an empty list replaces the violations of the previous period.
+	vr.RuleViolations = append([]model.RuleViolation{}, violations...)
+	return true
+}
+
 // Delete a VacationRequest associated to the given VacationRequestID in the URL.
+// Requests of other users than the user of the URL are not found.
 func (v *VacationRequestService) Delete(w http.ResponseWriter, r *http.Request) {
 	logger := v.logger.WithField("method", "delete")
 	logger.Info("delete vacation-request")
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 	vrID, err := extractVacationRequestID(r)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	vR, err := v.store.GetVacationRequestByID(r.Context(), vrID)
+	if err != nil || vR.UserID != userID {
+		logger.Error("no vacation-request found: ", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
 	err = v.store.DeleteVacationRequest(r.Context(), vrID)
 	if err != nil {
 		logger.Error(err)
@@ -286,6 +609,1037 @@ func (v *VacationRequestService) Delete(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusAccepted)
 }
 
+type rejectRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Reject checks if a user has the necessary permissions to decide about the
+// current step of the approval chain of a request.
+// If this is the case, the request is moved to rejected and the requesting
+// user gets informed about the given reason. Rejected requests remain
+// available in the history of the user.
+// Example request:
+// PUT /v1/user/{userID}/vacation/request/{vacationRequestID}/reject/{parentID}
+// {"reason": "team event"}
+func (v *VacationRequestService) Reject(w http.ResponseWriter, r *http.Request) {
+	logger := v.logger.WithField("method", "reject")
+	var rr rejectRequest
+	err := json.NewDecoder(r.Body).Decode(&rr)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(rr.Reason) == "" {
+		logger.Error(model.ErrMissingRejectionReason)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	vR, parent, onBehalfOf, ok := v.authorizeApprover(w, r, logger)
+	if !ok {
+		return
+	}
+	logger = logger.WithFields(logrus.Fields{
+		"vac-request": vR.ID,
+		"parentID":    parent.ID,
+		"userID":      vR.UserID,
+	})
+
+	logger.Info("reject vacation-request")
+	vR, err = v.store.UpdateVacationRequest(r.Context(), &model.VacationRequest{
+		ID:                 vR.ID,
+		Status:             model.StatusRejected,
+		RejectedBy:         &parent.ID,
+		RejectedOnBehalfOf: onBehalfOf,
+		RejectionReason:    &rr.Reason,
+	})
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(statusCode(err))
+		return
+	}
+
+	approver, err := v.approverName(r.Context(), parent, onBehalfOf)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	msg := fmt.Sprintf(
+		"your vacation request '%s', from: %s, to: %s got rejected by: %s, reason: %s",
+		vR.ID, vR.From.String(), vR.To.String(), approver, rr.Reason,
+	)
+	err = v.notifier.NotifyUser(r.Context(), vR.UserID, msg)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	v.encode(w, logger, vR)
+}
+
+// authorizeApprover loads the vacation-request of the URL and verifies that the
+// parentID of the URL is allowed to decide about its current approval step.
+// Requests without approval chain can be decided by any parent of the user.
+// Active delegates of an approver are accepted, in this case the delegating
+// user is returned as well. If the parentID is not allowed to decide, an
+// error code is written to the response writer and false is returned.
+func (v *VacationRequestService) authorizeApprover(
+	w http.ResponseWriter,
+	r *http.Request,
+	logger logrus.FieldLogger,
+) (*model.VacationRequest, *model.User, *string, bool) {
+	vrID, err := extractVacationRequestID(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, nil, nil, false
+	}
+
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, nil, nil, false
+	}
+
+	vR, err := v.store.GetVacationRequestByID(r.Context(), vrID)
+	if err != nil || vR.UserID != userID {
+		logger.Error("no vacation-request found: ", err)
+		w.WriteHeader(http.StatusNotFound)
+		return nil, nil, nil, false
+	}
+
+	parentID, err := util.ParentIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, nil, nil, false
+	}
+
+	step := model.ApprovalStep{Role: model.ApproverParent}
+	if i := vR.NextApprovalStep(); i >= 0 {
+		step = vR.ApprovalSteps[i]
+	}
+	onBehalfOf, ok, err := v.canApprove(r.Context(), userID, parentID, step)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, nil, nil, false
+	}
+	if !ok {
+		logger.Error("missing permission - can not decide about vacation-request")
+		w.WriteHeader(http.StatusUnauthorized)
+		return nil, nil, nil, false
+	}
+
+	parent, err := v.store.GetUserByID(r.Context(), parentID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, nil, nil, false
+	}
+	return vR, parent, onBehalfOf, true
+}
+
+// canApprove verifies if approverID is allowed to approve the given step of a
+// request of userID, either directly or as active delegate. If the approver
+// acts as delegate, the delegating user is returned.
+func (v *VacationRequestService) canApprove(
+	ctx context.Context,
+	userID, approverID string,
+	step model.ApprovalStep,
+) (*string, bool, error) {
+	ok, err := v.relationStore.CanApprove(ctx, userID, approverID, step)
+	if err != nil || ok {
+		return nil, ok, err
+	}
+	// NOTE: users can not decide about their own requests as delegate.
+	if userID == approverID {
+		return nil, false, nil
+	}
+	delegators, err := v.relationStore.Delegators(ctx, approverID, time.Now())
+	if err != nil {
+		return nil, false, err
+	}
+	for _, delegatorID := range delegators {
+		ok, err := v.relationStore.CanApprove(ctx, userID, delegatorID, step)
+		if err != nil {
+			return nil, false, err
+		}
+		if ok {
+			onBehalfOf := delegatorID
+			return &onBehalfOf, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// approverName returns the name of the given approver, which is extended by
+// the delegating user, if set.
+func (v *VacationRequestService) approverName(ctx context.Context, approver *model.User, onBehalfOf *string) (string, error) {
+	name := fmt.Sprintf("%s %s", approver.FirstName, approver.LastName)
+	if onBehalfOf == nil {
+		return name, nil
+	}
+	delegator, err := v.store.GetUserByID(ctx, *onBehalfOf)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s on behalf of %s %s", name, delegator.FirstName, delegator.LastName), nil
+}
+
+// notifyApprover informs the given approver. If the approver delegated the
+// approval rights, the active delegates are informed instead.
+func (v *VacationRequestService) notifyApprover(ctx context.Context, approverID, msg string) error {
+	delegates, err := v.relationStore.Delegates(ctx, approverID, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(delegates) == 0 {
+		delegates = []string{approverID}
+	}
+	for _, delegateID := range delegates {
+		err = v.notifier.NotifyUser(ctx, delegateID, msg)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Submit moves a draft vacation-request to pending and informs the parent of
+// the requesting user. Drafts violating a blocking rule of the team of the
+// user are rejected with 409.
+func (v *VacationRequestService) Submit(w http.ResponseWriter, r *http.Request) {
+	logger := v.logger.WithField("method", "submit")
+	vrID, err := extractVacationRequestID(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	// NOTE: unknown requests are handled by changeStatus.
+	if draft, err := v.store.GetVacationRequestByID(r.Context(), vrID); err == nil {
+		requiresApproval, err := v.requiresApproval(r.Context(), draft)
+		if err != nil {
+			logger.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if requiresApproval && !v.checkRules(w, r, logger, draft) {
+			return
+		}
+	}
+	vR, ok := v.changeStatus(w, r, logger, model.StatusPending)
+	if !ok {
+		return
+	}
+	user, err := v.store.GetUserByID(r.Context(), vR.UserID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	err = v.submitted(r.Context(), user, vR)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	v.encode(w, logger, vR)
+}
+
+// submitted handles a request, which just became pending. Requests of an
+// absence type without approval are approved by the requesting user right
+// away, requests matching the auto-approval policy by the system. All other
+// requests record the approval chain of the policy and the violated team
+// rules. The parent of the user is informed in all cases, violated rules are
+// named as warning.
+func (v *VacationRequestService) submitted(ctx context.Context, user *model.User, vR *model.VacationRequest) error {
+	requiresApproval, err := v.requiresApproval(ctx, vR)
+	if err != nil {
+		return err
+	}
+	var violations []model.RuleViolation
+	autoApproved := false
+	if requiresApproval {
+		violations, err = v.ruleStore.Violations(ctx, vR)
+		if err != nil {
+			return err
+		}
+		// NOTE: requests violating a team rule are never approved automatically.
+		if len(violations) == 0 {
+			autoApproved, err = v.autoApprovable(ctx, user, vR)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	action := fmt.Sprintf("new vacation request from %s %s, id: %s", user.FirstName, user.LastName, user.ID)
+	switch {
+	case !requiresApproval:
+		_, err := v.approve(ctx, vR, user.ID, nil)
+		if err != nil {
+			return err
+		}
+		action = fmt.Sprintf(
+			"new absence of %s %s, from: %s, to: %s",
+			user.FirstName, user.LastName, vR.From.String(), vR.To.String(),
+		)
+	case autoApproved:
+		_, err := v.approve(ctx, vR, model.SystemPrincipalID, nil)
+		if err != nil {
+			return err
+		}
+		msg := fmt.Sprintf(
+			"your vacation request '%s', from: %s, to: %s got approved automatically",
+			vR.ID, vR.From.String(), vR.To.String(),
+		)
+		err = v.notifier.NotifyUser(ctx, user.ID, msg)
+		if err != nil {
+			return err
+		}
+		action = fmt.Sprintf(
+			"new vacation of %s %s, from: %s, to: %s got approved automatically",
+			user.FirstName, user.LastName, vR.From.String(), vR.To.String(),
+		)
+	default:
+		steps, err := v.approvalSteps(ctx, user, vR)
+		if err != nil {
+			return err
+		}
+		updated, err := v.store.UpdateVacationRequest(ctx, &model.VacationRequest{
+			ID:             vR.ID,
+			ApprovalSteps:  steps,
+			RuleViolations: violations,
+		})
+		if err != nil {
+			return err
+		}
+		vR.ApprovalSteps = updated.ApprovalSteps
+		vR.RuleViolations = updated.RuleViolations
+		for _, violation := range violations {
+			action += ", warning: " + violation.String()
+		}
+	}
+	if user.ParentID == nil {
+		return nil
+	}
+	return v.notifyApprover(ctx, *user.ParentID, action)
+}
+
+// requiresApproval reports whether the absence type of the given request
+// requires approval. Regular vacations always require approval.
+func (v *VacationRequestService) requiresApproval(ctx context.Context, vR *model.VacationRequest) (bool, error) {
+	if vR.AbsenceTypeID == nil {
+		return true, nil
+	}
+	absenceType, err := v.store.GetAbsenceTypeByID(ctx, *vR.AbsenceTypeID)
+	if err != nil {
+		return false, err
+	}
+	return absenceType.RequiresApproval, nil
+}
+
+type ruleViolationResponse struct {
+	RuleViolations []model.RuleViolation `json:"rule_violations"`
+}
+
+// checkRules verifies that the given request does not violate a blocking rule
+// of the team of the user. Otherwise 409 and the blocking violations are
+// written to the response writer and false is returned.
+func (v *VacationRequestService) checkRules(
+	w http.ResponseWriter,
+	r *http.Request,
+	logger logrus.FieldLogger,
+	vR *model.VacationRequest,
+) bool {
+	violations, err := v.ruleStore.Violations(r.Context(), vR)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return false
+	}
+	var resp ruleViolationResponse
+	for _, violation := range violations {
+		if violation.Blocking {
+			resp.RuleViolations = append(resp.RuleViolations, violation)
+		}
+	}
+	if len(resp.RuleViolations) == 0 {
+		return true
+	}
+	logger.Error("vacation-request violates blocking team rules: ", resp.RuleViolations)
+	w.WriteHeader(http.StatusConflict)
+	err = json.NewEncoder(w).Encode(&resp)
+	if err != nil {
+		logger.Error(err)
+	}
+	return false
+}
+
+// approvalSteps returns the approval chain of the policy for the given request.
+// Steps, which can not be resolved for the user, e.g. a manager step of a user
+// without parent or a team owner step of a user without team membership, are
+// skipped. If no step remains, any parent approves.
+func (v *VacationRequestService) approvalSteps(ctx context.Context, user *model.User, vR *model.VacationRequest) ([]model.ApprovalStep, error) {
+	days, err := v.workingDays(ctx, user, vR)
+	if err != nil {
+		return nil, err
+	}
+	memberships, err := v.store.ListUserTeamMemberships(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	steps := make([]model.ApprovalStep, 0, len(v.approvalPolicy))
+	for _, step := range v.approvalPolicy.Steps(days) {
+		switch step.Role {
+		case model.ApproverManager:
+			if user.ParentID == nil {
+				continue
+			}
+		case model.ApproverTeamOwner:
+			if len(memberships) == 0 {
+				continue
+			}
+		}
+		steps = append(steps, step)
+	}
+	if len(steps) == 0 {
+		steps = append(steps, model.ApprovalStep{Role: model.ApproverParent})
+	}
+	return steps, nil
+}
+
+// autoApprovable reports whether the given request matches the auto-approval
+// policy. Requests waiting for their deputy pass the approval chain.
+func (v *VacationRequestService) autoApprovable(ctx context.Context, user *model.User, vR *model.VacationRequest) (bool, error) {
+	if !v.autoApproval.Enabled() || !vR.DeputyConfirmed() {
+		return false, nil
+	}
+	days, err := v.workingDays(ctx, user, vR)
+	if err != nil {
+		return false, err
+	}
+	availability, err := v.teamAvailability(ctx, user, vR)
+	if err != nil {
+		return false, err
+	}
+	return v.autoApproval.Matches(vR, days, availability, time.Now()), nil
+}
+
+// workingDays returns the working days of the given request, according to the
+// holiday calendar of the user and the portion of the request.
+func (v *VacationRequestService) workingDays(ctx context.Context, user *model.User, vR *model.VacationRequest) (float64, error) {
+	cal, err := v.calendarStore.UserCalendar(ctx, user.ID)
+	if err != nil {
+		return 0, err
+	}
+	return cal.WorkingDays(vR.From, vR.To) * vR.Portion.Fraction(vR.Hours), nil
+}
+
+// teamAvailability returns the lowest share of available members of the teams
+// of the user on a working day of the given request, assuming the request is
+// approved. Members count by their allocation, e.g. a member split across two
+// teams counts half in both of them. Users without team have an availability
+// of 0, unless the policy does not check the availability at all.
+func (v *VacationRequestService) teamAvailability(ctx context.Context, user *model.User, vR *model.VacationRequest) (float64, error) {
+	if v.autoApproval.MinTeamAvailability <= 0 {
+		return 1, nil
+	}
+	memberships, err := v.store.ListUserTeamMemberships(ctx, user.ID)
+	if err != nil {
+		return 0, err
+	}
+	if len(memberships) == 0 {
+		return 0, nil
+	}
+	lowest := 1.0
+	for _, m := range memberships {
+		availability, err := v.availability(ctx, m, vR)
+		if err != nil {
+			return 0, err
+		}
+		if availability < lowest {
+			lowest = availability
+		}
+	}
+	return lowest, nil
+}
+
+// availability returns the lowest share of available members of the team of
+// the given membership on a working day of the given request, assuming the
+// request is approved.
+func (v *VacationRequestService) availability(ctx context.Context, membership *model.TeamMembership, vR *model.VacationRequest) (float64, error) {
+	memberships, err := v.store.ListTeamMemberships(ctx, membership.TeamID)
+	if err != nil {
+		return 0, err
+	}
+	shares := map[string]float64{}
+	var members float64
+	for _, m := range memberships {
+		shares[m.UserID] = m.Share()
+		members += m.Share()
+	}
+	if members == 0 {
+		return 0, nil
+	}
+	vacs, err := v.store.GetVacationsByTeamID(ctx, membership.TeamID)
+	if err != nil {
+		return 0, err
+	}
+	cal, err := v.calendarStore.TeamCalendar(ctx, membership.TeamID)
+	if err != nil {
+		return 0, err
+	}
+	lowest := 1.0
+	for d := vR.From; !d.After(vR.To); d = d.AddDate(0, 0, 1) {
+		if !cal.IsWorkingDay(d) {
+			continue
+		}
+		absent := vR.Portion.Fraction(vR.Hours) * membership.Share()
+		for _, vac := range vacs {
+			if !d.Before(vac.From) && !d.After(vac.To) {
+				absent += vac.Portion.Fraction(vac.Hours) * shares[vac.UserID]
+			}
+		}
+		if availability := (members - absent) / members; availability < lowest {
+			lowest = availability
+		}
+	}
+	return lowest, nil
+}
+
+// Withdraw moves a draft or pending vacation-request to withdrawn.
+func (v *VacationRequestService) Withdraw(w http.ResponseWriter, r *http.Request) {
+	logger := v.logger.WithField("method", "withdraw")
+	vR, ok := v.changeStatus(w, r, logger, model.StatusWithdrawn)
+	if !ok {
+		return
+	}
+	v.encode(w, logger, vR)
+}
+
+// Cancel moves an approved vacation-request to cancelled. The associated
+// Vacation is removed along with the status change and the parent of the
+// requesting user gets informed.
+func (v *VacationRequestService) Cancel(w http.ResponseWriter, r *http.Request) {
+	logger := v.logger.WithField("method", "cancel")
+	vrID, err := extractVacationRequestID(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	vR, err := v.store.GetVacationRequestByID(r.Context(), vrID)
+	if err != nil || vR.UserID != userID {
+		logger.Error("no vacation-request found: ", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	vR, err = v.store.CancelVacationRequest(r.Context(), vrID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(statusCode(err))
+		return
+	}
+	// NOTE: the request is cancelled at this point, failing notifications
+	// are logged only.
+	user, err := v.store.GetUserByID(r.Context(), vR.UserID)
+	if err != nil {
+		logger.Error(err)
+	}
+	if err == nil && user.ParentID != nil {
+		action := fmt.Sprintf(
+			"vacation from %s %s, from: %s, to: %s got cancelled",
+			user.FirstName, user.LastName, vR.From.String(), vR.To.String(),
+		)
+		err = v.notifyApprover(r.Context(), *user.ParentID, action)
+		if err != nil {
+			logger.Error(err)
+		}
+	}
+	v.encode(w, logger, vR)
+}
+
+// CreateComment reads the given payload and adds a comment of the user of the
+// token to the vacation-request in the URL. The requesting user and the other
+// commenters get informed, the approvers of the user as well, if the
+// requesting user comments.
+// Example request:
+// PUT /v1/user/{userID}/vacation/request/{vacationRequestID}/comments
+// {"text": "can you move it one week?"}
+func (v *VacationRequestService) CreateComment(w http.ResponseWriter, r *http.Request) {
+	logger := v.logger.WithField("method", "create-comment")
+	logger.Info("create new comment")
+	vR, author, ok := v.authorizeParticipant(w, r, logger)
+	if !ok {
+		return
+	}
+	var comment model.Comment
+	err := json.NewDecoder(r.Body).Decode(&comment)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	comment.VacationRequestID = vR.ID
+	comment.AuthorID = author.ID
+	// NOTE: previous commenters are collected before the new comment is
+	// stored, the author itself is not informed.
+	comments, err := v.store.ListComments(r.Context(), vR.ID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	newComment, err := v.store.CreateComment(r.Context(), &comment)
+	if errors.Is(err, model.ErrInvalidComment) {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	err = v.notifyParticipants(r.Context(), vR, author, comments)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(newComment)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	v.logger.Info("create comment with ID: ", newComment.ID)
+}
+
+// ListComments writes all comments of the vacation-request in the URL into the
+// given response writer, ordered by creation.
+func (v *VacationRequestService) ListComments(w http.ResponseWriter, r *http.Request) {
+	logger := v.logger.WithField("method", "list-comments")
+	logger.Info("retrieve comment list")
+	vR, _, ok := v.authorizeParticipant(w, r, logger)
+	if !ok {
+		return
+	}
+	list, err := v.store.ListComments(r.Context(), vR.ID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(&list)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// authorizeParticipant loads the vacation-request of the URL and verifies that
+// the user of the token takes part in the discussion of the request. The
+// requesting user and everyone, who is allowed to decide about a step of the
+// approval chain, directly or as active delegate, take part. If this is not
+// the case, an error code is written to the response writer and false is
+// returned.
+func (v *VacationRequestService) authorizeParticipant(
+	w http.ResponseWriter,
+	r *http.Request,
+	logger logrus.FieldLogger,
+) (*model.VacationRequest, *model.User, bool) {
+	vrID, err := extractVacationRequestID(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, nil, false
+	}
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, nil, false
+	}
+	token, err := jwt.ExtractToken(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, nil, false
+	}
+	participantID, _, err := v.tokenizer.Valid(token)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return nil, nil, false
+	}
+	vR, err := v.store.GetVacationRequestByID(r.Context(), vrID)
+	if err != nil || vR.UserID != userID {
+		logger.Error("no vacation-request found: ", err)
+		w.WriteHeader(http.StatusNotFound)
+		return nil, nil, false
+	}
+	if participantID != vR.UserID {
+		steps := append([]model.ApprovalStep{{Role: model.ApproverParent}}, vR.ApprovalSteps...)
+		ok := false
+		for _, step := range steps {
+			_, ok, err = v.canApprove(r.Context(), vR.UserID, participantID, step)
+			if err != nil {
+				logger.Error(err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return nil, nil, false
+			}
+			if ok {
+				break
+			}
+		}
+		if !ok {
+			logger.Error("missing permission - not part of the vacation-request")
+			w.WriteHeader(http.StatusForbidden)
+			return nil, nil, false
+		}
+	}
+	participant, err := v.store.GetUserByID(r.Context(), participantID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	return vR, participant, true
+}
+
+// notifyParticipants informs the requesting user and the authors of the given
+// comments about a new comment of author. Comments of the requesting user are
+// forwarded to the parent approver as well.
+func (v *VacationRequestService) notifyParticipants(
+	ctx context.Context,
+	vR *model.VacationRequest,
+	author *model.User,
+	comments []*model.Comment,
+) error {
+	action := fmt.Sprintf(
+		"%s %s commented on vacation request '%s', from: %s, to: %s",
+		author.FirstName, author.LastName, vR.ID, vR.From.String(), vR.To.String(),
+	)
+	informed := map[string]bool{author.ID: true}
+	if author.ID == vR.UserID && author.ParentID != nil {
+		informed[*author.ParentID] = true
+		err := v.notifyApprover(ctx, *author.ParentID, action)
+		if err != nil {
+			return err
+		}
+	}
+	participants := []string{vR.UserID}
+	for _, c := range comments {
+		participants = append(participants, c.AuthorID)
+	}
+	for _, participantID := range participants {
+		if informed[participantID] {
+			continue
+		}
+		informed[participantID] = true
+		err := v.notifier.NotifyUser(ctx, participantID, action)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListDeputyRequests writes all vacation-requests, in which the user of the
+// URL is assigned as deputy, into the given response writer. The list can be
+// filtered by one or more comma separated states of the requests and is paged
+// like List.
+// Example request:
+// GET /v1/user/{userID}/deputy?status=draft,pending
+func (v *VacationRequestService) ListDeputyRequests(w http.ResponseWriter, r *http.Request) {
+	logger := v.logger.WithField("method", "list-deputy")
+	logger.Info("retrieve deputy vacation-request list")
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	states, err := statusFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	opts, err := util.ListOptionsFromRequest(r, model.EntityVacationRequest)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	opts = append(opts, query.DeputyID(userID))
+	if len(states) > 0 {
+		opts = append(opts, query.Status(states...))
+	}
+	list, err := v.store.ListVacationRequests(r.Context(), opts...)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if n := len(list); n > 0 {
+		err = util.SetNextCursor(w, opts, n, list[n-1])
+		if err != nil {
+			logger.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+	err = json.NewEncoder(w).Encode(&list)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// AcceptDeputy accepts the deputy assignment of the user of the URL for the
+// vacation-request of the URL. The requesting user gets informed, the request
+// can be approved afterwards.
+func (v *VacationRequestService) AcceptDeputy(w http.ResponseWriter, r *http.Request) {
+	logger := v.logger.WithField("method", "accept-deputy")
+	vR, ok := v.answerDeputy(w, r, logger, model.DeputyAccepted)
+	if !ok {
+		return
+	}
+	v.encode(w, logger, vR)
+}
+
+// DeclineDeputy declines the deputy assignment of the user of the URL for the
+// vacation-request of the URL. The requesting user gets informed and has to
+// assign another deputy.
+func (v *VacationRequestService) DeclineDeputy(w http.ResponseWriter, r *http.Request) {
+	logger := v.logger.WithField("method", "decline-deputy")
+	vR, ok := v.answerDeputy(w, r, logger, model.DeputyDeclined)
+	if !ok {
+		return
+	}
+	v.encode(w, logger, vR)
+}
+
+// answerDeputy stores the answer of the deputy of the URL and informs the
+// requesting user. If the user of the URL is not the deputy of the request, or
+// the assignment is already answered, an error code is written to the response
+// writer and false is returned.
+func (v *VacationRequestService) answerDeputy(
+	w http.ResponseWriter,
+	r *http.Request,
+	logger logrus.FieldLogger,
+	answer model.DeputyStatus,
+) (*model.VacationRequest, bool) {
+	vrID, err := extractVacationRequestID(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, false
+	}
+	deputyID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, false
+	}
+	vR, err := v.store.GetVacationRequestByID(r.Context(), vrID)
+	if err != nil || vR.DeputyID == nil || *vR.DeputyID != deputyID {
+		logger.Error("no vacation-request found: ", err)
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+	logger.WithField("vac-request", vrID).Infof("deputy %s assignment", answer)
+	vR, err = v.store.UpdateVacationRequest(r.Context(), &model.VacationRequest{
+		ID:           vrID,
+		DeputyStatus: answer,
+	})
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(statusCode(err))
+		return nil, false
+	}
+	deputy, err := v.store.GetUserByID(r.Context(), deputyID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, false
+	}
+	action := fmt.Sprintf(
+		"%s %s %s to cover for your vacation request '%s', from: %s, to: %s",
+		deputy.FirstName, deputy.LastName, answer, vR.ID, vR.From.String(), vR.To.String(),
+	)
+	err = v.notifier.NotifyUser(r.Context(), vR.UserID, action)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, false
+	}
+	return vR, true
+}
+
+// validateDeputy verifies that the given deputy is an active user and member
+// of one of the teams of the requesting user. Users without team can choose
+// any active user.
+func (v *VacationRequestService) validateDeputy(ctx context.Context, user *model.User, deputyID *string) error {
+	if deputyID == nil {
+		return nil
+	}
+	if *deputyID == user.ID {
+		return fmt.Errorf("%w: users can not cover for themselves", model.ErrInvalidDeputy)
+	}
+	deputy, err := v.store.GetUserByID(ctx, *deputyID)
+	if err != nil || deputy.DeletedAt != nil {
+		return fmt.Errorf("%w: unknown user %s", model.ErrInvalidDeputy, *deputyID)
+	}
+	memberships, err := v.store.ListUserTeamMemberships(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if len(memberships) == 0 {
+		return nil
+	}
+	for _, m := range memberships {
+		isMember, err := v.relationStore.IsTeamMember(ctx, m.TeamID, deputy.ID)
+		if err != nil {
+			return err
+		}
+		if isMember {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s is no member of the teams of the user", model.ErrInvalidDeputy, deputy.ID)
+}
+
+// notifyDeputy asks the deputy of the given request of user to accept the
+// assignment, as long as it is not answered yet.
+func (v *VacationRequestService) notifyDeputy(ctx context.Context, user *model.User, vR *model.VacationRequest) error {
+	if vR.DeputyID == nil || vR.DeputyStatus != model.DeputyPending {
+		return nil
+	}
+	action := fmt.Sprintf(
+		"%s %s asks you to cover for vacation request '%s', from: %s, to: %s, please accept or decline",
+		user.FirstName, user.LastName, vR.ID, vR.From.String(), vR.To.String(),
+	)
+	return v.notifier.NotifyUser(ctx, *vR.DeputyID, action)
+}
+
+// changeStatus moves the vacation-request of the URL to the given status.
+// If the status can not be changed, an error code is written to the response
+// writer and false is returned.
+func (v *VacationRequestService) changeStatus(
+	w http.ResponseWriter,
+	r *http.Request,
+	logger logrus.FieldLogger,
+	status model.VacationRequestStatus,
+) (*model.VacationRequest, bool) {
+	vrID, err := extractVacationRequestID(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, false
+	}
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, false
+	}
+	vR, err := v.store.GetVacationRequestByID(r.Context(), vrID)
+	if err != nil || vR.UserID != userID {
+		logger.Error("no vacation-request found: ", err)
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+	logger.WithField("vac-request", vrID).Infof("change vacation-request status to %s", status)
+	vR, err = v.store.UpdateVacationRequest(r.Context(), &model.VacationRequest{
+		ID:     vrID,
+		Status: status,
+	})
+	if v.overlap(w, logger, err) {
+		return nil, false
+	}
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(statusCode(err))
+		return nil, false
+	}
+	return vR, true
+}
+
+func (v *VacationRequestService) encode(w http.ResponseWriter, logger logrus.FieldLogger, vR *model.VacationRequest) {
+	err := json.NewEncoder(w).Encode(vR)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// overlap writes 409 and the clashing records to the response writer, if the
+// given error is a *model.OverlapError. Returns true in this case.
+func (v *VacationRequestService) overlap(w http.ResponseWriter, logger logrus.FieldLogger, err error) bool {
+	var overlapErr *model.OverlapError
+	if !errors.As(err, &overlapErr) {
+		return false
+	}
+	logger.Error(err)
+	w.WriteHeader(http.StatusConflict)
+	err = json.NewEncoder(w).Encode(overlapErr)
+	if err != nil {
+		logger.Error(err)
+	}
+	return true
+}
+
+// statusCode maps validation and store errors to http status codes, unknown
+// errors are mapped to 500.
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, model.ErrVersionConflict):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, model.ErrEntityNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrInvalidStatusTransition),
+		errors.Is(err, model.ErrOverlappingAbsence),
+		errors.Is(err, model.ErrDeputyNotAccepted):
+		return http.StatusConflict
+	case errors.Is(err, model.ErrMissingRejectionReason),
+		errors.Is(err, model.ErrInvalidPortion),
+		errors.Is(err, model.ErrInvalidDeputy):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// statusFromRequest reads the comma separated status query parameter of the
+// given request.
+func statusFromRequest(r *http.Request) ([]model.VacationRequestStatus, error) {
+	var states []model.VacationRequestStatus
+	raw := r.URL.Query().Get("status")
+	if raw == "" {
+		return states, nil
+	}
+	for _, s := range strings.Split(raw, ",") {
+		status := model.VacationRequestStatus(strings.TrimSpace(s))
+		if !status.Valid() {
+			return nil, fmt.Errorf("unknown vacation-request status: %s", status)
+		}
+		states = append(states, status)
+	}
+	return states, nil
+}
+
 func extractVacationRequestID(r *http.Request) (string, error) {
 	vars := mux.Vars(r)
 	vacationRequestID, ok := vars["vacationRequestID"]
@@ -0,0 +1,374 @@
+package vacationrequest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/MninaTB/vacadm/api/v1/util"
+	"github.com/MninaTB/vacadm/pkg/database/inmemory"
+	"github.com/MninaTB/vacadm/pkg/model"
+	"github.com/MninaTB/vacadm/pkg/notify"
+)
+
+func TestVacationRequestService_Update(t *testing.T) {
+	ctx := context.Background()
+	db := inmemory.NewInmemoryDB()
+	owner, err := db.CreateUser(ctx, &model.User{Email: "owner@inform.de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	team, err := db.CreateTeam(ctx, &model.Team{Name: "dev", OwnerID: owner.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := db.CreateUser(ctx, &model.User{Email: "user@inform.de", ParentID: &owner.ID, TeamID: &team.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	monday := time.Date(2022, time.April, 4, 0, 0, 0, 0, time.UTC)
+	freezeFrom, freezeTo := monday.AddDate(0, 0, 7), monday.AddDate(0, 0, 11)
+	_, err = db.CreateTeamRule(ctx, &model.TeamRule{
+		TeamID:   team.ID,
+		Name:     "release freeze",
+		Kind:     model.TeamRuleBlackout,
+		Blocking: true,
+		From:     &freezeFrom,
+		To:       &freezeTo,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vR, err := db.CreateVacationRequest(ctx, &model.VacationRequest{
+		UserID:        user.ID,
+		From:          monday,
+		To:            monday.AddDate(0, 0, 2),
+		ApprovalSteps: []model.ApprovalStep{{Role: model.ApproverManager}, {Role: model.ApproverParent}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.UpdateVacationRequest(ctx, &model.VacationRequest{
+		ID:            vR.ID,
+		ApprovalSteps: []model.ApprovalStep{{Role: model.ApproverManager, ApprovedBy: &owner.ID}, {Role: model.ApproverParent}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewVacationRequestService(db, notify.NewNoopNotifier(), nil, model.AutoApprovalPolicy{}, logrus.New(), nil)
+	update := func(u *model.VacationRequest) *httptest.ResponseRecorder {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(u); err != nil {
+			t.Fatal(err)
+		}
+		req, err := http.NewRequest(http.MethodPatch, "/user/"+user.ID+"/vacation/request/"+vR.ID, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("If-Match", "*")
+		req = mux.SetURLVars(req, map[string]string{"userID": user.ID, "vacationRequestID": vR.ID})
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(svc.Update).ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := update(&model.VacationRequest{ID: vR.ID, From: freezeFrom, To: freezeFrom.AddDate(0, 0, 1)})
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected blocking rule to reject the new period, got: %d", rr.Code)
+	}
+
+	reason := "forged"
+	rr = update(&model.VacationRequest{
+		ID:              vR.ID,
+		To:              monday.AddDate(0, 0, 3),
+		RuleViolations:  []model.RuleViolation{{RuleID: "forged"}},
+		RejectedBy:      &owner.ID,
+		RejectionReason: &reason,
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	got := &model.VacationRequest{}
+	if err := json.NewDecoder(rr.Body).Decode(got); err != nil {
+		t.Fatal(err)
+	}
+	if got.RejectedBy != nil || got.RejectionReason != nil || len(got.RuleViolations) != 0 {
+		t.Fatalf("expected rejection and rule violations to be ignored, got: %+v", got)
+	}
+	if next := got.NextApprovalStep(); next != 0 {
+		t.Fatalf("expected the approval chain to restart, next step: %d", next)
+	}
+}
+
+func TestVacationRequestService_SecondaryTeams(t *testing.T) {
+	ctx := context.Background()
+	db := inmemory.NewInmemoryDB()
+	mustUser := func(u *model.User) *model.User {
+		t.Helper()
+		u, err := db.CreateUser(ctx, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+	owner := mustUser(&model.User{Email: "owner@inform.de"})
+	primary, err := db.CreateTeam(ctx, &model.Team{Name: "dev", OwnerID: owner.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondary, err := db.CreateTeam(ctx, &model.Team{Name: "ops", OwnerID: owner.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := mustUser(&model.User{Email: "user@inform.de", ParentID: &owner.ID})
+	colleague := mustUser(&model.User{Email: "colleague@inform.de", ParentID: &owner.ID, TeamID: &secondary.ID})
+	stranger := mustUser(&model.User{Email: "stranger@inform.de", ParentID: &owner.ID})
+	for _, m := range []*model.TeamMembership{
+		{TeamID: primary.ID, UserID: user.ID, Allocation: 50},
+		{TeamID: secondary.ID, UserID: user.ID, Allocation: 50},
+	} {
+		if _, err := db.CreateTeamMembership(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	monday := time.Date(2022, time.April, 4, 0, 0, 0, 0, time.UTC)
+	_, err = db.CreateVacation(ctx, &model.Vacation{UserID: colleague.ID, ApprovedBy: &owner.ID, From: monday, To: monday})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewVacationRequestService(db, notify.NewNoopNotifier(), nil, model.AutoApprovalPolicy{MinTeamAvailability: 0.5}, logrus.New(), nil)
+	if err := svc.validateDeputy(ctx, user, &colleague.ID); err != nil {
+		t.Fatalf("expected member of a secondary team to be a valid deputy, got: %v", err)
+	}
+	if err := svc.validateDeputy(ctx, user, &stranger.ID); !errors.Is(err, model.ErrInvalidDeputy) {
+		t.Fatalf("expected %v, got: %v", model.ErrInvalidDeputy, err)
+	}
+	// NOTE: on monday the secondary team misses its full member and the half
+	// allocated user, none of its 1.5 members is left.
+	got, err := svc.teamAvailability(ctx, user, &model.VacationRequest{UserID: user.ID, From: monday, To: monday, Portion: model.PortionFullDay})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 0.0; got != want {
+		t.Fatalf("want: %v, got: %v", want, got)
+	}
+}
+
+func TestVacationRequestService_ListDeputyRequests(t *testing.T) {
+	ctx := context.Background()
+	db := inmemory.NewInmemoryDB()
+	user, err := db.CreateUser(ctx, &model.User{Email: "user@inform.de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deputy, err := db.CreateUser(ctx, &model.User{Email: "deputy@inform.de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	monday := time.Date(2022, time.April, 4, 0, 0, 0, 0, time.UTC)
+	want := map[string]bool{}
+	for i := 0; i < 6; i++ {
+		vR := &model.VacationRequest{UserID: user.ID, From: monday.AddDate(0, 0, 7*i), To: monday.AddDate(0, 0, 7*i)}
+		// NOTE: every other request has no deputy, they must not shorten the
+		// pages of the deputy.
+		if i%2 == 1 {
+			vR.DeputyID = &deputy.ID
+		}
+		vR, err = db.CreateVacationRequest(ctx, vR)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if vR.DeputyID != nil {
+			want[vR.ID] = true
+		}
+	}
+
+	svc := NewVacationRequestService(db, notify.NewNoopNotifier(), nil, model.AutoApprovalPolicy{}, logrus.New(), nil)
+	list := func(cursor string) ([]*model.VacationRequest, string) {
+		t.Helper()
+		path := "/user/" + deputy.ID + "/deputy?limit=2"
+		if cursor != "" {
+			path += "&cursor=" + cursor
+		}
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"userID": deputy.ID})
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(svc.ListDeputyRequests).ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+		var page []*model.VacationRequest
+		if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+			t.Fatal(err)
+		}
+		return page, rr.Header().Get(util.NextCursorHeader)
+	}
+
+	first, cursor := list("")
+	if len(first) != 2 || cursor == "" {
+		t.Fatalf("expected a full first page with cursor, got %d entries and cursor %q", len(first), cursor)
+	}
+	second, cursor := list(cursor)
+	if len(second) != 1 || cursor != "" {
+		t.Fatalf("expected the last entry without cursor, got %d entries and cursor %q", len(second), cursor)
+	}
+	for _, vR := range append(first, second...) {
+		if !want[vR.ID] {
+			t.Fatalf("unexpected vacation-request %s", vR.ID)
+		}
+		delete(want, vR.ID)
+	}
+	if len(want) != 0 {
+		t.Fatalf("missing vacation-requests: %v", want)
+	}
+}
+
+func TestVacationRequestService_ApprovalSteps(t *testing.T) {
+	ctx := context.Background()
+	db := inmemory.NewInmemoryDB()
+	owner, err := db.CreateUser(ctx, &model.User{Email: "owner@inform.de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	team, err := db.CreateTeam(ctx, &model.Team{Name: "dev", OwnerID: owner.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// NOTE: member joined its team through a membership only.
+	member, err := db.CreateUser(ctx, &model.User{Email: "member@inform.de", ParentID: &owner.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.CreateTeamMembership(ctx, &model.TeamMembership{TeamID: team.ID, UserID: member.ID}); err != nil {
+		t.Fatal(err)
+	}
+	loner, err := db.CreateUser(ctx, &model.User{Email: "loner@inform.de", ParentID: &owner.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := model.ApprovalPolicy{{Role: model.ApproverManager}, {Role: model.ApproverTeamOwner}}
+	svc := NewVacationRequestService(db, notify.NewNoopNotifier(), policy, model.AutoApprovalPolicy{}, logrus.New(), nil)
+	monday := time.Date(2022, time.April, 4, 0, 0, 0, 0, time.UTC)
+	tt := []struct {
+		name string
+		user *model.User
+		want []model.ApprovalStep
+	}{
+		{name: "member", user: member, want: []model.ApprovalStep{{Role: model.ApproverManager}, {Role: model.ApproverTeamOwner}}},
+		{name: "without team", user: loner, want: []model.ApprovalStep{{Role: model.ApproverManager}}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := svc.approvalSteps(ctx, tc.user, &model.VacationRequest{UserID: tc.user.ID, From: monday, To: monday, Portion: model.PortionFullDay})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(tc.want, got) {
+				t.Fatal(cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
+
+// failingNotifier fails every notification.
+type failingNotifier struct{}
+
+func (failingNotifier) NotifyUser(context.Context, string, string) error {
+	return errors.New("mail server unavailable")
+}
+
+func (failingNotifier) NotifyTeam(context.Context, string, string) error {
+	return errors.New("mail server unavailable")
+}
+
+func TestVacationRequestService_Cancel(t *testing.T) {
+	ctx := context.Background()
+	db := inmemory.NewInmemoryDB()
+	owner, err := db.CreateUser(ctx, &model.User{Email: "owner@inform.de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := db.CreateUser(ctx, &model.User{Email: "user@inform.de", ParentID: &owner.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	monday := time.Date(2022, time.April, 4, 0, 0, 0, 0, time.UTC)
+	vR, err := db.CreateVacationRequest(ctx, &model.VacationRequest{UserID: user.ID, From: monday, To: monday})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, vac, err := db.ApproveVacationRequest(ctx, vR.ID, &model.Vacation{UserID: user.ID, ApprovedBy: &owner.ID, From: monday, To: monday})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// NOTE: the vacation was already removed by the manager.
+	if err = db.DeleteVacation(ctx, vac.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewVacationRequestService(db, failingNotifier{}, nil, model.AutoApprovalPolicy{}, logrus.New(), nil)
+	cancel := func() *httptest.ResponseRecorder {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPut, "/user/"+user.ID+"/vacation/request/"+vR.ID+"/cancel", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"userID": user.ID, "vacationRequestID": vR.ID})
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(svc.Cancel).ServeHTTP(rr, req)
+		return rr
+	}
+	if rr := cancel(); rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	got, err := db.GetVacationRequestByID(ctx, vR.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != model.StatusCancelled {
+		t.Fatalf("expected cancelled request, got: %s", got.Status)
+	}
+	if rr := cancel(); rr.Code != http.StatusConflict {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusConflict)
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"version conflict", model.ErrVersionConflict, http.StatusPreconditionFailed},
+		{"not found", model.ErrEntityNotFound, http.StatusNotFound},
+		{"transition", model.ErrInvalidStatusTransition, http.StatusConflict},
+		{"overlap", model.ErrOverlappingAbsence, http.StatusConflict},
+		{"invalid portion", model.ErrInvalidPortion, http.StatusBadRequest},
+		{"wrapped", fmt.Errorf("%w: test", model.ErrInvalidDeputy), http.StatusBadRequest},
+		{"unknown", errors.New("connection refused"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statusCode(tt.err); got != tt.want {
+				t.Errorf("statusCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 
+	"github.com/MninaTB/vacadm/api/v1/util"
 	"github.com/MninaTB/vacadm/pkg/database"
 	"github.com/MninaTB/vacadm/pkg/model"
 )
@@ -30,6 +31,7 @@ type VacationResourceService struct {
 }
 
 // Create reads the given payload and creates a store representation accordingly.
+// Resources of the same user must not overlap.
 func (v *VacationResourceService) Create(w http.ResponseWriter, r *http.Request) {
 	logger := v.logger.WithField("method", "create")
 	logger.Info("create new vacation-resource")
@@ -41,6 +43,11 @@ func (v *VacationResourceService) Create(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	newVR, err := v.store.CreateVacationResource(r.Context(), &vr)
+	if errors.Is(err, model.ErrOverlappingResource) {
+		w.WriteHeader(http.StatusConflict)
+		logger.Error(err)
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		logger.Error(err)
@@ -61,18 +68,31 @@ func (v *VacationResourceService) Create(w http.ResponseWriter, r *http.Request)
 func (v *VacationResourceService) GetByID(w http.ResponseWriter, r *http.Request) {
 	logger := v.logger.WithField("component", "read")
 	logger.Info("get vacation-resource by id")
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 	vrID, err := extractVacationResourceID(r)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	vr, err := v.store.GetVacationResourceByID(r.Context(), vrID)
+	opts, err := util.QueryOptionsFromRequest(r)
 	if err != nil {
 		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	vr, err := v.store.GetVacationResourceByID(r.Context(), vrID, opts...)
+	if err != nil || vr.UserID != userID {
+		logger.Error("no vacation-resource found: ", err)
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
+	util.SetETag(w, vr.Version)
 	err = json.NewEncoder(w).Encode(vr)
 	if err != nil {
 		logger.Error(err)
@@ -81,16 +101,38 @@ func (v *VacationResourceService) GetByID(w http.ResponseWriter, r *http.Request
 	v.logger.Info("get vacation-resource with id: ", vr)
 }
 
-// List retuns a list of all VacationResources available on the internal store.
+// List retuns a list of all VacationResources of the user associated to the
+// userID in the URL. The list can be filtered by period, sorted and paged, see
+// util.ListOptionsFromRequest.
 func (v *VacationResourceService) List(w http.ResponseWriter, r *http.Request) {
 	logger := v.logger.WithField("method", "list")
 	logger.Info("retrieve vacation-resource list")
-	list, err := v.store.ListVacationResource(r.Context())
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	opts, err := util.ListOptionsFromRequest(r, model.EntityVacationResource)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	list, err := v.store.GetVacationResourcesByUserID(r.Context(), userID, opts...)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
+	if n := len(list); n > 0 {
+		err = util.SetNextCursor(w, opts, n, list[n-1])
+		if err != nil {
+			logger.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
 	err = json.NewEncoder(w).Encode(&list)
 	if err != nil {
 		logger.Error(err)
@@ -99,24 +141,60 @@ func (v *VacationResourceService) List(w http.ResponseWriter, r *http.Request) {
 	v.logger.Info("get list of vacation-resource")
 }
 
-// Update reads new VacationResource information from the request body and updates the store
-// representation accordingly.
+// Update reads new VacationResource information from the request body and updates the
+// resource of the URL accordingly, the id of the payload is ignored. Resources of the same
+// user must not overlap.
 func (v *VacationResourceService) Update(w http.ResponseWriter, r *http.Request) {
 	logger := v.logger.WithField("method", "update")
 	logger.Info("update vacation-resource")
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 	var vr model.VacationResource
-	err := json.NewDecoder(r.Body).Decode(&vr)
+	err = json.NewDecoder(r.Body).Decode(&vr)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	vr.ID, err = extractVacationResourceID(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	vr.Version, err = util.VersionFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(util.IfMatchStatusCode(err))
+		return
+	}
+	current, err := v.store.GetVacationResourceByID(r.Context(), vr.ID)
+	if err != nil || current.UserID != userID {
+		logger.Error("no vacation-resource found: ", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
 	newVR, err := v.store.UpdateVacationResource(r.Context(), &vr)
+	if errors.Is(err, model.ErrOverlappingResource) {
+		logger.Error(err)
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+	if errors.Is(err, model.ErrVersionConflict) {
+		logger.Error(err)
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	util.SetETag(w, newVR.Version)
 	err = json.NewEncoder(w).Encode(&newVR)
 	if err != nil {
 		logger.Error(err)
@@ -126,15 +204,28 @@ func (v *VacationResourceService) Update(w http.ResponseWriter, r *http.Request)
 }
 
 // Delete a VacationResource associated to the given VacationResourceID in the URL.
+// Resources of other users than the user of the URL are not found.
 func (v *VacationResourceService) Delete(w http.ResponseWriter, r *http.Request) {
 	logger := v.logger.WithField("method", "delete")
 	logger.Info("delete vacation-resscource")
+	userID, err := util.UserIDFromRequest(r)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 	vrID, err := extractVacationResourceID(r)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	vr, err := v.store.GetVacationResourceByID(r.Context(), vrID)
+	if err != nil || vr.UserID != userID {
+		logger.Error("no vacation-resource found: ", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
 	err = v.store.DeleteVacationResource(r.Context(), vrID)
 	if err != nil {
 		logger.Error(err)
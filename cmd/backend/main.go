@@ -4,7 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"flag"
-	"fmt"
 	"net/http"
 	"time"
 
@@ -15,6 +14,7 @@ import (
 	"github.com/MninaTB/vacadm/api/token"
 	v1 "github.com/MninaTB/vacadm/api/v1"
 	"github.com/MninaTB/vacadm/assets/swagger"
+	"github.com/MninaTB/vacadm/pkg/blob"
 	"github.com/MninaTB/vacadm/pkg/database"
 	"github.com/MninaTB/vacadm/pkg/database/inmemory"
 	"github.com/MninaTB/vacadm/pkg/database/mariadb"
@@ -22,6 +22,7 @@ import (
 	"github.com/MninaTB/vacadm/pkg/middleware"
 	"github.com/MninaTB/vacadm/pkg/model"
 	"github.com/MninaTB/vacadm/pkg/notify"
+	"github.com/MninaTB/vacadm/pkg/policy"
 	"github.com/MninaTB/vacadm/pkg/version"
 )
 
@@ -38,20 +39,39 @@ func main() {
 		smtpPort     = flag.String("smtp.port", "", "port of smtp server")
 		smtpUser     = flag.String("smtp.user", "", "smtp user mail address")
 		smtpPassword = flag.String("smtp.password", "", "smtp user password")
+		rounding     = flag.String("entitlement.rounding", string(database.RoundingNearest),
+			"rounding of prorated vacation entitlements: none, half_day, nearest or up")
+		carryOverCap    = flag.Float64("carryover.cap", 0, "maximum of days carried over into the next year, 0 means unlimited")
+		carryOverExpiry = flag.String("carryover.expiry", "03-31", "day (MM-DD) after which carried days expire")
+		approvalChain   = flag.String("approval.chain", string(model.ApproverParent), `comma separated approval steps: parent, manager, team_owner or team:<teamID>,
+		a step can be limited to requests longer than n working days by >n, example: manager,team_owner,team:<teamID>>10`)
+		autoApprovalMaxDays      = flag.Float64("autoapproval.max-days", 0, "maximum of working days of automatically approved requests, 0 disables auto-approval")
+		autoApprovalMinNotice    = flag.Int("autoapproval.min-notice", 0, "minimum of days between submission and start of automatically approved requests")
+		autoApprovalAvailability = flag.Float64("autoapproval.min-availability", 0, "minimum share (0-1) of available team members during automatically approved requests")
+		autoApprovalBlackouts    = flag.String("autoapproval.blackout", "", `comma separated periods (from:to) without auto-approval,
+		example: 2022-12-19:2023-01-06,2023-06-30:2023-06-30`)
+		attachmentDir          = flag.String("attachment.dir", "attachments", "directory of uploaded attachments")
+		attachmentMaxSize      = flag.Int64("attachment.max-size", 5<<20, "maximum size of an attachment in bytes")
+		attachmentContentTypes = flag.String("attachment.content-types", model.DefaultAttachmentContentTypes, "comma separated content types of allowed attachments")
+		retention              = flag.Duration("retention", 30*24*time.Hour, "period deleted entries are kept before they can be purged")
 	)
 	flag.Parse()
 
 	logger := logrus.New()
 	logger.Info(version.Version())
 
-	var db database.Database = inmemory.NewInmemoryDB()
+	mem := inmemory.NewInmemoryDB()
+	var db database.Database = mem
+	var audits database.AuditStore = mem
 	if *sqlConnStr != "" {
 		sqlDB, err := sql.Open("mysql", *sqlConnStr)
 		if err != nil {
 			logger.Fatal(err)
 		}
 		defer sqlDB.Close()
-		db = mariadb.NewMariaDB(sqlDB)
+		maria := mariadb.NewMariaDB(sqlDB)
+		db = maria
+		audits = maria
 	}
 
 	var notifier notify.Notifier = notify.NewNoopNotifier()
@@ -70,13 +90,50 @@ func main() {
 	}
 	t := jwt.NewTokenizer(secret, 365*24*time.Hour)
 	router.Path("/token/new/{userID}").Methods(http.MethodGet).HandlerFunc(token.NewTokenService(db, t).Refresh)
-	apiv1 := v1.NewServer(db, notifier, t, middleware.Logging(), middleware.Auth(t, database.NewRelationDB(db)))
+	entitlementRounding, err := database.ParseRounding(*rounding)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	carryOver := database.CarryOverPolicy{Cap: *carryOverCap}
+	carryOver.ExpiryMonth, carryOver.ExpiryDay, err = database.ParseCarryOverExpiry(*carryOverExpiry)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	approvalPolicy, err := model.ParseApprovalPolicy(*approvalChain)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	blackouts, err := model.ParseBlackoutPeriods(*autoApprovalBlackouts)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	contentTypes, err := model.ParseContentTypes(*attachmentContentTypes)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	blobs, err := blob.NewFileStore(*attachmentDir)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	cfg := v1.Config{
+		Rounding:       entitlementRounding,
+		CarryOver:      carryOver,
+		ApprovalPolicy: approvalPolicy,
+		AutoApproval: model.AutoApprovalPolicy{
+			MaxDays:             *autoApprovalMaxDays,
+			MinNoticeDays:       *autoApprovalMinNotice,
+			MinTeamAvailability: *autoApprovalAvailability,
+			Blackouts:           blackouts,
+		},
+		Attachments: model.AttachmentPolicy{
+			MaxSize:      *attachmentMaxSize,
+			ContentTypes: contentTypes,
+		},
+		Retention: *retention,
+	}
+	apiv1 := v1.NewServer(db, notifier, blobs, audits, t, cfg, middleware.RequestID(), middleware.Logging(), middleware.Auth(t, policy.NewEngine(db)))
 	const pathPrefixV1 = "/v1"
-	// HACK: allow sub routes on v1 router.
-	router.Handle(fmt.Sprintf("%s/{dummy1}", pathPrefixV1), http.StripPrefix(pathPrefixV1, apiv1))
-	router.Handle(fmt.Sprintf("%s/{dummy1}/{dummy2}", pathPrefixV1), http.StripPrefix(pathPrefixV1, apiv1))
-	router.Handle(fmt.Sprintf("%s/{dummy1}/{dummy2}/{dummy3}", pathPrefixV1), http.StripPrefix(pathPrefixV1, apiv1))
-	router.Handle(fmt.Sprintf("%s/{dummy1}/{dummy2}/{dummy3}/{dummy4}", pathPrefixV1), http.StripPrefix(pathPrefixV1, apiv1))
+	router.PathPrefix(pathPrefixV1 + "/").Handler(http.StripPrefix(pathPrefixV1, apiv1))
 
 	if *swaggerEnabled {
 		logger.Info("swagger endpoint \"/swagger\" enabled")
@@ -88,6 +145,7 @@ func main() {
 
 	if *initRoot {
 		u := &model.User{
+			Role:      model.RoleAdmin,
 			FirstName: "Max",
 			LastName:  "Mustermann",
 			Email:     "admin@inform.de",
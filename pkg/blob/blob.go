@@ -0,0 +1,109 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var (
+	// ErrNotFound is returned if no blob is stored for a key.
+	ErrNotFound = errors.New("blob not found")
+	// ErrInvalidKey is returned if a key contains other characters than
+	// letters, digits, dashes and underscores.
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+var validKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Store implements methods to keep binary content, e.g. uploaded documents.
+type Store interface {
+	// Put stores the content of the given reader under key, an existing blob
+	// is replaced.
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Get returns a reader of the blob stored under key, which has to be
+	// closed by the caller.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob stored under key.
+	Delete(ctx context.Context, key string) error
+}
+
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore returns a FileStore, which keeps blobs in the given directory.
+// The directory is created, if it does not exist.
+func NewFileStore(dir string) (*FileStore, error) {
+	err := os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// FileStore keeps every blob as file in a local directory.
+type FileStore struct {
+	dir string
+}
+
+// Put stores the content of the given reader as file named by key. The file
+// is written to a temporary file first, so readers never see partial content.
+func (f *FileStore) Put(_ context.Context, key string, r io.Reader) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(f.dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get opens the file named by key.
+func (f *FileStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return file, err
+}
+
+// Delete removes the file named by key.
+func (f *FileStore) Delete(_ context.Context, key string) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return err
+}
+
+// path returns the file of the given key. Keys are restricted to a single
+// path element, so blobs can not be stored outside of the directory.
+func (f *FileStore) path(key string) (string, error) {
+	if !validKey.MatchString(key) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return filepath.Join(f.dir, key), nil
+}
@@ -0,0 +1,71 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.Put(ctx, "certificate", strings.NewReader("first"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Put(ctx, "certificate", strings.NewReader("second"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := store.Get(ctx, "certificate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "second" {
+		t.Fatalf("want: %q, got: %q", "second", got)
+	}
+
+	err = store.Delete(ctx, "certificate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = store.Get(ctx, "certificate")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected %v, got: %v", ErrNotFound, err)
+	}
+	err = store.Delete(ctx, "certificate")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected %v, got: %v", ErrNotFound, err)
+	}
+}
+
+func TestFileStore_InvalidKey(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"", "../escape", "sub/dir", "."} {
+		t.Run(key, func(t *testing.T) {
+			err := store.Put(ctx, key, strings.NewReader("content"))
+			if !errors.Is(err, ErrInvalidKey) {
+				t.Fatalf("expected %v, got: %v", ErrInvalidKey, err)
+			}
+			_, err = store.Get(ctx, key)
+			if !errors.Is(err, ErrInvalidKey) {
+				t.Fatalf("expected %v, got: %v", ErrInvalidKey, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,476 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/MninaTB/vacadm/pkg/database/query"
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+// AuditStore is implemented by any structure providing all AuditStore methods.
+type AuditStore interface {
+	// CreateAuditEntry stores the given audit entry. Returns copy with
+	// assigned auditEntryID.
+	CreateAuditEntry(ctx context.Context, auditEntry *model.AuditEntry) (*model.AuditEntry, error)
+	// ListAuditEntries returns all audit entries matching the given filter,
+	// ordered by creation.
+	ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error)
+}
+
+type auditContextKey int
+
+const (
+	actorKey auditContextKey = iota
+	requestIDKey
+)
+
+// WithActor returns a copy of ctx carrying the user, who performs the
+// mutations.
+func WithActor(ctx context.Context, actorID string) context.Context {
+	return context.WithValue(ctx, actorKey, actorID)
+}
+
+// WithRequestID returns a copy of ctx carrying the id of the request, which
+// causes the mutations.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request id carried by ctx, see WithRequestID.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// NewAuditDB returns a Database, which records an audit entry in store for
+// every mutation of db. Actor and request of the entries are read from the
+// context, see WithActor and WithRequestID.
+func NewAuditDB(db Database, store AuditStore) Database {
+	return &auditDB{
+		Database: db,
+		store:    store,
+	}
+}
+
+// auditDB records the mutations of the embedded Database. Reads are passed
+// through. If the audit entry of a successful mutation can not be stored, an
+// error is returned.
+type auditDB struct {
+	Database
+	store AuditStore
+}
+
+// record stores an audit entry of the given mutation. before and after are
+// the entry before and after the mutation, nil if it did not exist.
+func (a *auditDB) record(ctx context.Context, action model.AuditAction, entity model.Entity, id string, before, after interface{}) error {
+	entry := &model.AuditEntry{
+		Action:   action,
+		Entity:   entity,
+		EntityID: id,
+	}
+	entry.ActorID, _ = ctx.Value(actorKey).(string)
+	entry.RequestID = RequestID(ctx)
+	var err error
+	entry.Before, err = marshalEntry(before)
+	if err != nil {
+		return err
+	}
+	entry.After, err = marshalEntry(after)
+	if err != nil {
+		return err
+	}
+	_, err = a.store.CreateAuditEntry(ctx, entry)
+	if err != nil {
+		return fmt.Errorf("audit %s of %s %s: %w", action, entity, id, err)
+	}
+	return nil
+}
+
+// marshalEntry encodes the given entry, nil is encoded as null.
+func marshalEntry(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// get returns the entry of entity by the given id including soft deleted
+// entries, nil if it does not exist.
+func (a *auditDB) get(ctx context.Context, entity model.Entity, id string) interface{} {
+	includeDeleted := query.IncludeDeleted(true)
+	var (
+		v   interface{}
+		err error
+	)
+	switch entity {
+	case model.EntityUser:
+		v, err = a.Database.GetUserByID(ctx, id, includeDeleted)
+	case model.EntityTeam:
+		v, err = a.Database.GetTeamByID(ctx, id, includeDeleted)
+	case model.EntityVacation:
+		v, err = a.Database.GetVacationByID(ctx, id, includeDeleted)
+	case model.EntityVacationRequest:
+		v, err = a.Database.GetVacationRequestByID(ctx, id, includeDeleted)
+	case model.EntityVacationResource:
+		v, err = a.Database.GetVacationResourceByID(ctx, id, includeDeleted)
+	case model.EntityAbsenceType:
+		v, err = a.Database.GetAbsenceTypeByID(ctx, id, includeDeleted)
+	case model.EntityDelegation:
+		v, err = a.Database.GetDelegationByID(ctx, id, includeDeleted)
+	case model.EntityTeamRule:
+		v, err = a.Database.GetTeamRuleByID(ctx, id, includeDeleted)
+	case model.EntityTeamMembership:
+		v, err = a.Database.GetTeamMembershipByID(ctx, id, includeDeleted)
+	case model.EntityAttachment:
+		v, err = a.Database.GetAttachmentByID(ctx, id, includeDeleted)
+	default:
+		return nil
+	}
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// created records the creation of the entry by the given id.
+func (a *auditDB) created(ctx context.Context, entity model.Entity, id string, after interface{}) error {
+	return a.record(ctx, model.AuditCreate, entity, id, nil, after)
+}
+
+// mutate records the mutation of the entry of entity by the given id, which
+// is performed by fn.
+func (a *auditDB) mutate(ctx context.Context, action model.AuditAction, entity model.Entity, id string, fn func() error) error {
+	before := a.get(ctx, entity, id)
+	err := fn()
+	if err != nil {
+		return err
+	}
+	return a.record(ctx, action, entity, id, before, a.get(ctx, entity, id))
+}
+
+// CreateUser creates the given user and records the creation.
+func (a *auditDB) CreateUser(ctx context.Context, user *model.User) (*model.User, error) {
+	u, err := a.Database.CreateUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return u, a.created(ctx, model.EntityUser, u.ID, u)
+}
+
+// UpdateUser updates the given user and records the update.
+func (a *auditDB) UpdateUser(ctx context.Context, user *model.User) (*model.User, error) {
+	var u *model.User
+	err := a.mutate(ctx, model.AuditUpdate, model.EntityUser, user.ID, func() (err error) {
+		u, err = a.Database.UpdateUser(ctx, user)
+		return err
+	})
+	return u, err
+}
+
+// DeleteUser deletes the user by the given id and records the deletion.
+func (a *auditDB) DeleteUser(ctx context.Context, userID string) error {
+	return a.mutate(ctx, model.AuditDelete, model.EntityUser, userID, func() error {
+		return a.Database.DeleteUser(ctx, userID)
+	})
+}
+
+// CreateTeam creates the given team and records the creation.
+func (a *auditDB) CreateTeam(ctx context.Context, team *model.Team) (*model.Team, error) {
+	t, err := a.Database.CreateTeam(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+	return t, a.created(ctx, model.EntityTeam, t.ID, t)
+}
+
+// UpdateTeam updates the given team and records the update.
+func (a *auditDB) UpdateTeam(ctx context.Context, team *model.Team) (*model.Team, error) {
+	var t *model.Team
+	err := a.mutate(ctx, model.AuditUpdate, model.EntityTeam, team.ID, func() (err error) {
+		t, err = a.Database.UpdateTeam(ctx, team)
+		return err
+	})
+	return t, err
+}
+
+// DeleteTeam deletes the team by the given id and records the deletion.
+func (a *auditDB) DeleteTeam(ctx context.Context, teamID string) error {
+	return a.mutate(ctx, model.AuditDelete, model.EntityTeam, teamID, func() error {
+		return a.Database.DeleteTeam(ctx, teamID)
+	})
+}
+
+// CreateVacation creates the given vacation and records the creation.
+func (a *auditDB) CreateVacation(ctx context.Context, vacation *model.Vacation) (*model.Vacation, error) {
+	v, err := a.Database.CreateVacation(ctx, vacation)
+	if err != nil {
+		return nil, err
+	}
+	return v, a.created(ctx, model.EntityVacation, v.ID, v)
+}
+
+// DeleteVacation deletes the vacation by the given id and records the
+// deletion.
+func (a *auditDB) DeleteVacation(ctx context.Context, vacationID string) error {
+	return a.mutate(ctx, model.AuditDelete, model.EntityVacation, vacationID, func() error {
+		return a.Database.DeleteVacation(ctx, vacationID)
+	})
+}
+
+// CreateVacationRequest creates the given vacation-request and records the
+// creation.
+func (a *auditDB) CreateVacationRequest(ctx context.Context, vacationRequest *model.VacationRequest) (*model.VacationRequest, error) {
+	vR, err := a.Database.CreateVacationRequest(ctx, vacationRequest)
+	if err != nil {
+		return nil, err
+	}
+	return vR, a.created(ctx, model.EntityVacationRequest, vR.ID, vR)
+}
+
+// UpdateVacationRequest updates the given vacation-request and records the
+// update. Updates, which approve or reject the request, are recorded as such.
+func (a *auditDB) UpdateVacationRequest(ctx context.Context, vacationRequest *model.VacationRequest) (*model.VacationRequest, error) {
+	before, err := a.Database.GetVacationRequestByID(ctx, vacationRequest.ID)
+	if err != nil {
+		return nil, err
+	}
+	vR, err := a.Database.UpdateVacationRequest(ctx, vacationRequest)
+	if err != nil {
+		return nil, err
+	}
+	action := model.AuditUpdate
+	if before.Status != vR.Status {
+		switch vR.Status {
+		case model.StatusApproved:
+			action = model.AuditApprove
+		case model.StatusRejected:
+			action = model.AuditReject
+		}
+	}
+	return vR, a.record(ctx, action, model.EntityVacationRequest, vR.ID, before, vR)
+}
+
+// ApproveVacationRequest approves the given vacation-request and records the
+// approval of the request and the creation of the vacation.
+func (a *auditDB) ApproveVacationRequest(ctx context.Context, vacationRequestID string, vacation *model.Vacation) (*model.VacationRequest, *model.Vacation, error) {
+	before, err := a.Database.GetVacationRequestByID(ctx, vacationRequestID)
+	if err != nil {
+		return nil, nil, err
+	}
+	vR, vac, err := a.Database.ApproveVacationRequest(ctx, vacationRequestID, vacation)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = a.record(ctx, model.AuditApprove, model.EntityVacationRequest, vR.ID, before, vR)
+	if err != nil {
+		return nil, nil, err
+	}
+	return vR, vac, a.created(ctx, model.EntityVacation, vac.ID, vac)
+}
+
+// CancelVacationRequest cancels the vacation-request by the given id and
+// records the update of the request and the deletion of its vacation.
+func (a *auditDB) CancelVacationRequest(ctx context.Context, vacationRequestID string) (*model.VacationRequest, error) {
+	before, err := a.Database.GetVacationRequestByID(ctx, vacationRequestID)
+	if err != nil {
+		return nil, err
+	}
+	// NOTE: a vacation, which is already deleted, is skipped by the cancel.
+	var vacation *model.Vacation
+	if before.VacationID != nil {
+		vacation, _ = a.Database.GetVacationByID(ctx, *before.VacationID)
+	}
+	vR, err := a.Database.CancelVacationRequest(ctx, vacationRequestID)
+	if err != nil {
+		return nil, err
+	}
+	err = a.record(ctx, model.AuditUpdate, model.EntityVacationRequest, vR.ID, before, vR)
+	if err != nil {
+		return nil, err
+	}
+	if vacation == nil {
+		return vR, nil
+	}
+	return vR, a.record(ctx, model.AuditDelete, model.EntityVacation, vacation.ID, vacation, a.get(ctx, model.EntityVacation, vacation.ID))
+}
+
+// DeleteVacationRequest deletes the vacation-request by the given id and
+// records the deletion.
+func (a *auditDB) DeleteVacationRequest(ctx context.Context, vacationRequestID string) error {
+	return a.mutate(ctx, model.AuditDelete, model.EntityVacationRequest, vacationRequestID, func() error {
+		return a.Database.DeleteVacationRequest(ctx, vacationRequestID)
+	})
+}
+
+// CreateVacationResource creates the given vacation-resource and records the
+// creation.
+func (a *auditDB) CreateVacationResource(ctx context.Context, vacationResource *model.VacationResource) (*model.VacationResource, error) {
+	vr, err := a.Database.CreateVacationResource(ctx, vacationResource)
+	if err != nil {
+		return nil, err
+	}
+	return vr, a.created(ctx, model.EntityVacationResource, vr.ID, vr)
+}
+
+// UpdateVacationResource updates the given vacation-resource and records the
+// update.
+func (a *auditDB) UpdateVacationResource(ctx context.Context, vacationResource *model.VacationResource) (*model.VacationResource, error) {
+	var vr *model.VacationResource
+	err := a.mutate(ctx, model.AuditUpdate, model.EntityVacationResource, vacationResource.ID, func() (err error) {
+		vr, err = a.Database.UpdateVacationResource(ctx, vacationResource)
+		return err
+	})
+	return vr, err
+}
+
+// DeleteVacationResource deletes the vacation-resource by the given id and
+// records the deletion.
+func (a *auditDB) DeleteVacationResource(ctx context.Context, vacationResourceID string) error {
+	return a.mutate(ctx, model.AuditDelete, model.EntityVacationResource, vacationResourceID, func() error {
+		return a.Database.DeleteVacationResource(ctx, vacationResourceID)
+	})
+}
+
+// CreateAbsenceType creates the given absence-type and records the creation.
+func (a *auditDB) CreateAbsenceType(ctx context.Context, absenceType *model.AbsenceType) (*model.AbsenceType, error) {
+	at, err := a.Database.CreateAbsenceType(ctx, absenceType)
+	if err != nil {
+		return nil, err
+	}
+	return at, a.created(ctx, model.EntityAbsenceType, at.ID, at)
+}
+
+// UpdateAbsenceType updates the given absence-type and records the update.
+func (a *auditDB) UpdateAbsenceType(ctx context.Context, absenceType *model.AbsenceType) (*model.AbsenceType, error) {
+	var at *model.AbsenceType
+	err := a.mutate(ctx, model.AuditUpdate, model.EntityAbsenceType, absenceType.ID, func() (err error) {
+		at, err = a.Database.UpdateAbsenceType(ctx, absenceType)
+		return err
+	})
+	return at, err
+}
+
+// DeleteAbsenceType deletes the absence-type by the given id and records the
+// deletion.
+func (a *auditDB) DeleteAbsenceType(ctx context.Context, absenceTypeID string) error {
+	return a.mutate(ctx, model.AuditDelete, model.EntityAbsenceType, absenceTypeID, func() error {
+		return a.Database.DeleteAbsenceType(ctx, absenceTypeID)
+	})
+}
+
+// CreateDelegation creates the given delegation and records the creation.
+func (a *auditDB) CreateDelegation(ctx context.Context, delegation *model.Delegation) (*model.Delegation, error) {
+	d, err := a.Database.CreateDelegation(ctx, delegation)
+	if err != nil {
+		return nil, err
+	}
+	return d, a.created(ctx, model.EntityDelegation, d.ID, d)
+}
+
+// DeleteDelegation deletes the delegation by the given id and records the
+// deletion.
+func (a *auditDB) DeleteDelegation(ctx context.Context, delegationID string) error {
+	return a.mutate(ctx, model.AuditDelete, model.EntityDelegation, delegationID, func() error {
+		return a.Database.DeleteDelegation(ctx, delegationID)
+	})
+}
+
+// CreateTeamRule creates the given team-rule and records the creation.
+func (a *auditDB) CreateTeamRule(ctx context.Context, teamRule *model.TeamRule) (*model.TeamRule, error) {
+	r, err := a.Database.CreateTeamRule(ctx, teamRule)
+	if err != nil {
+		return nil, err
+	}
+	return r, a.created(ctx, model.EntityTeamRule, r.ID, r)
+}
+
+// UpdateTeamRule updates the given team-rule and records the update.
+func (a *auditDB) UpdateTeamRule(ctx context.Context, teamRule *model.TeamRule) (*model.TeamRule, error) {
+	var r *model.TeamRule
+	err := a.mutate(ctx, model.AuditUpdate, model.EntityTeamRule, teamRule.ID, func() (err error) {
+		r, err = a.Database.UpdateTeamRule(ctx, teamRule)
+		return err
+	})
+	return r, err
+}
+
+// DeleteTeamRule deletes the team-rule by the given id and records the
+// deletion.
+func (a *auditDB) DeleteTeamRule(ctx context.Context, teamRuleID string) error {
+	return a.mutate(ctx, model.AuditDelete, model.EntityTeamRule, teamRuleID, func() error {
+		return a.Database.DeleteTeamRule(ctx, teamRuleID)
+	})
+}
+
+// CreateComment creates the given comment and records the creation.
+func (a *auditDB) CreateComment(ctx context.Context, comment *model.Comment) (*model.Comment, error) {
+	c, err := a.Database.CreateComment(ctx, comment)
+	if err != nil {
+		return nil, err
+	}
+	return c, a.created(ctx, model.EntityComment, c.ID, c)
+}
+
+// CreateAttachment creates the given attachment and records the creation.
+func (a *auditDB) CreateAttachment(ctx context.Context, attachment *model.Attachment) (*model.Attachment, error) {
+	at, err := a.Database.CreateAttachment(ctx, attachment)
+	if err != nil {
+		return nil, err
+	}
+	return at, a.created(ctx, model.EntityAttachment, at.ID, at)
+}
+
+// DeleteAttachment deletes the attachment by the given id and records the
+// deletion.
+func (a *auditDB) DeleteAttachment(ctx context.Context, attachmentID string) error {
+	return a.mutate(ctx, model.AuditDelete, model.EntityAttachment, attachmentID, func() error {
+		return a.Database.DeleteAttachment(ctx, attachmentID)
+	})
+}
+
+// CreateTeamMembership creates the given team-membership and records the
+// creation.
+func (a *auditDB) CreateTeamMembership(ctx context.Context, teamMembership *model.TeamMembership) (*model.TeamMembership, error) {
+	t, err := a.Database.CreateTeamMembership(ctx, teamMembership)
+	if err != nil {
+		return nil, err
+	}
+	return t, a.created(ctx, model.EntityTeamMembership, t.ID, t)
+}
+
+// UpdateTeamMembership updates the given team-membership and records the
+// update.
+func (a *auditDB) UpdateTeamMembership(ctx context.Context, teamMembership *model.TeamMembership) (*model.TeamMembership, error) {
+	var t *model.TeamMembership
+	err := a.mutate(ctx, model.AuditUpdate, model.EntityTeamMembership, teamMembership.ID, func() (err error) {
+		t, err = a.Database.UpdateTeamMembership(ctx, teamMembership)
+		return err
+	})
+	return t, err
+}
+
+// DeleteTeamMembership deletes the team-membership by the given id and
+// records the deletion.
+func (a *auditDB) DeleteTeamMembership(ctx context.Context, teamMembershipID string) error {
+	return a.mutate(ctx, model.AuditDelete, model.EntityTeamMembership, teamMembershipID, func() error {
+		return a.Database.DeleteTeamMembership(ctx, teamMembershipID)
+	})
+}
+
+// Restore restores the entry of entity by the given id and records the
+// restore.
+func (a *auditDB) Restore(ctx context.Context, entity model.Entity, id string) error {
+	return a.mutate(ctx, model.AuditRestore, entity, id, func() error {
+		return a.Database.Restore(ctx, entity, id)
+	})
+}
+
+// Purge purges the entry of entity by the given id and records the purge.
+func (a *auditDB) Purge(ctx context.Context, entity model.Entity, id string, deletedBefore time.Time) error {
+	return a.mutate(ctx, model.AuditPurge, entity, id, func() error {
+		return a.Database.Purge(ctx, entity, id, deletedBefore)
+	})
+}
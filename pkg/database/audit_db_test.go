@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/MninaTB/vacadm/pkg/database/inmemory"
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+func TestAuditDB(t *testing.T) {
+	store := inmemory.NewInmemoryDB()
+	db := NewAuditDB(store, store)
+	ctx := WithRequestID(WithActor(context.Background(), "actor"), "request")
+
+	owner, err := db.CreateUser(ctx, &model.User{Email: "owner@inform.de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := db.CreateUser(ctx, &model.User{Email: "user@inform.de", ParentID: &owner.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.FirstName = "Max"
+	_, err = db.UpdateUser(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vR, err := db.CreateVacationRequest(ctx, &model.VacationRequest{
+		UserID: user.ID,
+		From:   time.Date(2022, time.November, 7, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2022, time.November, 9, 0, 0, 0, 0, time.UTC),
+		Status: model.StatusPending,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vR.Status = model.StatusApproved
+	_, err = db.UpdateVacationRequest(ctx, vR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.DeleteUser(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := store.ListAuditEntries(ctx, model.AuditFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		action   model.AuditAction
+		entity   model.Entity
+		entityID string
+	}{
+		{model.AuditCreate, model.EntityUser, owner.ID},
+		{model.AuditCreate, model.EntityUser, user.ID},
+		{model.AuditUpdate, model.EntityUser, user.ID},
+		{model.AuditCreate, model.EntityVacationRequest, vR.ID},
+		{model.AuditApprove, model.EntityVacationRequest, vR.ID},
+		{model.AuditDelete, model.EntityUser, user.ID},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d audit entries, got: %d", len(want), len(entries))
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Action != w.action || e.Entity != w.entity || e.EntityID != w.entityID {
+			t.Fatalf("entry %d: expected %s %s %s, got: %s %s %s", i, w.action, w.entity, w.entityID, e.Action, e.Entity, e.EntityID)
+		}
+		if e.ActorID != "actor" || e.RequestID != "request" {
+			t.Fatalf("entry %d: expected actor and request, got: %q %q", i, e.ActorID, e.RequestID)
+		}
+	}
+	if entries[0].Before != nil {
+		t.Fatalf("expected no state before creation, got: %s", entries[0].Before)
+	}
+
+	var before, after model.User
+	if err := json.Unmarshal(entries[2].Before, &before); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(entries[2].After, &after); err != nil {
+		t.Fatal(err)
+	}
+	if before.FirstName != "" || after.FirstName != "Max" {
+		t.Fatalf("expected first name change, got: %q -> %q", before.FirstName, after.FirstName)
+	}
+	var deleted model.User
+	if err := json.Unmarshal(entries[5].After, &deleted); err != nil {
+		t.Fatal(err)
+	}
+	if deleted.DeletedAt == nil {
+		t.Fatal("expected deletion time after delete")
+	}
+
+	approvals, err := store.ListAuditEntries(ctx, model.AuditFilter{Action: model.AuditApprove})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(approvals) != 1 || approvals[0].EntityID != vR.ID {
+		t.Fatalf("expected approval of %s, got: %v", vR.ID, approvals)
+	}
+}
@@ -0,0 +1,336 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/MninaTB/vacadm/pkg/holiday"
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+// BalanceDB is implemented by any structure providing all BalanceDB methods.
+type BalanceDB interface {
+	// Balance returns entitlement, taken, pending and remaining days of the
+	// given userID for the given year.
+	Balance(ctx context.Context, userID string, year int) (*model.Balance, error)
+	// CarryOver carries the remaining days of the previous year of the given
+	// userID over into the given year. Returns the carry-over resource or nil,
+	// if no days are left.
+	CarryOver(ctx context.Context, userID string, year int) (*model.VacationResource, error)
+}
+
+// ErrUnknownRounding is returned if a rounding rule is not supported.
+var ErrUnknownRounding = errors.New("unknown rounding rule")
+
+// Rounding describes how a prorated entitlement is rounded.
+type Rounding string
+
+const (
+	// RoundingNone keeps the exact prorated entitlement.
+	RoundingNone Rounding = "none"
+	// RoundingHalfDay rounds up to the next half day.
+	RoundingHalfDay Rounding = "half_day"
+	// RoundingNearest rounds to the nearest full day, fractions of at least
+	// half a day are rounded up.
+	RoundingNearest Rounding = "nearest"
+	// RoundingUp rounds up to the next full day.
+	RoundingUp Rounding = "up"
+)
+
+// ParseRounding returns the Rounding of the given name.
+func ParseRounding(name string) (Rounding, error) {
+	switch r := Rounding(name); r {
+	case RoundingNone, RoundingHalfDay, RoundingNearest, RoundingUp:
+		return r, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownRounding, name)
+}
+
+// Round applies the rounding rule to the given days.
+func (r Rounding) Round(days float64) float64 {
+	switch r {
+	case RoundingHalfDay:
+		return math.Ceil(days*2) / 2
+	case RoundingNearest:
+		return math.Round(days)
+	case RoundingUp:
+		return math.Ceil(days)
+	default:
+		return days
+	}
+}
+
+// ErrInvalidCarryOverExpiry is returned if an expiry date of carried days
+// can not be parsed.
+var ErrInvalidCarryOverExpiry = errors.New("invalid carry-over expiry")
+
+// CarryOverPolicy describes how unused days are carried over into the next
+// year.
+type CarryOverPolicy struct {
+	// Cap is the maximum of carried days, 0 means unlimited.
+	Cap float64
+	// ExpiryMonth and ExpiryDay define the day of the year, after which
+	// carried days expire.
+	ExpiryMonth time.Month
+	ExpiryDay   int
+}
+
+// ParseCarryOverExpiry returns month and day of the given expiry in the format
+// MM-DD, e.g. 03-31.
+func ParseCarryOverExpiry(expiry string) (time.Month, int, error) {
+	t, err := time.Parse("01-02", expiry)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %s", ErrInvalidCarryOverExpiry, expiry)
+	}
+	return t.Month(), t.Day(), nil
+}
+
+// expiry returns the date, carried days of the given year expire at.
+func (c CarryOverPolicy) expiry(year int) time.Time {
+	return time.Date(year, c.ExpiryMonth, c.ExpiryDay, 0, 0, 0, 0, time.UTC)
+}
+
+// NewBalanceDB returns initialized BalanceDB that matches
+// the BalanceDB interface. The prorated entitlement is rounded by the given
+// rounding rule, unused days are carried over by the given policy.
+func NewBalanceDB(db Database, rounding Rounding, carryOver CarryOverPolicy) BalanceDB {
+	return &balanceDB{
+		db:         db,
+		calendarDB: NewCalendarDB(db),
+		rounding:   rounding,
+		carryOver:  carryOver,
+		now:        time.Now,
+	}
+}
+
+type balanceDB struct {
+	db         Database
+	calendarDB CalendarDB
+	rounding   Rounding
+	carryOver  CarryOverPolicy
+	now        func() time.Time
+}
+
+// Balance returns entitlement, taken, pending and remaining days of the
+// given userID for the given year.
+// Entitlement is the sum of all vacation resources, which are valid within the
+// given year. Resources covering only a part of the year are prorated by their
+// calendar days, e.g. a hire in July gets about half of the yearly days. The
+// sum is rounded by the configured rounding rule. Taken days are calculated based on approved vacations, pending
+// days based on vacation requests in status pending. Only
+// working days of the users holiday calendar are taken into account, half-day
+// and hourly absences count as the according fraction of a day. Absences of a
+// type, which does not deduct from the vacation resources, are ignored.
+// Days carried over from the previous year are consumed first, carried days
+// not taken until their expiry date are lost.
+func (b *balanceDB) Balance(ctx context.Context, userID string, year int) (*model.Balance, error) {
+	return b.balance(ctx, userID, year, b.now())
+}
+
+// balance returns the balance of the given year, carried days expire if their
+// expiry date is before at.
+func (b *balanceDB) balance(ctx context.Context, userID string, year int, at time.Time) (*model.Balance, error) {
+	cal, err := b.calendarDB.UserCalendar(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	start, end := yearRange(year)
+
+	absenceTypes, err := b.db.ListAbsenceTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// NOTE: absences without type are regular vacations.
+	deducts := func(absenceTypeID *string) bool {
+		if absenceTypeID == nil {
+			return true
+		}
+		for _, a := range absenceTypes {
+			if a.ID == *absenceTypeID {
+				return a.DeductsVacation
+			}
+		}
+		return true
+	}
+
+	resources, err := b.db.GetVacationResourcesByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var entitlement float64
+	var carried []*model.VacationResource
+	for _, r := range resources {
+		if r.IsCarryOver() {
+			if !r.From.Before(start) && !r.From.After(end) {
+				carried = append(carried, r)
+			}
+			continue
+		}
+		entitlement += prorate(r, start, end)
+	}
+	entitlement = b.rounding.Round(entitlement)
+	// NOTE: carried days are consumed in the order of their expiry.
+	sort.Slice(carried, func(i, j int) bool {
+		return carriedUntil(carried[i], end).Before(carriedUntil(carried[j], end))
+	})
+
+	vacations, err := b.db.GetVacationsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var taken float64
+	var deducted []*model.Vacation
+	for _, v := range vacations {
+		if !deducts(v.AbsenceTypeID) {
+			continue
+		}
+		taken += workingDaysWithin(cal, v.From, v.To, start, end) * v.Portion.Fraction(v.Hours)
+		deducted = append(deducted, v)
+	}
+
+	var carriedDays, carriedTaken, carriedExpired float64
+	for _, r := range carried {
+		until := carriedUntil(r, end)
+		// NOTE: days taken until the expiry date, which are not yet consumed
+		// by carried days with an earlier expiry date.
+		var takenUntil float64
+		for _, v := range deducted {
+			takenUntil += workingDaysWithin(cal, v.From, v.To, start, until) * v.Portion.Fraction(v.Hours)
+		}
+		consumed := math.Max(0, math.Min(r.CarriedDays, takenUntil-carriedTaken))
+		carriedDays += r.CarriedDays
+		carriedTaken += consumed
+		if at.After(until) {
+			carriedExpired += r.CarriedDays - consumed
+		}
+	}
+
+	requests, err := b.db.GetVacationRequestsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var pending float64
+	for _, r := range requests {
+		if r.Status != model.StatusPending || !deducts(r.AbsenceTypeID) {
+			continue
+		}
+		pending += workingDaysWithin(cal, r.From, r.To, start, end) * r.Portion.Fraction(r.Hours)
+	}
+
+	return &model.Balance{
+		UserID:         userID,
+		Year:           year,
+		Entitlement:    entitlement,
+		Carried:        carriedDays,
+		CarriedTaken:   carriedTaken,
+		CarriedExpired: carriedExpired,
+		Taken:          taken,
+		Pending:        pending,
+		Remaining:      entitlement + carriedDays - taken - carriedExpired,
+	}, nil
+}
+
+// CarryOver carries the remaining days of the previous year of the given
+// userID over into the given year. The carried days are limited by the cap
+// of the policy and expire at the configured day. An existing carry-over
+// resource of the year is updated, it is deleted if no days are left.
+// Returns the carry-over resource or nil, if no days are left.
+func (b *balanceDB) CarryOver(ctx context.Context, userID string, year int) (*model.VacationResource, error) {
+	start, _ := yearRange(year)
+	// NOTE: at the start of the year, all days carried into the previous year
+	// are expired.
+	previous, err := b.balance(ctx, userID, year-1, start)
+	if err != nil {
+		return nil, err
+	}
+	days := previous.Remaining
+	if b.carryOver.Cap > 0 && days > b.carryOver.Cap {
+		days = b.carryOver.Cap
+	}
+
+	resources, err := b.db.GetVacationResourcesByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var existing *model.VacationResource
+	for _, r := range resources {
+		if r.IsCarryOver() && r.From.Year() == year {
+			existing = r
+			break
+		}
+	}
+
+	if days <= 0 {
+		if existing != nil {
+			return nil, b.db.DeleteVacationResource(ctx, existing.ID)
+		}
+		return nil, nil
+	}
+	carried := &model.VacationResource{
+		UserID:      userID,
+		From:        start,
+		To:          b.carryOver.expiry(year),
+		CarriedDays: days,
+	}
+	if existing != nil {
+		carried.ID = existing.ID
+		return b.db.UpdateVacationResource(ctx, carried)
+	}
+	return b.db.CreateVacationResource(ctx, carried)
+}
+
+// carriedUntil returns the expiry date of the carry-over resource r, a
+// resource without end date expires at the given end of the year.
+func carriedUntil(r *model.VacationResource, end time.Time) time.Time {
+	if r.To.IsZero() || r.To.After(end) {
+		return end
+	}
+	return r.To
+}
+
+// yearRange returns the first and the last day of the given year.
+func yearRange(year int) (time.Time, time.Time) {
+	return time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+}
+
+// prorate returns the part of the yearly days of r, which belongs to the year
+// from start to end.
+func prorate(r *model.VacationResource, start, end time.Time) float64 {
+	from, to := r.From, r.To
+	// NOTE: a resource without end date is valid until further notice.
+	if to.IsZero() || to.After(end) {
+		to = end
+	}
+	if from.Before(start) {
+		from = start
+	}
+	days := calendarDays(from, to)
+	if days <= 0 {
+		return 0
+	}
+	return float64(r.YearlyDays) * float64(days) / float64(calendarDays(start, end))
+}
+
+// calendarDays returns the number of days from from to to, both inclusive.
+func calendarDays(from, to time.Time) int {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours()/24) + 1
+}
+
+// workingDaysWithin returns the number of working days between from and to,
+// that are part of the period start to end. Both limits are inclusive.
+func workingDaysWithin(cal *holiday.Calendar, from, to, start, end time.Time) float64 {
+	if from.Before(start) {
+		from = start
+	}
+	if to.After(end) {
+		to = end
+	}
+	return cal.WorkingDays(from, to)
+}
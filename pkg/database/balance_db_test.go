@@ -0,0 +1,418 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/MninaTB/vacadm/pkg/database/inmemory"
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestBalanceDB_Balance(t *testing.T) {
+	tt := []struct {
+		name            string
+		year            int
+		holidayCalendar *string
+		rounding        Rounding
+		now             time.Time
+		resources       []*model.VacationResource
+		vacations       []*model.Vacation
+		requests        []*model.VacationRequest
+		expect          *model.Balance
+	}{
+		{
+			name: "no resources",
+			year: 2022,
+			expect: &model.Balance{
+				Year: 2022,
+			},
+		},
+		{
+			name: "taken and pending days",
+			year: 2022,
+			resources: []*model.VacationResource{
+				{YearlyDays: 10, From: date(2021, time.January, 1), To: date(2021, time.December, 31)},
+				{YearlyDays: 30, From: date(2022, time.January, 1)},
+			},
+			vacations: []*model.Vacation{
+				{From: date(2022, time.April, 4), To: date(2022, time.April, 8)},
+				// NOTE: only 2 weekend days are part of 2022
+				{From: date(2021, time.December, 30), To: date(2022, time.January, 2)},
+			},
+			requests: []*model.VacationRequest{
+				{From: date(2022, time.May, 2), To: date(2022, time.May, 4)},
+				// NOTE: drafts are not pending
+				{From: date(2022, time.June, 1), To: date(2022, time.June, 3), Status: model.StatusDraft},
+			},
+			expect: &model.Balance{
+				Year:        2022,
+				Entitlement: 30,
+				Taken:       5,
+				Pending:     3,
+				Remaining:   25,
+			},
+		},
+		{
+			name: "half-day and hourly absences",
+			year: 2022,
+			resources: []*model.VacationResource{
+				{YearlyDays: 30, From: date(2022, time.January, 1)},
+			},
+			vacations: []*model.Vacation{
+				{From: date(2022, time.April, 8), To: date(2022, time.April, 8), Portion: model.PortionAfternoon},
+				{From: date(2022, time.April, 11), To: date(2022, time.April, 11), Portion: model.PortionHours, Hours: 2},
+			},
+			requests: []*model.VacationRequest{
+				{From: date(2022, time.May, 2), To: date(2022, time.May, 2), Portion: model.PortionMorning},
+			},
+			expect: &model.Balance{
+				Year:        2022,
+				Entitlement: 30,
+				Taken:       0.75,
+				Pending:     0.5,
+				Remaining:   29.25,
+			},
+		},
+		{
+			name: "absence types without deduction",
+			year: 2022,
+			resources: []*model.VacationResource{
+				{YearlyDays: 30, From: date(2022, time.January, 1)},
+			},
+			vacations: []*model.Vacation{
+				{From: date(2022, time.April, 4), To: date(2022, time.April, 5)},
+				{From: date(2022, time.April, 6), To: date(2022, time.April, 8), AbsenceTypeID: func() *string { tmp := model.AbsenceTypeSickLeave; return &tmp }()},
+			},
+			requests: []*model.VacationRequest{
+				{From: date(2022, time.May, 2), To: date(2022, time.May, 4), AbsenceTypeID: func() *string { tmp := model.AbsenceTypeTraining; return &tmp }()},
+			},
+			expect: &model.Balance{
+				Year:        2022,
+				Entitlement: 30,
+				Taken:       2,
+				Remaining:   28,
+			},
+		},
+		{
+			name:     "prorated joiner",
+			year:     2022,
+			rounding: RoundingNearest,
+			resources: []*model.VacationResource{
+				// NOTE: 184 of 365 days
+				{YearlyDays: 30, From: date(2022, time.July, 1)},
+			},
+			expect: &model.Balance{
+				Year:        2022,
+				Entitlement: 15,
+				Remaining:   15,
+			},
+		},
+		{
+			name:     "contract change",
+			year:     2022,
+			rounding: RoundingHalfDay,
+			resources: []*model.VacationResource{
+				// NOTE: 30 * 181/365 + 25 * 184/365 = 27.48
+				{YearlyDays: 30, From: date(2020, time.January, 1), To: date(2022, time.June, 30)},
+				{YearlyDays: 25, From: date(2022, time.July, 1)},
+			},
+			expect: &model.Balance{
+				Year:        2022,
+				Entitlement: 27.5,
+				Remaining:   27.5,
+			},
+		},
+		{
+			name:     "leaver",
+			year:     2022,
+			rounding: RoundingUp,
+			resources: []*model.VacationResource{
+				// NOTE: 30 * 90/365 = 7.4
+				{YearlyDays: 30, From: date(2021, time.January, 1), To: date(2022, time.March, 31)},
+			},
+			expect: &model.Balance{
+				Year:        2022,
+				Entitlement: 8,
+				Remaining:   8,
+			},
+		},
+		{
+			name: "carried days are consumed first",
+			year: 2022,
+			now:  date(2022, time.June, 1),
+			resources: []*model.VacationResource{
+				{YearlyDays: 30, From: date(2022, time.January, 1)},
+				{CarriedDays: 5, From: date(2022, time.January, 1), To: date(2022, time.March, 31)},
+			},
+			vacations: []*model.Vacation{
+				{From: date(2022, time.March, 14), To: date(2022, time.March, 18)},
+				{From: date(2022, time.April, 4), To: date(2022, time.April, 8)},
+			},
+			expect: &model.Balance{
+				Year:         2022,
+				Entitlement:  30,
+				Carried:      5,
+				CarriedTaken: 5,
+				Taken:        10,
+				Remaining:    25,
+			},
+		},
+		{
+			name: "carried days expire",
+			year: 2022,
+			now:  date(2022, time.June, 1),
+			resources: []*model.VacationResource{
+				{YearlyDays: 30, From: date(2022, time.January, 1)},
+				{CarriedDays: 5, From: date(2022, time.January, 1), To: date(2022, time.March, 31)},
+			},
+			vacations: []*model.Vacation{
+				{From: date(2022, time.March, 14), To: date(2022, time.March, 16)},
+				{From: date(2022, time.April, 4), To: date(2022, time.April, 8)},
+			},
+			expect: &model.Balance{
+				Year:           2022,
+				Entitlement:    30,
+				Carried:        5,
+				CarriedTaken:   3,
+				CarriedExpired: 2,
+				Taken:          8,
+				Remaining:      25,
+			},
+		},
+		{
+			name: "carried days before expiry",
+			year: 2022,
+			now:  date(2022, time.March, 1),
+			resources: []*model.VacationResource{
+				{YearlyDays: 30, From: date(2022, time.January, 1)},
+				{CarriedDays: 5, From: date(2022, time.January, 1), To: date(2022, time.March, 31)},
+			},
+			vacations: []*model.Vacation{
+				{From: date(2022, time.March, 14), To: date(2022, time.March, 16)},
+				{From: date(2022, time.April, 4), To: date(2022, time.April, 8)},
+			},
+			expect: &model.Balance{
+				Year:         2022,
+				Entitlement:  30,
+				Carried:      5,
+				CarriedTaken: 3,
+				Taken:        8,
+				Remaining:    27,
+			},
+		},
+		{
+			name:            "holidays are no vacation days",
+			year:            2022,
+			holidayCalendar: func() *string { tmp := "DE"; return &tmp }(),
+			resources: []*model.VacationResource{
+				{YearlyDays: 30, From: date(2022, time.January, 1), To: date(2022, time.December, 31)},
+			},
+			vacations: []*model.Vacation{
+				// NOTE: easter 2022
+				{From: date(2022, time.April, 11), To: date(2022, time.April, 22)},
+			},
+			expect: &model.Balance{
+				Year:        2022,
+				Entitlement: 30,
+				Taken:       8,
+				Remaining:   22,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := inmemory.NewInmemoryDB()
+			u, err := db.CreateUser(ctx, &model.User{
+				Email:           "balance@inform.de",
+				HolidayCalendar: tc.holidayCalendar,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			approver := "approver-id"
+			for _, r := range tc.resources {
+				r.UserID = u.ID
+				if _, err := db.CreateVacationResource(ctx, r); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, v := range tc.vacations {
+				v.UserID = u.ID
+				v.ApprovedBy = &approver
+				if _, err := db.CreateVacation(ctx, v); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, r := range tc.requests {
+				r.UserID = u.ID
+				if _, err := db.CreateVacationRequest(ctx, r); err != nil {
+					t.Fatal(err)
+				}
+			}
+			rounding := tc.rounding
+			if rounding == "" {
+				rounding = RoundingNone
+			}
+			b := NewBalanceDB(db, rounding, CarryOverPolicy{}).(*balanceDB)
+			if !tc.now.IsZero() {
+				b.now = func() time.Time { return tc.now }
+			}
+			got, err := b.Balance(ctx, u.ID, tc.year)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.expect.UserID = u.ID
+			if !cmp.Equal(tc.expect, got) {
+				t.Fatal(cmp.Diff(tc.expect, got))
+			}
+		})
+	}
+}
+
+func TestBalanceDB_CarryOver(t *testing.T) {
+	tt := []struct {
+		name      string
+		cap       float64
+		resources []*model.VacationResource
+		vacations []*model.Vacation
+		// repeat runs the carry-over twice, to verify existing carry-over
+		// resources are updated.
+		repeat bool
+		expect *model.VacationResource
+	}{
+		{
+			name: "remaining days",
+			resources: []*model.VacationResource{
+				{YearlyDays: 30, From: date(2021, time.January, 1)},
+			},
+			vacations: []*model.Vacation{
+				{From: date(2021, time.April, 5), To: date(2021, time.April, 9)},
+			},
+			expect: &model.VacationResource{
+				From:        date(2022, time.January, 1),
+				To:          date(2022, time.March, 31),
+				CarriedDays: 25,
+			},
+		},
+		{
+			name: "capped",
+			cap:  10,
+			resources: []*model.VacationResource{
+				{YearlyDays: 30, From: date(2021, time.January, 1)},
+			},
+			expect: &model.VacationResource{
+				From:        date(2022, time.January, 1),
+				To:          date(2022, time.March, 31),
+				CarriedDays: 10,
+			},
+		},
+		{
+			name: "expired carried days are not carried again",
+			resources: []*model.VacationResource{
+				{YearlyDays: 10, From: date(2021, time.January, 1)},
+				{CarriedDays: 5, From: date(2021, time.January, 1), To: date(2021, time.March, 31)},
+			},
+			vacations: []*model.Vacation{
+				{From: date(2021, time.April, 5), To: date(2021, time.April, 9)},
+			},
+			expect: &model.VacationResource{
+				From:        date(2022, time.January, 1),
+				To:          date(2022, time.March, 31),
+				CarriedDays: 5,
+			},
+		},
+		{
+			name: "nothing left",
+			resources: []*model.VacationResource{
+				{YearlyDays: 5, From: date(2021, time.January, 1)},
+			},
+			vacations: []*model.Vacation{
+				{From: date(2021, time.April, 5), To: date(2021, time.April, 9)},
+			},
+		},
+		{
+			name:   "update existing carry-over",
+			repeat: true,
+			resources: []*model.VacationResource{
+				{YearlyDays: 30, From: date(2021, time.January, 1)},
+			},
+			expect: &model.VacationResource{
+				From:        date(2022, time.January, 1),
+				To:          date(2022, time.March, 31),
+				CarriedDays: 30,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := inmemory.NewInmemoryDB()
+			u, err := db.CreateUser(ctx, &model.User{Email: "carry-over@inform.de"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			approver := "approver-id"
+			for _, r := range tc.resources {
+				r.UserID = u.ID
+				if _, err := db.CreateVacationResource(ctx, r); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, v := range tc.vacations {
+				v.UserID = u.ID
+				v.ApprovedBy = &approver
+				if _, err := db.CreateVacation(ctx, v); err != nil {
+					t.Fatal(err)
+				}
+			}
+			b := NewBalanceDB(db, RoundingNone, CarryOverPolicy{
+				Cap:         tc.cap,
+				ExpiryMonth: time.March,
+				ExpiryDay:   31,
+			})
+			got, err := b.CarryOver(ctx, u.ID, 2022)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.repeat {
+				got, err = b.CarryOver(ctx, u.ID, 2022)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			resources, err := db.ListVacationResource(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := len(tc.resources)
+			if tc.expect != nil {
+				want++
+			}
+			if len(resources) != want {
+				t.Fatalf("expected %d resources, got %d", want, len(resources))
+			}
+			if tc.expect == nil {
+				if got != nil {
+					t.Fatalf("expected no carry-over, got %v", got)
+				}
+				return
+			}
+			tc.expect.UserID = u.ID
+			opt := cmpopts.IgnoreFields(model.VacationResource{}, "ID", "CreatedAt", "UpdatedAt", "Version")
+			if !cmp.Equal(tc.expect, got, opt) {
+				t.Fatal(cmp.Diff(tc.expect, got, opt))
+			}
+		})
+	}
+}
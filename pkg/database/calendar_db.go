@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+
+	"github.com/MninaTB/vacadm/pkg/holiday"
+)
+
+// CalendarDB is implemented by any structure providing all CalendarDB methods.
+type CalendarDB interface {
+	// UserCalendar returns the holiday calendar that applies to the given userID.
+	UserCalendar(ctx context.Context, userID string) (*holiday.Calendar, error)
+	// TeamCalendar returns the holiday calendar that applies to the given teamID.
+	TeamCalendar(ctx context.Context, teamID string) (*holiday.Calendar, error)
+}
+
+// NewCalendarDB returns initialized CalendarDB that matches
+// the CalendarDB interface.
+func NewCalendarDB(db Database) CalendarDB {
+	return &calendarDB{
+		db: db,
+	}
+}
+
+type calendarDB struct {
+	db Database
+}
+
+// UserCalendar returns the holiday calendar that applies to the given userID.
+// A calendar attached to the user takes precedence over the calendar of the
+// users team. If neither user nor team refer to a calendar, nil is returned.
+// A nil calendar is valid and only considers weekends as non working days.
+func (c *calendarDB) UserCalendar(ctx context.Context, userID string) (*holiday.Calendar, error) {
+	u, err := c.db.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.HolidayCalendar != nil {
+		return holiday.Lookup(*u.HolidayCalendar)
+	}
+	if u.TeamID == nil {
+		return nil, nil
+	}
+	return c.TeamCalendar(ctx, *u.TeamID)
+}
+
+// TeamCalendar returns the holiday calendar that applies to the given teamID.
+// If the team does not refer to a calendar, nil is returned.
+func (c *calendarDB) TeamCalendar(ctx context.Context, teamID string) (*holiday.Calendar, error) {
+	t, err := c.db.GetTeamByID(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if t.HolidayCalendar == nil {
+		return nil, nil
+	}
+	return holiday.Lookup(*t.HolidayCalendar)
+}
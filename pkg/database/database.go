@@ -2,9 +2,11 @@ package database
 
 import (
 	"context"
+	"time"
 
 	"github.com/MninaTB/vacadm/pkg/database/inmemory"
 	"github.com/MninaTB/vacadm/pkg/database/mariadb"
+	"github.com/MninaTB/vacadm/pkg/database/query"
 	"github.com/MninaTB/vacadm/pkg/model"
 )
 
@@ -13,67 +15,178 @@ var _ Database = (*mariadb.MariaDB)(nil)
 
 // Database is implemented by any structure providing all Database methods,
 // defines how models are handled.
+// Deletions are soft, deleted entries are kept until they are purged. Get and
+// list methods exclude deleted entries, unless query.IncludeDeleted is given.
+// Deleted entries can not be updated.
 type Database interface {
 	// CreateUser stores an internal copy of the given user, if email address is
 	// not already in use, given parentID and/or teamID exists.
 	// Returns copy with assigned userID.
 	CreateUser(ctx context.Context, user *model.User) (*model.User, error)
 	// GetUserByID returns the associated user by the given id.
-	GetUserByID(ctx context.Context, userID string) (*model.User, error)
-	// ListUsers returns a copy of the internal user list.
-	ListUsers(ctx context.Context) ([]*model.User, error)
+	GetUserByID(ctx context.Context, userID string, opts ...query.Option) (*model.User, error)
+	// ListUsers returns a copy of the internal user list. Supports the team
+	// filter, sorting and paging of query.Options.
+	ListUsers(ctx context.Context, opts ...query.Option) ([]*model.User, error)
 	// UpdateUser updates user entry by the given user.
 	UpdateUser(ctx context.Context, user *model.User) (*model.User, error)
-	// DeleteUser removes user entry by the given id.
+	// DeleteUser marks user entry by the given id as deleted.
 	DeleteUser(ctx context.Context, userID string) error
 
 	// CreateTeam stores an internal copy of the given team.
 	// Returns copy with assigned teamID.
 	CreateTeam(ctx context.Context, team *model.Team) (*model.Team, error)
 	// GetTeamByID returns the associated team by the given id.
-	GetTeamByID(ctx context.Context, teamID string) (*model.Team, error)
-	// ListTeams returns a copy of the internal team list.
-	ListTeams(ctx context.Context) ([]*model.Team, error)
+	GetTeamByID(ctx context.Context, teamID string, opts ...query.Option) (*model.Team, error)
+	// ListTeams returns a copy of the internal team list. Supports sorting and
+	// paging of query.Options.
+	ListTeams(ctx context.Context, opts ...query.Option) ([]*model.Team, error)
 	// ListTeamUsers returns a list of users associated by the given teamID
-	ListTeamUsers(ctx context.Context, teamID string) ([]*model.User, error)
+	// through their team memberships.
+	ListTeamUsers(ctx context.Context, teamID string, opts ...query.Option) ([]*model.User, error)
 	// UpdateTeam updates team entry by the given team.
 	UpdateTeam(ctx context.Context, team *model.Team) (*model.Team, error)
-	// DeleteTeam removes team entry by the given id.
+	// DeleteTeam marks team entry by the given id as deleted.
 	DeleteTeam(ctx context.Context, teamID string) error
 
 	// CreateVacation stores an internal copy of the given vacation resource.
 	// Returns copy with assigned vacationID.
 	CreateVacation(ctx context.Context, vacation *model.Vacation) (*model.Vacation, error)
-	// GetVacationsByTeamID returns the associated vacation by the given teamID.
-	GetVacationsByTeamID(ctx context.Context, teamID string) ([]*model.Vacation, error)
+	// GetVacationsByUserID returns the vacations of the given userID.
+	GetVacationsByUserID(ctx context.Context, userID string, opts ...query.Option) ([]*model.Vacation, error)
+	// GetVacationsByTeamID returns the vacations of the members of the given
+	// teamID.
+	GetVacationsByTeamID(ctx context.Context, teamID string, opts ...query.Option) ([]*model.Vacation, error)
 	// GetVacationByID returns the associated vacation by the given id.
-	GetVacationByID(ctx context.Context, vacationID string) (*model.Vacation, error)
-	// ListVacations returns a copy of the internal vacation list.
-	ListVacations(ctx context.Context) ([]*model.Vacation, error)
-	// DeleteVacation removes vacation entry by the given id.
+	GetVacationByID(ctx context.Context, vacationID string, opts ...query.Option) (*model.Vacation, error)
+	// ListVacations returns a copy of the internal vacation list. Supports the
+	// user, team and period filter, sorting and paging of query.Options.
+	ListVacations(ctx context.Context, opts ...query.Option) ([]*model.Vacation, error)
+	// DeleteVacation marks vacation entry by the given id as deleted.
 	DeleteVacation(ctx context.Context, vacationID string) error
 
 	// CreateVacationRequest stores an internal copy of the given vacationRequest.
 	// Returns copy with assigned vacationRequestID.
 	CreateVacationRequest(ctx context.Context, vacationRequest *model.VacationRequest) (*model.VacationRequest, error)
 	// GetVacationRequestByID returns the associated vacationRequest by the given id.
-	GetVacationRequestByID(ctx context.Context, vacationRequestID string) (*model.VacationRequest, error)
+	GetVacationRequestByID(ctx context.Context, vacationRequestID string, opts ...query.Option) (*model.VacationRequest, error)
 	// ListVacationRequests returns a copy of the internal vacationRequest list.
-	ListVacationRequests(ctx context.Context) ([]*model.VacationRequest, error)
+	// Supports all filters, sorting and paging of query.Options.
+	ListVacationRequests(ctx context.Context, opts ...query.Option) ([]*model.VacationRequest, error)
+	// GetVacationRequestsByUserID returns the vacationRequests of the given
+	// userID.
+	GetVacationRequestsByUserID(ctx context.Context, userID string, opts ...query.Option) ([]*model.VacationRequest, error)
+	// GetVacationRequestsByTeamID returns the vacationRequests of the members
+	// of the given teamID.
+	GetVacationRequestsByTeamID(ctx context.Context, teamID string, opts ...query.Option) ([]*model.VacationRequest, error)
 	// UpdateVacationRequest updates vacationRequest entry by the given vacationRequest.
 	UpdateVacationRequest(ctx context.Context, vacationRequest *model.VacationRequest) (*model.VacationRequest, error)
-	// DeleteVacationRequest removes vacationRequest entry by the given id.
+	// ApproveVacationRequest moves the vacationRequest by the given id to
+	// approved and stores the given vacation, which is linked to the request.
+	// Either both succeed or nothing is changed.
+	ApproveVacationRequest(ctx context.Context, vacationRequestID string, vacation *model.Vacation) (*model.VacationRequest, *model.Vacation, error)
+	// CancelVacationRequest moves the vacationRequest by the given id to
+	// cancelled and marks the linked vacation as deleted. Either both succeed
+	// or nothing is changed, an already deleted vacation is skipped.
+	CancelVacationRequest(ctx context.Context, vacationRequestID string) (*model.VacationRequest, error)
+	// DeleteVacationRequest marks vacationRequest entry by the given id as deleted.
 	DeleteVacationRequest(ctx context.Context, vacationRequestID string) error
 
 	// CreateVacationResource stores an internal copy of the given vacationResource.
 	// Returns copy with assigned vacationResourceID.
 	CreateVacationResource(ctx context.Context, vacationResource *model.VacationResource) (*model.VacationResource, error)
 	// GetVacationResourceByID returns the associated vacationResource by the given id.
-	GetVacationResourceByID(ctx context.Context, vacationResourceID string) (*model.VacationResource, error)
+	GetVacationResourceByID(ctx context.Context, vacationResourceID string, opts ...query.Option) (*model.VacationResource, error)
 	// ListVacationResource returns a copy of the internal vacationResource list.
-	ListVacationResource(ctx context.Context) ([]*model.VacationResource, error)
+	// Supports the user, team and period filter, sorting and paging of
+	// query.Options.
+	ListVacationResource(ctx context.Context, opts ...query.Option) ([]*model.VacationResource, error)
+	// GetVacationResourcesByUserID returns the vacationResources of the given
+	// userID.
+	GetVacationResourcesByUserID(ctx context.Context, userID string, opts ...query.Option) ([]*model.VacationResource, error)
+	// GetVacationResourcesByTeamID returns the vacationResources of the
+	// members of the given teamID.
+	GetVacationResourcesByTeamID(ctx context.Context, teamID string, opts ...query.Option) ([]*model.VacationResource, error)
 	// UpdateVacationResource updates vacationResource entry by the given vacationResource.
 	UpdateVacationResource(ctx context.Context, vacationResource *model.VacationResource) (*model.VacationResource, error)
-	// DeleteVacationResource removes vacationResource entry by the given id.
+	// DeleteVacationResource marks vacationResource entry by the given id as deleted.
 	DeleteVacationResource(ctx context.Context, vacationResourceID string) error
+
+	// CreateAbsenceType stores an internal copy of the given absenceType.
+	// Returns copy with assigned absenceTypeID.
+	CreateAbsenceType(ctx context.Context, absenceType *model.AbsenceType) (*model.AbsenceType, error)
+	// GetAbsenceTypeByID returns the associated absenceType by the given id.
+	GetAbsenceTypeByID(ctx context.Context, absenceTypeID string, opts ...query.Option) (*model.AbsenceType, error)
+	// ListAbsenceTypes returns a copy of the internal absenceType list.
+	ListAbsenceTypes(ctx context.Context, opts ...query.Option) ([]*model.AbsenceType, error)
+	// UpdateAbsenceType updates absenceType entry by the given absenceType.
+	UpdateAbsenceType(ctx context.Context, absenceType *model.AbsenceType) (*model.AbsenceType, error)
+	// DeleteAbsenceType marks absenceType entry by the given id as deleted.
+	DeleteAbsenceType(ctx context.Context, absenceTypeID string) error
+
+	// CreateDelegation stores an internal copy of the given delegation.
+	// Returns copy with assigned delegationID.
+	CreateDelegation(ctx context.Context, delegation *model.Delegation) (*model.Delegation, error)
+	// GetDelegationByID returns the associated delegation by the given id.
+	GetDelegationByID(ctx context.Context, delegationID string, opts ...query.Option) (*model.Delegation, error)
+	// ListDelegations returns a copy of the internal delegation list.
+	ListDelegations(ctx context.Context, opts ...query.Option) ([]*model.Delegation, error)
+	// DeleteDelegation marks delegation entry by the given id as deleted.
+	DeleteDelegation(ctx context.Context, delegationID string) error
+
+	// CreateTeamRule stores an internal copy of the given teamRule.
+	// Returns copy with assigned teamRuleID.
+	CreateTeamRule(ctx context.Context, teamRule *model.TeamRule) (*model.TeamRule, error)
+	// GetTeamRuleByID returns the associated teamRule by the given id.
+	GetTeamRuleByID(ctx context.Context, teamRuleID string, opts ...query.Option) (*model.TeamRule, error)
+	// ListTeamRules returns a list of teamRules associated by the given teamID.
+	ListTeamRules(ctx context.Context, teamID string, opts ...query.Option) ([]*model.TeamRule, error)
+	// UpdateTeamRule updates teamRule entry by the given teamRule.
+	UpdateTeamRule(ctx context.Context, teamRule *model.TeamRule) (*model.TeamRule, error)
+	// DeleteTeamRule marks teamRule entry by the given id as deleted.
+	DeleteTeamRule(ctx context.Context, teamRuleID string) error
+
+	// CreateComment stores an internal copy of the given comment.
+	// Returns copy with assigned commentID.
+	CreateComment(ctx context.Context, comment *model.Comment) (*model.Comment, error)
+	// ListComments returns a list of comments associated by the given
+	// vacationRequestID, ordered by creation.
+	ListComments(ctx context.Context, vacationRequestID string, opts ...query.Option) ([]*model.Comment, error)
+
+	// CreateAttachment stores an internal copy of the given attachment.
+	// Returns copy with assigned attachmentID.
+	CreateAttachment(ctx context.Context, attachment *model.Attachment) (*model.Attachment, error)
+	// GetAttachmentByID returns the associated attachment by the given id.
+	GetAttachmentByID(ctx context.Context, attachmentID string, opts ...query.Option) (*model.Attachment, error)
+	// ListAttachments returns a list of attachments associated by the given
+	// resource.
+	ListAttachments(ctx context.Context, kind model.AttachmentResource, resourceID string, opts ...query.Option) ([]*model.Attachment, error)
+	// DeleteAttachment marks attachment entry by the given id as deleted.
+	DeleteAttachment(ctx context.Context, attachmentID string) error
+
+	// CreateTeamMembership stores an internal copy of the given
+	// teamMembership, if the user is not yet member of the team and the
+	// allocations of the user do not exceed 100 percent.
+	// Returns copy with assigned teamMembershipID.
+	CreateTeamMembership(ctx context.Context, teamMembership *model.TeamMembership) (*model.TeamMembership, error)
+	// GetTeamMembershipByID returns the associated teamMembership by the given id.
+	GetTeamMembershipByID(ctx context.Context, teamMembershipID string, opts ...query.Option) (*model.TeamMembership, error)
+	// ListTeamMemberships returns a list of teamMemberships associated by the
+	// given teamID.
+	ListTeamMemberships(ctx context.Context, teamID string, opts ...query.Option) ([]*model.TeamMembership, error)
+	// ListUserTeamMemberships returns a list of teamMemberships associated by
+	// the given userID.
+	ListUserTeamMemberships(ctx context.Context, userID string, opts ...query.Option) ([]*model.TeamMembership, error)
+	// UpdateTeamMembership updates role and allocation of the teamMembership
+	// entry by the given teamMembership.
+	UpdateTeamMembership(ctx context.Context, teamMembership *model.TeamMembership) (*model.TeamMembership, error)
+	// DeleteTeamMembership marks teamMembership entry by the given id as deleted.
+	DeleteTeamMembership(ctx context.Context, teamMembershipID string) error
+
+	// Restore removes the deletion mark of the entry of the given entity and
+	// id.
+	Restore(ctx context.Context, entity model.Entity, id string) error
+	// Purge permanently removes the entry of the given entity and id, if it
+	// was deleted before deletedBefore.
+	Purge(ctx context.Context, entity model.Entity, id string, deletedBefore time.Time) error
 }
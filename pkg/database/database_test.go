@@ -0,0 +1,324 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+
+	"github.com/MninaTB/vacadm/pkg/database/inmemory"
+	"github.com/MninaTB/vacadm/pkg/database/mariadb"
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+// backends returns the Database implementations, which are verified by the
+// backend-agnostic tests. MariaDB is verified, if VACADM_TEST_SQL holds the
+// connection string of a migrated database, e.g.
+// user:password@/vacadm?parseTime=true
+func backends(t *testing.T) map[string]Database {
+	t.Helper()
+	dbs := map[string]Database{
+		"inmemory": inmemory.NewInmemoryDB(),
+	}
+	if conn := os.Getenv("VACADM_TEST_SQL"); conn != "" {
+		sqlDB, err := sql.Open("mysql", conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { sqlDB.Close() })
+		dbs["mariadb"] = mariadb.NewMariaDB(sqlDB)
+	}
+	return dbs
+}
+
+func TestDatabase_UpdateUserTeam(t *testing.T) {
+	for name, db := range backends(t) {
+		db := db
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			// NOTE: unique emails allow to run against a shared database.
+			email := func(name string) string { return name + "-" + uuid.NewString() + "@inform.de" }
+			owner, err := db.CreateUser(ctx, &model.User{Email: email("owner")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			first, err := db.CreateTeam(ctx, &model.Team{Name: "first", OwnerID: owner.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := db.CreateTeam(ctx, &model.Team{Name: "second", OwnerID: owner.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			user, err := db.CreateUser(ctx, &model.User{Email: email("user"), ParentID: &owner.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = db.CreateTeamMembership(ctx, &model.TeamMembership{TeamID: first.ID, UserID: user.ID, Allocation: 60})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			user.TeamID = &second.ID
+			updated, err := db.UpdateUser(ctx, user)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if updated.TeamID == nil || *updated.TeamID != second.ID {
+				t.Fatalf("expected team %s, got: %v", second.ID, updated.TeamID)
+			}
+			memberships, err := db.ListUserTeamMemberships(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			allocations := map[string]int{}
+			for _, m := range memberships {
+				allocations[m.TeamID] = m.Allocation
+			}
+			if len(allocations) != 2 || allocations[first.ID] != 60 || allocations[second.ID] != 40 {
+				t.Fatalf("expected memberships of 60 and 40 percent, got: %v", allocations)
+			}
+
+			// NOTE: updating the user again keeps the existing membership.
+			if _, err = db.UpdateUser(ctx, updated); err != nil {
+				t.Fatal(err)
+			}
+			memberships, err = db.ListUserTeamMemberships(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(memberships) != 2 {
+				t.Fatalf("expected 2 memberships, got: %d", len(memberships))
+			}
+		})
+	}
+}
+
+func TestDatabase_MoveUserTeam(t *testing.T) {
+	for name, db := range backends(t) {
+		db := db
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			email := func(name string) string { return name + "-" + uuid.NewString() + "@inform.de" }
+			owner, err := db.CreateUser(ctx, &model.User{Email: email("owner")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			first, err := db.CreateTeam(ctx, &model.Team{Name: "first", OwnerID: owner.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := db.CreateTeam(ctx, &model.Team{Name: "second", OwnerID: owner.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// NOTE: the user is created with a full membership of the first team.
+			user, err := db.CreateUser(ctx, &model.User{Email: email("user"), ParentID: &owner.ID, TeamID: &first.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			user.TeamID = &second.ID
+			updated, err := db.UpdateUser(ctx, user)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if updated.TeamID == nil || *updated.TeamID != second.ID {
+				t.Fatalf("expected team %s, got: %v", second.ID, updated.TeamID)
+			}
+			memberships, err := db.ListUserTeamMemberships(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(memberships) != 1 || memberships[0].TeamID != second.ID || memberships[0].Allocation != model.FullAllocation {
+				t.Fatalf("expected the membership to move to team %s, got: %+v", second.ID, memberships)
+			}
+		})
+	}
+}
+
+func TestDatabase_UpdateTeam(t *testing.T) {
+	for name, db := range backends(t) {
+		db := db
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			email := func(name string) string { return name + "-" + uuid.NewString() + "@inform.de" }
+			owner, err := db.CreateUser(ctx, &model.User{Email: email("owner")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			successor, err := db.CreateUser(ctx, &model.User{Email: email("successor")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			parent, err := db.CreateTeam(ctx, &model.Team{Name: "department", OwnerID: owner.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			calendar := "DE-BY"
+			team, err := db.CreateTeam(ctx, &model.Team{Name: "backend", OwnerID: owner.ID, ParentID: &parent.ID, HolidayCalendar: &calendar})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// NOTE: fields missing in the payload keep their value.
+			other := "DE-BE"
+			updated, err := db.UpdateTeam(ctx, &model.Team{ID: team.ID, HolidayCalendar: &other})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := func(got *model.Team, ownerID, calendar string) {
+				t.Helper()
+				if got.Name != "backend" || got.OwnerID != ownerID || got.ParentID == nil || *got.ParentID != parent.ID ||
+					got.HolidayCalendar == nil || *got.HolidayCalendar != calendar {
+					t.Fatalf("unexpected team: %+v", got)
+				}
+			}
+			want(updated, owner.ID, other)
+
+			updated, err = db.UpdateTeam(ctx, &model.Team{ID: team.ID, OwnerID: successor.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want(updated, successor.ID, other)
+
+			// NOTE: the returned team is a copy, changing it keeps the store.
+			updated.Name = "changed"
+			got, err := db.GetTeamByID(ctx, team.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want(got, successor.ID, other)
+		})
+	}
+}
+
+func TestDatabase_PurgeReferenced(t *testing.T) {
+	for name, db := range backends(t) {
+		db := db
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			email := func(name string) string { return name + "-" + uuid.NewString() + "@inform.de" }
+			owner, err := db.CreateUser(ctx, &model.User{Email: email("owner")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			team, err := db.CreateTeam(ctx, &model.Team{Name: "team", OwnerID: owner.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			user, err := db.CreateUser(ctx, &model.User{Email: email("user"), ParentID: &owner.ID, TeamID: &team.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = db.CreateVacation(ctx, &model.Vacation{
+				UserID:     user.ID,
+				ApprovedBy: &owner.ID,
+				From:       time.Date(2022, time.April, 4, 0, 0, 0, 0, time.UTC),
+				To:         time.Date(2022, time.April, 8, 0, 0, 0, 0, time.UTC),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			unreferenced, err := db.CreateUser(ctx, &model.User{Email: email("unreferenced")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range []string{user.ID, unreferenced.ID} {
+				if err := db.DeleteUser(ctx, id); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := db.DeleteTeam(ctx, team.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			deletedBefore := time.Now().Add(time.Hour)
+			err = db.Purge(ctx, model.EntityUser, user.ID, deletedBefore)
+			if !errors.Is(err, model.ErrEntityReferenced) {
+				t.Fatalf("expected %v for the user, got: %v", model.ErrEntityReferenced, err)
+			}
+			err = db.Purge(ctx, model.EntityTeam, team.ID, deletedBefore)
+			if !errors.Is(err, model.ErrEntityReferenced) {
+				t.Fatalf("expected %v for the team, got: %v", model.ErrEntityReferenced, err)
+			}
+			if err := db.Purge(ctx, model.EntityUser, unreferenced.ID, deletedBefore); err != nil {
+				t.Fatal(err)
+			}
+			err = db.Purge(ctx, model.EntityUser, unreferenced.ID, deletedBefore)
+			if !errors.Is(err, model.ErrEntityNotFound) {
+				t.Fatalf("expected %v, got: %v", model.ErrEntityNotFound, err)
+			}
+		})
+	}
+}
+
+func TestDatabase_RestoreOverlap(t *testing.T) {
+	for name, db := range backends(t) {
+		db := db
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			day := func(d int) time.Time {
+				return time.Date(2022, time.April, d, 0, 0, 0, 0, time.UTC)
+			}
+			user, err := db.CreateUser(ctx, &model.User{Email: "user-" + uuid.NewString() + "@inform.de"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			request, err := db.CreateVacationRequest(ctx, &model.VacationRequest{
+				UserID: user.ID, Status: model.StatusPending, From: day(4), To: day(8),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			vacation, err := db.CreateVacation(ctx, &model.Vacation{
+				UserID: user.ID, ApprovedBy: &user.ID, From: day(18), To: day(22),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := db.DeleteVacationRequest(ctx, request.ID); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.DeleteVacation(ctx, vacation.ID); err != nil {
+				t.Fatal(err)
+			}
+			// NOTE: the deleted absences no longer reserve their periods.
+			blocking := make([]string, 0, 2)
+			for _, d := range []int{6, 20} {
+				b, err := db.CreateVacationRequest(ctx, &model.VacationRequest{
+					UserID: user.ID, Status: model.StatusPending, From: day(d), To: day(d),
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				blocking = append(blocking, b.ID)
+			}
+
+			err = db.Restore(ctx, model.EntityVacationRequest, request.ID)
+			if !errors.Is(err, model.ErrOverlappingAbsence) {
+				t.Fatalf("expected %v for the vacation-request, got: %v", model.ErrOverlappingAbsence, err)
+			}
+			err = db.Restore(ctx, model.EntityVacation, vacation.ID)
+			if !errors.Is(err, model.ErrOverlappingAbsence) {
+				t.Fatalf("expected %v for the vacation, got: %v", model.ErrOverlappingAbsence, err)
+			}
+			for _, id := range blocking {
+				if err := db.DeleteVacationRequest(ctx, id); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := db.Restore(ctx, model.EntityVacationRequest, request.ID); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.Restore(ctx, model.EntityVacation, vacation.ID); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
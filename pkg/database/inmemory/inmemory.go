@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/MninaTB/vacadm/pkg/database/query"
 	"github.com/MninaTB/vacadm/pkg/model"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
@@ -21,10 +22,27 @@ func NewInmemoryDB() *InmemoryDB {
 		vacationStore:         make([]*model.Vacation, 0),
 		vacationRequestStore:  make([]*model.VacationRequest, 0),
 		vacationResourceStore: make([]*model.VacationResource, 0),
+		absenceTypeStore:      defaultAbsenceTypes(),
+		delegationStore:       make([]*model.Delegation, 0),
+		teamRuleStore:         make([]*model.TeamRule, 0),
+		commentStore:          make([]*model.Comment, 0),
+		attachmentStore:       make([]*model.Attachment, 0),
+		teamMembershipStore:   make([]*model.TeamMembership, 0),
+		auditStore:            make([]*model.AuditEntry, 0),
 		logger:                logrus.New().WithField("component", "inmemoryDB"),
 	}
 }
 
+func defaultAbsenceTypes() []*model.AbsenceType {
+	createdAt := time.Now()
+	absenceTypes := model.DefaultAbsenceTypes()
+	for _, a := range absenceTypes {
+		a.CreatedAt = &createdAt
+		a.Version = 1
+	}
+	return absenceTypes
+}
+
 // InmemoryDB is a threadsafe inmemory database implementation.
 type InmemoryDB struct {
 	muUserStore sync.Mutex
@@ -42,11 +60,33 @@ type InmemoryDB struct {
 	muVacationResourceStore sync.Mutex
 	vacationResourceStore   []*model.VacationResource
 
+	muAbsenceTypeStore sync.Mutex
+	absenceTypeStore   []*model.AbsenceType
+
+	muDelegationStore sync.Mutex
+	delegationStore   []*model.Delegation
+
+	muTeamRuleStore sync.Mutex
+	teamRuleStore   []*model.TeamRule
+
+	muCommentStore sync.Mutex
+	commentStore   []*model.Comment
+
+	muAttachmentStore sync.Mutex
+	attachmentStore   []*model.Attachment
+
+	muTeamMembershipStore sync.Mutex
+	teamMembershipStore   []*model.TeamMembership
+
+	muAuditStore sync.Mutex
+	auditStore   []*model.AuditEntry
+
 	logger logrus.FieldLogger
 }
 
 // CreateUser stores an internal copy of the given user, if email address is
-// not already in use, given parentID and/or teamID exists.
+// not already in use, given parentID and/or teamID exists. The user becomes a
+// fully allocated member of the given team.
 // Returns copy with assigned userID.
 func (i *InmemoryDB) CreateUser(ctx context.Context, user *model.User) (*model.User, error) {
 	i.muUserStore.Lock()
@@ -60,17 +100,8 @@ func (i *InmemoryDB) CreateUser(ctx context.Context, user *model.User) (*model.U
 		}
 	}
 
-	if user.ParentID != nil {
-		var foundParent bool
-		for _, p := range i.userStore {
-			if p.ID == *user.ParentID {
-				foundParent = true
-				break
-			}
-		}
-		if !foundParent {
-			return nil, fmt.Errorf("missing parent with id %s", *user.ParentID)
-		}
+	if err := model.ValidateUserParent(i.userStore, "", user.ParentID); err != nil {
+		return nil, err
 	}
 
 	if user.TeamID != nil {
@@ -80,22 +111,43 @@ func (i *InmemoryDB) CreateUser(ctx context.Context, user *model.User) (*model.U
 		}
 	}
 
+	role, err := model.ParseRole(string(user.Role))
+	if err != nil {
+		return nil, err
+	}
+	user.Role = role
+
 	createdAt := time.Now()
 	user.CreatedAt = &createdAt
 	user.ID = uuid.NewString()
+	user.Version = 1
 	usrCopy := user.Copy()
 
+	if user.TeamID != nil {
+		i.muTeamMembershipStore.Lock()
+		i.teamMembershipStore = append(i.teamMembershipStore, &model.TeamMembership{
+			ID:         uuid.NewString(),
+			TeamID:     *user.TeamID,
+			UserID:     user.ID,
+			Role:       model.TeamRoleMember,
+			Allocation: model.FullAllocation,
+			CreatedAt:  &createdAt,
+		})
+		i.muTeamMembershipStore.Unlock()
+	}
+
 	i.logger.Info("create user with id: ", user.ID)
 	i.userStore = append(i.userStore, usrCopy)
 	return user, nil
 }
 
 // GetUserByID returns the associated user by the given id.
-func (i *InmemoryDB) GetUserByID(_ context.Context, id string) (*model.User, error) {
+func (i *InmemoryDB) GetUserByID(_ context.Context, id string, opts ...query.Option) (*model.User, error) {
 	i.muUserStore.Lock()
 	defer i.muUserStore.Unlock()
+	o := query.New(opts...)
 	for _, s := range i.userStore {
-		if s.ID == id {
+		if s.ID == id && o.Visible(s.DeletedAt) {
 			i.logger.Info("get user with id: ", s.ID)
 			return s.Copy(), nil
 		}
@@ -105,65 +157,151 @@ func (i *InmemoryDB) GetUserByID(_ context.Context, id string) (*model.User, err
 }
 
 // ListUsers returns a copy of the internal user list.
-func (i *InmemoryDB) ListUsers(_ context.Context) ([]*model.User, error) {
+func (i *InmemoryDB) ListUsers(ctx context.Context, opts ...query.Option) ([]*model.User, error) {
+	o := query.New(opts...)
+	members, err := i.teamMembers(ctx, o.Filter)
+	if err != nil {
+		return nil, err
+	}
 	i.muUserStore.Lock()
 	defer i.muUserStore.Unlock()
 	i.logger.Info("get list of users")
 
-	userStore := make([]*model.User, len(i.userStore))
-	for j, u := range i.userStore {
-		userStore[j] = u.Copy()
+	userStore := make([]*model.User, 0, len(i.userStore))
+	for _, u := range i.userStore {
+		if o.Visible(u.DeletedAt) && isMember(members, u.ID) {
+			userStore = append(userStore, u.Copy())
+		}
+	}
+	idx, err := o.Apply(len(userStore), func(x int) interface{} { return userStore[x] })
+	if err != nil {
+		return nil, err
+	}
+	page := make([]*model.User, 0, len(idx))
+	for _, x := range idx {
+		page = append(page, userStore[x])
 	}
-	return userStore, nil
+	return page, nil
 }
 
-// UpdateUser updates user entry by the given user.
-func (i *InmemoryDB) UpdateUser(_ context.Context, user *model.User) (*model.User, error) {
+// UpdateUser updates user entry by the given user. A new parent must not be
+// one of the direct or indirect reports of the user. The user becomes a member
+// of a new team with its remaining allocation.
+func (i *InmemoryDB) UpdateUser(ctx context.Context, user *model.User) (*model.User, error) {
 	i.muUserStore.Lock()
 	defer i.muUserStore.Unlock()
 	updatededAt := time.Now()
 	for x := 0; x < len(i.userStore); x++ {
-		if i.userStore[x].ID != user.ID {
+		if i.userStore[x].ID != user.ID || i.userStore[x].DeletedAt != nil {
 			continue
 		}
+		if err := checkVersion(model.EntityUser, user.ID, i.userStore[x].Version, user.Version); err != nil {
+			return nil, err
+		}
+		updated := i.userStore[x].Copy()
+		previousTeamID := updated.TeamID
 		if user.Email != "" {
-			i.userStore[x].Email = user.Email
+			updated.Email = user.Email
 		}
 		if user.ParentID != nil {
-			var found bool
-			for _, u := range i.userStore {
-				if u.ID == *user.ParentID {
-					i.userStore[x].ParentID = user.ParentID
-					found = true
-					break
-				}
+			err := model.ValidateUserParent(i.userStore, user.ID, user.ParentID)
+			if err != nil {
+				return nil, err
 			}
-			if !found {
-				return nil, fmt.Errorf("parent with id: '%s' not found", *user.ParentID)
+			updated.ParentID = user.ParentID
+		}
+		if user.TeamID != nil {
+			if _, err := i.GetTeamByID(ctx, *user.TeamID); err != nil {
+				return nil, err
 			}
+			teamID := *user.TeamID
+			updated.TeamID = &teamID
 		}
 		if user.FirstName != "" {
-			i.userStore[x].FirstName = user.FirstName
+			updated.FirstName = user.FirstName
 		}
 		if user.LastName != "" {
-			i.userStore[x].LastName = user.LastName
+			updated.LastName = user.LastName
 		}
-		i.userStore[x].UpdatedAt = &updatededAt
+		if user.HolidayCalendar != nil {
+			updated.HolidayCalendar = user.HolidayCalendar
+		}
+		if user.Role != "" {
+			role, err := model.ParseRole(string(user.Role))
+			if err != nil {
+				return nil, err
+			}
+			updated.Role = role
+		}
+		if user.TeamID != nil {
+			if err := i.ensureTeamMembership(previousTeamID, *user.TeamID, user.ID, updatededAt); err != nil {
+				return nil, err
+			}
+		}
+		updated.UpdatedAt = &updatededAt
+		updated.Version++
+		i.userStore[x] = updated
 		i.logger.Info("update user with id: ", user.ID)
-		return i.userStore[x].Copy(), nil
+		return updated.Copy(), nil
 	}
 	i.logger.Error("update failed: no user found")
 	return nil, errors.New("update failed: no user found")
 }
 
-// DeleteUser removes user entry by the given id.
+// ensureTeamMembership makes the user a member of the given team, if this is
+// not already the case. The membership of the previous team of the user is
+// ended, the new membership gets the remaining allocation of the user.
+func (i *InmemoryDB) ensureTeamMembership(previousTeamID *string, teamID, userID string, createdAt time.Time) error {
+	i.muTeamMembershipStore.Lock()
+	defer i.muTeamMembershipStore.Unlock()
+	allocation := model.FullAllocation
+	var member bool
+	var previous *model.TeamMembership
+	for _, e := range i.teamMembershipStore {
+		if e.UserID != userID || e.DeletedAt != nil {
+			continue
+		}
+		switch {
+		case e.TeamID == teamID:
+			member = true
+		case previousTeamID != nil && e.TeamID == *previousTeamID:
+			previous = e
+			continue
+		}
+		allocation -= e.Allocation
+	}
+	if !member && allocation <= 0 {
+		return fmt.Errorf("%w: user %s", model.ErrOverAllocated, userID)
+	}
+	if previous != nil {
+		previous.DeletedAt = &createdAt
+		previous.UpdatedAt = &createdAt
+	}
+	if member {
+		return nil
+	}
+	i.teamMembershipStore = append(i.teamMembershipStore, &model.TeamMembership{
+		ID:         uuid.NewString(),
+		TeamID:     teamID,
+		UserID:     userID,
+		Role:       model.TeamRoleMember,
+		Allocation: allocation,
+		CreatedAt:  &createdAt,
+		Version:    1,
+	})
+	return nil
+}
+
+// DeleteUser marks user entry by the given id as deleted.
 func (i *InmemoryDB) DeleteUser(_ context.Context, id string) error {
 	i.muUserStore.Lock()
 	defer i.muUserStore.Unlock()
-	for x, user := range i.userStore {
-		if user.ID == id {
+	deletedAt := time.Now()
+	for _, user := range i.userStore {
+		if user.ID == id && user.DeletedAt == nil {
 			i.logger.Info("deleted user with id: ", id)
-			i.userStore = append(i.userStore[:x], i.userStore[x+1:]...)
+			user.DeletedAt = &deletedAt
+			user.UpdatedAt = &deletedAt
 			return nil
 		}
 	}
@@ -194,9 +332,16 @@ func (i *InmemoryDB) CreateTeam(ctx context.Context, team *model.Team) (*model.T
 	if !found {
 		return nil, fmt.Errorf("missing user with id: '%s'", team.OwnerID)
 	}
+	if err := model.ValidateTeamParent(i.teamStore, "", team.ParentID); err != nil {
+		return nil, err
+	}
+	if team.ParentID != nil && *team.ParentID == "" {
+		team.ParentID = nil
+	}
 	createdAt := time.Now()
 	team.CreatedAt = &createdAt
 	team.ID = uuid.NewString()
+	team.Version = 1
 	teamCopy := team.Copy()
 
 	i.logger.Info("create team with id: ", team.ID)
@@ -205,11 +350,12 @@ func (i *InmemoryDB) CreateTeam(ctx context.Context, team *model.Team) (*model.T
 }
 
 // GetTeamByID returns the associated team by the given id.
-func (i *InmemoryDB) GetTeamByID(_ context.Context, id string) (*model.Team, error) {
+func (i *InmemoryDB) GetTeamByID(_ context.Context, id string, opts ...query.Option) (*model.Team, error) {
 	i.muTeamStore.Lock()
 	defer i.muTeamStore.Unlock()
+	o := query.New(opts...)
 	for _, s := range i.teamStore {
-		if s.ID == id {
+		if s.ID == id && o.Visible(s.DeletedAt) {
 			i.logger.Info("get team with id: ", s.ID)
 			return s.Copy(), nil
 		}
@@ -219,59 +365,117 @@ func (i *InmemoryDB) GetTeamByID(_ context.Context, id string) (*model.Team, err
 }
 
 // ListTeams returns a copy of the internal team list.
-func (i *InmemoryDB) ListTeams(_ context.Context) ([]*model.Team, error) {
+func (i *InmemoryDB) ListTeams(_ context.Context, opts ...query.Option) ([]*model.Team, error) {
 	i.muTeamStore.Lock()
 	defer i.muTeamStore.Unlock()
 	i.logger.Info("get list of teams")
-	teamStore := make([]*model.Team, len(i.teamStore))
-	for j, t := range i.teamStore {
-		teamStore[j] = t.Copy()
+	o := query.New(opts...)
+	teamStore := make([]*model.Team, 0, len(i.teamStore))
+	for _, t := range i.teamStore {
+		if o.Visible(t.DeletedAt) {
+			teamStore = append(teamStore, t.Copy())
+		}
+	}
+	idx, err := o.Apply(len(teamStore), func(x int) interface{} { return teamStore[x] })
+	if err != nil {
+		return nil, err
+	}
+	page := make([]*model.Team, 0, len(idx))
+	for _, x := range idx {
+		page = append(page, teamStore[x])
 	}
-	return teamStore, nil
+	return page, nil
 }
 
 // ListTeamUsers returns a list of users associated by the given teamID
-func (i *InmemoryDB) ListTeamUsers(ctx context.Context, teamID string) ([]*model.User, error) {
-	i.muTeamStore.Lock()
-	defer i.muTeamStore.Unlock()
+// through their team memberships.
+func (i *InmemoryDB) ListTeamUsers(ctx context.Context, teamID string, opts ...query.Option) ([]*model.User, error) {
+	memberships, err := i.ListTeamMemberships(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	isMember := map[string]bool{}
+	for _, m := range memberships {
+		isMember[m.UserID] = true
+	}
 	var users []*model.User
-	allUsers, err := i.ListUsers(ctx)
+	allUsers, err := i.ListUsers(ctx, opts...)
 	if err != nil {
 		return nil, err
 	}
 	for _, u := range allUsers {
-		if u.TeamID != nil && *u.TeamID == teamID {
+		if isMember[u.ID] {
 			users = append(users, u.Copy())
 		}
 	}
 	return users, nil
 }
 
-// UpdateTeam updates team entry by the given team.
-func (i *InmemoryDB) UpdateTeam(_ context.Context, team *model.Team) (*model.Team, error) {
+// UpdateTeam updates team entry by the given team. Empty fields keep their
+// current value, an empty parentID moves the team to the top level.
+func (i *InmemoryDB) UpdateTeam(ctx context.Context, team *model.Team) (*model.Team, error) {
+	if team.OwnerID != "" {
+		if _, err := i.GetUserByID(ctx, team.OwnerID); err != nil {
+			return nil, err
+		}
+	}
 	i.muTeamStore.Lock()
 	defer i.muTeamStore.Unlock()
 	updatededAt := time.Now()
 	for x := 0; x < len(i.teamStore); x++ {
-		if i.teamStore[x].ID == team.ID {
-			i.teamStore[x].Name = team.Name
-			i.teamStore[x].UpdatedAt = &updatededAt
-			i.logger.Info("update team with id: ", team.ID)
-			return i.teamStore[x], nil
+		if i.teamStore[x].ID != team.ID || i.teamStore[x].DeletedAt != nil {
+			continue
+		}
+		if err := checkVersion(model.EntityTeam, team.ID, i.teamStore[x].Version, team.Version); err != nil {
+			return nil, err
 		}
+		updated := i.teamStore[x].Copy()
+		if team.OwnerID != "" {
+			updated.OwnerID = team.OwnerID
+		}
+		if team.ParentID != nil {
+			if err := model.ValidateTeamParent(i.teamStore, team.ID, team.ParentID); err != nil {
+				return nil, err
+			}
+			// NOTE: an empty parentID moves the team to the top level.
+			updated.ParentID = nil
+			if *team.ParentID != "" {
+				parentID := *team.ParentID
+				updated.ParentID = &parentID
+			}
+		}
+		if team.Name != "" {
+			updated.Name = team.Name
+		}
+		if team.HolidayCalendar != nil {
+			holidayCalendar := *team.HolidayCalendar
+			updated.HolidayCalendar = &holidayCalendar
+		}
+		updated.UpdatedAt = &updatededAt
+		updated.Version++
+		i.teamStore[x] = updated
+		i.logger.Info("update team with id: ", team.ID)
+		return updated.Copy(), nil
 	}
 	i.logger.Info("update failed: no team found")
 	return nil, errors.New("update failed: no team found")
 }
 
-// DeleteTeam removes team entry by the given id.
+// DeleteTeam marks team entry by the given id as deleted.
 func (i *InmemoryDB) DeleteTeam(_ context.Context, id string) error {
 	i.muTeamStore.Lock()
 	defer i.muTeamStore.Unlock()
-	for x, team := range i.teamStore {
-		if team.ID == id {
+	for _, team := range i.teamStore {
+		if team.ParentID != nil && *team.ParentID == id && team.DeletedAt == nil {
+			return fmt.Errorf("%w: %s", model.ErrTeamHasSubTeams, id)
+		}
+	}
+	deletedAt := time.Now()
+	for _, team := range i.teamStore {
+		if team.ID == id && team.DeletedAt == nil {
 			i.logger.Info("delete team with id: ", id)
-			i.teamStore = append(i.teamStore[:x], i.teamStore[x+1:]...)
+			team.DeletedAt = &deletedAt
+			team.UpdatedAt = &deletedAt
 			return nil
 		}
 	}
@@ -284,7 +488,12 @@ func (i *InmemoryDB) DeleteTeam(_ context.Context, id string) error {
 func (i *InmemoryDB) CreateVacation(ctx context.Context, v *model.Vacation) (*model.Vacation, error) {
 	i.muVacationStore.Lock()
 	defer i.muVacationStore.Unlock()
+	return i.createVacation(v)
+}
 
+// createVacation stores an internal copy of the given vacation. The caller
+// must hold muVacationStore.
+func (i *InmemoryDB) createVacation(v *model.Vacation) (*model.Vacation, error) {
 	if v.UserID == "" {
 		return nil, fmt.Errorf("missing userID")
 	}
@@ -293,6 +502,10 @@ func (i *InmemoryDB) CreateVacation(ctx context.Context, v *model.Vacation) (*mo
 		return nil, fmt.Errorf("missing approverID")
 	}
 
+	if err := v.Validate(); err != nil {
+		return nil, err
+	}
+
 	createdAt := time.Now()
 	v.CreatedAt = &createdAt
 	v.ID = uuid.NewString()
@@ -304,11 +517,12 @@ func (i *InmemoryDB) CreateVacation(ctx context.Context, v *model.Vacation) (*mo
 }
 
 // GetVacationByID returns the associated vacation by the given id.
-func (i *InmemoryDB) GetVacationByID(_ context.Context, id string) (*model.Vacation, error) {
+func (i *InmemoryDB) GetVacationByID(_ context.Context, id string, opts ...query.Option) (*model.Vacation, error) {
 	i.muVacationStore.Lock()
 	defer i.muVacationStore.Unlock()
+	o := query.New(opts...)
 	for _, s := range i.vacationStore {
-		if s.ID == id {
+		if s.ID == id && o.Visible(s.DeletedAt) {
 			i.logger.Info("get vacation with id: ", id)
 			return s.Copy(), nil
 		}
@@ -318,57 +532,53 @@ func (i *InmemoryDB) GetVacationByID(_ context.Context, id string) (*model.Vacat
 }
 
 // GetVacationsByUserID returns list of vacations of one user by given userID.
-func (i *InmemoryDB) GetVacationsByUserID(_ context.Context, id string) ([]*model.Vacation, error) {
-	i.muVacationStore.Lock()
-	defer i.muVacationStore.Unlock()
-	result := []*model.Vacation{}
-	for _, v := range i.vacationStore {
-		if id != v.UserID {
-			continue
-		}
-		result = append(result, v)
-	}
-	return result, nil
+func (i *InmemoryDB) GetVacationsByUserID(ctx context.Context, id string, opts ...query.Option) ([]*model.Vacation, error) {
+	return i.ListVacations(ctx, query.With(opts, query.UserID(id))...)
 }
 
-// GetVacationByTeamID returns the list of vacations of one team by given teamID.
-func (i *InmemoryDB) GetVacationsByTeamID(ctx context.Context, tID string) ([]*model.Vacation, error) {
-	// NOTE: mutex not needed, since no direct access on table
-	users, err := i.ListTeamUsers(ctx, tID)
-	if err != nil {
-		return nil, err
-	}
-	result := []*model.Vacation{}
-	for _, u := range users {
-		vacs, err := i.GetVacationsByUserID(ctx, u.ID)
-		if err != nil {
-			return nil, err
-		}
-		result = append(result, vacs...)
-	}
-	return result, nil
+// GetVacationsByTeamID returns the list of vacations of the members of one
+// team by given teamID.
+func (i *InmemoryDB) GetVacationsByTeamID(ctx context.Context, tID string, opts ...query.Option) ([]*model.Vacation, error) {
+	return i.ListVacations(ctx, query.With(opts, query.TeamID(tID))...)
 }
 
 // ListVacations returns a copy of the internal vacation list.
-func (i *InmemoryDB) ListVacations(_ context.Context) ([]*model.Vacation, error) {
+func (i *InmemoryDB) ListVacations(ctx context.Context, opts ...query.Option) ([]*model.Vacation, error) {
+	o := query.New(opts...)
+	members, err := i.teamMembers(ctx, o.Filter)
+	if err != nil {
+		return nil, err
+	}
 	i.muVacationStore.Lock()
 	defer i.muVacationStore.Unlock()
 	i.logger.Info("get list of vacations")
-	vacationStore := make([]*model.Vacation, len(i.vacationStore))
-	for j, v := range i.vacationStore {
-		vacationStore[j] = v.Copy()
+	vacationStore := make([]*model.Vacation, 0, len(i.vacationStore))
+	for _, v := range i.vacationStore {
+		if o.Visible(v.DeletedAt) && o.Filter.MatchUser(v.UserID) && isMember(members, v.UserID) &&
+			o.Filter.MatchPeriod(v.From, v.To) {
+			vacationStore = append(vacationStore, v.Copy())
+		}
+	}
+	idx, err := o.Apply(len(vacationStore), func(x int) interface{} { return vacationStore[x] })
+	if err != nil {
+		return nil, err
+	}
+	page := make([]*model.Vacation, 0, len(idx))
+	for _, x := range idx {
+		page = append(page, vacationStore[x])
 	}
-	return vacationStore, nil
+	return page, nil
 }
 
-// DeleteVacation removes vacation entry by the given id.
+// DeleteVacation marks vacation entry by the given id as deleted.
 func (i *InmemoryDB) DeleteVacation(_ context.Context, id string) error {
 	i.muVacationStore.Lock()
 	defer i.muVacationStore.Unlock()
-	for x, vacation := range i.vacationStore {
-		if vacation.ID == id {
+	deletedAt := time.Now()
+	for _, vacation := range i.vacationStore {
+		if vacation.ID == id && vacation.DeletedAt == nil {
 			i.logger.Info("delete vacation with id: ", vacation.ID)
-			i.vacationStore = append(i.vacationStore[:x], i.vacationStore[x+1:]...)
+			vacation.DeletedAt = &deletedAt
 			return nil
 		}
 	}
@@ -377,6 +587,9 @@ func (i *InmemoryDB) DeleteVacation(_ context.Context, id string) error {
 }
 
 // CreateVacationRequest stores an internal copy of the given vacationRequest.
+// A request starts either as draft or pending, if no status is given pending
+// is assumed. The request must not overlap with blocking requests or vacations
+// of the same user.
 // Returns copy with assigned vacationRequestID.
 func (i *InmemoryDB) CreateVacationRequest(_ context.Context, v *model.VacationRequest) (*model.VacationRequest, error) {
 	i.muVacationRequestStore.Lock()
@@ -384,9 +597,16 @@ func (i *InmemoryDB) CreateVacationRequest(_ context.Context, v *model.VacationR
 	if v.UserID == "" {
 		return nil, fmt.Errorf("missing userID")
 	}
+	if err := v.Validate(); err != nil {
+		return nil, err
+	}
+	if err := i.checkAbsenceOverlap(v); err != nil {
+		return nil, err
+	}
 	createdAt := time.Now()
 	v.CreatedAt = &createdAt
 	v.ID = uuid.NewString()
+	v.Version = 1
 	vCopy := v.Copy()
 
 	i.logger.Info("create vacation-request with id: ", v.ID)
@@ -395,11 +615,12 @@ func (i *InmemoryDB) CreateVacationRequest(_ context.Context, v *model.VacationR
 }
 
 // GetVacationRequestByID returns the associated vacationRequest by the given id.
-func (i *InmemoryDB) GetVacationRequestByID(_ context.Context, id string) (*model.VacationRequest, error) {
+func (i *InmemoryDB) GetVacationRequestByID(_ context.Context, id string, opts ...query.Option) (*model.VacationRequest, error) {
 	i.muVacationRequestStore.Lock()
 	defer i.muVacationRequestStore.Unlock()
+	o := query.New(opts...)
 	for _, s := range i.vacationRequestStore {
-		if s.ID == id {
+		if s.ID == id && o.Visible(s.DeletedAt) {
 			i.logger.Info("get vacation-request with id: ", id)
 			return s.Copy(), nil
 		}
@@ -409,31 +630,174 @@ func (i *InmemoryDB) GetVacationRequestByID(_ context.Context, id string) (*mode
 }
 
 // ListVacationRequests returns a copy of the internal vacationRequest list.
-func (i *InmemoryDB) ListVacationRequests(_ context.Context) ([]*model.VacationRequest, error) {
+func (i *InmemoryDB) ListVacationRequests(ctx context.Context, opts ...query.Option) ([]*model.VacationRequest, error) {
+	o := query.New(opts...)
+	members, err := i.teamMembers(ctx, o.Filter)
+	if err != nil {
+		return nil, err
+	}
 	i.muVacationRequestStore.Lock()
 	defer i.muVacationRequestStore.Unlock()
 	i.logger.Info("get list of vacation-requests")
-	vacationRequestStore := make([]*model.VacationRequest, len(i.vacationRequestStore))
-	for j, v := range i.vacationRequestStore {
-		vacationRequestStore[j] = v.Copy()
+	vacationRequestStore := make([]*model.VacationRequest, 0, len(i.vacationRequestStore))
+	for _, v := range i.vacationRequestStore {
+		if o.Visible(v.DeletedAt) && o.Filter.MatchUser(v.UserID) && isMember(members, v.UserID) &&
+			o.Filter.MatchDeputy(v.DeputyID) && o.Filter.MatchStatus(v.Status) && o.Filter.MatchPeriod(v.From, v.To) {
+			vacationRequestStore = append(vacationRequestStore, v.Copy())
+		}
+	}
+	idx, err := o.Apply(len(vacationRequestStore), func(x int) interface{} { return vacationRequestStore[x] })
+	if err != nil {
+		return nil, err
 	}
-	return vacationRequestStore, nil
+	page := make([]*model.VacationRequest, 0, len(idx))
+	for _, x := range idx {
+		page = append(page, vacationRequestStore[x])
+	}
+	return page, nil
+}
+
+// GetVacationRequestsByUserID returns list of vacationRequests of one user by
+// given userID.
+func (i *InmemoryDB) GetVacationRequestsByUserID(ctx context.Context, id string, opts ...query.Option) ([]*model.VacationRequest, error) {
+	return i.ListVacationRequests(ctx, query.With(opts, query.UserID(id))...)
+}
+
+// GetVacationRequestsByTeamID returns list of vacationRequests of the members
+// of one team by given teamID.
+func (i *InmemoryDB) GetVacationRequestsByTeamID(ctx context.Context, tID string, opts ...query.Option) ([]*model.VacationRequest, error) {
+	return i.ListVacationRequests(ctx, query.With(opts, query.TeamID(tID))...)
 }
 
 // UpdateVacationRequest updates vacationRequest entry by the given vacationRequest.
+// Status changes must follow the vacation-request lifecycle, the period can
+// only be changed as long as the request is a draft or pending. Drafts and
+// pending requests must not overlap with blocking requests or vacations of the
+// same user.
 func (i *InmemoryDB) UpdateVacationRequest(_ context.Context, v *model.VacationRequest) (*model.VacationRequest, error) {
-	i.logger.Error("update failed: no update on vacation-request possible")
-	return nil, errors.New("update failed: no update on vacation-request possible")
+	i.muVacationRequestStore.Lock()
+	defer i.muVacationRequestStore.Unlock()
+	updatedAt := time.Now()
+	for x := 0; x < len(i.vacationRequestStore); x++ {
+		if i.vacationRequestStore[x].ID != v.ID || i.vacationRequestStore[x].DeletedAt != nil {
+			continue
+		}
+		if err := checkVersion(model.EntityVacationRequest, v.ID, i.vacationRequestStore[x].Version, v.Version); err != nil {
+			return nil, err
+		}
+		updated := i.vacationRequestStore[x].Copy()
+		if err := updated.Update(v); err != nil {
+			return nil, err
+		}
+		if updated.Status.Editable() {
+			if err := i.checkAbsenceOverlap(updated); err != nil {
+				return nil, err
+			}
+		}
+		updated.UpdatedAt = &updatedAt
+		updated.Version++
+		i.vacationRequestStore[x] = updated
+		i.logger.Info("update vacation-request with id: ", v.ID)
+		return updated.Copy(), nil
+	}
+	i.logger.Error("update failed: no vacation-request found")
+	return nil, errors.New("update failed: no vacation-request found")
+}
+
+// ApproveVacationRequest moves the vacationRequest by the given id to approved
+// and stores the given vacation, which is linked to the request. Either both
+// succeed or nothing is changed.
+func (i *InmemoryDB) ApproveVacationRequest(_ context.Context, id string, v *model.Vacation) (*model.VacationRequest, *model.Vacation, error) {
+	i.muVacationRequestStore.Lock()
+	defer i.muVacationRequestStore.Unlock()
+	for x := 0; x < len(i.vacationRequestStore); x++ {
+		if i.vacationRequestStore[x].ID != id || i.vacationRequestStore[x].DeletedAt != nil {
+			continue
+		}
+		updated := i.vacationRequestStore[x].Copy()
+		if err := updated.Approve(); err != nil {
+			return nil, nil, err
+		}
+		i.muVacationStore.Lock()
+		vac, err := i.createVacation(v)
+		i.muVacationStore.Unlock()
+		if err != nil {
+			return nil, nil, err
+		}
+		updatedAt := time.Now()
+		vacationID := vac.ID
+		updated.VacationID = &vacationID
+		updated.UpdatedAt = &updatedAt
+		updated.Version++
+		i.vacationRequestStore[x] = updated
+		i.logger.Info("approve vacation-request with id: ", id)
+		return updated.Copy(), vac, nil
+	}
+	i.logger.Error("approve failed: no vacation-request found")
+	return nil, nil, errors.New("approve failed: no vacation-request found")
+}
+
+// CancelVacationRequest moves the vacationRequest by the given id to cancelled
+// and marks the linked vacation as deleted. Either both succeed or nothing is
+// changed, an already deleted vacation is skipped.
+func (i *InmemoryDB) CancelVacationRequest(_ context.Context, id string) (*model.VacationRequest, error) {
+	i.muVacationRequestStore.Lock()
+	defer i.muVacationRequestStore.Unlock()
+	for x := 0; x < len(i.vacationRequestStore); x++ {
+		if i.vacationRequestStore[x].ID != id || i.vacationRequestStore[x].DeletedAt != nil {
+			continue
+		}
+		updated := i.vacationRequestStore[x].Copy()
+		if err := updated.Cancel(); err != nil {
+			return nil, err
+		}
+		updatedAt := time.Now()
+		if updated.VacationID != nil {
+			i.muVacationStore.Lock()
+			for _, vacation := range i.vacationStore {
+				if vacation.ID == *updated.VacationID && vacation.DeletedAt == nil {
+					vacation.DeletedAt = &updatedAt
+				}
+			}
+			i.muVacationStore.Unlock()
+		}
+		updated.UpdatedAt = &updatedAt
+		updated.Version++
+		i.vacationRequestStore[x] = updated
+		i.logger.Info("cancel vacation-request with id: ", id)
+		return updated.Copy(), nil
+	}
+	i.logger.Error("cancel failed: no vacation-request found")
+	return nil, errors.New("cancel failed: no vacation-request found")
 }
 
-// DeleteVacationRequest removes vacationRequest entry by the given id.
+// checkAbsenceOverlap returns a *model.OverlapError, if v overlaps with any
+// blocking request or vacation of the same user. The caller must hold
+// muVacationRequestStore.
+func (i *InmemoryDB) checkAbsenceOverlap(v *model.VacationRequest) error {
+	i.muVacationStore.Lock()
+	defer i.muVacationStore.Unlock()
+	requests := make([]*model.VacationRequest, len(i.vacationRequestStore))
+	for j, r := range i.vacationRequestStore {
+		requests[j] = r.Copy()
+	}
+	vacations := make([]*model.Vacation, len(i.vacationStore))
+	for j, vac := range i.vacationStore {
+		vacations[j] = vac.Copy()
+	}
+	return v.CheckOverlaps(requests, vacations)
+}
+
+// DeleteVacationRequest marks vacationRequest entry by the given id as deleted.
 func (i *InmemoryDB) DeleteVacationRequest(_ context.Context, id string) error {
 	i.muVacationRequestStore.Lock()
 	defer i.muVacationRequestStore.Unlock()
-	for x, vacationRequest := range i.vacationRequestStore {
-		if vacationRequest.ID == id {
+	deletedAt := time.Now()
+	for _, vacationRequest := range i.vacationRequestStore {
+		if vacationRequest.ID == id && vacationRequest.DeletedAt == nil {
 			i.logger.Info("delete vacation-request with id: ", vacationRequest.ID)
-			i.vacationRequestStore = append(i.vacationRequestStore[:x], i.vacationRequestStore[x+1:]...)
+			vacationRequest.DeletedAt = &deletedAt
+			vacationRequest.UpdatedAt = &deletedAt
 			return nil
 		}
 	}
@@ -449,9 +813,13 @@ func (i *InmemoryDB) CreateVacationResource(_ context.Context, v *model.Vacation
 	if v.UserID == "" {
 		return nil, fmt.Errorf("missing userID")
 	}
+	if err := i.checkResourceOverlap(v); err != nil {
+		return nil, err
+	}
 	createdAt := time.Now()
 	v.CreatedAt = &createdAt
 	v.ID = uuid.NewString()
+	v.Version = 1
 	vCopy := v.Copy()
 
 	i.logger.Info("create vacation-resource with id: ", v.ID)
@@ -460,11 +828,12 @@ func (i *InmemoryDB) CreateVacationResource(_ context.Context, v *model.Vacation
 }
 
 // GetVacationResourceByID returns the associated vacationResource by the given id.
-func (i *InmemoryDB) GetVacationResourceByID(_ context.Context, id string) (*model.VacationResource, error) {
+func (i *InmemoryDB) GetVacationResourceByID(_ context.Context, id string, opts ...query.Option) (*model.VacationResource, error) {
 	i.muVacationResourceStore.Lock()
 	defer i.muVacationResourceStore.Unlock()
+	o := query.New(opts...)
 	for _, s := range i.vacationResourceStore {
-		if s.ID == id {
+		if s.ID == id && o.Visible(s.DeletedAt) {
 			i.logger.Info("get vacation-resource with id: ", id)
 			return s.Copy(), nil
 		}
@@ -474,34 +843,1024 @@ func (i *InmemoryDB) GetVacationResourceByID(_ context.Context, id string) (*mod
 }
 
 // ListVacationResource returns a copy of the internal vacationResource list.
-func (i *InmemoryDB) ListVacationResource(_ context.Context) ([]*model.VacationResource, error) {
+func (i *InmemoryDB) ListVacationResource(ctx context.Context, opts ...query.Option) ([]*model.VacationResource, error) {
+	o := query.New(opts...)
+	members, err := i.teamMembers(ctx, o.Filter)
+	if err != nil {
+		return nil, err
+	}
 	i.muVacationResourceStore.Lock()
 	defer i.muVacationResourceStore.Unlock()
 	i.logger.Info("get list of vacation-resource")
-	vacationResourceStore := make([]*model.VacationResource, len(i.vacationResourceStore))
-	for j, v := range i.vacationResourceStore {
-		vacationResourceStore[j] = v.Copy()
+	vacationResourceStore := make([]*model.VacationResource, 0, len(i.vacationResourceStore))
+	for _, v := range i.vacationResourceStore {
+		if o.Visible(v.DeletedAt) && o.Filter.MatchUser(v.UserID) && isMember(members, v.UserID) &&
+			o.Filter.MatchPeriod(v.From, v.To) {
+			vacationResourceStore = append(vacationResourceStore, v.Copy())
+		}
 	}
-	return vacationResourceStore, nil
+	idx, err := o.Apply(len(vacationResourceStore), func(x int) interface{} { return vacationResourceStore[x] })
+	if err != nil {
+		return nil, err
+	}
+	page := make([]*model.VacationResource, 0, len(idx))
+	for _, x := range idx {
+		page = append(page, vacationResourceStore[x])
+	}
+	return page, nil
+}
+
+// GetVacationResourcesByUserID returns list of vacationResources of one user by
+// given userID.
+func (i *InmemoryDB) GetVacationResourcesByUserID(ctx context.Context, id string, opts ...query.Option) ([]*model.VacationResource, error) {
+	return i.ListVacationResource(ctx, query.With(opts, query.UserID(id))...)
+}
+
+// GetVacationResourcesByTeamID returns list of vacationResources of the
+// members of one team by given teamID.
+func (i *InmemoryDB) GetVacationResourcesByTeamID(ctx context.Context, tID string, opts ...query.Option) ([]*model.VacationResource, error) {
+	return i.ListVacationResource(ctx, query.With(opts, query.TeamID(tID))...)
 }
 
 // UpdateVacationResource updates vacationResource entry by the given vacationResource.
+// Resources of the same user must not overlap.
 func (i *InmemoryDB) UpdateVacationResource(_ context.Context, v *model.VacationResource) (*model.VacationResource, error) {
-	i.logger.Error("update failed: no update on vacation-resource possible")
-	return nil, errors.New("update failed: no update on vacation-resource possible")
+	i.muVacationResourceStore.Lock()
+	defer i.muVacationResourceStore.Unlock()
+	updatedAt := time.Now()
+	for x := 0; x < len(i.vacationResourceStore); x++ {
+		if i.vacationResourceStore[x].ID != v.ID || i.vacationResourceStore[x].DeletedAt != nil {
+			continue
+		}
+		if err := checkVersion(model.EntityVacationResource, v.ID, i.vacationResourceStore[x].Version, v.Version); err != nil {
+			return nil, err
+		}
+		updated := i.vacationResourceStore[x].Copy()
+		updated.YearlyDays = v.YearlyDays
+		updated.From = v.From
+		updated.To = v.To
+		updated.CarriedDays = v.CarriedDays
+		if err := i.checkResourceOverlap(updated); err != nil {
+			return nil, err
+		}
+		updated.UpdatedAt = &updatedAt
+		updated.Version++
+		i.vacationResourceStore[x] = updated
+		i.logger.Info("update vacation-resource with id: ", v.ID)
+		return updated.Copy(), nil
+	}
+	i.logger.Error("update failed: no vacation-resource found")
+	return nil, errors.New("update failed: no vacation-resource found")
 }
 
-// DeleteVacationResource removes vacationResource entry by the given id.
+// checkResourceOverlap returns model.ErrOverlappingResource, if v overlaps
+// with any stored resource. The caller must hold muVacationResourceStore.
+func (i *InmemoryDB) checkResourceOverlap(v *model.VacationResource) error {
+	for _, r := range i.vacationResourceStore {
+		if r.DeletedAt == nil && v.Overlaps(r) {
+			return fmt.Errorf("%w: %s", model.ErrOverlappingResource, r.ID)
+		}
+	}
+	return nil
+}
+
+// DeleteVacationResource marks vacationResource entry by the given id as deleted.
 func (i *InmemoryDB) DeleteVacationResource(_ context.Context, id string) error {
 	i.muVacationResourceStore.Lock()
 	defer i.muVacationResourceStore.Unlock()
-	for x, vacationResource := range i.vacationResourceStore {
-		if vacationResource.ID == id {
+	deletedAt := time.Now()
+	for _, vacationResource := range i.vacationResourceStore {
+		if vacationResource.ID == id && vacationResource.DeletedAt == nil {
 			i.logger.Info("delete vacation-resource with id: ", vacationResource.ID)
-			i.vacationResourceStore = append(i.vacationResourceStore[:x], i.vacationResourceStore[x+1:]...)
+			vacationResource.DeletedAt = &deletedAt
+			vacationResource.UpdatedAt = &deletedAt
 			return nil
 		}
 	}
 	i.logger.Error("vacation-resource didn't exist")
 	return errors.New("vacation-resource didn't exist")
 }
+
+// CreateAbsenceType stores an internal copy of the given absenceType.
+// Returns copy with assigned absenceTypeID.
+func (i *InmemoryDB) CreateAbsenceType(_ context.Context, a *model.AbsenceType) (*model.AbsenceType, error) {
+	i.muAbsenceTypeStore.Lock()
+	defer i.muAbsenceTypeStore.Unlock()
+
+	if a.Name == "" {
+		return nil, fmt.Errorf("missing name")
+	}
+
+	createdAt := time.Now()
+	a.CreatedAt = &createdAt
+	a.ID = uuid.NewString()
+	a.Version = 1
+
+	i.logger.Info("create absence-type with id: ", a.ID)
+	i.absenceTypeStore = append(i.absenceTypeStore, a.Copy())
+	return a, nil
+}
+
+// GetAbsenceTypeByID returns the associated absenceType by the given id.
+func (i *InmemoryDB) GetAbsenceTypeByID(_ context.Context, id string, opts ...query.Option) (*model.AbsenceType, error) {
+	i.muAbsenceTypeStore.Lock()
+	defer i.muAbsenceTypeStore.Unlock()
+	o := query.New(opts...)
+	for _, a := range i.absenceTypeStore {
+		if a.ID == id && o.Visible(a.DeletedAt) {
+			i.logger.Info("get absence-type with id: ", a.ID)
+			return a.Copy(), nil
+		}
+	}
+	i.logger.Error("no absence-type found")
+	return nil, errors.New("no absence-type found")
+}
+
+// ListAbsenceTypes returns a copy of the internal absenceType list.
+func (i *InmemoryDB) ListAbsenceTypes(_ context.Context, opts ...query.Option) ([]*model.AbsenceType, error) {
+	i.muAbsenceTypeStore.Lock()
+	defer i.muAbsenceTypeStore.Unlock()
+	i.logger.Info("get list of absence-types")
+	o := query.New(opts...)
+	absenceTypeStore := make([]*model.AbsenceType, 0, len(i.absenceTypeStore))
+	for _, a := range i.absenceTypeStore {
+		if o.Visible(a.DeletedAt) {
+			absenceTypeStore = append(absenceTypeStore, a.Copy())
+		}
+	}
+	return absenceTypeStore, nil
+}
+
+// UpdateAbsenceType updates absenceType entry by the given absenceType.
+func (i *InmemoryDB) UpdateAbsenceType(_ context.Context, a *model.AbsenceType) (*model.AbsenceType, error) {
+	i.muAbsenceTypeStore.Lock()
+	defer i.muAbsenceTypeStore.Unlock()
+
+	if a.Name == "" {
+		return nil, fmt.Errorf("missing name")
+	}
+
+	updatedAt := time.Now()
+	for x := 0; x < len(i.absenceTypeStore); x++ {
+		if i.absenceTypeStore[x].ID == a.ID && i.absenceTypeStore[x].DeletedAt == nil {
+			if err := checkVersion(model.EntityAbsenceType, a.ID, i.absenceTypeStore[x].Version, a.Version); err != nil {
+				return nil, err
+			}
+			i.absenceTypeStore[x].Name = a.Name
+			i.absenceTypeStore[x].RequiresApproval = a.RequiresApproval
+			i.absenceTypeStore[x].DeductsVacation = a.DeductsVacation
+			i.absenceTypeStore[x].VisibleToTeam = a.VisibleToTeam
+			i.absenceTypeStore[x].UpdatedAt = &updatedAt
+			i.absenceTypeStore[x].Version++
+			i.logger.Info("update absence-type with id: ", a.ID)
+			return i.absenceTypeStore[x].Copy(), nil
+		}
+	}
+	i.logger.Error("update failed: no absence-type found")
+	return nil, errors.New("update failed: no absence-type found")
+}
+
+// DeleteAbsenceType marks absenceType entry by the given id as deleted.
+func (i *InmemoryDB) DeleteAbsenceType(_ context.Context, id string) error {
+	i.muAbsenceTypeStore.Lock()
+	defer i.muAbsenceTypeStore.Unlock()
+	deletedAt := time.Now()
+	for _, a := range i.absenceTypeStore {
+		if a.ID == id && a.DeletedAt == nil {
+			i.logger.Info("delete absence-type with id: ", id)
+			a.DeletedAt = &deletedAt
+			a.UpdatedAt = &deletedAt
+			return nil
+		}
+	}
+	i.logger.Error("absence-type didn't exist")
+	return errors.New("absence-type didn't exist")
+}
+
+// CreateDelegation stores an internal copy of the given delegation.
+// Returns copy with assigned delegationID.
+func (i *InmemoryDB) CreateDelegation(_ context.Context, d *model.Delegation) (*model.Delegation, error) {
+	i.muDelegationStore.Lock()
+	defer i.muDelegationStore.Unlock()
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	createdAt := time.Now()
+	d.CreatedAt = &createdAt
+	d.ID = uuid.NewString()
+
+	i.logger.Info("create delegation with id: ", d.ID)
+	i.delegationStore = append(i.delegationStore, d.Copy())
+	return d, nil
+}
+
+// GetDelegationByID returns the associated delegation by the given id.
+func (i *InmemoryDB) GetDelegationByID(_ context.Context, id string, opts ...query.Option) (*model.Delegation, error) {
+	i.muDelegationStore.Lock()
+	defer i.muDelegationStore.Unlock()
+	o := query.New(opts...)
+	for _, d := range i.delegationStore {
+		if d.ID == id && o.Visible(d.DeletedAt) {
+			i.logger.Info("get delegation with id: ", d.ID)
+			return d.Copy(), nil
+		}
+	}
+	i.logger.Error("no delegation found")
+	return nil, errors.New("no delegation found")
+}
+
+// ListDelegations returns a copy of the internal delegation list.
+func (i *InmemoryDB) ListDelegations(_ context.Context, opts ...query.Option) ([]*model.Delegation, error) {
+	i.muDelegationStore.Lock()
+	defer i.muDelegationStore.Unlock()
+	i.logger.Info("get list of delegations")
+	o := query.New(opts...)
+	delegationStore := make([]*model.Delegation, 0, len(i.delegationStore))
+	for _, d := range i.delegationStore {
+		if o.Visible(d.DeletedAt) {
+			delegationStore = append(delegationStore, d.Copy())
+		}
+	}
+	return delegationStore, nil
+}
+
+// DeleteDelegation marks delegation entry by the given id as deleted.
+func (i *InmemoryDB) DeleteDelegation(_ context.Context, id string) error {
+	i.muDelegationStore.Lock()
+	defer i.muDelegationStore.Unlock()
+	deletedAt := time.Now()
+	for _, d := range i.delegationStore {
+		if d.ID == id && d.DeletedAt == nil {
+			i.logger.Info("delete delegation with id: ", id)
+			d.DeletedAt = &deletedAt
+			d.UpdatedAt = &deletedAt
+			return nil
+		}
+	}
+	i.logger.Error("delegation didn't exist")
+	return errors.New("delegation didn't exist")
+}
+
+// CreateTeamRule stores an internal copy of the given teamRule.
+// Returns copy with assigned teamRuleID.
+func (i *InmemoryDB) CreateTeamRule(_ context.Context, t *model.TeamRule) (*model.TeamRule, error) {
+	i.muTeamRuleStore.Lock()
+	defer i.muTeamRuleStore.Unlock()
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	createdAt := time.Now()
+	t.CreatedAt = &createdAt
+	t.ID = uuid.NewString()
+	t.Version = 1
+
+	i.logger.Info("create team-rule with id: ", t.ID)
+	i.teamRuleStore = append(i.teamRuleStore, t.Copy())
+	return t, nil
+}
+
+// GetTeamRuleByID returns the associated teamRule by the given id.
+func (i *InmemoryDB) GetTeamRuleByID(_ context.Context, id string, opts ...query.Option) (*model.TeamRule, error) {
+	i.muTeamRuleStore.Lock()
+	defer i.muTeamRuleStore.Unlock()
+	o := query.New(opts...)
+	for _, t := range i.teamRuleStore {
+		if t.ID == id && o.Visible(t.DeletedAt) {
+			i.logger.Info("get team-rule with id: ", t.ID)
+			return t.Copy(), nil
+		}
+	}
+	i.logger.Error("no team-rule found")
+	return nil, errors.New("no team-rule found")
+}
+
+// ListTeamRules returns a copy of the internal teamRule list of the given
+// teamID.
+func (i *InmemoryDB) ListTeamRules(_ context.Context, teamID string, opts ...query.Option) ([]*model.TeamRule, error) {
+	i.muTeamRuleStore.Lock()
+	defer i.muTeamRuleStore.Unlock()
+	i.logger.Info("get list of team-rules")
+	teamRules := make([]*model.TeamRule, 0)
+	o := query.New(opts...)
+	for _, t := range i.teamRuleStore {
+		if t.TeamID == teamID && o.Visible(t.DeletedAt) {
+			teamRules = append(teamRules, t.Copy())
+		}
+	}
+	return teamRules, nil
+}
+
+// UpdateTeamRule updates teamRule entry by the given teamRule. The team of a
+// rule can not be changed.
+func (i *InmemoryDB) UpdateTeamRule(_ context.Context, t *model.TeamRule) (*model.TeamRule, error) {
+	i.muTeamRuleStore.Lock()
+	defer i.muTeamRuleStore.Unlock()
+	updatedAt := time.Now()
+	for x := 0; x < len(i.teamRuleStore); x++ {
+		if i.teamRuleStore[x].ID != t.ID || i.teamRuleStore[x].DeletedAt != nil {
+			continue
+		}
+		if err := checkVersion(model.EntityTeamRule, t.ID, i.teamRuleStore[x].Version, t.Version); err != nil {
+			return nil, err
+		}
+		updated := t.Copy()
+		updated.TeamID = i.teamRuleStore[x].TeamID
+		updated.CreatedAt = i.teamRuleStore[x].CreatedAt
+		updated.DeletedAt = i.teamRuleStore[x].DeletedAt
+		if err := updated.Validate(); err != nil {
+			return nil, err
+		}
+		updated.UpdatedAt = &updatedAt
+		updated.Version = i.teamRuleStore[x].Version + 1
+		i.teamRuleStore[x] = updated
+		i.logger.Info("update team-rule with id: ", t.ID)
+		return updated.Copy(), nil
+	}
+	i.logger.Error("update failed: no team-rule found")
+	return nil, errors.New("update failed: no team-rule found")
+}
+
+// DeleteTeamRule marks teamRule entry by the given id as deleted.
+func (i *InmemoryDB) DeleteTeamRule(_ context.Context, id string) error {
+	i.muTeamRuleStore.Lock()
+	defer i.muTeamRuleStore.Unlock()
+	deletedAt := time.Now()
+	for _, t := range i.teamRuleStore {
+		if t.ID == id && t.DeletedAt == nil {
+			i.logger.Info("delete team-rule with id: ", id)
+			t.DeletedAt = &deletedAt
+			t.UpdatedAt = &deletedAt
+			return nil
+		}
+	}
+	i.logger.Error("team-rule didn't exist")
+	return errors.New("team-rule didn't exist")
+}
+
+// CreateComment stores an internal copy of the given comment, if the
+// associated vacationRequest exists.
+// Returns copy with assigned commentID.
+func (i *InmemoryDB) CreateComment(ctx context.Context, c *model.Comment) (*model.Comment, error) {
+	i.muCommentStore.Lock()
+	defer i.muCommentStore.Unlock()
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	_, err := i.GetVacationRequestByID(ctx, c.VacationRequestID)
+	if err != nil {
+		return nil, err
+	}
+	createdAt := time.Now()
+	c.CreatedAt = &createdAt
+	c.ID = uuid.NewString()
+
+	i.logger.Info("create comment with id: ", c.ID)
+	i.commentStore = append(i.commentStore, c.Copy())
+	return c, nil
+}
+
+// ListComments returns a copy of the internal comment list of the given
+// vacationRequestID, ordered by creation.
+func (i *InmemoryDB) ListComments(_ context.Context, vacationRequestID string, opts ...query.Option) ([]*model.Comment, error) {
+	i.muCommentStore.Lock()
+	defer i.muCommentStore.Unlock()
+	i.logger.Info("get list of comments")
+	comments := make([]*model.Comment, 0)
+	o := query.New(opts...)
+	for _, c := range i.commentStore {
+		if c.VacationRequestID == vacationRequestID && o.Visible(c.DeletedAt) {
+			comments = append(comments, c.Copy())
+		}
+	}
+	return comments, nil
+}
+
+// CreateAttachment stores an internal copy of the given attachment.
+// Returns copy with assigned attachmentID.
+func (i *InmemoryDB) CreateAttachment(_ context.Context, a *model.Attachment) (*model.Attachment, error) {
+	i.muAttachmentStore.Lock()
+	defer i.muAttachmentStore.Unlock()
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	createdAt := time.Now()
+	a.CreatedAt = &createdAt
+	a.ID = uuid.NewString()
+
+	i.logger.Info("create attachment with id: ", a.ID)
+	i.attachmentStore = append(i.attachmentStore, a.Copy())
+	return a, nil
+}
+
+// GetAttachmentByID returns the associated attachment by the given id.
+func (i *InmemoryDB) GetAttachmentByID(_ context.Context, id string, opts ...query.Option) (*model.Attachment, error) {
+	i.muAttachmentStore.Lock()
+	defer i.muAttachmentStore.Unlock()
+	o := query.New(opts...)
+	for _, a := range i.attachmentStore {
+		if a.ID == id && o.Visible(a.DeletedAt) {
+			i.logger.Info("get attachment with id: ", a.ID)
+			return a.Copy(), nil
+		}
+	}
+	i.logger.Error("no attachment found")
+	return nil, errors.New("no attachment found")
+}
+
+// ListAttachments returns a copy of the internal attachment list of the given
+// resource.
+func (i *InmemoryDB) ListAttachments(_ context.Context, kind model.AttachmentResource, resourceID string, opts ...query.Option) ([]*model.Attachment, error) {
+	i.muAttachmentStore.Lock()
+	defer i.muAttachmentStore.Unlock()
+	i.logger.Info("get list of attachments")
+	attachments := make([]*model.Attachment, 0)
+	o := query.New(opts...)
+	for _, a := range i.attachmentStore {
+		if a.ResourceKind == kind && a.ResourceID == resourceID && o.Visible(a.DeletedAt) {
+			attachments = append(attachments, a.Copy())
+		}
+	}
+	return attachments, nil
+}
+
+// DeleteAttachment marks attachment entry by the given id as deleted.
+func (i *InmemoryDB) DeleteAttachment(_ context.Context, id string) error {
+	i.muAttachmentStore.Lock()
+	defer i.muAttachmentStore.Unlock()
+	deletedAt := time.Now()
+	for _, a := range i.attachmentStore {
+		if a.ID == id && a.DeletedAt == nil {
+			i.logger.Info("delete attachment with id: ", id)
+			a.DeletedAt = &deletedAt
+			a.UpdatedAt = &deletedAt
+			return nil
+		}
+	}
+	i.logger.Error("attachment didn't exist")
+	return errors.New("attachment didn't exist")
+}
+
+// CreateTeamMembership stores an internal copy of the given teamMembership, if
+// team and user exist, the user is not yet member of the team and the
+// allocations of the user do not exceed 100 percent.
+// Returns copy with assigned teamMembershipID.
+func (i *InmemoryDB) CreateTeamMembership(ctx context.Context, m *model.TeamMembership) (*model.TeamMembership, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	// NOTE: team and user are verified before locking the memberships, since
+	// CreateUser locks the memberships while holding the users.
+	if _, err := i.GetTeamByID(ctx, m.TeamID); err != nil {
+		return nil, err
+	}
+	if _, err := i.GetUserByID(ctx, m.UserID); err != nil {
+		return nil, err
+	}
+	i.muTeamMembershipStore.Lock()
+	defer i.muTeamMembershipStore.Unlock()
+	for _, e := range i.teamMembershipStore {
+		if e.TeamID == m.TeamID && e.UserID == m.UserID && e.DeletedAt == nil {
+			return nil, fmt.Errorf("%w: user is already member of the team", model.ErrInvalidMembership)
+		}
+	}
+	if err := m.CheckAllocation(i.teamMembershipStore); err != nil {
+		return nil, err
+	}
+	createdAt := time.Now()
+	m.CreatedAt = &createdAt
+	m.ID = uuid.NewString()
+	m.Version = 1
+
+	i.logger.Info("create team-membership with id: ", m.ID)
+	i.teamMembershipStore = append(i.teamMembershipStore, m.Copy())
+	return m, nil
+}
+
+// GetTeamMembershipByID returns the associated teamMembership by the given id.
+func (i *InmemoryDB) GetTeamMembershipByID(_ context.Context, id string, opts ...query.Option) (*model.TeamMembership, error) {
+	i.muTeamMembershipStore.Lock()
+	defer i.muTeamMembershipStore.Unlock()
+	o := query.New(opts...)
+	for _, m := range i.teamMembershipStore {
+		if m.ID == id && o.Visible(m.DeletedAt) {
+			i.logger.Info("get team-membership with id: ", m.ID)
+			return m.Copy(), nil
+		}
+	}
+	i.logger.Error("no team-membership found")
+	return nil, errors.New("no team-membership found")
+}
+
+// ListTeamMemberships returns a copy of the internal teamMembership list of
+// the given teamID.
+func (i *InmemoryDB) ListTeamMemberships(_ context.Context, teamID string, opts ...query.Option) ([]*model.TeamMembership, error) {
+	i.muTeamMembershipStore.Lock()
+	defer i.muTeamMembershipStore.Unlock()
+	i.logger.Info("get list of team-memberships")
+	memberships := make([]*model.TeamMembership, 0)
+	o := query.New(opts...)
+	for _, m := range i.teamMembershipStore {
+		if m.TeamID == teamID && o.Visible(m.DeletedAt) {
+			memberships = append(memberships, m.Copy())
+		}
+	}
+	return memberships, nil
+}
+
+// ListUserTeamMemberships returns a copy of the internal teamMembership list
+// of the given userID.
+func (i *InmemoryDB) ListUserTeamMemberships(_ context.Context, userID string, opts ...query.Option) ([]*model.TeamMembership, error) {
+	i.muTeamMembershipStore.Lock()
+	defer i.muTeamMembershipStore.Unlock()
+	i.logger.Info("get list of team-memberships")
+	memberships := make([]*model.TeamMembership, 0)
+	o := query.New(opts...)
+	for _, m := range i.teamMembershipStore {
+		if m.UserID == userID && o.Visible(m.DeletedAt) {
+			memberships = append(memberships, m.Copy())
+		}
+	}
+	return memberships, nil
+}
+
+// UpdateTeamMembership updates role and allocation of the teamMembership entry
+// by the given teamMembership. Team and user of a membership can not be
+// changed.
+func (i *InmemoryDB) UpdateTeamMembership(_ context.Context, m *model.TeamMembership) (*model.TeamMembership, error) {
+	i.muTeamMembershipStore.Lock()
+	defer i.muTeamMembershipStore.Unlock()
+	updatedAt := time.Now()
+	for x := 0; x < len(i.teamMembershipStore); x++ {
+		if i.teamMembershipStore[x].ID != m.ID || i.teamMembershipStore[x].DeletedAt != nil {
+			continue
+		}
+		if err := checkVersion(model.EntityTeamMembership, m.ID, i.teamMembershipStore[x].Version, m.Version); err != nil {
+			return nil, err
+		}
+		updated := i.teamMembershipStore[x].Copy()
+		updated.Role = m.Role
+		updated.Allocation = m.Allocation
+		if err := updated.Validate(); err != nil {
+			return nil, err
+		}
+		if err := updated.CheckAllocation(i.teamMembershipStore); err != nil {
+			return nil, err
+		}
+		updated.UpdatedAt = &updatedAt
+		updated.Version++
+		i.teamMembershipStore[x] = updated
+		i.logger.Info("update team-membership with id: ", m.ID)
+		return updated.Copy(), nil
+	}
+	i.logger.Error("update failed: no team-membership found")
+	return nil, errors.New("update failed: no team-membership found")
+}
+
+// DeleteTeamMembership marks teamMembership entry by the given id as deleted.
+func (i *InmemoryDB) DeleteTeamMembership(_ context.Context, id string) error {
+	i.muTeamMembershipStore.Lock()
+	defer i.muTeamMembershipStore.Unlock()
+	deletedAt := time.Now()
+	for _, m := range i.teamMembershipStore {
+		if m.ID == id && m.DeletedAt == nil {
+			i.logger.Info("delete team-membership with id: ", id)
+			m.DeletedAt = &deletedAt
+			m.UpdatedAt = &deletedAt
+			return nil
+		}
+	}
+	i.logger.Error("team-membership didn't exist")
+	return errors.New("team-membership didn't exist")
+}
+
+// Restore removes the deletion mark of the entry of the given entity and id.
+// Vacations and vacation-requests can only be restored, if they do not overlap
+// with blocking requests or vacations of the same user.
+func (i *InmemoryDB) Restore(_ context.Context, entity model.Entity, id string) error {
+	mu, entries, _, err := i.deletable(entity)
+	if err != nil {
+		return err
+	}
+	if entity == model.EntityVacation || entity == model.EntityVacationRequest {
+		// NOTE: both stores are locked in the order of the approval.
+		i.muVacationRequestStore.Lock()
+		defer i.muVacationRequestStore.Unlock()
+		i.muVacationStore.Lock()
+		defer i.muVacationStore.Unlock()
+	} else {
+		mu.Lock()
+		defer mu.Unlock()
+	}
+	for _, e := range entries() {
+		if e.id != id {
+			continue
+		}
+		if *e.deletedAt == nil {
+			return fmt.Errorf("%w: %s %s", model.ErrNotDeleted, entity, id)
+		}
+		if err := i.checkRestoredOverlap(entity, id); err != nil {
+			return err
+		}
+		i.logger.Info("restore ", entity, " with id: ", id)
+		*e.deletedAt = nil
+		if e.updatedAt != nil {
+			updatedAt := time.Now()
+			*e.updatedAt = &updatedAt
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s %s", model.ErrEntityNotFound, entity, id)
+}
+
+// checkRestoredOverlap returns a *model.OverlapError, if the vacation or the
+// open vacation-request of the given id overlaps with any blocking request or
+// vacation of the same user. Other entities never overlap. The caller must
+// hold muVacationRequestStore and muVacationStore.
+func (i *InmemoryDB) checkRestoredOverlap(entity model.Entity, id string) error {
+	if entity != model.EntityVacation && entity != model.EntityVacationRequest {
+		return nil
+	}
+	requests := make([]*model.VacationRequest, len(i.vacationRequestStore))
+	for j, r := range i.vacationRequestStore {
+		requests[j] = r.Copy()
+	}
+	vacations := make([]*model.Vacation, len(i.vacationStore))
+	for j, vac := range i.vacationStore {
+		vacations[j] = vac.Copy()
+	}
+	if entity == model.EntityVacation {
+		for _, v := range vacations {
+			if v.ID == id {
+				return v.CheckOverlaps(requests, vacations)
+			}
+		}
+		return nil
+	}
+	for _, r := range requests {
+		if r.ID == id && (r.Status.Editable() || r.Status.Blocking()) {
+			return r.CheckOverlaps(requests, vacations)
+		}
+	}
+	return nil
+}
+
+// Purge permanently removes the entry of the given entity and id, if it was
+// deleted before deletedBefore. Entries, which are still referenced by other
+// entries, can not be purged.
+func (i *InmemoryDB) Purge(_ context.Context, entity model.Entity, id string, deletedBefore time.Time) error {
+	mu, entries, remove, err := i.deletable(entity)
+	if err != nil {
+		return err
+	}
+	// NOTE: the stores of the referencing entries are locked by other write
+	// paths before the store of the entry, the references are therefore
+	// checked in between two lookups of the entry.
+	mu.Lock()
+	_, err = purgeable(entries(), entity, id, deletedBefore)
+	mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if i.referenced(entity, id) {
+		return fmt.Errorf("%w: %s %s", model.ErrEntityReferenced, entity, id)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	x, err := purgeable(entries(), entity, id, deletedBefore)
+	if err != nil {
+		return err
+	}
+	i.logger.Info("purge ", entity, " with id: ", id)
+	remove(x)
+	return nil
+}
+
+// purgeable returns the index of the entry of the given entity and id, if it
+// was deleted before deletedBefore.
+func purgeable(entries []entry, entity model.Entity, id string, deletedBefore time.Time) (int, error) {
+	for x, e := range entries {
+		if e.id != id {
+			continue
+		}
+		if *e.deletedAt == nil {
+			return 0, fmt.Errorf("%w: %s %s", model.ErrNotDeleted, entity, id)
+		}
+		if !(*e.deletedAt).Before(deletedBefore) {
+			return 0, fmt.Errorf("%w: %s %s", model.ErrRetentionPeriod, entity, id)
+		}
+		return x, nil
+	}
+	return 0, fmt.Errorf("%w: %s %s", model.ErrEntityNotFound, entity, id)
+}
+
+// referenced reports whether any entry, deleted or not, refers to the entry of
+// the given entity and id.
+func (i *InmemoryDB) referenced(entity model.Entity, id string) bool {
+	switch entity {
+	case model.EntityUser:
+		return locked(&i.muVacationStore, func() bool {
+			for _, v := range i.vacationStore {
+				if v.UserID == id {
+					return true
+				}
+			}
+			return false
+		}) || locked(&i.muVacationRequestStore, func() bool {
+			for _, v := range i.vacationRequestStore {
+				if v.UserID == id || isID(v.RejectedBy, id) {
+					return true
+				}
+			}
+			return false
+		}) || locked(&i.muVacationResourceStore, func() bool {
+			for _, v := range i.vacationResourceStore {
+				if v.UserID == id {
+					return true
+				}
+			}
+			return false
+		}) || locked(&i.muDelegationStore, func() bool {
+			for _, d := range i.delegationStore {
+				if d.DelegatorID == id || d.DelegateID == id {
+					return true
+				}
+			}
+			return false
+		}) || locked(&i.muCommentStore, func() bool {
+			for _, c := range i.commentStore {
+				if c.AuthorID == id {
+					return true
+				}
+			}
+			return false
+		}) || locked(&i.muAttachmentStore, func() bool {
+			for _, a := range i.attachmentStore {
+				if a.UserID == id {
+					return true
+				}
+			}
+			return false
+		}) || locked(&i.muTeamMembershipStore, func() bool {
+			for _, m := range i.teamMembershipStore {
+				if m.UserID == id {
+					return true
+				}
+			}
+			return false
+		})
+	case model.EntityTeam:
+		return locked(&i.muUserStore, func() bool {
+			for _, u := range i.userStore {
+				if isID(u.TeamID, id) {
+					return true
+				}
+			}
+			return false
+		}) || locked(&i.muTeamStore, func() bool {
+			for _, t := range i.teamStore {
+				if isID(t.ParentID, id) {
+					return true
+				}
+			}
+			return false
+		}) || locked(&i.muTeamRuleStore, func() bool {
+			for _, r := range i.teamRuleStore {
+				if r.TeamID == id {
+					return true
+				}
+			}
+			return false
+		}) || locked(&i.muTeamMembershipStore, func() bool {
+			for _, m := range i.teamMembershipStore {
+				if m.TeamID == id {
+					return true
+				}
+			}
+			return false
+		})
+	case model.EntityVacation:
+		return locked(&i.muVacationRequestStore, func() bool {
+			for _, v := range i.vacationRequestStore {
+				if isID(v.VacationID, id) {
+					return true
+				}
+			}
+			return false
+		})
+	case model.EntityVacationRequest:
+		return locked(&i.muCommentStore, func() bool {
+			for _, c := range i.commentStore {
+				if c.VacationRequestID == id {
+					return true
+				}
+			}
+			return false
+		})
+	case model.EntityAbsenceType:
+		return locked(&i.muVacationStore, func() bool {
+			for _, v := range i.vacationStore {
+				if isID(v.AbsenceTypeID, id) {
+					return true
+				}
+			}
+			return false
+		}) || locked(&i.muVacationRequestStore, func() bool {
+			for _, v := range i.vacationRequestStore {
+				if isID(v.AbsenceTypeID, id) {
+					return true
+				}
+			}
+			return false
+		})
+	}
+	return false
+}
+
+// locked calls f, while holding mu.
+func locked(mu *sync.Mutex, f func() bool) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return f()
+}
+
+// isID reports whether the optional reference ref refers to id.
+func isID(ref *string, id string) bool {
+	return ref != nil && *ref == id
+}
+
+// entry refers to the id and the timestamps of a stored entry.
+type entry struct {
+	id        string
+	deletedAt **time.Time
+	// updatedAt is nil for entities without update timestamp.
+	updatedAt **time.Time
+}
+
+// deletable returns the mutex of the store of the given entity, a function
+// listing the entries of the store and a function removing the entry at the
+// given index. Both functions require the mutex to be held.
+func (i *InmemoryDB) deletable(entity model.Entity) (*sync.Mutex, func() []entry, func(x int), error) {
+	switch entity {
+	case model.EntityUser:
+		return &i.muUserStore, func() []entry {
+				entries := make([]entry, len(i.userStore))
+				for x, u := range i.userStore {
+					entries[x] = entry{u.ID, &u.DeletedAt, &u.UpdatedAt}
+				}
+				return entries
+			}, func(x int) {
+				i.userStore = append(i.userStore[:x], i.userStore[x+1:]...)
+			}, nil
+	case model.EntityTeam:
+		return &i.muTeamStore, func() []entry {
+				entries := make([]entry, len(i.teamStore))
+				for x, t := range i.teamStore {
+					entries[x] = entry{t.ID, &t.DeletedAt, &t.UpdatedAt}
+				}
+				return entries
+			}, func(x int) {
+				i.teamStore = append(i.teamStore[:x], i.teamStore[x+1:]...)
+			}, nil
+	case model.EntityVacation:
+		return &i.muVacationStore, func() []entry {
+				entries := make([]entry, len(i.vacationStore))
+				for x, v := range i.vacationStore {
+					entries[x] = entry{v.ID, &v.DeletedAt, nil}
+				}
+				return entries
+			}, func(x int) {
+				i.vacationStore = append(i.vacationStore[:x], i.vacationStore[x+1:]...)
+			}, nil
+	case model.EntityVacationRequest:
+		return &i.muVacationRequestStore, func() []entry {
+				entries := make([]entry, len(i.vacationRequestStore))
+				for x, v := range i.vacationRequestStore {
+					entries[x] = entry{v.ID, &v.DeletedAt, &v.UpdatedAt}
+				}
+				return entries
+			}, func(x int) {
+				i.vacationRequestStore = append(i.vacationRequestStore[:x], i.vacationRequestStore[x+1:]...)
+			}, nil
+	case model.EntityVacationResource:
+		return &i.muVacationResourceStore, func() []entry {
+				entries := make([]entry, len(i.vacationResourceStore))
+				for x, v := range i.vacationResourceStore {
+					entries[x] = entry{v.ID, &v.DeletedAt, &v.UpdatedAt}
+				}
+				return entries
+			}, func(x int) {
+				i.vacationResourceStore = append(i.vacationResourceStore[:x], i.vacationResourceStore[x+1:]...)
+			}, nil
+	case model.EntityAbsenceType:
+		return &i.muAbsenceTypeStore, func() []entry {
+				entries := make([]entry, len(i.absenceTypeStore))
+				for x, a := range i.absenceTypeStore {
+					entries[x] = entry{a.ID, &a.DeletedAt, &a.UpdatedAt}
+				}
+				return entries
+			}, func(x int) {
+				i.absenceTypeStore = append(i.absenceTypeStore[:x], i.absenceTypeStore[x+1:]...)
+			}, nil
+	case model.EntityDelegation:
+		return &i.muDelegationStore, func() []entry {
+				entries := make([]entry, len(i.delegationStore))
+				for x, d := range i.delegationStore {
+					entries[x] = entry{d.ID, &d.DeletedAt, &d.UpdatedAt}
+				}
+				return entries
+			}, func(x int) {
+				i.delegationStore = append(i.delegationStore[:x], i.delegationStore[x+1:]...)
+			}, nil
+	case model.EntityTeamRule:
+		return &i.muTeamRuleStore, func() []entry {
+				entries := make([]entry, len(i.teamRuleStore))
+				for x, t := range i.teamRuleStore {
+					entries[x] = entry{t.ID, &t.DeletedAt, &t.UpdatedAt}
+				}
+				return entries
+			}, func(x int) {
+				i.teamRuleStore = append(i.teamRuleStore[:x], i.teamRuleStore[x+1:]...)
+			}, nil
+	case model.EntityTeamMembership:
+		return &i.muTeamMembershipStore, func() []entry {
+				entries := make([]entry, len(i.teamMembershipStore))
+				for x, m := range i.teamMembershipStore {
+					entries[x] = entry{m.ID, &m.DeletedAt, &m.UpdatedAt}
+				}
+				return entries
+			}, func(x int) {
+				i.teamMembershipStore = append(i.teamMembershipStore[:x], i.teamMembershipStore[x+1:]...)
+			}, nil
+	case model.EntityAttachment:
+		return &i.muAttachmentStore, func() []entry {
+				entries := make([]entry, len(i.attachmentStore))
+				for x, a := range i.attachmentStore {
+					entries[x] = entry{a.ID, &a.DeletedAt, &a.UpdatedAt}
+				}
+				return entries
+			}, func(x int) {
+				i.attachmentStore = append(i.attachmentStore[:x], i.attachmentStore[x+1:]...)
+			}, nil
+	}
+	return nil, nil, nil, fmt.Errorf("%w: %s", model.ErrUnknownEntity, entity)
+}
+
+// checkVersion returns model.ErrVersionConflict, if a version is given, which
+// differs from the current version of the entry. Version 0 skips the check.
+func checkVersion(entity model.Entity, id string, current, version int) error {
+	if version != 0 && version != current {
+		return fmt.Errorf("%w: %s %s has version %d", model.ErrVersionConflict, entity, id, current)
+	}
+	return nil
+}
+
+// teamMembers returns the ids of the users with a membership in the team of
+// the given filter, nil if the filter matches all teams.
+func (i *InmemoryDB) teamMembers(ctx context.Context, f query.Filter) (map[string]bool, error) {
+	if f.TeamID == "" {
+		return nil, nil
+	}
+	memberships, err := i.ListTeamMemberships(ctx, f.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[string]bool, len(memberships))
+	for _, m := range memberships {
+		members[m.UserID] = true
+	}
+	return members, nil
+}
+
+// isMember reports whether the given user is one of the given members, a nil
+// set contains all users.
+func isMember(members map[string]bool, userID string) bool {
+	return members == nil || members[userID]
+}
+
+// CreateAuditEntry stores an internal copy of the given audit entry.
+// Returns copy with assigned auditEntryID.
+func (i *InmemoryDB) CreateAuditEntry(_ context.Context, a *model.AuditEntry) (*model.AuditEntry, error) {
+	i.muAuditStore.Lock()
+	defer i.muAuditStore.Unlock()
+	createdAt := time.Now()
+	a.CreatedAt = &createdAt
+	a.ID = uuid.NewString()
+
+	i.auditStore = append(i.auditStore, a.Copy())
+	return a, nil
+}
+
+// ListAuditEntries returns a copy of the internal audit entries matching the
+// given filter, ordered by creation.
+func (i *InmemoryDB) ListAuditEntries(_ context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	i.muAuditStore.Lock()
+	defer i.muAuditStore.Unlock()
+	i.logger.Info("get list of audit entries")
+	entries := make([]*model.AuditEntry, 0)
+	for _, a := range i.auditStore {
+		if filter.Match(a) {
+			entries = append(entries, a.Copy())
+		}
+	}
+	return entries, nil
+}
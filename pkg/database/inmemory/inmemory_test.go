@@ -2,12 +2,15 @@ package inmemory
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/uuid"
 
+	"github.com/MninaTB/vacadm/pkg/database/query"
 	"github.com/MninaTB/vacadm/pkg/model"
 )
 
@@ -65,6 +68,17 @@ func TestInmemoryDB_CreateUser(t *testing.T) {
 			userCount: 0,
 			wantErr:   true,
 		},
+		{
+			name: "create user with unknown role",
+			user: &model.User{
+				FirstName: "firstname-role",
+				LastName:  "lastname-role",
+				Email:     "role@inform.de",
+				Role:      "owner",
+			},
+			userCount: 0,
+			wantErr:   true,
+		},
 		{
 			name: "create user but email address is empty",
 			user: &model.User{
@@ -258,6 +272,45 @@ func TestInmemoryDB_UpdateUser(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "update role",
+			userStore: []*model.User{
+				{
+					ID:        "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+					FirstName: "firstname-existing",
+					LastName:  "lastname-existing",
+					Email:     "admin@inform.de",
+					Role:      model.RoleEmployee,
+				},
+			},
+			user: &model.User{
+				ID:        "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+				FirstName: "firstname-existing",
+				LastName:  "lastname-existing",
+				Email:     "admin@inform.de",
+				Role:      model.RoleHR,
+			},
+			wantErr: false,
+		},
+		{
+			name: "update parent to own report",
+			userStore: []*model.User{
+				{
+					ID:    "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+					Email: "manager@inform.de",
+				},
+				{
+					ID:       "c1d1b2c8-1b1a-4e9e-9b43-3a1c0e1f4d7a",
+					ParentID: func() *string { tmp := "f95128f7-733d-48b3-9306-cc5fe27cf6a5"; return &tmp }(),
+					Email:    "report@inform.de",
+				},
+			},
+			user: &model.User{
+				ID:       "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+				ParentID: func() *string { tmp := "c1d1b2c8-1b1a-4e9e-9b43-3a1c0e1f4d7a"; return &tmp }(),
+			},
+			wantErr: true,
+		},
 		{
 			name: "update user but parent does not exist",
 			user: &model.User{
@@ -299,7 +352,8 @@ func TestInmemoryDB_UpdateUser(t *testing.T) {
 
 			ignoreFields := cmp.FilterPath(func(p cmp.Path) bool {
 				return strings.Contains(p.String(), "ID") ||
-					strings.Contains(p.String(), "UpdatedAt")
+					strings.Contains(p.String(), "UpdatedAt") ||
+					strings.Contains(p.String(), "Version")
 			}, cmp.Ignore())
 
 			// NOTE: compare original struct, ignore ID and CreatedAt (should be different)
@@ -351,8 +405,19 @@ func TestInmemoryDB_DeleteUser(t *testing.T) {
 				return
 			}
 
-			if expectCount != len(db.userStore) {
-				t.Fatalf("invalid count, want: %d, got: %d", expectCount, len(db.userStore))
+			users, err := db.ListUsers(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expectCount != len(users) {
+				t.Fatalf("invalid count, want: %d, got: %d", expectCount, len(users))
+			}
+			deleted, err := db.GetUserByID(context.Background(), tc.userID, query.IncludeDeleted(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted.DeletedAt == nil {
+				t.Fatal("missing deletion time")
 			}
 		})
 	}
@@ -536,12 +601,13 @@ func TestInmemoryDB_ListTeams(t *testing.T) {
 
 func TestInmemoryDB_ListTeamUsers(t *testing.T) {
 	tt := []struct {
-		name        string
-		teamID      string
-		userStore   []*model.User
-		teamStore   []*model.Team
-		expectUsers int
-		wantErr     bool
+		name                string
+		teamID              string
+		userStore           []*model.User
+		teamStore           []*model.Team
+		teamMembershipStore []*model.TeamMembership
+		expectUsers         int
+		wantErr             bool
 	}{
 		{
 			name:        "empty store",
@@ -550,7 +616,7 @@ func TestInmemoryDB_ListTeamUsers(t *testing.T) {
 		},
 		{
 			name:        "list users as expected",
-			expectUsers: 2,
+			expectUsers: 3,
 			teamStore: []*model.Team{
 				{
 					ID: "d4ee305c-18cc-4f1e-a752-764b6913ab67",
@@ -584,6 +650,19 @@ func TestInmemoryDB_ListTeamUsers(t *testing.T) {
 					LastName:  "lastname-other-team",
 					Email:     "other@inform.de",
 				},
+				{
+					ID:        "5c0a3e39-55a4-4d5b-9b0e-2f0b1b0d8e11",
+					FirstName: "firstname-split",
+					LastName:  "lastname-split",
+					Email:     "split@inform.de",
+				},
+			},
+			teamMembershipStore: []*model.TeamMembership{
+				{TeamID: "d4ee305c-18cc-4f1e-a752-764b6913ab67", UserID: "f95128f7-733d-48b3-9306-cc5fe27cf6a5", Allocation: 100},
+				{TeamID: "d4ee305c-18cc-4f1e-a752-764b6913ab67", UserID: "fed75474-29df-4d99-a792-09f0bf7ae848", Allocation: 100},
+				{TeamID: "other-team", UserID: "23ebe54c-2d91-4a00-8e13-6b12a1a47000", Allocation: 100},
+				{TeamID: "other-team", UserID: "5c0a3e39-55a4-4d5b-9b0e-2f0b1b0d8e11", Allocation: 50},
+				{TeamID: "d4ee305c-18cc-4f1e-a752-764b6913ab67", UserID: "5c0a3e39-55a4-4d5b-9b0e-2f0b1b0d8e11", Allocation: 50},
 			},
 			wantErr: false,
 		},
@@ -598,6 +677,9 @@ func TestInmemoryDB_ListTeamUsers(t *testing.T) {
 			if tc.teamStore != nil {
 				db.teamStore = tc.teamStore
 			}
+			if tc.teamMembershipStore != nil {
+				db.teamMembershipStore = tc.teamMembershipStore
+			}
 			users, err := db.ListTeamUsers(context.Background(), tc.teamID)
 			if err != nil && !tc.wantErr {
 				t.Fatal(err)
@@ -640,6 +722,32 @@ func TestInmemoryDB_UpdateTeam(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "move team into department",
+			teamStore: []*model.Team{
+				{ID: "department", Name: "dev"},
+				{ID: "team", Name: "backend"},
+			},
+			team: &model.Team{
+				ID:       "team",
+				ParentID: func() *string { s := "department"; return &s }(),
+				Name:     "backend",
+			},
+			wantErr: false,
+		},
+		{
+			name: "move department into own sub-team",
+			teamStore: []*model.Team{
+				{ID: "department", Name: "dev"},
+				{ID: "team", ParentID: func() *string { s := "department"; return &s }(), Name: "backend"},
+			},
+			team: &model.Team{
+				ID:       "department",
+				ParentID: func() *string { s := "team"; return &s }(),
+				Name:     "dev",
+			},
+			wantErr: true,
+		},
 		{
 			name: "update team but owner does not exist",
 			team: &model.Team{
@@ -669,7 +777,8 @@ func TestInmemoryDB_UpdateTeam(t *testing.T) {
 			}
 
 			ignoreFields := cmp.FilterPath(func(p cmp.Path) bool {
-				return strings.Contains(p.String(), "UpdatedAt")
+				return strings.Contains(p.String(), "UpdatedAt") ||
+					strings.Contains(p.String(), "Version")
 			}, cmp.Ignore())
 
 			// NOTE: compare original struct, ignore ID and CreatedAt (should be different)
@@ -703,6 +812,15 @@ func TestInmemoryDB_DeleteTeam(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:   "team has sub-teams",
+			teamID: "department",
+			teamStore: []*model.Team{
+				{ID: "department", Name: "dev"},
+				{ID: "team", ParentID: func() *string { s := "department"; return &s }(), Name: "backend"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tc := range tt {
@@ -719,8 +837,19 @@ func TestInmemoryDB_DeleteTeam(t *testing.T) {
 				return
 			}
 
-			if expectCount != len(db.userStore) {
-				t.Fatalf("invalid count, want: %d, got: %d", expectCount, len(db.teamStore))
+			teams, err := db.ListTeams(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expectCount != len(teams) {
+				t.Fatalf("invalid count, want: %d, got: %d", expectCount, len(teams))
+			}
+			deleted, err := db.GetTeamByID(context.Background(), tc.teamID, query.IncludeDeleted(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted.DeletedAt == nil {
+				t.Fatal("missing deletion time")
 			}
 		})
 	}
@@ -939,8 +1068,19 @@ func TestInmemoryDB_DeleteVacation(t *testing.T) {
 				return
 			}
 
-			if expectCount != len(db.vacationStore) {
-				t.Fatalf("invalid count, want: %d, got: %d", expectCount, len(db.vacationStore))
+			vacations, err := db.ListVacations(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expectCount != len(vacations) {
+				t.Fatalf("invalid count, want: %d, got: %d", expectCount, len(vacations))
+			}
+			deleted, err := db.GetVacationByID(context.Background(), tc.vacationID, query.IncludeDeleted(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted.DeletedAt == nil {
+				t.Fatal("missing deletion time")
 			}
 		})
 	}
@@ -951,6 +1091,7 @@ func TestInmemoryDB_CreateVacationRequest(t *testing.T) {
 		name                 string
 		vacationRequest      *model.VacationRequest
 		vacationRequestStore []*model.VacationRequest
+		vacationStore        []*model.Vacation
 		vacationRequestCount int
 		wantErr              bool
 	}{
@@ -973,6 +1114,59 @@ func TestInmemoryDB_CreateVacationRequest(t *testing.T) {
 			vacationRequestCount: 2,
 			wantErr:              false,
 		},
+		{
+			name: "overlapping pending request",
+			vacationRequestStore: []*model.VacationRequest{
+				{
+					ID:     "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+					UserID: "abc",
+					Status: model.StatusPending,
+					From:   time.Date(2022, time.April, 4, 0, 0, 0, 0, time.UTC),
+					To:     time.Date(2022, time.April, 8, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			vacationRequest: &model.VacationRequest{
+				UserID: "abc",
+				From:   time.Date(2022, time.April, 8, 0, 0, 0, 0, time.UTC),
+				To:     time.Date(2022, time.April, 12, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: true,
+		},
+		{
+			name: "overlapping vacation",
+			vacationStore: []*model.Vacation{
+				{
+					ID:     "9d7b7a4a-6f0e-4e8f-a8a1-2f3c0c5b1a11",
+					UserID: "abc",
+					From:   time.Date(2022, time.April, 4, 0, 0, 0, 0, time.UTC),
+					To:     time.Date(2022, time.April, 8, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			vacationRequest: &model.VacationRequest{
+				UserID: "abc",
+				From:   time.Date(2022, time.April, 6, 0, 0, 0, 0, time.UTC),
+				To:     time.Date(2022, time.April, 6, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: true,
+		},
+		{
+			name: "withdrawn request does not block",
+			vacationRequestStore: []*model.VacationRequest{
+				{
+					ID:     "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+					UserID: "abc",
+					Status: model.StatusWithdrawn,
+					From:   time.Date(2022, time.April, 4, 0, 0, 0, 0, time.UTC),
+					To:     time.Date(2022, time.April, 8, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			vacationRequest: &model.VacationRequest{
+				UserID: "abc",
+				From:   time.Date(2022, time.April, 4, 0, 0, 0, 0, time.UTC),
+				To:     time.Date(2022, time.April, 8, 0, 0, 0, 0, time.UTC),
+			},
+			vacationRequestCount: 2,
+		},
 	}
 
 	for _, tc := range tt {
@@ -981,11 +1175,16 @@ func TestInmemoryDB_CreateVacationRequest(t *testing.T) {
 			if tc.vacationRequestStore != nil {
 				db.vacationRequestStore = tc.vacationRequestStore
 			}
+			if tc.vacationStore != nil {
+				db.vacationStore = tc.vacationStore
+			}
 			newVacationRequest, err := db.CreateVacationRequest(context.Background(), tc.vacationRequest)
 			if err != nil && !tc.wantErr {
 				t.Fatal(err)
 			} else if err != nil && tc.wantErr {
 				return
+			} else if tc.wantErr {
+				t.Fatal("expected error")
 			}
 
 			if tc.vacationRequestCount != len(db.vacationRequestStore) {
@@ -1112,27 +1311,195 @@ func TestInmemoryDB_ListVacationRequests(t *testing.T) {
 	}
 }
 
-func TestInmemoryDB_DeleteVacationRequest(t *testing.T) {
+func TestInmemoryDB_ListVacationRequestsOptions(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2022, time.April, d, 0, 0, 0, 0, time.UTC)
+	}
+	from, to := day(5), day(6)
+	store := []*model.VacationRequest{
+		{ID: "a", UserID: "u1", Status: model.StatusPending, From: day(4), To: day(8)},
+		{ID: "b", UserID: "u2", Status: model.StatusApproved, From: day(1), To: day(2)},
+		{ID: "c", UserID: "u1", Status: model.StatusApproved, From: day(6), To: day(6)},
+		{ID: "d", UserID: "u2", Status: model.StatusPending, From: day(7), To: day(9)},
+	}
+	tt := []struct {
+		name    string
+		opts    []query.Option
+		wantIDs []string
+	}{
+		{name: "store order", wantIDs: []string{"a", "b", "c", "d"}},
+		{name: "user", opts: []query.Option{query.UserID("u2")}, wantIDs: []string{"b", "d"}},
+		{name: "team", opts: []query.Option{query.TeamID("team")}, wantIDs: []string{"a", "c"}},
+		{name: "status", opts: []query.Option{query.Status(model.StatusApproved)}, wantIDs: []string{"b", "c"}},
+		{name: "period", opts: []query.Option{query.Period(&from, &to)}, wantIDs: []string{"a", "c"}},
+		{name: "sort", opts: []query.Option{query.SortBy(query.SortFrom, true)}, wantIDs: []string{"d", "c", "a", "b"}},
+		{name: "first page", opts: []query.Option{query.SortBy(query.SortFrom, false), query.Page(3, nil)}, wantIDs: []string{"b", "a", "c"}},
+		{name: "next page", opts: []query.Option{
+			query.SortBy(query.SortFrom, false), query.Page(3, &query.Cursor{Key: "2022-04-06 00:00:00.000000", ID: "c"}),
+		}, wantIDs: []string{"d"}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			db := NewInmemoryDB()
+			db.vacationRequestStore = store
+			db.teamMembershipStore = []*model.TeamMembership{{ID: "m", TeamID: "team", UserID: "u1"}}
+			list, err := db.ListVacationRequests(context.Background(), tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids := make([]string, 0, len(list))
+			for _, v := range list {
+				ids = append(ids, v.ID)
+			}
+			if diff := cmp.Diff(tc.wantIDs, ids); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestInmemoryDB_GetVacationRequestsByScope(t *testing.T) {
+	ctx := context.Background()
+	db := NewInmemoryDB()
+	db.vacationRequestStore = []*model.VacationRequest{
+		{ID: "a", UserID: "u1"},
+		{ID: "b", UserID: "u2"},
+		{ID: "c", UserID: "u1"},
+	}
+	db.teamMembershipStore = []*model.TeamMembership{{ID: "m", TeamID: "team", UserID: "u2"}}
+	ids := func(list []*model.VacationRequest) []string {
+		result := make([]string, 0, len(list))
+		for _, v := range list {
+			result = append(result, v.ID)
+		}
+		return result
+	}
+
+	byUser, err := db.GetVacationRequestsByUserID(ctx, "u1", query.UserID("u2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"a", "c"}, ids(byUser)); diff != "" {
+		t.Fatalf("expected requests of the user (-want +got):\n%s", diff)
+	}
+	byTeam, err := db.GetVacationRequestsByTeamID(ctx, "team")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"b"}, ids(byTeam)); diff != "" {
+		t.Fatalf("expected requests of the team members (-want +got):\n%s", diff)
+	}
+}
+
+func TestInmemoryDB_UpdateVacationRequest(t *testing.T) {
 	tt := []struct {
 		name                 string
-		vacationRequestID    string
+		vacationRequest      *model.VacationRequest
 		vacationRequestStore []*model.VacationRequest
+		expectStatus         model.VacationRequestStatus
 		wantErr              bool
 	}{
 		{
-			name:              "vacationRequest does not exist",
-			vacationRequestID: "does-not-exist",
-			wantErr:           true,
+			name: "vacationRequest does not exist",
+			vacationRequest: &model.VacationRequest{
+				ID: "does-not-exist",
+			},
+			wantErr: true,
 		},
 		{
-			name: "get vacationRequest by id as expected",
+			name: "submit draft",
 			vacationRequestStore: []*model.VacationRequest{
 				{
-					ID: "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+					ID:     "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+					Status: model.StatusDraft,
 				},
 			},
-			vacationRequestID: "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
-			wantErr:           false,
+			vacationRequest: &model.VacationRequest{
+				ID:     "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+				Status: model.StatusPending,
+			},
+			expectStatus: model.StatusPending,
+		},
+		{
+			name: "submit overlapping draft",
+			vacationRequestStore: []*model.VacationRequest{
+				{
+					ID:     "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+					UserID: "abc",
+					Status: model.StatusDraft,
+					From:   time.Date(2022, time.April, 4, 0, 0, 0, 0, time.UTC),
+					To:     time.Date(2022, time.April, 8, 0, 0, 0, 0, time.UTC),
+				},
+				{
+					ID:     "0b6f2d1e-1a43-4d8c-9f3e-5c2a7b8e9d10",
+					UserID: "abc",
+					Status: model.StatusPending,
+					From:   time.Date(2022, time.April, 8, 0, 0, 0, 0, time.UTC),
+					To:     time.Date(2022, time.April, 8, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			vacationRequest: &model.VacationRequest{
+				ID:     "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+				Status: model.StatusPending,
+			},
+			wantErr: true,
+		},
+		{
+			name: "approve rejected request",
+			vacationRequestStore: []*model.VacationRequest{
+				{
+					ID:     "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+					Status: model.StatusRejected,
+				},
+			},
+			vacationRequest: &model.VacationRequest{
+				ID:     "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+				Status: model.StatusApproved,
+			},
+			wantErr: true,
+		},
+		{
+			name: "reject without reason",
+			vacationRequestStore: []*model.VacationRequest{
+				{
+					ID:     "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+					Status: model.StatusPending,
+				},
+			},
+			vacationRequest: &model.VacationRequest{
+				ID:     "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+				Status: model.StatusRejected,
+			},
+			wantErr: true,
+		},
+		{
+			name: "reject with reason",
+			vacationRequestStore: []*model.VacationRequest{
+				{
+					ID:     "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+					Status: model.StatusPending,
+				},
+			},
+			vacationRequest: &model.VacationRequest{
+				ID:              "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+				Status:          model.StatusRejected,
+				RejectionReason: func() *string { tmp := "team event"; return &tmp }(),
+			},
+			expectStatus: model.StatusRejected,
+		},
+		{
+			name: "change period of approved request",
+			vacationRequestStore: []*model.VacationRequest{
+				{
+					ID:     "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+					Status: model.StatusApproved,
+				},
+			},
+			vacationRequest: &model.VacationRequest{
+				ID:   "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+				From: time.Date(2022, time.April, 4, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: true,
 		},
 	}
 
@@ -1142,38 +1509,193 @@ func TestInmemoryDB_DeleteVacationRequest(t *testing.T) {
 			if tc.vacationRequestStore != nil {
 				db.vacationRequestStore = tc.vacationRequestStore
 			}
-			expectCount := len(tc.vacationRequestStore) - 1
-			err := db.DeleteVacationRequest(context.Background(), tc.vacationRequestID)
+			updated, err := db.UpdateVacationRequest(context.Background(), tc.vacationRequest)
 			if err != nil && !tc.wantErr {
 				t.Fatal(err)
 			} else if err != nil && tc.wantErr {
 				return
+			} else if tc.wantErr {
+				t.Fatal("expected error")
 			}
 
-			if expectCount != len(db.vacationRequestStore) {
-				t.Fatalf("invalid count, want: %d, got: %d", expectCount, len(db.vacationRequestStore))
+			if updated.Status != tc.expectStatus {
+				t.Fatalf("invalid status, want: %s, got: %s", tc.expectStatus, updated.Status)
+			}
+			if updated.UpdatedAt == nil {
+				t.Error("missing timestamp updated_at")
 			}
 		})
 	}
 }
 
-func TestInmemoryDB_CreateVacationResource(t *testing.T) {
+func TestInmemoryDB_ApproveVacationRequest(t *testing.T) {
+	ctx := context.Background()
+	db := NewInmemoryDB()
+	from := time.Date(2022, time.April, 4, 0, 0, 0, 0, time.UTC)
+	vR, err := db.CreateVacationRequest(ctx, &model.VacationRequest{
+		UserID: "user-id",
+		From:   from,
+		To:     from.AddDate(0, 0, 4),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	approverID := "approver-id"
+	_, _, err = db.ApproveVacationRequest(ctx, vR.ID, &model.Vacation{UserID: vR.UserID})
+	if err == nil {
+		t.Fatal("expected invalid vacation to be rejected")
+	}
+	stored, err := db.GetVacationRequestByID(ctx, vR.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != model.StatusPending || stored.VacationID != nil {
+		t.Fatalf("expected pending request without vacation, got: %s", stored.Status)
+	}
+	approved, vac, err := db.ApproveVacationRequest(ctx, vR.ID, &model.Vacation{
+		UserID:     vR.UserID,
+		ApprovedBy: &approverID,
+		From:       vR.From,
+		To:         vR.To,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if approved.Status != model.StatusApproved || approved.VacationID == nil || *approved.VacationID != vac.ID {
+		t.Fatalf("expected approved request linked to vacation %s, got: %+v", vac.ID, approved)
+	}
+	_, _, err = db.ApproveVacationRequest(ctx, vR.ID, &model.Vacation{
+		UserID:     vR.UserID,
+		ApprovedBy: &approverID,
+		From:       vR.From,
+		To:         vR.To,
+	})
+	if !errors.Is(err, model.ErrInvalidStatusTransition) {
+		t.Fatalf("expected %v, got: %v", model.ErrInvalidStatusTransition, err)
+	}
+	vacs, err := db.ListVacations(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vacs) != 1 {
+		t.Fatalf("expected a single vacation, got: %d", len(vacs))
+	}
+}
+
+func TestInmemoryDB_CancelVacationRequest(t *testing.T) {
+	ctx := context.Background()
+	db := NewInmemoryDB()
+	from := time.Date(2022, time.April, 4, 0, 0, 0, 0, time.UTC)
+	approverID := "approver-id"
+	cancel := func(removeVacation bool) {
+		t.Helper()
+		vR, err := db.CreateVacationRequest(ctx, &model.VacationRequest{UserID: "user-id", From: from, To: from})
+		if err != nil {
+			t.Fatal(err)
+		}
+		vR, vac, err := db.ApproveVacationRequest(ctx, vR.ID, &model.Vacation{UserID: vR.UserID, ApprovedBy: &approverID, From: vR.From, To: vR.To})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if removeVacation {
+			if err = db.DeleteVacation(ctx, vac.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+		cancelled, err := db.CancelVacationRequest(ctx, vR.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cancelled.Status != model.StatusCancelled {
+			t.Fatalf("expected cancelled request, got: %s", cancelled.Status)
+		}
+		if _, err = db.GetVacationByID(ctx, vac.ID); err == nil {
+			t.Fatal("expected vacation to be deleted")
+		}
+		_, err = db.CancelVacationRequest(ctx, vR.ID)
+		if !errors.Is(err, model.ErrInvalidStatusTransition) {
+			t.Fatalf("expected %v, got: %v", model.ErrInvalidStatusTransition, err)
+		}
+	}
+	cancel(false)
+	// NOTE: a vacation, which is already removed, does not block the cancel.
+	from = from.AddDate(0, 0, 7)
+	cancel(true)
+}
+
+func TestInmemoryDB_DeleteVacationRequest(t *testing.T) {
 	tt := []struct {
-		name                  string
-		vacationResource      *model.VacationResource
-		vacationResourceStore []*model.VacationResource
-		vacationResourceCount int
-		wantErr               bool
+		name                 string
+		vacationRequestID    string
+		vacationRequestStore []*model.VacationRequest
+		wantErr              bool
 	}{
 		{
-			name:                  "missing userID",
-			vacationResource:      &model.VacationResource{},
-			vacationResourceCount: 1,
-			wantErr:               true,
+			name:              "vacationRequest does not exist",
+			vacationRequestID: "does-not-exist",
+			wantErr:           true,
 		},
 		{
-			name: "creation matches parent",
-			vacationResourceStore: []*model.VacationResource{
+			name: "get vacationRequest by id as expected",
+			vacationRequestStore: []*model.VacationRequest{
+				{
+					ID: "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+				},
+			},
+			vacationRequestID: "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+			wantErr:           false,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			db := NewInmemoryDB()
+			if tc.vacationRequestStore != nil {
+				db.vacationRequestStore = tc.vacationRequestStore
+			}
+			expectCount := len(tc.vacationRequestStore) - 1
+			err := db.DeleteVacationRequest(context.Background(), tc.vacationRequestID)
+			if err != nil && !tc.wantErr {
+				t.Fatal(err)
+			} else if err != nil && tc.wantErr {
+				return
+			}
+
+			vacationRequests, err := db.ListVacationRequests(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expectCount != len(vacationRequests) {
+				t.Fatalf("invalid count, want: %d, got: %d", expectCount, len(vacationRequests))
+			}
+			deleted, err := db.GetVacationRequestByID(context.Background(), tc.vacationRequestID, query.IncludeDeleted(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted.DeletedAt == nil {
+				t.Fatal("missing deletion time")
+			}
+		})
+	}
+}
+
+func TestInmemoryDB_CreateVacationResource(t *testing.T) {
+	tt := []struct {
+		name                  string
+		vacationResource      *model.VacationResource
+		vacationResourceStore []*model.VacationResource
+		vacationResourceCount int
+		wantErr               bool
+	}{
+		{
+			name:                  "missing userID",
+			vacationResource:      &model.VacationResource{},
+			vacationResourceCount: 1,
+			wantErr:               true,
+		},
+		{
+			name: "creation matches parent",
+			vacationResourceStore: []*model.VacationResource{
 				{
 					ID: "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
 				},
@@ -1184,6 +1706,54 @@ func TestInmemoryDB_CreateVacationResource(t *testing.T) {
 			vacationResourceCount: 2,
 			wantErr:               false,
 		},
+		{
+			name: "overlapping resource",
+			vacationResourceStore: []*model.VacationResource{
+				{
+					ID:     "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+					UserID: "some-user-id",
+					From:   time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			vacationResource: &model.VacationResource{
+				UserID: "some-user-id",
+				From:   time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: true,
+		},
+		{
+			name: "following resource",
+			vacationResourceStore: []*model.VacationResource{
+				{
+					ID:     "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+					UserID: "some-user-id",
+					From:   time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC),
+					To:     time.Date(2022, time.December, 31, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			vacationResource: &model.VacationResource{
+				UserID: "some-user-id",
+				From:   time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+			},
+			vacationResourceCount: 2,
+		},
+		{
+			name: "carry-over resource",
+			vacationResourceStore: []*model.VacationResource{
+				{
+					ID:     "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+					UserID: "some-user-id",
+					From:   time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			vacationResource: &model.VacationResource{
+				UserID:      "some-user-id",
+				From:        time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+				To:          time.Date(2023, time.March, 31, 0, 0, 0, 0, time.UTC),
+				CarriedDays: 5,
+			},
+			vacationResourceCount: 2,
+		},
 	}
 
 	for _, tc := range tt {
@@ -1323,6 +1893,76 @@ func TestInmemoryDB_ListVacationResource(t *testing.T) {
 	}
 }
 
+func TestInmemoryDB_UpdateVacationResource(t *testing.T) {
+	tt := []struct {
+		name             string
+		vacationResource *model.VacationResource
+		wantErr          bool
+	}{
+		{
+			name: "vacationResource does not exist",
+			vacationResource: &model.VacationResource{
+				ID: "does-not-exist",
+			},
+			wantErr: true,
+		},
+		{
+			name: "overlap with following resource",
+			vacationResource: &model.VacationResource{
+				ID:         "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+				YearlyDays: 30,
+				From:       time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: true,
+		},
+		{
+			name: "update as expected",
+			vacationResource: &model.VacationResource{
+				ID:         "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+				YearlyDays: 25,
+				From:       time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC),
+				To:         time.Date(2022, time.June, 30, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			db := NewInmemoryDB()
+			db.vacationResourceStore = []*model.VacationResource{
+				{
+					ID:         "f95128f7-733d-48b3-9306-cc5fe27cf6a5",
+					UserID:     "some-user-id",
+					YearlyDays: 30,
+					From:       time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC),
+					To:         time.Date(2022, time.December, 31, 0, 0, 0, 0, time.UTC),
+				},
+				{
+					ID:         "fed75474-29df-4d99-a792-09f0bf7ae848",
+					UserID:     "some-user-id",
+					YearlyDays: 30,
+					From:       time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+				},
+			}
+			updated, err := db.UpdateVacationResource(context.Background(), tc.vacationResource)
+			if err != nil && !tc.wantErr {
+				t.Fatal(err)
+			} else if err != nil && tc.wantErr {
+				return
+			} else if tc.wantErr {
+				t.Fatal("expected error")
+			}
+
+			if updated.YearlyDays != tc.vacationResource.YearlyDays || !updated.To.Equal(tc.vacationResource.To) {
+				t.Fatalf("resource not updated: %+v", updated)
+			}
+			if updated.UpdatedAt == nil {
+				t.Error("missing timestamp updated_at")
+			}
+		})
+	}
+}
+
 func TestInmemoryDB_DeleteVacationResource(t *testing.T) {
 	tt := []struct {
 		name                  string
@@ -1361,8 +2001,494 @@ func TestInmemoryDB_DeleteVacationResource(t *testing.T) {
 				return
 			}
 
-			if expectCount != len(db.vacationResourceStore) {
-				t.Fatalf("invalid count, want: %d, got: %d", expectCount, len(db.vacationResourceStore))
+			vacationResources, err := db.ListVacationResource(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expectCount != len(vacationResources) {
+				t.Fatalf("invalid count, want: %d, got: %d", expectCount, len(vacationResources))
+			}
+			deleted, err := db.GetVacationResourceByID(context.Background(), tc.vacationResourceID, query.IncludeDeleted(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted.DeletedAt == nil {
+				t.Fatal("missing deletion time")
+			}
+		})
+	}
+}
+
+func TestInmemoryDB_CreateAbsenceType(t *testing.T) {
+	tt := []struct {
+		name        string
+		absenceType *model.AbsenceType
+		wantErr     bool
+	}{
+		{
+			name:        "missing name",
+			absenceType: &model.AbsenceType{},
+			wantErr:     true,
+		},
+		{
+			name: "creation expected",
+			absenceType: &model.AbsenceType{
+				Name:             "Wedding",
+				RequiresApproval: true,
+				VisibleToTeam:    true,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			db := NewInmemoryDB()
+			expectCount := len(db.absenceTypeStore) + 1
+			newAbsenceType, err := db.CreateAbsenceType(context.Background(), tc.absenceType)
+			if err != nil && !tc.wantErr {
+				t.Fatal(err)
+			} else if err != nil && tc.wantErr {
+				return
+			}
+
+			if expectCount != len(db.absenceTypeStore) {
+				t.Fatalf("invalid count, want: %d, got: %d", expectCount, len(db.absenceTypeStore))
+			}
+
+			_, err = uuid.Parse(newAbsenceType.ID)
+			if err != nil {
+				t.Error(err)
+			}
+
+			got, err := db.GetAbsenceTypeByID(context.Background(), newAbsenceType.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(newAbsenceType, got) {
+				t.Fatal(cmp.Diff(newAbsenceType, got))
+			}
+		})
+	}
+}
+
+func TestInmemoryDB_UpdateAbsenceType(t *testing.T) {
+	tt := []struct {
+		name        string
+		absenceType *model.AbsenceType
+		wantErr     bool
+	}{
+		{
+			name: "absence-type does not exist",
+			absenceType: &model.AbsenceType{
+				ID:   "does-not-exist",
+				Name: "Wedding",
+			},
+			wantErr: true,
+		},
+		{
+			name: "update as expected",
+			absenceType: &model.AbsenceType{
+				ID:            model.AbsenceTypeSickLeave,
+				Name:          "Sick",
+				VisibleToTeam: true,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			db := NewInmemoryDB()
+			updated, err := db.UpdateAbsenceType(context.Background(), tc.absenceType)
+			if err != nil && !tc.wantErr {
+				t.Fatal(err)
+			} else if err != nil && tc.wantErr {
+				return
+			}
+
+			if updated.UpdatedAt == nil {
+				t.Error("missing timestamp updated_at")
+			}
+			ignoreFields := cmp.FilterPath(func(p cmp.Path) bool {
+				return strings.Contains(p.String(), "At") ||
+					strings.Contains(p.String(), "Version")
+			}, cmp.Ignore())
+			if !cmp.Equal(tc.absenceType, updated, ignoreFields) {
+				t.Fatal(cmp.Diff(tc.absenceType, updated, ignoreFields))
+			}
+		})
+	}
+}
+
+func TestInmemoryDB_CreateDelegation(t *testing.T) {
+	from := time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC)
+	tt := []struct {
+		name       string
+		delegation *model.Delegation
+		wantErr    bool
+	}{
+		{
+			name:       "missing delegate",
+			delegation: &model.Delegation{DelegatorID: "manager-id", From: from, To: from},
+			wantErr:    true,
+		},
+		{
+			name:       "delegate to itself",
+			delegation: &model.Delegation{DelegatorID: "manager-id", DelegateID: "manager-id", From: from, To: from},
+			wantErr:    true,
+		},
+		{
+			name:       "ends before start",
+			delegation: &model.Delegation{DelegatorID: "manager-id", DelegateID: "deputy-id", From: from, To: from.AddDate(0, 0, -1)},
+			wantErr:    true,
+		},
+		{
+			name:       "creation expected",
+			delegation: &model.Delegation{DelegatorID: "manager-id", DelegateID: "deputy-id", From: from, To: from.AddDate(0, 0, 13)},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			db := NewInmemoryDB()
+			newDelegation, err := db.CreateDelegation(context.Background(), tc.delegation)
+			if err != nil && !tc.wantErr {
+				t.Fatal(err)
+			} else if err != nil && tc.wantErr {
+				return
+			}
+			if tc.wantErr {
+				t.Fatal("expected error")
+			}
+
+			if len(db.delegationStore) != 1 {
+				t.Fatalf("invalid count, want: %d, got: %d", 1, len(db.delegationStore))
+			}
+
+			_, err = uuid.Parse(newDelegation.ID)
+			if err != nil {
+				t.Error(err)
+			}
+
+			got, err := db.GetDelegationByID(context.Background(), newDelegation.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(newDelegation, got) {
+				t.Fatal(cmp.Diff(newDelegation, got))
+			}
+		})
+	}
+}
+
+func TestInmemoryDB_CreateTeamRule(t *testing.T) {
+	from := time.Date(2022, time.December, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, time.December, 15, 0, 0, 0, 0, time.UTC)
+	tt := []struct {
+		name    string
+		rule    *model.TeamRule
+		wantErr bool
+	}{
+		{
+			name:    "missing team",
+			rule:    &model.TeamRule{Name: "two present", Kind: model.TeamRuleMinStaffing, MinPresent: 2},
+			wantErr: true,
+		},
+		{
+			name:    "blackout without period",
+			rule:    &model.TeamRule{TeamID: "team-id", Name: "freeze", Kind: model.TeamRuleBlackout},
+			wantErr: true,
+		},
+		{
+			name: "creation expected",
+			rule: &model.TeamRule{TeamID: "team-id", Name: "freeze", Kind: model.TeamRuleBlackout, From: &from, To: &to, Yearly: true},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			db := NewInmemoryDB()
+			newRule, err := db.CreateTeamRule(context.Background(), tc.rule)
+			if err != nil && !tc.wantErr {
+				t.Fatal(err)
+			} else if err != nil && tc.wantErr {
+				return
+			}
+			if tc.wantErr {
+				t.Fatal("expected error")
+			}
+
+			if len(db.teamRuleStore) != 1 {
+				t.Fatalf("invalid count, want: %d, got: %d", 1, len(db.teamRuleStore))
+			}
+
+			_, err = uuid.Parse(newRule.ID)
+			if err != nil {
+				t.Error(err)
+			}
+
+			got, err := db.GetTeamRuleByID(context.Background(), newRule.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(newRule, got) {
+				t.Fatal(cmp.Diff(newRule, got))
+			}
+		})
+	}
+}
+
+func TestInmemoryDB_CreateComment(t *testing.T) {
+	tt := []struct {
+		name            string
+		vacationRequest *model.VacationRequest
+		comment         *model.Comment
+		wantErr         bool
+	}{
+		{
+			name:    "vacation-request does not exist",
+			comment: &model.Comment{VacationRequestID: "does-not-exist", AuthorID: "author-id", Text: "can you move it one week?"},
+			wantErr: true,
+		},
+		{
+			name:            "missing text",
+			vacationRequest: &model.VacationRequest{ID: "request-id", UserID: "user-id"},
+			comment:         &model.Comment{VacationRequestID: "request-id", AuthorID: "author-id"},
+			wantErr:         true,
+		},
+		{
+			name:            "creation expected",
+			vacationRequest: &model.VacationRequest{ID: "request-id", UserID: "user-id"},
+			comment:         &model.Comment{VacationRequestID: "request-id", AuthorID: "author-id", Text: "can you move it one week?"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			db := NewInmemoryDB()
+			if tc.vacationRequest != nil {
+				db.vacationRequestStore = append(db.vacationRequestStore, tc.vacationRequest)
+			}
+			newComment, err := db.CreateComment(context.Background(), tc.comment)
+			if err != nil && !tc.wantErr {
+				t.Fatal(err)
+			} else if err != nil && tc.wantErr {
+				return
+			}
+			if tc.wantErr {
+				t.Fatal("expected error")
+			}
+
+			_, err = uuid.Parse(newComment.ID)
+			if err != nil {
+				t.Error(err)
+			}
+
+			got, err := db.ListComments(context.Background(), tc.comment.VacationRequestID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []*model.Comment{newComment}
+			if !cmp.Equal(want, got) {
+				t.Fatal(cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
+func TestInmemoryDB_CreateTeamMembership(t *testing.T) {
+	ctx := context.Background()
+	db := NewInmemoryDB()
+	owner, err := db.CreateUser(ctx, &model.User{Email: "owner@inform.de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend, err := db.CreateTeam(ctx, &model.Team{Name: "backend", OwnerID: owner.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	frontend, err := db.CreateTeam(ctx, &model.Team{Name: "frontend", OwnerID: owner.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := db.CreateUser(ctx, &model.User{Email: "user@inform.de", TeamID: &backend.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	memberships, err := db.ListUserTeamMemberships(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(memberships) != 1 || memberships[0].TeamID != backend.ID || memberships[0].Allocation != model.FullAllocation {
+		t.Fatalf("expected full membership of the primary team, got: %+v", memberships)
+	}
+
+	_, err = db.CreateTeamMembership(ctx, &model.TeamMembership{TeamID: frontend.ID, UserID: user.ID, Allocation: 50})
+	if !errors.Is(err, model.ErrOverAllocated) {
+		t.Fatalf("expected %v, got: %v", model.ErrOverAllocated, err)
+	}
+	_, err = db.CreateTeamMembership(ctx, &model.TeamMembership{TeamID: backend.ID, UserID: user.ID})
+	if !errors.Is(err, model.ErrInvalidMembership) {
+		t.Fatalf("expected %v, got: %v", model.ErrInvalidMembership, err)
+	}
+
+	primary := memberships[0]
+	primary.Allocation = 50
+	if _, err = db.UpdateTeamMembership(ctx, primary); err != nil {
+		t.Fatal(err)
+	}
+	split, err := db.CreateTeamMembership(ctx, &model.TeamMembership{TeamID: frontend.ID, UserID: user.ID, Role: model.TeamRoleLead, Allocation: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, teamID := range []string{backend.ID, frontend.ID} {
+		users, err := db.ListTeamUsers(ctx, teamID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(users) != 1 || users[0].ID != user.ID {
+			t.Fatalf("expected user to be member of team %s, got: %+v", teamID, users)
+		}
+	}
+
+	if err = db.DeleteTeamMembership(ctx, split.ID); err != nil {
+		t.Fatal(err)
+	}
+	users, err := db.ListTeamUsers(ctx, frontend.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 0 {
+		t.Fatalf("expected no members, got: %d", len(users))
+	}
+}
+
+func TestInmemoryDB_Restore(t *testing.T) {
+	ctx := context.Background()
+	db := NewInmemoryDB()
+	user, err := db.CreateUser(ctx, &model.User{Email: "user@inform.de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Restore(ctx, model.EntityUser, user.ID)
+	if !errors.Is(err, model.ErrNotDeleted) {
+		t.Fatalf("expected %v, got: %v", model.ErrNotDeleted, err)
+	}
+	if err = db.DeleteUser(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.UpdateUser(ctx, &model.User{ID: user.ID, FirstName: "deleted"}); err == nil {
+		t.Fatal("expected update of deleted user to fail")
+	}
+	if _, err = db.GetUserByID(ctx, user.ID); err == nil {
+		t.Fatal("expected deleted user to be hidden")
+	}
+	if err = db.Restore(ctx, model.EntityUser, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := db.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.DeletedAt != nil {
+		t.Fatalf("expected no deletion time, got: %v", restored.DeletedAt)
+	}
+
+	err = db.Restore(ctx, model.EntityUser, "does-not-exist")
+	if !errors.Is(err, model.ErrEntityNotFound) {
+		t.Fatalf("expected %v, got: %v", model.ErrEntityNotFound, err)
+	}
+	err = db.Restore(ctx, model.EntityComment, user.ID)
+	if !errors.Is(err, model.ErrUnknownEntity) {
+		t.Fatalf("expected %v, got: %v", model.ErrUnknownEntity, err)
+	}
+}
+
+func TestInmemoryDB_UpdateVersion(t *testing.T) {
+	ctx := context.Background()
+	db := NewInmemoryDB()
+	owner, err := db.CreateUser(ctx, &model.User{Email: "owner@inform.de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	team, err := db.CreateTeam(ctx, &model.Team{OwnerID: owner.ID, Name: "team"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if team.Version != 1 {
+		t.Fatalf("expected version 1, got: %d", team.Version)
+	}
+	updated, err := db.UpdateTeam(ctx, &model.Team{ID: team.ID, Name: "first", Version: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Version != 2 {
+		t.Fatalf("expected version 2, got: %d", updated.Version)
+	}
+	_, err = db.UpdateTeam(ctx, &model.Team{ID: team.ID, Name: "stale", Version: 1})
+	if !errors.Is(err, model.ErrVersionConflict) {
+		t.Fatalf("expected %v, got: %v", model.ErrVersionConflict, err)
+	}
+	stored, err := db.GetTeamByID(ctx, team.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Name != "first" {
+		t.Fatalf("expected stale update to be rejected, got name: %s", stored.Name)
+	}
+	updated, err = db.UpdateTeam(ctx, &model.Team{ID: team.ID, Name: "unconditional"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Version != 3 {
+		t.Fatalf("expected version 3, got: %d", updated.Version)
+	}
+}
+
+func TestInmemoryDB_Purge(t *testing.T) {
+	now := time.Now()
+	deletedAt := now.AddDate(0, 0, -10)
+	tt := []struct {
+		name          string
+		deletedAt     *time.Time
+		deletedBefore time.Time
+		wantErr       error
+	}{
+		{
+			name:          "not deleted",
+			deletedBefore: now,
+			wantErr:       model.ErrNotDeleted,
+		},
+		{
+			name:          "within retention period",
+			deletedAt:     &deletedAt,
+			deletedBefore: now.AddDate(0, 0, -30),
+			wantErr:       model.ErrRetentionPeriod,
+		},
+		{
+			name:          "purge as expected",
+			deletedAt:     &deletedAt,
+			deletedBefore: now,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := NewInmemoryDB()
+			db.teamRuleStore = []*model.TeamRule{{ID: "rule", TeamID: "team", DeletedAt: tc.deletedAt}}
+			err := db.Purge(ctx, model.EntityTeamRule, "rule", tc.deletedBefore)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected %v, got: %v", tc.wantErr, err)
+			}
+			rules, err := db.ListTeamRules(ctx, "team", query.IncludeDeleted(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantCount := 1
+			if tc.wantErr == nil {
+				wantCount = 0
+			}
+			if len(rules) != wantCount {
+				t.Fatalf("invalid count, want: %d, got: %d", wantCount, len(rules))
 			}
 		})
 	}
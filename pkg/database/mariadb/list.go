@@ -0,0 +1,141 @@
+package mariadb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MninaTB/vacadm/pkg/database/query"
+)
+
+// listColumns maps the filters and sort fields of a list to the columns of its
+// table. Filters without column are not supported by the list.
+type listColumns struct {
+	id     string
+	user   string
+	member string
+	deputy string
+	status string
+	from   string
+	to     string
+	sorts  map[query.SortField]string
+}
+
+var (
+	userColumns = listColumns{
+		id:     "id",
+		member: "id",
+		sorts: map[query.SortField]string{
+			query.SortCreatedAt: "created_at",
+			query.SortFirstName: "firstname",
+			query.SortLastName:  "lastname",
+			query.SortEmail:     "email",
+		},
+	}
+
+	teamColumns = listColumns{
+		id: "id",
+		sorts: map[query.SortField]string{
+			query.SortCreatedAt: "created_at",
+			query.SortName:      "name",
+		},
+	}
+
+	vacationColumns = listColumns{
+		id:     "vacation.id",
+		user:   "vacation.user_id",
+		member: "vacation.user_id",
+		from:   "vacation.`from`",
+		to:     "vacation.`to`",
+		sorts: map[query.SortField]string{
+			query.SortCreatedAt: "vacation.created_at",
+			query.SortFrom:      "vacation.`from`",
+			query.SortTo:        "vacation.`to`",
+		},
+	}
+
+	vacationRequestColumns = listColumns{
+		id:     "id",
+		user:   "user_id",
+		member: "user_id",
+		deputy: "deputy_id",
+		status: "status",
+		from:   "`from`",
+		to:     "`to`",
+		sorts: map[query.SortField]string{
+			query.SortCreatedAt: "created_at",
+			query.SortFrom:      "`from`",
+			query.SortTo:        "`to`",
+		},
+	}
+
+	vacationResourceColumns = listColumns{
+		id:     "id",
+		user:   "user_id",
+		member: "user_id",
+		from:   "`from`",
+		to:     "`to`",
+		sorts: map[query.SortField]string{
+			query.SortCreatedAt: "created_at",
+			query.SortFrom:      "`from`",
+		},
+	}
+)
+
+// listSelect appends the filters, order and limit of the given options to the
+// given select, which ends with the soft delete condition. Returns the
+// statement and its arguments.
+func listSelect(stmt string, c listColumns, o query.Options) (string, []interface{}, error) {
+	var b strings.Builder
+	b.WriteString(stmt)
+	args := []interface{}{o.IncludeDeleted}
+	and := func(cond string, condArgs ...interface{}) {
+		b.WriteString(" AND ")
+		b.WriteString(cond)
+		args = append(args, condArgs...)
+	}
+	f := o.Filter
+	if c.user != "" && f.UserID != "" {
+		and(c.user+" = ?", f.UserID)
+	}
+	if c.member != "" && f.TeamID != "" {
+		and(c.member+" IN (SELECT user_id FROM team_membership WHERE team_id = ? AND deleted_at IS NULL)", f.TeamID)
+	}
+	if c.deputy != "" && f.DeputyID != "" {
+		and(c.deputy+" = ?", f.DeputyID)
+	}
+	if c.status != "" && len(f.Status) > 0 {
+		placeholders := make([]string, 0, len(f.Status))
+		for _, s := range f.Status {
+			placeholders = append(placeholders, "?")
+			args = append(args, s)
+		}
+		and(c.status + " IN (" + strings.Join(placeholders, ", ") + ")")
+	}
+	if c.to != "" && f.From != nil {
+		and("("+c.to+" IS NULL OR "+c.to+" >= ?)", *f.From)
+	}
+	if c.from != "" && f.To != nil {
+		and(c.from+" <= ?", *f.To)
+	}
+	if !o.Paged() {
+		return b.String(), args, nil
+	}
+	column, ok := c.sorts[o.SortField()]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %s", query.ErrUnknownSortField, o.SortField())
+	}
+	op, dir := ">", "ASC"
+	if o.Desc {
+		op, dir = "<", "DESC"
+	}
+	if o.After != nil {
+		and(fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND %[3]s %[2]s ?))", column, op, c.id),
+			o.After.Key, o.After.Key, o.After.ID)
+	}
+	fmt.Fprintf(&b, " ORDER BY %s %s, %s %s", column, dir, c.id, dir)
+	if o.Limit > 0 {
+		b.WriteString(" LIMIT ?")
+		args = append(args, o.Limit)
+	}
+	return b.String(), args, nil
+}
@@ -3,10 +3,15 @@ package mariadb
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/sirupsen/logrus"
 
+	"github.com/MninaTB/vacadm/pkg/database/query"
 	"github.com/MninaTB/vacadm/pkg/model"
 )
 
@@ -16,12 +21,14 @@ const (
 			id, parent_id,
 			team_id, email,
 			firstname, lastname,
+			holiday_calendar, role,
 			created_at
 		)
 		VALUES (
 			UUID(), ?,
 			?, ?,
 			?, ?,
+			?, ?,
 			NOW()
 		) RETURNING id, created_at
 	`
@@ -31,14 +38,24 @@ const (
 			id,
 			parent_id,
 			team_id,
-			created_at, updated_at,
+			created_at, updated_at, deleted_at,
 			firstname, lastname,
-			email
+			email, holiday_calendar,
+			role, version
 		FROM user
 	`
 
+	userSelect = basicUserSelect + `
+		WHERE (deleted_at IS NULL OR ?)
+	`
+
 	userSelectByID = basicUserSelect + `
-		WHERE id = ?
+		WHERE id = ? AND (deleted_at IS NULL OR ?)
+	`
+
+	userSelectForUpdate = basicUserSelect + `
+		WHERE deleted_at IS NULL
+		FOR UPDATE
 	`
 
 	userUpdate = `
@@ -46,8 +63,18 @@ const (
 		SET
 			parent_id = ?, team_id = ?,
 			firstname = ?, lastname = ?,
-			email = ?, updated_at = NOW()
-		WHERE id = ?
+			email = ?, holiday_calendar = ?,
+			role = COALESCE(NULLIF(?, ''), role),
+			updated_at = NOW(),
+			version = version + 1
+		WHERE id = ? AND deleted_at IS NULL AND (? = 0 OR version = ?)
+		RETURNING updated_at, version
+	`
+
+	userTeamSelectForUpdate = `
+		SELECT team_id FROM user
+		WHERE id = ? AND deleted_at IS NULL
+		FOR UPDATE
 	`
 
 	userDelete = `
@@ -55,45 +82,72 @@ const (
 		SET
 			updated_at = NOW(),
 			deleted_at = Now()
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
 	teamCreate = `
 		INSERT INTO team (
 			id,
-			owner_id, name,
+			owner_id, parent_id, name,
+			holiday_calendar,
 			created_at
 		)
 		VALUES (
 			UUID(),
-			?, ?,
+			?, ?, ?,
+			?,
 			NOW()
-		)RETURNING id, created_at
+		) RETURNING id, created_at
 	`
 
 	basicTeamSelect = `
 		SELECT
 			id,
-			owner_id, name,
-			created_at, updated_at,
+			owner_id, parent_id, name,
+			holiday_calendar,
+			created_at, updated_at, deleted_at,
+			version
 		FROM team
 	`
 
+	teamSelect = basicTeamSelect + `
+		WHERE (deleted_at IS NULL OR ?)
+	`
+
 	teamSelectByID = basicTeamSelect + `
-		WHERE id = ?
+		WHERE id = ? AND (deleted_at IS NULL OR ?)
+	`
+
+	teamSelectForUpdate = basicTeamSelect + `
+		WHERE deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	teamCountSubTeams = `
+		SELECT COUNT(*)
+		FROM team
+		WHERE parent_id = ? AND deleted_at IS NULL
 	`
 
 	teamUserSelectByID = basicUserSelect + `
-		WHERE team_id = ?
+		WHERE id IN (
+			SELECT user_id
+			FROM team_membership
+			WHERE team_id = ? AND deleted_at IS NULL
+		) AND (deleted_at IS NULL OR ?)
 	`
 
 	teamUpdate = `
 		UPDATE team
 		SET
 			owner_id = ?,
+			parent_id = ?,
 			name = ?,
-			updated_at = NOW()
-		WHERE id = ?
+			holiday_calendar = ?,
+			updated_at = NOW(),
+			version = version + 1
+		WHERE id = ? AND deleted_at IS NULL AND (? = 0 OR version = ?)
+		RETURNING updated_at, version
 	`
 
 	teamDelete = `
@@ -101,57 +155,75 @@ const (
 		SET
 			updated_at = NOW(),
 			deleted_at = Now()
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
 	vacationCreate = `
-	INSERT INTO vacation (
-		id, user_id,
-		from, to,
-		created_at
-	)
-	VALUES (
-		UUID(), ?
-		?, ?
-		NOW()
-	) RETURNING id, created_at
-`
+		INSERT INTO vacation (
+			id, user_id,
+			approved_id, approved_on_behalf_of, absence_type_id,
+			deputy_id,
+			from, to,
+			portion, hours,
+			created_at
+		)
+		VALUES (
+			UUID(), ?,
+			?, ?, ?,
+			?,
+			?, ?,
+			?, ?,
+			NOW()
+		) RETURNING id, created_at
+	`
 
 	basicVacationSelect = `
 		SELECT
-			id,
-			user_id,
-			approved_id,
-			from, to,
-			created_at
+			vacation.id,
+			vacation.user_id,
+			vacation.approved_id, vacation.approved_on_behalf_of, vacation.absence_type_id,
+			vacation.deputy_id,
+			vacation.from, vacation.to,
+			vacation.portion, vacation.hours,
+			vacation.created_at, vacation.deleted_at
 		FROM vacation
 	`
 
-	teamVacationSelect = basicVacationSelect + `
-		INNER JOIN user ON vacation.user_id=user.id
-		WHERE user.team_id = ?
+	vacationSelect = basicVacationSelect + `
+		WHERE (vacation.deleted_at IS NULL OR ?)
 	`
 
 	vacationSelectByID = basicVacationSelect + `
-		WHERE id = ?
+		WHERE vacation.id = ? AND (vacation.deleted_at IS NULL OR ?)
+	`
+
+	vacationSelectByUserForUpdate = basicVacationSelect + `
+		WHERE vacation.user_id = ? AND vacation.deleted_at IS NULL
+		FOR UPDATE
 	`
 
 	vacationDelete = `
 		UPDATE vacation
 		SET
 			deleted_at = Now()
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
 	vacationRequestCreate = `
-		INSERT INTO vaccation_request (
+		INSERT INTO vacation_request (
 			id, user_id,
+			status, absence_type_id,
+			deputy_id, deputy_status,
 			from, to,
+			portion, hours,
 			created_at
 		)
 		VALUES (
-			UUID(), ?
-			?, ?
+			UUID(), ?,
+			?, ?,
+			?, ?,
+			?, ?,
+			?, ?,
 			NOW()
 		) RETURNING id, created_at
 	`
@@ -160,22 +232,48 @@ const (
 		SELECT
 			id,
 			user_id,
+			status, vacation_id, absence_type_id,
+			rejected_by, rejected_on_behalf_of, rejection_reason,
+			approval_steps, rule_violations,
+			deputy_id, deputy_status,
 			from, to,
-			created_at, updated_at,
+			portion, hours,
+			created_at, updated_at, deleted_at,
+			version
 		FROM vacation_request
 	`
 
+	vacationRequestSelect = basicVacationRequestSelect + `
+		WHERE (deleted_at IS NULL OR ?)
+	`
+
 	vacationRequestSelectByID = basicVacationRequestSelect + `
-		WHERE id = ?
+		WHERE id = ? AND (deleted_at IS NULL OR ?)
+	`
+
+	vacationRequestSelectForUpdate = basicVacationRequestSelect + `
+		WHERE id = ? AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	vacationRequestSelectBlockingForUpdate = basicVacationRequestSelect + `
+		WHERE user_id = ? AND status IN ('pending', 'approved') AND deleted_at IS NULL
+		FOR UPDATE
 	`
 
 	vacationRequestUpdate = `
 		UPDATE vacation_request
 		SET
-			user_id = ?,
+			status = ?, vacation_id = ?,
+			rejected_by = ?, rejected_on_behalf_of = ?, rejection_reason = ?,
+			approval_steps = ?, rule_violations = ?,
+			deputy_id = ?, deputy_status = ?,
 			from = ?, to = ?,
-			updated_at = NOW()
-		WHERE id = ?
+			portion = ?, hours = ?,
+			updated_at = NOW(),
+			version = version + 1
+		WHERE id = ? AND deleted_at IS NULL AND (? = 0 OR version = ?)
+		RETURNING updated_at, version
 	`
 
 	vacationRequestDelete = `
@@ -183,18 +281,22 @@ const (
 		SET
 			updated_at = NOW(),
 			deleted_at = Now()
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
 	vacationResourceCreate = `
 		INSERT INTO vacation_resource (
 			id,
 			user_id, yearly_days,
+			from, to,
+			carried_days,
 			created_at
 		)
 		VALUES (
 			UUID(),
-			?, ?
+			?, ?,
+			?, ?,
+			?,
 			NOW()
 		) RETURNING id, created_at
 	`
@@ -205,33 +307,400 @@ const (
 			user_id,
 			yearly_days,
 			from, to,
-			created_at, updated_at,
+			carried_days,
+			created_at, updated_at, deleted_at,
+			version
 		FROM vacation_resource
 	`
 
+	vacationResourceSelect = basicVacationResourceSelect + `
+		WHERE (deleted_at IS NULL OR ?)
+	`
+
 	vacationResourceSelectByID = basicVacationResourceSelect + `
-		WHERE id = ?
+		WHERE id = ? AND (deleted_at IS NULL OR ?)
+	`
+
+	vacationResourceSelectByIDForUpdate = basicVacationResourceSelect + `
+		WHERE id = ? AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	vacationResourceSelectByUserForUpdate = basicVacationResourceSelect + `
+		WHERE user_id = ? AND deleted_at IS NULL
+		FOR UPDATE
 	`
 
 	vacationResourceUpdate = `
 		UPDATE vacation_resource
 		SET
-			user_id = ?,
 			yearly_days = ?,
 			from = ?, to = ?,
-			updated_at = NOW()
-		WHERE id = ?
+			carried_days = ?,
+			updated_at = NOW(),
+			version = version + 1
+		WHERE id = ? AND deleted_at IS NULL AND (? = 0 OR version = ?)
+		RETURNING updated_at, version
 	`
 
 	vacationResourceDelete = `
-		UPDATE vacationResource
+		UPDATE vacation_resource
 		SET
 			updated_at = NOW(),
 			deleted_at = Now()
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	absenceTypeCreate = `
+		INSERT INTO absence_type (
+			id, name,
+			requires_approval, deducts_vacation,
+			visible_to_team,
+			created_at
+		)
+		VALUES (
+			UUID(), ?,
+			?, ?,
+			?,
+			NOW()
+		) RETURNING id, created_at
+	`
+
+	basicAbsenceTypeSelect = `
+		SELECT
+			id, name,
+			requires_approval, deducts_vacation,
+			visible_to_team,
+			created_at, updated_at, deleted_at,
+			version
+		FROM absence_type
+	`
+
+	absenceTypeSelect = basicAbsenceTypeSelect + `
+		WHERE deleted_at IS NULL OR ?
+	`
+
+	absenceTypeSelectByID = basicAbsenceTypeSelect + `
+		WHERE id = ? AND (deleted_at IS NULL OR ?)
+	`
+
+	absenceTypeUpdate = `
+		UPDATE absence_type
+		SET
+			name = ?,
+			requires_approval = ?, deducts_vacation = ?,
+			visible_to_team = ?,
+			updated_at = NOW(),
+			version = version + 1
+		WHERE id = ? AND deleted_at IS NULL AND (? = 0 OR version = ?)
+		RETURNING created_at, updated_at, version
+	`
+
+	absenceTypeDelete = `
+		UPDATE absence_type
+		SET
+			updated_at = NOW(),
+			deleted_at = NOW()
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	delegationCreate = `
+		INSERT INTO delegation (
+			id,
+			delegator_id, delegate_id,
+			from, to,
+			created_at
+		)
+		VALUES (
+			UUID(),
+			?, ?,
+			?, ?,
+			NOW()
+		) RETURNING id, created_at
+	`
+
+	basicDelegationSelect = `
+		SELECT
+			id,
+			delegator_id, delegate_id,
+			from, to,
+			created_at, updated_at, deleted_at
+		FROM delegation
+	`
+
+	delegationSelect = basicDelegationSelect + `
+		WHERE deleted_at IS NULL OR ?
+	`
+
+	delegationSelectByID = basicDelegationSelect + `
+		WHERE id = ? AND (deleted_at IS NULL OR ?)
+	`
+
+	delegationDelete = `
+		UPDATE delegation
+		SET
+			updated_at = NOW(),
+			deleted_at = NOW()
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	teamRuleCreate = `
+		INSERT INTO team_rule (
+			id, team_id,
+			name, kind, blocking,
+			min_present,
+			from, to, yearly,
+			created_at
+		)
+		VALUES (
+			UUID(), ?,
+			?, ?, ?,
+			?,
+			?, ?, ?,
+			NOW()
+		) RETURNING id, created_at
+	`
+
+	basicTeamRuleSelect = `
+		SELECT
+			id, team_id,
+			name, kind, blocking,
+			min_present,
+			from, to, yearly,
+			created_at, updated_at, deleted_at,
+			version
+		FROM team_rule
+	`
+
+	teamRuleSelectByID = basicTeamRuleSelect + `
+		WHERE id = ? AND (deleted_at IS NULL OR ?)
+	`
+
+	teamRuleSelectByTeam = basicTeamRuleSelect + `
+		WHERE team_id = ? AND (deleted_at IS NULL OR ?)
+	`
+
+	teamRuleSelectForUpdate = basicTeamRuleSelect + `
+		WHERE id = ? AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	teamRuleUpdate = `
+		UPDATE team_rule
+		SET
+			name = ?, kind = ?, blocking = ?,
+			min_present = ?,
+			from = ?, to = ?, yearly = ?,
+			updated_at = NOW(),
+			version = version + 1
+		WHERE id = ? AND deleted_at IS NULL AND (? = 0 OR version = ?)
+		RETURNING updated_at, version
+	`
+
+	teamRuleDelete = `
+		UPDATE team_rule
+		SET
+			updated_at = NOW(),
+			deleted_at = NOW()
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	commentCreate = `
+		INSERT INTO comment (
+			id, vacation_request_id, author_id,
+			text,
+			created_at
+		)
+		VALUES (
+			UUID(), ?, ?,
+			?,
+			NOW()
+		) RETURNING id, created_at
+	`
+
+	commentSelectByVacationRequest = `
+		SELECT
+			id, vacation_request_id, author_id,
+			text,
+			created_at, updated_at, deleted_at
+		FROM comment
+		WHERE vacation_request_id = ? AND (deleted_at IS NULL OR ?)
+		ORDER BY created_at
+	`
+
+	attachmentCreate = `
+		INSERT INTO attachment (
+			id, resource_kind, resource_id, user_id,
+			file_name, content_type, size, checksum,
+			created_at
+		)
+		VALUES (
+			UUID(), ?, ?, ?,
+			?, ?, ?, ?,
+			NOW()
+		) RETURNING id, created_at
+	`
+
+	basicAttachmentSelect = `
+		SELECT
+			id, resource_kind, resource_id, user_id,
+			file_name, content_type, size, checksum,
+			created_at, updated_at, deleted_at
+		FROM attachment
+	`
+
+	attachmentSelectByID = basicAttachmentSelect + `
+		WHERE id = ? AND (deleted_at IS NULL OR ?)
+	`
+
+	attachmentSelectByResource = basicAttachmentSelect + `
+		WHERE resource_kind = ? AND resource_id = ? AND (deleted_at IS NULL OR ?)
+	`
+
+	teamMembershipCreate = `
+		INSERT INTO team_membership (
+			id, team_id, user_id,
+			role, allocation,
+			created_at
+		)
+		VALUES (
+			UUID(), ?, ?,
+			?, ?,
+			NOW()
+		) RETURNING id, created_at
+	`
+
+	basicTeamMembershipSelect = `
+		SELECT
+			id, team_id, user_id,
+			role, allocation,
+			created_at, updated_at, deleted_at,
+			version
+		FROM team_membership
+	`
+
+	teamMembershipSelectByID = basicTeamMembershipSelect + `
+		WHERE id = ? AND (deleted_at IS NULL OR ?)
+	`
+
+	teamMembershipSelectByTeam = basicTeamMembershipSelect + `
+		WHERE team_id = ? AND (deleted_at IS NULL OR ?)
+	`
+
+	teamMembershipSelectByUser = basicTeamMembershipSelect + `
+		WHERE user_id = ? AND (deleted_at IS NULL OR ?)
+	`
+
+	teamMembershipSelectByUserForUpdate = basicTeamMembershipSelect + `
+		WHERE user_id = ? AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	teamMembershipUpdate = `
+		UPDATE team_membership
+		SET
+			role = ?, allocation = ?,
+			updated_at = NOW(),
+			version = version + 1
+		WHERE id = ? AND deleted_at IS NULL AND (? = 0 OR version = ?)
+		RETURNING updated_at, version
+	`
+
+	teamMembershipDelete = `
+		UPDATE team_membership
+		SET
+			updated_at = NOW(),
+			deleted_at = NOW()
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	attachmentDelete = `
+		UPDATE attachment
+		SET
+			updated_at = NOW(),
+			deleted_at = NOW()
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	auditEntryCreate = `
+		INSERT INTO audit_entry (
+			id, actor_id, action,
+			entity, entity_id,
+			before_json, after_json,
+			request_id, created_at
+		)
+		VALUES (
+			UUID(), ?, ?,
+			?, ?,
+			?, ?,
+			?, NOW(6)
+		) RETURNING id, created_at
+	`
+
+	// NOTE: empty filters match every entry.
+	auditEntrySelect = `
+		SELECT
+			id, actor_id, action,
+			entity, entity_id,
+			before_json, after_json,
+			request_id, created_at
+		FROM audit_entry
+		WHERE (? = '' OR actor_id = ?)
+			AND (? = '' OR action = ?)
+			AND (? = '' OR entity = ?)
+			AND (? = '' OR entity_id = ?)
+			AND (? = '' OR request_id = ?)
+			AND (? IS NULL OR created_at >= ?)
+			AND (? IS NULL OR created_at <= ?)
+		ORDER BY created_at
+	`
+
+	// NOTE: the table of the following queries is inserted per entity, see
+	// tables.
+	entitySelectDeletedAt = `
+		SELECT deleted_at
+		FROM %s
+		WHERE id = ?
+		FOR UPDATE
+	`
+
+	entityRestore = `
+		UPDATE %s
+		SET
+			deleted_at = NULL
+		WHERE id = ?
+	`
+
+	entityPurge = `
+		DELETE FROM %s
 		WHERE id = ?
 	`
+
+	entitySelectVersion = `
+		SELECT version
+		FROM %s
+		WHERE id = ? AND deleted_at IS NULL
+	`
 )
 
+// tables maps the entities, which can be restored and purged, to their tables.
+var tables = map[model.Entity]string{
+	model.EntityUser:             "user",
+	model.EntityTeam:             "team",
+	model.EntityVacation:         "vacation",
+	model.EntityVacationRequest:  "vacation_request",
+	model.EntityVacationResource: "vacation_resource",
+	model.EntityAbsenceType:      "absence_type",
+	model.EntityDelegation:       "delegation",
+	model.EntityTeamRule:         "team_rule",
+	model.EntityTeamMembership:   "team_membership",
+	model.EntityAttachment:       "attachment",
+}
+
+// errRowIsReferenced is the error number of MariaDB for deletions violating a
+// foreign key.
+const errRowIsReferenced = 1451
+
 // NewMariaDB returns initialized MariaDB that fulfills
 // the database interface.
 func NewMariaDB(db *sql.DB) *MariaDB {
@@ -248,111 +717,116 @@ type MariaDB struct {
 }
 
 // CreateUser stores an internal copy of the given user, if email address is
-// not already in use, given parentID and/or teamID exists.
+// not already in use, given parentID and/or teamID exists. The user becomes a
+// fully allocated member of the given team.
 // Returns copy with assigned userID.
 func (m *MariaDB) CreateUser(ctx context.Context, u *model.User) (*model.User, error) {
-	row, err := m.db.QueryContext(ctx, userCreate, u.ParentID, u.TeamID, u.Email, u.FirstName, u.LastName)
+	role, err := model.ParseRole(string(u.Role))
 	if err != nil {
 		return nil, err
 	}
-	err = row.Err()
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return nil, err
 	}
+	if u.ParentID != nil {
+		err = validateUserParent(ctx, tx, "", u.ParentID)
+		if err != nil {
+			return nil, rollback(tx, err)
+		}
+	}
 	var id string
 	var createdAt time.Time
-	row.Next()
-	err = row.Scan(&id, &createdAt)
+	err = tx.QueryRowContext(ctx, userCreate, u.ParentID, u.TeamID, u.Email, u.FirstName, u.LastName, u.HolidayCalendar, role).Scan(&id, &createdAt)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+	if u.TeamID != nil {
+		_, err = tx.ExecContext(ctx, teamMembershipCreate, *u.TeamID, id, model.TeamRoleMember, model.FullAllocation)
+		if err != nil {
+			return nil, rollback(tx, err)
+		}
+	}
+	err = tx.Commit()
 	if err != nil {
 		return nil, err
 	}
 	u.ID = id
+	u.Role = role
 	u.CreatedAt = &createdAt
+	u.Version = 1
 	return u, nil
 }
 
 // GetUserByID returns the associated user by the given id.
-func (m *MariaDB) GetUserByID(ctx context.Context, uuid string) (*model.User, error) {
-	row := m.db.QueryRowContext(ctx, userSelectByID, uuid)
-	err := row.Err()
+func (m *MariaDB) GetUserByID(ctx context.Context, uuid string, opts ...query.Option) (*model.User, error) {
+	o := query.New(opts...)
+	return scanUser(m.db.QueryRowContext(ctx, userSelectByID, uuid, o.IncludeDeleted))
+}
+
+// ListUsers returns a copy of the internal user list.
+func (m *MariaDB) ListUsers(ctx context.Context, opts ...query.Option) ([]*model.User, error) {
+	stmt, args, err := listSelect(userSelect, userColumns, query.New(opts...))
 	if err != nil {
 		return nil, err
 	}
-	u := &model.User{}
-	var parentID, teamID sql.NullString
-	var createdAt, updatedAt sql.NullTime
-	err = row.Scan(&u.ID, &parentID, &teamID, &createdAt, &updatedAt, &u.FirstName, &u.LastName, &u.Email)
+	return m.listUsers(ctx, stmt, args...)
+}
+
+func (m *MariaDB) listUsers(ctx context.Context, stmt string, args ...interface{}) ([]*model.User, error) {
+	users := make([]*model.User, 0)
+	rows, err := m.db.QueryContext(ctx, stmt, args...)
 	if err != nil {
 		return nil, err
 	}
-	if parentID.Valid {
-		u.ParentID = &parentID.String
-	}
-	if teamID.Valid {
-		u.TeamID = &teamID.String
-	}
-	if createdAt.Valid {
-		u.CreatedAt = &createdAt.Time
+	defer rows.Close()
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
 	}
-	if updatedAt.Valid {
-		u.UpdatedAt = &updatedAt.Time
-	}
-	return u, nil
+	return users, rows.Err()
 }
 
-// ListUsers returns a copy of the internal user list.
-func (m *MariaDB) ListUsers(ctx context.Context) ([]*model.User, error) {
-	allusr := make([]*model.User, 0)
-	rows, err := m.db.QueryContext(ctx, basicUserSelect)
-	if err != nil {
-		return nil, err
-	}
-	var parentID, teamID sql.NullString
-	var createdAt, updatedAt sql.NullTime
-	for rows.Next() {
-		u := model.User{}
-		err = rows.Scan(&u.ID, &parentID, &teamID, &createdAt, &updatedAt, &u.FirstName, &u.LastName, &u.Email)
-		if err != nil {
+// UpdateUser updates user entry by the given user.
+func (m *MariaDB) UpdateUser(ctx context.Context, u *model.User) (*model.User, error) {
+	if u.Role != "" {
+		if _, err := model.ParseRole(string(u.Role)); err != nil {
 			return nil, err
 		}
-		if parentID.Valid {
-			u.ParentID = &parentID.String
-		}
-		if teamID.Valid {
-			u.TeamID = &teamID.String
-		}
-		if createdAt.Valid {
-			u.CreatedAt = &createdAt.Time
-		}
-		if updatedAt.Valid {
-			u.UpdatedAt = &updatedAt.Time
-		}
-		allusr = append(allusr, &u)
 	}
-	return allusr, nil
-}
-
-// UpdateUser updates user entry by the given user.
-func (m *MariaDB) UpdateUser(ctx context.Context, u *model.User) (*model.User, error) {
 	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return nil, err
 	}
-	_, err = tx.ExecContext(ctx, userUpdate, u.ParentID, u.TeamID, u.FirstName, u.LastName, u.Email, u.ID)
-	if err != nil {
-		if errTX := tx.Rollback(); err != nil {
-			return nil, errTX
+	if u.ParentID != nil {
+		err = validateUserParent(ctx, tx, u.ID, u.ParentID)
+		if err != nil {
+			return nil, rollback(tx, err)
+		}
+	}
+	var previousTeamID *string
+	if u.TeamID != nil {
+		err = tx.QueryRowContext(ctx, userTeamSelectForUpdate, u.ID).Scan(&previousTeamID)
+		if err != nil {
+			return nil, rollback(tx, err)
 		}
-		return nil, err
 	}
-	row := tx.QueryRowContext(ctx, "SELECT updated_at WHERE id = ?", u.ID)
 	var updatedAt time.Time
-	err = row.Scan(&updatedAt)
+	err = tx.QueryRowContext(ctx, userUpdate,
+		u.ParentID, u.TeamID, u.FirstName, u.LastName, u.Email, u.HolidayCalendar, u.Role,
+		u.ID, u.Version, u.Version,
+	).Scan(&updatedAt, &u.Version)
 	if err != nil {
-		if errTX := tx.Rollback(); err != nil {
-			return nil, errTX
+		return nil, rollback(tx, staleVersion(ctx, tx, model.EntityUser, u.ID, u.Version, err))
+	}
+	if u.TeamID != nil {
+		err = ensureTeamMembership(ctx, tx, previousTeamID, *u.TeamID, u.ID)
+		if err != nil {
+			return nil, rollback(tx, err)
 		}
-		return nil, err
 	}
 	err = tx.Commit()
 	if err != nil {
@@ -362,7 +836,29 @@ func (m *MariaDB) UpdateUser(ctx context.Context, u *model.User) (*model.User, e
 	return u, nil
 }
 
-// DeleteUser removes user entry by the given id.
+// validateUserParent locks all active users and verifies, that parentID refers
+// to an active user outside of the reports of userID.
+func validateUserParent(ctx context.Context, tx *sql.Tx, userID string, parentID *string) error {
+	rows, err := tx.QueryContext(ctx, userSelectForUpdate)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var users []*model.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return err
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return model.ValidateUserParent(users, userID, parentID)
+}
+
+// DeleteUser marks user entry by the given id as deleted.
 func (m *MariaDB) DeleteUser(ctx context.Context, uuid string) error {
 	row := m.db.QueryRowContext(ctx, userDelete, uuid)
 	err := row.Err()
@@ -375,276 +871,234 @@ func (m *MariaDB) DeleteUser(ctx context.Context, uuid string) error {
 // CreateTeam stores an internal copy of the given team.
 // Returns copy with assigned teamID.
 func (m *MariaDB) CreateTeam(ctx context.Context, t *model.Team) (*model.Team, error) {
-	row, err := m.db.QueryContext(ctx, teamCreate, t.OwnerID, t.Name)
+	if t.ParentID != nil && *t.ParentID == "" {
+		t.ParentID = nil
+	}
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return nil, err
 	}
-	err = row.Err()
+	teams, err := selectTeamsForUpdate(ctx, tx)
 	if err != nil {
-		return nil, err
+		return nil, rollback(tx, err)
+	}
+	err = model.ValidateTeamParent(teams, "", t.ParentID)
+	if err != nil {
+		return nil, rollback(tx, err)
 	}
 	var id string
 	var createdAt time.Time
-	row.Next()
-	err = row.Scan(&id, &createdAt)
+	err = tx.QueryRowContext(ctx, teamCreate, t.OwnerID, t.ParentID, t.Name, t.HolidayCalendar).Scan(&id, &createdAt)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+	err = tx.Commit()
 	if err != nil {
 		return nil, err
 	}
 	t.ID = id
 	t.CreatedAt = &createdAt
+	t.Version = 1
 	return t, nil
 }
 
 // GetTeamByID returns the associated team by the given id.
-func (m *MariaDB) GetTeamByID(ctx context.Context, uuid string) (*model.Team, error) {
-	row := m.db.QueryRowContext(ctx, teamSelectByID, uuid)
-	err := row.Err()
-	if err != nil {
-		return nil, err
-	}
-	t := &model.Team{}
-	var createdAt, updatedAt sql.NullTime
-	err = row.Scan(&t.ID, &t.OwnerID, &t.Name, &createdAt, &updatedAt)
-	if err != nil {
-		return nil, err
-	}
-	if createdAt.Valid {
-		t.CreatedAt = &createdAt.Time
-	}
-	if updatedAt.Valid {
-		t.UpdatedAt = &updatedAt.Time
-	}
-	return t, nil
+func (m *MariaDB) GetTeamByID(ctx context.Context, uuid string, opts ...query.Option) (*model.Team, error) {
+	o := query.New(opts...)
+	return scanTeam(m.db.QueryRowContext(ctx, teamSelectByID, uuid, o.IncludeDeleted))
 }
 
 // ListTeams returns a copy of the internal team list.
-func (m *MariaDB) ListTeams(ctx context.Context) ([]*model.Team, error) {
+func (m *MariaDB) ListTeams(ctx context.Context, opts ...query.Option) ([]*model.Team, error) {
+	stmt, args, err := listSelect(teamSelect, teamColumns, query.New(opts...))
+	if err != nil {
+		return nil, err
+	}
 	allTeams := make([]*model.Team, 0)
-	rows, err := m.db.QueryContext(ctx, basicTeamSelect)
+	rows, err := m.db.QueryContext(ctx, stmt, args...)
 	if err != nil {
 		return nil, err
 	}
-	var createdAt, updatedAt sql.NullTime
+	defer rows.Close()
 	for rows.Next() {
-		t := model.Team{}
-		err = rows.Scan(&t.ID, &t.OwnerID, t.Name, &createdAt, &updatedAt)
+		t, err := scanTeam(rows)
 		if err != nil {
 			return nil, err
 		}
-		if createdAt.Valid {
-			t.CreatedAt = &createdAt.Time
-		}
-		if updatedAt.Valid {
-			t.UpdatedAt = &updatedAt.Time
-		}
-		allTeams = append(allTeams, &t)
+		allTeams = append(allTeams, t)
 	}
-	return allTeams, nil
+	return allTeams, rows.Err()
 }
 
 // ListTeamUsers returns a list of users associated by the given teamID
-func (m *MariaDB) ListTeamUsers(ctx context.Context, uuid string) ([]*model.User, error) {
-	teamUser := make([]*model.User, 0)
-	rows, err := m.db.QueryContext(ctx, teamUserSelectByID, uuid)
+// through their team memberships.
+func (m *MariaDB) ListTeamUsers(ctx context.Context, uuid string, opts ...query.Option) ([]*model.User, error) {
+	o := query.New(opts...)
+	return m.listUsers(ctx, teamUserSelectByID, uuid, o.IncludeDeleted)
+}
+
+// UpdateTeam updates team entry by the given team. Empty fields keep their
+// current value, an empty parentID moves the team to the top level.
+func (m *MariaDB) UpdateTeam(ctx context.Context, t *model.Team) (*model.Team, error) {
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return nil, err
 	}
-	var parentID, teamID sql.NullString
-	var createdAt, updatedAt sql.NullTime
-	for rows.Next() {
-		u := model.User{}
-		err = rows.Scan(&u.ID, &parentID, &teamID, &createdAt, &updatedAt, &u.FirstName, &u.LastName, &u.Email)
-		if err != nil {
-			return nil, err
+	teams, err := selectTeamsForUpdate(ctx, tx)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+	updated := t.Copy()
+	for _, current := range teams {
+		if current.ID != t.ID {
+			continue
 		}
-		if parentID.Valid {
-			u.ParentID = &parentID.String
+		updated = current.Copy()
+		if t.OwnerID != "" {
+			updated.OwnerID = t.OwnerID
 		}
-		if teamID.Valid {
-			u.TeamID = &teamID.String
+		if t.ParentID != nil {
+			updated.ParentID = t.ParentID
 		}
-		if createdAt.Valid {
-			u.CreatedAt = &createdAt.Time
+		if t.Name != "" {
+			updated.Name = t.Name
 		}
-		if updatedAt.Valid {
-			u.UpdatedAt = &updatedAt.Time
+		if t.HolidayCalendar != nil {
+			updated.HolidayCalendar = t.HolidayCalendar
 		}
-		teamUser = append(teamUser, &u)
 	}
-	return teamUser, nil
-}
-
-// UpdateTeam updates team entry by the given team.
-func (m *MariaDB) UpdateTeam(ctx context.Context, t *model.Team) (*model.Team, error) {
-	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
+	err = model.ValidateTeamParent(teams, t.ID, updated.ParentID)
 	if err != nil {
-		return nil, err
+		return nil, rollback(tx, err)
 	}
-	_, err = tx.ExecContext(ctx, teamUpdate, t.OwnerID, t.Name, t.ID)
-	if err != nil {
-		if errTX := tx.Rollback(); err != nil {
-			return nil, errTX
-		}
-		return nil, err
+	if updated.ParentID != nil && *updated.ParentID == "" {
+		updated.ParentID = nil
 	}
-	row := tx.QueryRowContext(ctx, "SELECT updated_at WHERE id = ?", t.ID)
 	var updatedAt time.Time
-	err = row.Scan(&updatedAt)
+	err = tx.QueryRowContext(ctx, teamUpdate,
+		updated.OwnerID, updated.ParentID, updated.Name, updated.HolidayCalendar,
+		t.ID, t.Version, t.Version,
+	).Scan(&updatedAt, &updated.Version)
 	if err != nil {
-		if errTX := tx.Rollback(); err != nil {
-			return nil, errTX
-		}
-		return nil, err
+		return nil, rollback(tx, staleVersion(ctx, tx, model.EntityTeam, t.ID, t.Version, err))
 	}
 	err = tx.Commit()
 	if err != nil {
 		return nil, err
 	}
-	t.UpdatedAt = &updatedAt
-	return t, nil
+	updated.UpdatedAt = &updatedAt
+	return updated, nil
 }
 
-// DeleteTeam removes team entry by the given id.
+// DeleteTeam marks team entry by the given id as deleted. Teams with
+// sub-teams can not be removed.
 func (m *MariaDB) DeleteTeam(ctx context.Context, uuid string) error {
-	row := m.db.QueryRowContext(ctx, teamDelete, uuid)
-	err := row.Err()
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return err
 	}
-	return err
+	_, err = selectTeamsForUpdate(ctx, tx)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	var subTeams int
+	err = tx.QueryRowContext(ctx, teamCountSubTeams, uuid).Scan(&subTeams)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	if subTeams > 0 {
+		return rollback(tx, fmt.Errorf("%w: %s", model.ErrTeamHasSubTeams, uuid))
+	}
+	_, err = tx.ExecContext(ctx, teamDelete, uuid)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	return tx.Commit()
 }
 
-// CreateVacation stores an internal copy of the given vacation resource.
-// Returns copy with assigned vacationID.
-func (m *MariaDB) CreateVacation(ctx context.Context, v *model.Vacation) (*model.Vacation, error) {
-	row, err := m.db.QueryContext(ctx, vacationCreate, v.UserID, v.ApprovedBy, v.From, v.To)
+// selectTeamsForUpdate returns all active teams. The teams are locked until
+// the given transaction ends, this serializes changes of the team hierarchy.
+func selectTeamsForUpdate(ctx context.Context, tx *sql.Tx) ([]*model.Team, error) {
+	teams := make([]*model.Team, 0)
+	rows, err := tx.QueryContext(ctx, teamSelectForUpdate)
 	if err != nil {
 		return nil, err
 	}
-	err = row.Err()
-	if err != nil {
+	defer rows.Close()
+	for rows.Next() {
+		t, err := scanTeam(rows)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, t)
+	}
+	return teams, rows.Err()
+}
+
+// CreateVacation stores an internal copy of the given vacation.
+// Returns copy with assigned vacationID.
+func (m *MariaDB) CreateVacation(ctx context.Context, v *model.Vacation) (*model.Vacation, error) {
+	if err := v.Validate(); err != nil {
 		return nil, err
 	}
+	row := m.db.QueryRowContext(ctx, vacationCreate,
+		v.UserID, v.ApprovedBy, v.ApprovedOnBehalfOf, v.AbsenceTypeID, v.DeputyID, v.From, v.To, v.Portion, v.Hours,
+	)
 	var id string
-	var createdAt, from, to time.Time
-	row.Next()
-	err = row.Scan(&id, &createdAt, &from, &to)
+	var createdAt time.Time
+	err := row.Scan(&id, &createdAt)
 	if err != nil {
 		return nil, err
 	}
 	v.ID = id
 	v.CreatedAt = &createdAt
-	v.From = from
-	v.To = to
 	return v, nil
 }
 
 // GetVacationByID returns the associated vacation by the given id.
-func (m *MariaDB) GetVacationByID(ctx context.Context, uuid string) (*model.Vacation, error) {
-	row := m.db.QueryRowContext(ctx, vacationSelectByID, uuid)
-	err := row.Err()
-	if err != nil {
-		return nil, err
-	}
-	v := &model.Vacation{}
-	var createdAt, from, to sql.NullTime
-	var userID sql.NullString
-	var approvedID sql.NullString
-	err = row.Scan(&v.ID, &createdAt, &from, &to, &userID, &approvedID)
-	if err != nil {
-		return nil, err
-	}
-	if createdAt.Valid {
-		v.CreatedAt = &createdAt.Time
-	}
-	if from.Valid {
-		v.From = from.Time
-	}
-	if to.Valid {
-		v.To = to.Time
-	}
-	if userID.Valid {
-		v.UserID = userID.String
-	}
-	if approvedID.Valid {
-		v.ApprovedBy = &approvedID.String
-	}
-	return v, nil
+func (m *MariaDB) GetVacationByID(ctx context.Context, uuid string, opts ...query.Option) (*model.Vacation, error) {
+	o := query.New(opts...)
+	return scanVacation(m.db.QueryRowContext(ctx, vacationSelectByID, uuid, o.IncludeDeleted))
+}
+
+// GetVacationsByUserID returns list of vacations of one user by given userID.
+func (m *MariaDB) GetVacationsByUserID(ctx context.Context, id string, opts ...query.Option) ([]*model.Vacation, error) {
+	return m.ListVacations(ctx, query.With(opts, query.UserID(id))...)
 }
 
-// GetVacationByTeamID returns the list of vacations of one team by given teamID.
-func (m *MariaDB) GetVacationsByTeamID(ctx context.Context, tID string) ([]*model.Vacation, error) {
-	teamVacations := make([]*model.Vacation, 0)
-	rows, err := m.db.QueryContext(ctx, teamVacationSelect, tID)
+// GetVacationsByTeamID returns the list of vacations of the members of one
+// team by given teamID.
+func (m *MariaDB) GetVacationsByTeamID(ctx context.Context, tID string, opts ...query.Option) ([]*model.Vacation, error) {
+	return m.ListVacations(ctx, query.With(opts, query.TeamID(tID))...)
+}
+
+// ListVacations returns a copy of the internal vacation list.
+func (m *MariaDB) ListVacations(ctx context.Context, opts ...query.Option) ([]*model.Vacation, error) {
+	stmt, args, err := listSelect(vacationSelect, vacationColumns, query.New(opts...))
 	if err != nil {
 		return nil, err
 	}
-	var createdAt, from, to sql.NullTime
-	var userID sql.NullString
-	var approvedID sql.NullString
-	for rows.Next() {
-		v := model.Vacation{}
-		err = rows.Scan(&v.ID, &createdAt, &from, &to, &userID, &approvedID)
-		if err != nil {
-			return nil, err
-		}
-		if createdAt.Valid {
-			v.CreatedAt = &createdAt.Time
-		}
-		if from.Valid {
-			v.From = from.Time
-		}
-		if to.Valid {
-			v.To = from.Time
-		}
-		if userID.Valid {
-			v.UserID = userID.String
-		}
-		if approvedID.Valid {
-			v.ApprovedBy = &approvedID.String
-		}
-		teamVacations = append(teamVacations, &v)
-	}
-	return teamVacations, nil
+	return m.listVacations(ctx, stmt, args...)
 }
 
-// ListVacations returns a copy of the internal vacation list.
-func (m *MariaDB) ListVacations(ctx context.Context) ([]*model.Vacation, error) {
-	allVacations := make([]*model.Vacation, 0)
-	rows, err := m.db.QueryContext(ctx, basicVacationSelect)
+func (m *MariaDB) listVacations(ctx context.Context, stmt string, args ...interface{}) ([]*model.Vacation, error) {
+	vacations := make([]*model.Vacation, 0)
+	rows, err := m.db.QueryContext(ctx, stmt, args...)
 	if err != nil {
 		return nil, err
 	}
-	var createdAt, from, to sql.NullTime
-	var userID sql.NullString
-	var approvedID sql.NullString
+	defer rows.Close()
 	for rows.Next() {
-		v := model.Vacation{}
-		err = rows.Scan(&v.ID, &createdAt, &from, &to, &userID, &approvedID)
+		v, err := scanVacation(rows)
 		if err != nil {
 			return nil, err
 		}
-		if createdAt.Valid {
-			v.CreatedAt = &createdAt.Time
-		}
-		if from.Valid {
-			v.From = from.Time
-		}
-		if to.Valid {
-			v.To = from.Time
-		}
-		if userID.Valid {
-			v.UserID = userID.String
-		}
-		if approvedID.Valid {
-			v.ApprovedBy = &approvedID.String
-		}
-		allVacations = append(allVacations, &v)
+		vacations = append(vacations, v)
 	}
-	return allVacations, nil
+	return vacations, rows.Err()
 }
 
-// DeleteVacation removes vacation entry by the given id.
+// DeleteVacation marks vacation entry by the given id as deleted.
 func (m *MariaDB) DeleteVacation(ctx context.Context, uuid string) error {
 	row := m.db.QueryRowContext(ctx, vacationDelete, uuid)
 	err := row.Err()
@@ -655,252 +1109,404 @@ func (m *MariaDB) DeleteVacation(ctx context.Context, uuid string) error {
 }
 
 // CreateVacationRequest stores an internal copy of the given vacationRequest.
+// A request starts either as draft or pending, if no status is given pending
+// is assumed. The request must not overlap with blocking requests or vacations
+// of the same user.
 // Returns copy with assigned vacationRequestID.
 func (m *MariaDB) CreateVacationRequest(ctx context.Context, v *model.VacationRequest) (*model.VacationRequest, error) {
-	row, err := m.db.QueryContext(ctx, vacationRequestCreate, v.UserID, v.From, v.To)
-	if err != nil {
+	if err := v.Validate(); err != nil {
 		return nil, err
 	}
-	err = row.Err()
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return nil, err
 	}
+	err = checkAbsenceOverlap(ctx, tx, v)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+	row := tx.QueryRowContext(ctx, vacationRequestCreate,
+		v.UserID, v.Status, v.AbsenceTypeID, v.DeputyID, v.DeputyStatus, v.From, v.To, v.Portion, v.Hours,
+	)
 	var id string
-	var createdAt, from, to time.Time
-	row.Next()
-	err = row.Scan(&id, &createdAt, &from, &to)
+	var createdAt time.Time
+	err = row.Scan(&id, &createdAt)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+	err = tx.Commit()
 	if err != nil {
 		return nil, err
 	}
 	v.ID = id
 	v.CreatedAt = &createdAt
-	v.From = from
-	v.To = to
+	v.Version = 1
 	return v, nil
 }
 
 // GetVacationRequestByID returns the associated vacationRequest by the given id.
-func (m *MariaDB) GetVacationRequestByID(ctx context.Context, uuid string) (*model.VacationRequest, error) {
-	row := m.db.QueryRowContext(ctx, vacationRequestSelectByID, uuid)
-	err := row.Err()
-	if err != nil {
-		return nil, err
-	}
-	v := &model.VacationRequest{}
-	var userID sql.NullString
-	var createdAt, updatedAt, from, to sql.NullTime
-	err = row.Scan(&v.ID, &userID, &createdAt, &updatedAt, &from, &to)
-	if err != nil {
-		return nil, err
-	}
-	if userID.Valid {
-		v.UserID = userID.String
-	}
-	if createdAt.Valid {
-		v.CreatedAt = &createdAt.Time
-	}
-	if updatedAt.Valid {
-		v.UpdatedAt = &updatedAt.Time
-	}
-	if from.Valid {
-		v.From = from.Time
-	}
-	if to.Valid {
-		v.To = to.Time
-	}
-	return v, nil
+func (m *MariaDB) GetVacationRequestByID(ctx context.Context, uuid string, opts ...query.Option) (*model.VacationRequest, error) {
+	o := query.New(opts...)
+	return scanVacationRequest(m.db.QueryRowContext(ctx, vacationRequestSelectByID, uuid, o.IncludeDeleted))
 }
 
 // ListVacationRequests returns a copy of the internal vacationRequest list.
-func (m *MariaDB) ListVacationRequests(ctx context.Context) ([]*model.VacationRequest, error) {
+func (m *MariaDB) ListVacationRequests(ctx context.Context, opts ...query.Option) ([]*model.VacationRequest, error) {
+	stmt, args, err := listSelect(vacationRequestSelect, vacationRequestColumns, query.New(opts...))
+	if err != nil {
+		return nil, err
+	}
 	allVacationRequests := make([]*model.VacationRequest, 0)
-	rows, err := m.db.QueryContext(ctx, basicVacationRequestSelect)
+	rows, err := m.db.QueryContext(ctx, stmt, args...)
 	if err != nil {
 		return nil, err
 	}
-	var userID sql.NullString
-	var createdAt, updatedAt, from, to sql.NullTime
+	defer rows.Close()
 	for rows.Next() {
-		v := model.VacationRequest{}
-		err = rows.Scan(&v.ID, &createdAt, &updatedAt, &from, &to, &userID)
+		v, err := scanVacationRequest(rows)
 		if err != nil {
 			return nil, err
 		}
-		if createdAt.Valid {
-			v.CreatedAt = &createdAt.Time
-		}
-		if from.Valid {
-			v.From = from.Time
-		}
-		if to.Valid {
-			v.To = from.Time
-		}
-		if userID.Valid {
-			v.UserID = userID.String
-		}
-		allVacationRequests = append(allVacationRequests, &v)
+		allVacationRequests = append(allVacationRequests, v)
 	}
-	return allVacationRequests, nil
+	return allVacationRequests, rows.Err()
+}
+
+// GetVacationRequestsByUserID returns list of vacationRequests of one user by
+// given userID.
+func (m *MariaDB) GetVacationRequestsByUserID(ctx context.Context, id string, opts ...query.Option) ([]*model.VacationRequest, error) {
+	return m.ListVacationRequests(ctx, query.With(opts, query.UserID(id))...)
+}
+
+// GetVacationRequestsByTeamID returns list of vacationRequests of the members
+// of one team by given teamID.
+func (m *MariaDB) GetVacationRequestsByTeamID(ctx context.Context, tID string, opts ...query.Option) ([]*model.VacationRequest, error) {
+	return m.ListVacationRequests(ctx, query.With(opts, query.TeamID(tID))...)
 }
 
 // UpdateVacationRequest updates vacationRequest entry by the given vacationRequest.
+// Status changes must follow the vacation-request lifecycle, the period can
+// only be changed as long as the request is a draft or pending. Drafts and
+// pending requests must not overlap with blocking requests or vacations of the
+// same user.
 func (m *MariaDB) UpdateVacationRequest(ctx context.Context, v *model.VacationRequest) (*model.VacationRequest, error) {
 	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return nil, err
 	}
-	_, err = tx.ExecContext(ctx, vacationRequestUpdate, v.UserID, v.From, v.To, v.ID)
+	updated, err := scanVacationRequest(tx.QueryRowContext(ctx, vacationRequestSelectForUpdate, v.ID))
 	if err != nil {
-		if errTX := tx.Rollback(); err != nil {
-			return nil, errTX
-		}
-		return nil, err
+		return nil, rollback(tx, err)
 	}
-	row := tx.QueryRowContext(ctx, "SELECT updated_at WHERE id = ?", v.ID)
-	var updatedAt time.Time
-	err = row.Scan(&updatedAt)
+	err = updated.Update(v)
 	if err != nil {
-		if errTX := tx.Rollback(); err != nil {
-			return nil, errTX
+		return nil, rollback(tx, err)
+	}
+	if updated.Status.Editable() {
+		err = checkAbsenceOverlap(ctx, tx, updated)
+		if err != nil {
+			return nil, rollback(tx, err)
 		}
-		return nil, err
+	}
+	err = updateVacationRequest(ctx, tx, updated, v.Version)
+	if err != nil {
+		return nil, rollback(tx, err)
 	}
 	err = tx.Commit()
 	if err != nil {
 		return nil, err
 	}
-	v.UpdatedAt = &updatedAt
-	return v, nil
+	return updated, nil
 }
 
-// DeleteVacationRequest removes vacationRequest entry by the given id.
-func (m *MariaDB) DeleteVacationRequest(ctx context.Context, uuid string) error {
-	row := m.db.QueryRowContext(ctx, vacationRequestDelete, uuid)
-	err := row.Err()
+// updateVacationRequest writes all fields of the given vacationRequest within
+// the given transaction, if its stored version matches the given version. A
+// version of 0 skips the check. Updated timestamp and version are assigned to
+// the given vacationRequest.
+func updateVacationRequest(ctx context.Context, tx *sql.Tx, v *model.VacationRequest, version int) error {
+	steps, err := encodeJSON(v.ApprovalSteps, v.ApprovalSteps == nil)
 	if err != nil {
 		return err
 	}
-	return err
+	violations, err := encodeJSON(v.RuleViolations, v.RuleViolations == nil)
+	if err != nil {
+		return err
+	}
+	var updatedAt time.Time
+	err = tx.QueryRowContext(ctx, vacationRequestUpdate,
+		v.Status, v.VacationID,
+		v.RejectedBy, v.RejectedOnBehalfOf, v.RejectionReason,
+		steps, violations,
+		v.DeputyID, v.DeputyStatus,
+		v.From, v.To,
+		v.Portion, v.Hours,
+		v.ID, version, version,
+	).Scan(&updatedAt, &v.Version)
+	if err != nil {
+		return staleVersion(ctx, tx, model.EntityVacationRequest, v.ID, version, err)
+	}
+	v.UpdatedAt = &updatedAt
+	return nil
 }
 
-// CreateVacationResource stores an internal copy of the given vacationResource.
-// Returns copy with assigned vacationResourceID.
-func (m *MariaDB) CreateVacationResource(ctx context.Context, v *model.VacationResource) (*model.VacationResource, error) {
-	row, err := m.db.QueryContext(ctx, vacationResourceCreate, v.UserID, v.YearlyDays)
+// ApproveVacationRequest moves the vacationRequest by the given id to approved
+// and stores the given vacation, which is linked to the request. Both happen
+// in one transaction.
+func (m *MariaDB) ApproveVacationRequest(ctx context.Context, uuid string, v *model.Vacation) (*model.VacationRequest, *model.Vacation, error) {
+	if err := v.Validate(); err != nil {
+		return nil, nil, err
+	}
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	err = row.Err()
+	updated, err := scanVacationRequest(tx.QueryRowContext(ctx, vacationRequestSelectForUpdate, uuid))
 	if err != nil {
-		return nil, err
+		return nil, nil, rollback(tx, err)
+	}
+	err = updated.Approve()
+	if err != nil {
+		return nil, nil, rollback(tx, err)
 	}
-	var id string
 	var createdAt time.Time
-	row.Next()
-	err = row.Scan(&id, &createdAt)
+	err = tx.QueryRowContext(ctx, vacationCreate,
+		v.UserID, v.ApprovedBy, v.ApprovedOnBehalfOf, v.AbsenceTypeID, v.DeputyID, v.From, v.To, v.Portion, v.Hours,
+	).Scan(&v.ID, &createdAt)
 	if err != nil {
-		return nil, err
+		return nil, nil, rollback(tx, err)
 	}
-	v.ID = id
 	v.CreatedAt = &createdAt
-	return v, nil
+	updated.VacationID = &v.ID
+	err = updateVacationRequest(ctx, tx, updated, updated.Version)
+	if err != nil {
+		return nil, nil, rollback(tx, err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, nil, err
+	}
+	return updated, v, nil
 }
 
-// GetVacationResourceByID returns the associated vacationResource by the given id.
-func (m *MariaDB) GetVacationResourceByID(ctx context.Context, uuid string) (*model.VacationResource, error) {
-	row := m.db.QueryRowContext(ctx, vacationResourceSelectByID, uuid)
-	err := row.Err()
+// CancelVacationRequest moves the vacationRequest by the given id to cancelled
+// and marks the linked vacation as deleted. Both happen in one transaction, an
+// already deleted vacation is skipped.
+func (m *MariaDB) CancelVacationRequest(ctx context.Context, uuid string) (*model.VacationRequest, error) {
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return nil, err
 	}
-	v := &model.VacationResource{}
-	var userID sql.NullString
-	var createdAt, updatedAt, from, to sql.NullTime
-	err = row.Scan(&v.ID, &userID, &createdAt, &updatedAt, &from, &to, v.YearlyDays)
+	updated, err := scanVacationRequest(tx.QueryRowContext(ctx, vacationRequestSelectForUpdate, uuid))
 	if err != nil {
-		return nil, err
+		return nil, rollback(tx, err)
 	}
-	if userID.Valid {
-		v.UserID = userID.String
+	err = updated.Cancel()
+	if err != nil {
+		return nil, rollback(tx, err)
 	}
-	if createdAt.Valid {
-		v.CreatedAt = &createdAt.Time
+	if updated.VacationID != nil {
+		_, err = tx.ExecContext(ctx, vacationDelete, *updated.VacationID)
+		if err != nil {
+			return nil, rollback(tx, err)
+		}
 	}
-	if updatedAt.Valid {
-		v.UpdatedAt = &updatedAt.Time
+	err = updateVacationRequest(ctx, tx, updated, updated.Version)
+	if err != nil {
+		return nil, rollback(tx, err)
 	}
-	if from.Valid {
-		v.From = from.Time
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
 	}
-	if to.Valid {
-		v.To = to.Time
+	return updated, nil
+}
+
+// checkAbsenceOverlap returns a *model.OverlapError, if v overlaps with any
+// blocking request or vacation of the same user. The requests and vacations of
+// the user are locked until the given transaction ends.
+func checkAbsenceOverlap(ctx context.Context, tx *sql.Tx, v *model.VacationRequest) error {
+	requests, vacations, err := selectAbsences(ctx, tx, v.UserID)
+	if err != nil {
+		return err
+	}
+	return v.CheckOverlaps(requests, vacations)
+}
+
+// selectAbsences returns the active blocking requests and vacations of the
+// given user. They are locked until the given transaction ends.
+func selectAbsences(ctx context.Context, tx *sql.Tx, userID string) ([]*model.VacationRequest, []*model.Vacation, error) {
+	requests := make([]*model.VacationRequest, 0)
+	rows, err := tx.QueryContext(ctx, vacationRequestSelectBlockingForUpdate, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		r, err := scanVacationRequest(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		requests = append(requests, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	vacations := make([]*model.Vacation, 0)
+	vRows, err := tx.QueryContext(ctx, vacationSelectByUserForUpdate, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer vRows.Close()
+	for vRows.Next() {
+		vac, err := scanVacation(vRows)
+		if err != nil {
+			return nil, nil, err
+		}
+		vacations = append(vacations, vac)
+	}
+	if err = vRows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return requests, vacations, nil
+}
+
+// DeleteVacationRequest marks vacationRequest entry by the given id as deleted.
+func (m *MariaDB) DeleteVacationRequest(ctx context.Context, uuid string) error {
+	row := m.db.QueryRowContext(ctx, vacationRequestDelete, uuid)
+	err := row.Err()
+	if err != nil {
+		return err
+	}
+	return err
+}
+
+// CreateVacationResource stores an internal copy of the given vacationResource.
+// Resources of the same user must not overlap.
+// Returns copy with assigned vacationResourceID.
+func (m *MariaDB) CreateVacationResource(ctx context.Context, v *model.VacationResource) (*model.VacationResource, error) {
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	err = checkResourceOverlap(ctx, tx, v)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+	var id string
+	var createdAt time.Time
+	err = tx.QueryRowContext(ctx, vacationResourceCreate,
+		v.UserID, v.YearlyDays, v.From, nullTime(v.To), v.CarriedDays,
+	).Scan(&id, &createdAt)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
 	}
+	v.ID = id
+	v.CreatedAt = &createdAt
+	v.Version = 1
 	return v, nil
 }
 
+// GetVacationResourceByID returns the associated vacationResource by the given id.
+func (m *MariaDB) GetVacationResourceByID(ctx context.Context, uuid string, opts ...query.Option) (*model.VacationResource, error) {
+	o := query.New(opts...)
+	return scanVacationResource(m.db.QueryRowContext(ctx, vacationResourceSelectByID, uuid, o.IncludeDeleted))
+}
+
 // ListVacationResource returns a copy of the internal vacationResource list.
-func (m *MariaDB) ListVacationResource(ctx context.Context) ([]*model.VacationResource, error) {
+func (m *MariaDB) ListVacationResource(ctx context.Context, opts ...query.Option) ([]*model.VacationResource, error) {
+	stmt, args, err := listSelect(vacationResourceSelect, vacationResourceColumns, query.New(opts...))
+	if err != nil {
+		return nil, err
+	}
 	allVacationResources := make([]*model.VacationResource, 0)
-	rows, err := m.db.QueryContext(ctx, basicVacationResourceSelect)
+	rows, err := m.db.QueryContext(ctx, stmt, args...)
 	if err != nil {
 		return nil, err
 	}
-	var userID sql.NullString
-	var createdAt, updatedAt, from, to sql.NullTime
+	defer rows.Close()
 	for rows.Next() {
-		v := model.VacationResource{}
-		err = rows.Scan(&v.ID, &createdAt, &updatedAt, &from, &to, &userID)
+		v, err := scanVacationResource(rows)
 		if err != nil {
 			return nil, err
 		}
-		if createdAt.Valid {
-			v.CreatedAt = &createdAt.Time
-		}
-		if from.Valid {
-			v.From = from.Time
-		}
-		if to.Valid {
-			v.To = from.Time
-		}
-		if userID.Valid {
-			v.UserID = userID.String
-		}
-		allVacationResources = append(allVacationResources, &v)
+		allVacationResources = append(allVacationResources, v)
 	}
-	return allVacationResources, nil
+	return allVacationResources, rows.Err()
+}
+
+// GetVacationResourcesByUserID returns list of vacationResources of one user by
+// given userID.
+func (m *MariaDB) GetVacationResourcesByUserID(ctx context.Context, id string, opts ...query.Option) ([]*model.VacationResource, error) {
+	return m.ListVacationResource(ctx, query.With(opts, query.UserID(id))...)
+}
+
+// GetVacationResourcesByTeamID returns list of vacationResources of the
+// members of one team by given teamID.
+func (m *MariaDB) GetVacationResourcesByTeamID(ctx context.Context, tID string, opts ...query.Option) ([]*model.VacationResource, error) {
+	return m.ListVacationResource(ctx, query.With(opts, query.TeamID(tID))...)
 }
 
 // UpdateVacationResource updates vacationResource entry by the given vacationResource.
+// Resources of the same user must not overlap.
 func (m *MariaDB) UpdateVacationResource(ctx context.Context, v *model.VacationResource) (*model.VacationResource, error) {
 	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return nil, err
 	}
-	_, err = tx.ExecContext(ctx, vacationResourceUpdate, v.UserID, v.YearlyDays, v.From, v.To, v.ID)
+	updated, err := scanVacationResource(tx.QueryRowContext(ctx, vacationResourceSelectByIDForUpdate, v.ID))
 	if err != nil {
-		if errTX := tx.Rollback(); err != nil {
-			return nil, errTX
-		}
-		return nil, err
+		return nil, rollback(tx, err)
+	}
+	updated.YearlyDays = v.YearlyDays
+	updated.From = v.From
+	updated.To = v.To
+	updated.CarriedDays = v.CarriedDays
+	err = checkResourceOverlap(ctx, tx, updated)
+	if err != nil {
+		return nil, rollback(tx, err)
 	}
-	row := tx.QueryRowContext(ctx, "SELECT updated_at WHERE id = ?", v.ID)
 	var updatedAt time.Time
-	err = row.Scan(&updatedAt)
+	err = tx.QueryRowContext(ctx, vacationResourceUpdate,
+		updated.YearlyDays, updated.From, nullTime(updated.To), updated.CarriedDays,
+		updated.ID, v.Version, v.Version,
+	).Scan(&updatedAt, &updated.Version)
 	if err != nil {
-		if errTX := tx.Rollback(); err != nil {
-			return nil, errTX
-		}
-		return nil, err
+		return nil, rollback(tx, staleVersion(ctx, tx, model.EntityVacationResource, v.ID, v.Version, err))
 	}
 	err = tx.Commit()
 	if err != nil {
 		return nil, err
 	}
-	v.UpdatedAt = &updatedAt
-	return v, nil
+	updated.UpdatedAt = &updatedAt
+	return updated, nil
+}
+
+// checkResourceOverlap returns model.ErrOverlappingResource, if v overlaps
+// with any resource of the same user. The resources of the user are locked
+// until the given transaction ends.
+func checkResourceOverlap(ctx context.Context, tx *sql.Tx, v *model.VacationResource) error {
+	rows, err := tx.QueryContext(ctx, vacationResourceSelectByUserForUpdate, v.UserID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		r, err := scanVacationResource(rows)
+		if err != nil {
+			return err
+		}
+		if v.Overlaps(r) {
+			return fmt.Errorf("%w: %s", model.ErrOverlappingResource, r.ID)
+		}
+	}
+	return rows.Err()
 }
 
-// DeleteVacationResource removes vacationResource entry by the given id.
+// DeleteVacationResource marks vacationResource entry by the given id as deleted.
 func (m *MariaDB) DeleteVacationResource(ctx context.Context, uuid string) error {
 	row := m.db.QueryRowContext(ctx, vacationResourceDelete, uuid)
 	err := row.Err()
@@ -909,3 +1515,1019 @@ func (m *MariaDB) DeleteVacationResource(ctx context.Context, uuid string) error
 	}
 	return err
 }
+
+// scanner is implemented by *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// rowQuerier is implemented by *sql.DB and *sql.Tx.
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// staleVersion maps the error of an update, which matched no entry, to
+// model.ErrVersionConflict, if the entry exists with another version than the
+// given one. Version 0 skips the check.
+func staleVersion(ctx context.Context, q rowQuerier, entity model.Entity, id string, version int, err error) error {
+	if version == 0 || !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	var current int
+	errVersion := q.QueryRowContext(ctx, fmt.Sprintf(entitySelectVersion, tables[entity]), id).Scan(&current)
+	if errVersion != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %s %s has version %d", model.ErrVersionConflict, entity, id, current)
+}
+
+// CreateAbsenceType stores an internal copy of the given absenceType.
+// Returns copy with assigned absenceTypeID.
+func (m *MariaDB) CreateAbsenceType(ctx context.Context, a *model.AbsenceType) (*model.AbsenceType, error) {
+	row := m.db.QueryRowContext(ctx, absenceTypeCreate,
+		a.Name, a.RequiresApproval, a.DeductsVacation, a.VisibleToTeam,
+	)
+	var id string
+	var createdAt time.Time
+	err := row.Scan(&id, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	a.ID = id
+	a.CreatedAt = &createdAt
+	a.Version = 1
+	return a, nil
+}
+
+// GetAbsenceTypeByID returns the associated absenceType by the given id.
+func (m *MariaDB) GetAbsenceTypeByID(ctx context.Context, uuid string, opts ...query.Option) (*model.AbsenceType, error) {
+	o := query.New(opts...)
+	return scanAbsenceType(m.db.QueryRowContext(ctx, absenceTypeSelectByID, uuid, o.IncludeDeleted))
+}
+
+// ListAbsenceTypes returns a copy of the internal absenceType list.
+func (m *MariaDB) ListAbsenceTypes(ctx context.Context, opts ...query.Option) ([]*model.AbsenceType, error) {
+	o := query.New(opts...)
+	absenceTypes := make([]*model.AbsenceType, 0)
+	rows, err := m.db.QueryContext(ctx, absenceTypeSelect, o.IncludeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		a, err := scanAbsenceType(rows)
+		if err != nil {
+			return nil, err
+		}
+		absenceTypes = append(absenceTypes, a)
+	}
+	return absenceTypes, rows.Err()
+}
+
+// UpdateAbsenceType updates absenceType entry by the given absenceType.
+func (m *MariaDB) UpdateAbsenceType(ctx context.Context, a *model.AbsenceType) (*model.AbsenceType, error) {
+	var createdAt, updatedAt time.Time
+	err := m.db.QueryRowContext(ctx, absenceTypeUpdate,
+		a.Name, a.RequiresApproval, a.DeductsVacation, a.VisibleToTeam,
+		a.ID, a.Version, a.Version,
+	).Scan(&createdAt, &updatedAt, &a.Version)
+	if err != nil {
+		return nil, staleVersion(ctx, m.db, model.EntityAbsenceType, a.ID, a.Version, err)
+	}
+	a.CreatedAt = &createdAt
+	a.UpdatedAt = &updatedAt
+	return a, nil
+}
+
+// DeleteAbsenceType marks absenceType entry by the given id as deleted.
+func (m *MariaDB) DeleteAbsenceType(ctx context.Context, uuid string) error {
+	_, err := m.db.ExecContext(ctx, absenceTypeDelete, uuid)
+	return err
+}
+
+// CreateDelegation stores an internal copy of the given delegation.
+// Returns copy with assigned delegationID.
+func (m *MariaDB) CreateDelegation(ctx context.Context, d *model.Delegation) (*model.Delegation, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	var id string
+	var createdAt time.Time
+	err := m.db.QueryRowContext(ctx, delegationCreate,
+		d.DelegatorID, d.DelegateID, d.From, d.To,
+	).Scan(&id, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	d.ID = id
+	d.CreatedAt = &createdAt
+	return d, nil
+}
+
+// GetDelegationByID returns the associated delegation by the given id.
+func (m *MariaDB) GetDelegationByID(ctx context.Context, uuid string, opts ...query.Option) (*model.Delegation, error) {
+	o := query.New(opts...)
+	return scanDelegation(m.db.QueryRowContext(ctx, delegationSelectByID, uuid, o.IncludeDeleted))
+}
+
+// ListDelegations returns a copy of the internal delegation list.
+func (m *MariaDB) ListDelegations(ctx context.Context, opts ...query.Option) ([]*model.Delegation, error) {
+	o := query.New(opts...)
+	delegations := make([]*model.Delegation, 0)
+	rows, err := m.db.QueryContext(ctx, delegationSelect, o.IncludeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		d, err := scanDelegation(rows)
+		if err != nil {
+			return nil, err
+		}
+		delegations = append(delegations, d)
+	}
+	return delegations, rows.Err()
+}
+
+// DeleteDelegation marks delegation entry by the given id as deleted.
+func (m *MariaDB) DeleteDelegation(ctx context.Context, uuid string) error {
+	_, err := m.db.ExecContext(ctx, delegationDelete, uuid)
+	return err
+}
+
+// CreateTeamRule stores an internal copy of the given teamRule.
+// Returns copy with assigned teamRuleID.
+func (m *MariaDB) CreateTeamRule(ctx context.Context, t *model.TeamRule) (*model.TeamRule, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	var id string
+	var createdAt time.Time
+	err := m.db.QueryRowContext(ctx, teamRuleCreate,
+		t.TeamID, t.Name, t.Kind, t.Blocking, t.MinPresent, t.From, t.To, t.Yearly,
+	).Scan(&id, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	t.ID = id
+	t.CreatedAt = &createdAt
+	t.Version = 1
+	return t, nil
+}
+
+// GetTeamRuleByID returns the associated teamRule by the given id.
+func (m *MariaDB) GetTeamRuleByID(ctx context.Context, uuid string, opts ...query.Option) (*model.TeamRule, error) {
+	o := query.New(opts...)
+	return scanTeamRule(m.db.QueryRowContext(ctx, teamRuleSelectByID, uuid, o.IncludeDeleted))
+}
+
+// ListTeamRules returns a list of teamRules associated by the given teamID.
+func (m *MariaDB) ListTeamRules(ctx context.Context, teamID string, opts ...query.Option) ([]*model.TeamRule, error) {
+	o := query.New(opts...)
+	teamRules := make([]*model.TeamRule, 0)
+	rows, err := m.db.QueryContext(ctx, teamRuleSelectByTeam, teamID, o.IncludeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		t, err := scanTeamRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		teamRules = append(teamRules, t)
+	}
+	return teamRules, rows.Err()
+}
+
+// UpdateTeamRule updates teamRule entry by the given teamRule. The team of a
+// rule can not be changed.
+func (m *MariaDB) UpdateTeamRule(ctx context.Context, t *model.TeamRule) (*model.TeamRule, error) {
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	current, err := scanTeamRule(tx.QueryRowContext(ctx, teamRuleSelectForUpdate, t.ID))
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+	updated := t.Copy()
+	updated.TeamID = current.TeamID
+	updated.CreatedAt = current.CreatedAt
+	err = updated.Validate()
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+	var updatedAt time.Time
+	err = tx.QueryRowContext(ctx, teamRuleUpdate,
+		updated.Name, updated.Kind, updated.Blocking,
+		updated.MinPresent,
+		updated.From, updated.To, updated.Yearly,
+		updated.ID, t.Version, t.Version,
+	).Scan(&updatedAt, &updated.Version)
+	if err != nil {
+		return nil, rollback(tx, staleVersion(ctx, tx, model.EntityTeamRule, t.ID, t.Version, err))
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	updated.UpdatedAt = &updatedAt
+	return updated, nil
+}
+
+// DeleteTeamRule marks teamRule entry by the given id as deleted.
+func (m *MariaDB) DeleteTeamRule(ctx context.Context, uuid string) error {
+	_, err := m.db.ExecContext(ctx, teamRuleDelete, uuid)
+	return err
+}
+
+// CreateComment stores an internal copy of the given comment.
+// Returns copy with assigned commentID.
+func (m *MariaDB) CreateComment(ctx context.Context, c *model.Comment) (*model.Comment, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	var id string
+	var createdAt time.Time
+	err := m.db.QueryRowContext(ctx, commentCreate,
+		c.VacationRequestID, c.AuthorID, c.Text,
+	).Scan(&id, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	c.ID = id
+	c.CreatedAt = &createdAt
+	return c, nil
+}
+
+// ListComments returns a list of comments associated by the given
+// vacationRequestID, ordered by creation.
+func (m *MariaDB) ListComments(ctx context.Context, vacationRequestID string, opts ...query.Option) ([]*model.Comment, error) {
+	o := query.New(opts...)
+	comments := make([]*model.Comment, 0)
+	rows, err := m.db.QueryContext(ctx, commentSelectByVacationRequest, vacationRequestID, o.IncludeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+// CreateAttachment stores an internal copy of the given attachment.
+// Returns copy with assigned attachmentID.
+func (m *MariaDB) CreateAttachment(ctx context.Context, a *model.Attachment) (*model.Attachment, error) {
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	var id string
+	var createdAt time.Time
+	err := m.db.QueryRowContext(ctx, attachmentCreate,
+		a.ResourceKind, a.ResourceID, a.UserID,
+		a.FileName, a.ContentType, a.Size, a.Checksum,
+	).Scan(&id, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	a.ID = id
+	a.CreatedAt = &createdAt
+	return a, nil
+}
+
+// GetAttachmentByID returns the associated attachment by the given id.
+func (m *MariaDB) GetAttachmentByID(ctx context.Context, uuid string, opts ...query.Option) (*model.Attachment, error) {
+	o := query.New(opts...)
+	return scanAttachment(m.db.QueryRowContext(ctx, attachmentSelectByID, uuid, o.IncludeDeleted))
+}
+
+// ListAttachments returns a list of attachments associated by the given
+// resource.
+func (m *MariaDB) ListAttachments(ctx context.Context, kind model.AttachmentResource, resourceID string, opts ...query.Option) ([]*model.Attachment, error) {
+	o := query.New(opts...)
+	attachments := make([]*model.Attachment, 0)
+	rows, err := m.db.QueryContext(ctx, attachmentSelectByResource, kind, resourceID, o.IncludeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
+
+// DeleteAttachment marks attachment entry by the given id as deleted.
+func (m *MariaDB) DeleteAttachment(ctx context.Context, uuid string) error {
+	_, err := m.db.ExecContext(ctx, attachmentDelete, uuid)
+	return err
+}
+
+// CreateTeamMembership stores an internal copy of the given teamMembership, if
+// the user is not yet member of the team and the allocations of the user do
+// not exceed 100 percent. The memberships of the user are locked until the
+// membership is created.
+// Returns copy with assigned teamMembershipID.
+func (m *MariaDB) CreateTeamMembership(ctx context.Context, t *model.TeamMembership) (*model.TeamMembership, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	memberships, err := selectUserTeamMemberships(ctx, tx, t.UserID)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+	for _, e := range memberships {
+		if e.TeamID == t.TeamID {
+			return nil, rollback(tx, fmt.Errorf("%w: user is already member of the team", model.ErrInvalidMembership))
+		}
+	}
+	err = t.CheckAllocation(memberships)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+	var id string
+	var createdAt time.Time
+	err = tx.QueryRowContext(ctx, teamMembershipCreate, t.TeamID, t.UserID, t.Role, t.Allocation).Scan(&id, &createdAt)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	t.ID = id
+	t.CreatedAt = &createdAt
+	t.Version = 1
+	return t, nil
+}
+
+// GetTeamMembershipByID returns the associated teamMembership by the given id.
+func (m *MariaDB) GetTeamMembershipByID(ctx context.Context, uuid string, opts ...query.Option) (*model.TeamMembership, error) {
+	o := query.New(opts...)
+	return scanTeamMembership(m.db.QueryRowContext(ctx, teamMembershipSelectByID, uuid, o.IncludeDeleted))
+}
+
+// ListTeamMemberships returns a list of teamMemberships associated by the
+// given teamID.
+func (m *MariaDB) ListTeamMemberships(ctx context.Context, teamID string, opts ...query.Option) ([]*model.TeamMembership, error) {
+	o := query.New(opts...)
+	return m.listTeamMemberships(ctx, teamMembershipSelectByTeam, teamID, o.IncludeDeleted)
+}
+
+// ListUserTeamMemberships returns a list of teamMemberships associated by the
+// given userID.
+func (m *MariaDB) ListUserTeamMemberships(ctx context.Context, userID string, opts ...query.Option) ([]*model.TeamMembership, error) {
+	o := query.New(opts...)
+	return m.listTeamMemberships(ctx, teamMembershipSelectByUser, userID, o.IncludeDeleted)
+}
+
+func (m *MariaDB) listTeamMemberships(ctx context.Context, stmt string, args ...interface{}) ([]*model.TeamMembership, error) {
+	memberships := make([]*model.TeamMembership, 0)
+	rows, err := m.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		t, err := scanTeamMembership(rows)
+		if err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, t)
+	}
+	return memberships, rows.Err()
+}
+
+// UpdateTeamMembership updates role and allocation of the teamMembership
+// entry by the given teamMembership. Team and user of a membership can not be
+// changed.
+func (m *MariaDB) UpdateTeamMembership(ctx context.Context, t *model.TeamMembership) (*model.TeamMembership, error) {
+	current, err := m.GetTeamMembershipByID(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	current.Role = t.Role
+	current.Allocation = t.Allocation
+	if err = current.Validate(); err != nil {
+		return nil, err
+	}
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	memberships, err := selectUserTeamMemberships(ctx, tx, current.UserID)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+	err = current.CheckAllocation(memberships)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+	var updatedAt time.Time
+	err = tx.QueryRowContext(ctx, teamMembershipUpdate,
+		current.Role, current.Allocation,
+		current.ID, t.Version, t.Version,
+	).Scan(&updatedAt, &current.Version)
+	if err != nil {
+		return nil, rollback(tx, staleVersion(ctx, tx, model.EntityTeamMembership, t.ID, t.Version, err))
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	current.UpdatedAt = &updatedAt
+	return current, nil
+}
+
+// DeleteTeamMembership marks teamMembership entry by the given id as deleted.
+func (m *MariaDB) DeleteTeamMembership(ctx context.Context, uuid string) error {
+	_, err := m.db.ExecContext(ctx, teamMembershipDelete, uuid)
+	return err
+}
+
+// ensureTeamMembership makes the user a member of the given team, if this is
+// not already the case. The membership of the previous team of the user is
+// ended, the new membership gets the remaining allocation of the user.
+func ensureTeamMembership(ctx context.Context, tx *sql.Tx, previousTeamID *string, teamID, userID string) error {
+	memberships, err := selectUserTeamMemberships(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+	allocation := model.FullAllocation
+	var member bool
+	for _, e := range memberships {
+		switch {
+		case e.TeamID == teamID:
+			member = true
+		case previousTeamID != nil && e.TeamID == *previousTeamID:
+			_, err = tx.ExecContext(ctx, teamMembershipDelete, e.ID)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		allocation -= e.Allocation
+	}
+	if member {
+		return nil
+	}
+	if allocation <= 0 {
+		return fmt.Errorf("%w: user %s", model.ErrOverAllocated, userID)
+	}
+	_, err = tx.ExecContext(ctx, teamMembershipCreate, teamID, userID, model.TeamRoleMember, allocation)
+	return err
+}
+
+// selectUserTeamMemberships returns all memberships of the given user. The
+// memberships are locked until the given transaction ends.
+func selectUserTeamMemberships(ctx context.Context, tx *sql.Tx, userID string) ([]*model.TeamMembership, error) {
+	memberships := make([]*model.TeamMembership, 0)
+	rows, err := tx.QueryContext(ctx, teamMembershipSelectByUserForUpdate, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		t, err := scanTeamMembership(rows)
+		if err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, t)
+	}
+	return memberships, rows.Err()
+}
+
+// Restore removes the deletion mark of the entry of the given entity and id.
+// Vacations and vacation-requests can only be restored, if they do not overlap
+// with blocking requests or vacations of the same user.
+func (m *MariaDB) Restore(ctx context.Context, entity model.Entity, id string) error {
+	table, ok := tables[entity]
+	if !ok {
+		return fmt.Errorf("%w: %s", model.ErrUnknownEntity, entity)
+	}
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	_, err = selectDeletedAt(ctx, tx, entity, id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	err = checkRestoredOverlap(ctx, tx, entity, id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(entityRestore, table), id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	return tx.Commit()
+}
+
+// checkRestoredOverlap returns a *model.OverlapError, if the deleted vacation
+// or open vacation-request of the given id overlaps with any blocking request
+// or vacation of the same user. Other entities never overlap.
+func checkRestoredOverlap(ctx context.Context, tx *sql.Tx, entity model.Entity, id string) error {
+	switch entity {
+	case model.EntityVacation:
+		v, err := scanVacation(tx.QueryRowContext(ctx, vacationSelectByID, id, true))
+		if err != nil {
+			return err
+		}
+		requests, vacations, err := selectAbsences(ctx, tx, v.UserID)
+		if err != nil {
+			return err
+		}
+		return v.CheckOverlaps(requests, vacations)
+	case model.EntityVacationRequest:
+		v, err := scanVacationRequest(tx.QueryRowContext(ctx, vacationRequestSelectByID, id, true))
+		if err != nil {
+			return err
+		}
+		if !v.Status.Editable() && !v.Status.Blocking() {
+			return nil
+		}
+		return checkAbsenceOverlap(ctx, tx, v)
+	}
+	return nil
+}
+
+// Purge permanently removes the entry of the given entity and id, if it was
+// deleted before deletedBefore. Entries, which are still referenced by other
+// entries, can not be purged.
+func (m *MariaDB) Purge(ctx context.Context, entity model.Entity, id string, deletedBefore time.Time) error {
+	table, ok := tables[entity]
+	if !ok {
+		return fmt.Errorf("%w: %s", model.ErrUnknownEntity, entity)
+	}
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	deletedAt, err := selectDeletedAt(ctx, tx, entity, id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	if !deletedAt.Before(deletedBefore) {
+		return rollback(tx, fmt.Errorf("%w: %s %s", model.ErrRetentionPeriod, entity, id))
+	}
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(entityPurge, table), id)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == errRowIsReferenced {
+			err = fmt.Errorf("%w: %s %s", model.ErrEntityReferenced, entity, id)
+		}
+		return rollback(tx, err)
+	}
+	return tx.Commit()
+}
+
+// selectDeletedAt returns the deletion time of the entry of the given entity
+// and id. The entry is locked until the given transaction ends.
+func selectDeletedAt(ctx context.Context, tx *sql.Tx, entity model.Entity, id string) (time.Time, error) {
+	var deletedAt sql.NullTime
+	err := tx.QueryRowContext(ctx, fmt.Sprintf(entitySelectDeletedAt, tables[entity]), id).Scan(&deletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, fmt.Errorf("%w: %s %s", model.ErrEntityNotFound, entity, id)
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !deletedAt.Valid {
+		return time.Time{}, fmt.Errorf("%w: %s %s", model.ErrNotDeleted, entity, id)
+	}
+	return deletedAt.Time, nil
+}
+
+// rollback aborts the given transaction and returns the original error,
+// unless the rollback itself fails.
+func rollback(tx *sql.Tx, err error) error {
+	if errTX := tx.Rollback(); errTX != nil {
+		return errTX
+	}
+	return err
+}
+
+// CreateAuditEntry stores the given audit entry.
+// Returns copy with assigned auditEntryID.
+func (m *MariaDB) CreateAuditEntry(ctx context.Context, a *model.AuditEntry) (*model.AuditEntry, error) {
+	var id string
+	var createdAt time.Time
+	err := m.db.QueryRowContext(ctx, auditEntryCreate,
+		a.ActorID, a.Action,
+		a.Entity, a.EntityID,
+		nullJSON(a.Before), nullJSON(a.After),
+		a.RequestID,
+	).Scan(&id, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	a.ID = id
+	a.CreatedAt = &createdAt
+	return a, nil
+}
+
+// ListAuditEntries returns a list of audit entries matching the given
+// filter, ordered by creation.
+func (m *MariaDB) ListAuditEntries(ctx context.Context, f model.AuditFilter) ([]*model.AuditEntry, error) {
+	entries := make([]*model.AuditEntry, 0)
+	rows, err := m.db.QueryContext(ctx, auditEntrySelect,
+		f.ActorID, f.ActorID,
+		f.Action, f.Action,
+		f.Entity, f.Entity,
+		f.EntityID, f.EntityID,
+		f.RequestID, f.RequestID,
+		f.From, f.From,
+		f.To, f.To,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		a, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, a)
+	}
+	return entries, rows.Err()
+}
+
+// nullJSON maps an empty JSON document to NULL.
+func nullJSON(raw []byte) sql.NullString {
+	return sql.NullString{String: string(raw), Valid: len(raw) > 0}
+}
+
+func scanAbsenceType(row scanner) (*model.AbsenceType, error) {
+	a := &model.AbsenceType{}
+	var createdAt, updatedAt, deletedAt sql.NullTime
+	err := row.Scan(
+		&a.ID, &a.Name,
+		&a.RequiresApproval, &a.DeductsVacation,
+		&a.VisibleToTeam,
+		&createdAt, &updatedAt, &deletedAt,
+		&a.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if createdAt.Valid {
+		a.CreatedAt = &createdAt.Time
+	}
+	if updatedAt.Valid {
+		a.UpdatedAt = &updatedAt.Time
+	}
+	if deletedAt.Valid {
+		a.DeletedAt = &deletedAt.Time
+	}
+	return a, nil
+}
+
+// nullTime maps the zero time to NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+func scanVacationResource(row scanner) (*model.VacationResource, error) {
+	v := &model.VacationResource{}
+	var to, createdAt, updatedAt, deletedAt sql.NullTime
+	err := row.Scan(&v.ID, &v.UserID, &v.YearlyDays, &v.From, &to, &v.CarriedDays, &createdAt, &updatedAt, &deletedAt, &v.Version)
+	if err != nil {
+		return nil, err
+	}
+	if to.Valid {
+		v.To = to.Time
+	}
+	if createdAt.Valid {
+		v.CreatedAt = &createdAt.Time
+	}
+	if updatedAt.Valid {
+		v.UpdatedAt = &updatedAt.Time
+	}
+	if deletedAt.Valid {
+		v.DeletedAt = &deletedAt.Time
+	}
+	return v, nil
+}
+
+func scanVacation(row scanner) (*model.Vacation, error) {
+	v := &model.Vacation{}
+	var approvedID, approvedOnBehalfOf, absenceTypeID, deputyID sql.NullString
+	var createdAt, deletedAt sql.NullTime
+	err := row.Scan(
+		&v.ID, &v.UserID, &approvedID, &approvedOnBehalfOf, &absenceTypeID, &deputyID,
+		&v.From, &v.To, &v.Portion, &v.Hours, &createdAt, &deletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if approvedID.Valid {
+		v.ApprovedBy = &approvedID.String
+	}
+	if approvedOnBehalfOf.Valid {
+		v.ApprovedOnBehalfOf = &approvedOnBehalfOf.String
+	}
+	if absenceTypeID.Valid {
+		v.AbsenceTypeID = &absenceTypeID.String
+	}
+	if deputyID.Valid {
+		v.DeputyID = &deputyID.String
+	}
+	if createdAt.Valid {
+		v.CreatedAt = &createdAt.Time
+	}
+	if deletedAt.Valid {
+		v.DeletedAt = &deletedAt.Time
+	}
+	return v, nil
+}
+
+func scanVacationRequest(row scanner) (*model.VacationRequest, error) {
+	v := &model.VacationRequest{}
+	var vacationID, absenceTypeID, rejectedBy, rejectedOnBehalfOf, rejectionReason, steps, violations, deputyID sql.NullString
+	var createdAt, updatedAt, deletedAt sql.NullTime
+	err := row.Scan(
+		&v.ID, &v.UserID, &v.Status, &vacationID, &absenceTypeID,
+		&rejectedBy, &rejectedOnBehalfOf, &rejectionReason, &steps, &violations,
+		&deputyID, &v.DeputyStatus,
+		&v.From, &v.To, &v.Portion, &v.Hours, &createdAt, &updatedAt, &deletedAt,
+		&v.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if vacationID.Valid {
+		v.VacationID = &vacationID.String
+	}
+	if absenceTypeID.Valid {
+		v.AbsenceTypeID = &absenceTypeID.String
+	}
+	if rejectedBy.Valid {
+		v.RejectedBy = &rejectedBy.String
+	}
+	if rejectedOnBehalfOf.Valid {
+		v.RejectedOnBehalfOf = &rejectedOnBehalfOf.String
+	}
+	if rejectionReason.Valid {
+		v.RejectionReason = &rejectionReason.String
+	}
+	if deputyID.Valid {
+		v.DeputyID = &deputyID.String
+	}
+	if steps.Valid {
+		err = json.Unmarshal([]byte(steps.String), &v.ApprovalSteps)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if violations.Valid {
+		err = json.Unmarshal([]byte(violations.String), &v.RuleViolations)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if createdAt.Valid {
+		v.CreatedAt = &createdAt.Time
+	}
+	if updatedAt.Valid {
+		v.UpdatedAt = &updatedAt.Time
+	}
+	if deletedAt.Valid {
+		v.DeletedAt = &deletedAt.Time
+	}
+	return v, nil
+}
+
+// encodeJSON returns the json representation of the given value, e.g. the
+// approval chain of a request. Unset values are stored as NULL.
+func encodeJSON(v interface{}, unset bool) (sql.NullString, error) {
+	if unset {
+		return sql.NullString{}, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(b), Valid: true}, nil
+}
+
+func scanDelegation(row scanner) (*model.Delegation, error) {
+	d := &model.Delegation{}
+	var createdAt, updatedAt, deletedAt sql.NullTime
+	err := row.Scan(
+		&d.ID,
+		&d.DelegatorID, &d.DelegateID,
+		&d.From, &d.To,
+		&createdAt, &updatedAt, &deletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if createdAt.Valid {
+		d.CreatedAt = &createdAt.Time
+	}
+	if updatedAt.Valid {
+		d.UpdatedAt = &updatedAt.Time
+	}
+	if deletedAt.Valid {
+		d.DeletedAt = &deletedAt.Time
+	}
+	return d, nil
+}
+
+func scanUser(row scanner) (*model.User, error) {
+	u := &model.User{}
+	var parentID, teamID, holidayCalendar sql.NullString
+	var createdAt, updatedAt, deletedAt sql.NullTime
+	err := row.Scan(&u.ID, &parentID, &teamID, &createdAt, &updatedAt, &deletedAt, &u.FirstName, &u.LastName, &u.Email, &holidayCalendar, &u.Role, &u.Version)
+	if err != nil {
+		return nil, err
+	}
+	if parentID.Valid {
+		u.ParentID = &parentID.String
+	}
+	if teamID.Valid {
+		u.TeamID = &teamID.String
+	}
+	if holidayCalendar.Valid {
+		u.HolidayCalendar = &holidayCalendar.String
+	}
+	if createdAt.Valid {
+		u.CreatedAt = &createdAt.Time
+	}
+	if updatedAt.Valid {
+		u.UpdatedAt = &updatedAt.Time
+	}
+	if deletedAt.Valid {
+		u.DeletedAt = &deletedAt.Time
+	}
+	return u, nil
+}
+
+func scanTeam(row scanner) (*model.Team, error) {
+	t := &model.Team{}
+	var parentID, holidayCalendar sql.NullString
+	var createdAt, updatedAt, deletedAt sql.NullTime
+	err := row.Scan(&t.ID, &t.OwnerID, &parentID, &t.Name, &holidayCalendar, &createdAt, &updatedAt, &deletedAt, &t.Version)
+	if err != nil {
+		return nil, err
+	}
+	if parentID.Valid {
+		t.ParentID = &parentID.String
+	}
+	if holidayCalendar.Valid {
+		t.HolidayCalendar = &holidayCalendar.String
+	}
+	if createdAt.Valid {
+		t.CreatedAt = &createdAt.Time
+	}
+	if updatedAt.Valid {
+		t.UpdatedAt = &updatedAt.Time
+	}
+	if deletedAt.Valid {
+		t.DeletedAt = &deletedAt.Time
+	}
+	return t, nil
+}
+
+func scanTeamRule(row scanner) (*model.TeamRule, error) {
+	t := &model.TeamRule{}
+	var from, to, createdAt, updatedAt, deletedAt sql.NullTime
+	err := row.Scan(
+		&t.ID, &t.TeamID,
+		&t.Name, &t.Kind, &t.Blocking,
+		&t.MinPresent,
+		&from, &to, &t.Yearly,
+		&createdAt, &updatedAt, &deletedAt,
+		&t.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if from.Valid {
+		t.From = &from.Time
+	}
+	if to.Valid {
+		t.To = &to.Time
+	}
+	if createdAt.Valid {
+		t.CreatedAt = &createdAt.Time
+	}
+	if updatedAt.Valid {
+		t.UpdatedAt = &updatedAt.Time
+	}
+	if deletedAt.Valid {
+		t.DeletedAt = &deletedAt.Time
+	}
+	return t, nil
+}
+
+func scanComment(row scanner) (*model.Comment, error) {
+	c := &model.Comment{}
+	var createdAt, updatedAt, deletedAt sql.NullTime
+	err := row.Scan(
+		&c.ID, &c.VacationRequestID, &c.AuthorID,
+		&c.Text,
+		&createdAt, &updatedAt, &deletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if createdAt.Valid {
+		c.CreatedAt = &createdAt.Time
+	}
+	if updatedAt.Valid {
+		c.UpdatedAt = &updatedAt.Time
+	}
+	if deletedAt.Valid {
+		c.DeletedAt = &deletedAt.Time
+	}
+	return c, nil
+}
+
+func scanTeamMembership(row scanner) (*model.TeamMembership, error) {
+	t := &model.TeamMembership{}
+	var createdAt, updatedAt, deletedAt sql.NullTime
+	err := row.Scan(
+		&t.ID, &t.TeamID, &t.UserID,
+		&t.Role, &t.Allocation,
+		&createdAt, &updatedAt, &deletedAt,
+		&t.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if createdAt.Valid {
+		t.CreatedAt = &createdAt.Time
+	}
+	if updatedAt.Valid {
+		t.UpdatedAt = &updatedAt.Time
+	}
+	if deletedAt.Valid {
+		t.DeletedAt = &deletedAt.Time
+	}
+	return t, nil
+}
+
+func scanAttachment(row scanner) (*model.Attachment, error) {
+	a := &model.Attachment{}
+	var createdAt, updatedAt, deletedAt sql.NullTime
+	err := row.Scan(
+		&a.ID, &a.ResourceKind, &a.ResourceID, &a.UserID,
+		&a.FileName, &a.ContentType, &a.Size, &a.Checksum,
+		&createdAt, &updatedAt, &deletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if createdAt.Valid {
+		a.CreatedAt = &createdAt.Time
+	}
+	if updatedAt.Valid {
+		a.UpdatedAt = &updatedAt.Time
+	}
+	if deletedAt.Valid {
+		a.DeletedAt = &deletedAt.Time
+	}
+	return a, nil
+}
+
+func scanAuditEntry(row scanner) (*model.AuditEntry, error) {
+	a := &model.AuditEntry{}
+	var before, after sql.NullString
+	var createdAt sql.NullTime
+	err := row.Scan(
+		&a.ID, &a.ActorID, &a.Action,
+		&a.Entity, &a.EntityID,
+		&before, &after,
+		&a.RequestID, &createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if before.Valid {
+		a.Before = []byte(before.String)
+	}
+	if after.Valid {
+		a.After = []byte(after.String)
+	}
+	if createdAt.Valid {
+		a.CreatedAt = &createdAt.Time
+	}
+	return a, nil
+}
@@ -0,0 +1,3 @@
+ALTER TABLE team ADD COLUMN holiday_calendar VARCHAR(16);
+
+ALTER TABLE user ADD COLUMN holiday_calendar VARCHAR(16);
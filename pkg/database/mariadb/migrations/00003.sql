@@ -0,0 +1,11 @@
+RENAME TABLE
+    vaccation TO vacation,
+    vaccation_request TO vacation_request,
+    vaccation_resource TO vacation_resource;
+
+ALTER TABLE vacation_resource RENAME COLUMN yearlyDays TO yearly_days;
+
+ALTER TABLE vacation_request
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    ADD COLUMN vacation_id UUID,
+    ADD FOREIGN KEY(vacation_id) REFERENCES vacation(id);
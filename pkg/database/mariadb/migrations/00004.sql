@@ -0,0 +1,4 @@
+ALTER TABLE vacation_request
+    ADD COLUMN rejected_by UUID,
+    ADD COLUMN rejection_reason TEXT,
+    ADD FOREIGN KEY(rejected_by) REFERENCES user(id);
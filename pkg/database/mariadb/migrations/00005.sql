@@ -0,0 +1,7 @@
+ALTER TABLE vacation
+    ADD COLUMN portion VARCHAR(16) NOT NULL DEFAULT 'full_day',
+    ADD COLUMN hours DECIMAL(4,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE vacation_request
+    ADD COLUMN portion VARCHAR(16) NOT NULL DEFAULT 'full_day',
+    ADD COLUMN hours DECIMAL(4,2) NOT NULL DEFAULT 0;
@@ -0,0 +1,27 @@
+CREATE TABLE absence_type (
+    id UUID NOT NULL DEFAULT UUID(),
+    `name` VARCHAR(255) NOT NULL,
+    requires_approval BOOLEAN NOT NULL DEFAULT TRUE,
+    deducts_vacation BOOLEAN NOT NULL DEFAULT FALSE,
+    visible_to_team BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATE NOT NULL,
+    deleted_at DATE,
+    updated_at DATE,
+    PRIMARY KEY(id)
+);
+
+INSERT INTO absence_type (id, `name`, requires_approval, deducts_vacation, visible_to_team, created_at)
+VALUES
+    ('5b9c1c7e-0b1a-4c2e-9a51-000000000001', 'Sick leave', FALSE, FALSE, FALSE, NOW()),
+    ('5b9c1c7e-0b1a-4c2e-9a51-000000000002', 'Special leave', TRUE, FALSE, TRUE, NOW()),
+    ('5b9c1c7e-0b1a-4c2e-9a51-000000000003', 'Unpaid leave', TRUE, FALSE, TRUE, NOW()),
+    ('5b9c1c7e-0b1a-4c2e-9a51-000000000004', 'Parental leave', TRUE, FALSE, TRUE, NOW()),
+    ('5b9c1c7e-0b1a-4c2e-9a51-000000000005', 'Training', TRUE, FALSE, TRUE, NOW());
+
+ALTER TABLE vacation
+    ADD COLUMN absence_type_id UUID,
+    ADD FOREIGN KEY(absence_type_id) REFERENCES absence_type(id);
+
+ALTER TABLE vacation_request
+    ADD COLUMN absence_type_id UUID,
+    ADD FOREIGN KEY(absence_type_id) REFERENCES absence_type(id);
@@ -0,0 +1,2 @@
+-- NOTE: a vacation resource without end date is valid until further notice.
+ALTER TABLE vacation_resource MODIFY `to` DATE NULL;
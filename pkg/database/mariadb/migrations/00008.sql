@@ -0,0 +1,4 @@
+-- NOTE: a vacation resource with carried days holds the unused days of the
+-- previous year, which expire at its end date.
+ALTER TABLE vacation_resource
+    ADD COLUMN carried_days DECIMAL(5,2) NOT NULL DEFAULT 0;
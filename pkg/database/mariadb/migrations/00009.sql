@@ -0,0 +1,4 @@
+-- NOTE: the approval chain of a request is recorded on submission, each step
+-- holds its role and the approving user.
+ALTER TABLE vacation_request
+    ADD COLUMN approval_steps JSON NULL;
@@ -0,0 +1,20 @@
+CREATE TABLE delegation (
+    id UUID NOT NULL DEFAULT UUID(),
+    delegator_id UUID NOT NULL,
+    delegate_id UUID NOT NULL,
+    `from` DATE NOT NULL,
+    `to` DATE NOT NULL,
+    created_at DATE NOT NULL,
+    deleted_at DATE,
+    updated_at DATE,
+    PRIMARY KEY(id),
+    FOREIGN KEY(delegator_id) REFERENCES user(id),
+    FOREIGN KEY(delegate_id) REFERENCES user(id)
+);
+
+-- NOTE: decisions of a delegate record the delegating user.
+ALTER TABLE vacation
+    ADD COLUMN approved_on_behalf_of UUID;
+
+ALTER TABLE vacation_request
+    ADD COLUMN rejected_on_behalf_of UUID;
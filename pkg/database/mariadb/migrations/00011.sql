@@ -0,0 +1,16 @@
+-- NOTE: automatically approved vacations are approved by the system principal
+-- 00000000-0000-0000-0000-000000000000, which is not a user.
+-- The generated name of the foreign key depends on the rename of 00003, it is
+-- looked up instead.
+SET @approved_fk = (
+    SELECT constraint_name
+    FROM information_schema.key_column_usage
+    WHERE table_schema = DATABASE()
+        AND table_name = 'vacation'
+        AND column_name = 'approved_id'
+        AND referenced_table_name = 'user'
+);
+SET @drop_approved_fk = CONCAT('ALTER TABLE vacation DROP FOREIGN KEY ', @approved_fk);
+PREPARE drop_approved_fk FROM @drop_approved_fk;
+EXECUTE drop_approved_fk;
+DEALLOCATE PREPARE drop_approved_fk;
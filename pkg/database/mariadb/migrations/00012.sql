@@ -0,0 +1,20 @@
+CREATE TABLE team_rule (
+    id UUID NOT NULL DEFAULT UUID(),
+    team_id UUID NOT NULL,
+    `name` VARCHAR(255) NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    blocking BOOLEAN NOT NULL DEFAULT FALSE,
+    min_present INT NOT NULL DEFAULT 0,
+    `from` DATE,
+    `to` DATE,
+    yearly BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATE NOT NULL,
+    deleted_at DATE,
+    updated_at DATE,
+    PRIMARY KEY(id),
+    FOREIGN KEY(team_id) REFERENCES team(id)
+);
+
+-- NOTE: violated non-blocking team rules are recorded on submission.
+ALTER TABLE vacation_request
+    ADD COLUMN rule_violations JSON NULL;
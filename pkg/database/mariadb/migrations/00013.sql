@@ -0,0 +1,13 @@
+-- NOTE: comments are ordered by creation, therefore created_at keeps the time.
+CREATE TABLE comment (
+    id UUID NOT NULL DEFAULT UUID(),
+    vacation_request_id UUID NOT NULL,
+    author_id UUID NOT NULL,
+    `text` TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    deleted_at DATE,
+    updated_at DATE,
+    PRIMARY KEY(id),
+    FOREIGN KEY(vacation_request_id) REFERENCES vacation_request(id),
+    FOREIGN KEY(author_id) REFERENCES user(id)
+);
@@ -0,0 +1,17 @@
+-- NOTE: the content of an attachment is kept in the blob store by its id.
+CREATE TABLE attachment (
+    id UUID NOT NULL DEFAULT UUID(),
+    resource_kind VARCHAR(32) NOT NULL,
+    resource_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    created_at DATE NOT NULL,
+    deleted_at DATE,
+    updated_at DATE,
+    PRIMARY KEY(id),
+    INDEX(resource_kind, resource_id),
+    FOREIGN KEY(user_id) REFERENCES user(id)
+);
@@ -0,0 +1,6 @@
+ALTER TABLE vacation_request
+    ADD COLUMN deputy_id UUID,
+    ADD COLUMN deputy_status VARCHAR(16) NOT NULL DEFAULT '';
+
+ALTER TABLE vacation
+    ADD COLUMN deputy_id UUID;
@@ -0,0 +1,3 @@
+ALTER TABLE team
+    ADD COLUMN parent_id UUID,
+    ADD FOREIGN KEY(parent_id) REFERENCES team(id);
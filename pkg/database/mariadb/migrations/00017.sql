@@ -0,0 +1,21 @@
+CREATE TABLE team_membership (
+    id UUID NOT NULL DEFAULT UUID(),
+    team_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    role VARCHAR(16) NOT NULL DEFAULT 'member',
+    allocation INT NOT NULL DEFAULT 100,
+    created_at DATETIME NOT NULL,
+    deleted_at DATETIME,
+    updated_at DATETIME,
+    PRIMARY KEY(id),
+    INDEX(team_id),
+    INDEX(user_id),
+    FOREIGN KEY(team_id) REFERENCES team(id),
+    FOREIGN KEY(user_id) REFERENCES user(id)
+);
+
+-- NOTE: the team of a user becomes a fully allocated membership.
+INSERT INTO team_membership (id, team_id, user_id, role, allocation, created_at)
+    SELECT UUID(), team_id, id, 'member', 100, NOW()
+    FROM user
+    WHERE team_id IS NOT NULL AND deleted_at IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE user ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'employee' AFTER team_id;
@@ -0,0 +1,10 @@
+ALTER TABLE user MODIFY deleted_at DATETIME;
+ALTER TABLE team MODIFY deleted_at DATETIME;
+ALTER TABLE vacation MODIFY deleted_at DATETIME;
+ALTER TABLE vacation_request MODIFY deleted_at DATETIME;
+ALTER TABLE vacation_resource MODIFY deleted_at DATETIME;
+ALTER TABLE absence_type MODIFY deleted_at DATETIME;
+ALTER TABLE delegation MODIFY deleted_at DATETIME;
+ALTER TABLE team_rule MODIFY deleted_at DATETIME;
+ALTER TABLE comment MODIFY deleted_at DATETIME;
+ALTER TABLE attachment MODIFY deleted_at DATETIME;
@@ -0,0 +1,18 @@
+-- NOTE: audit entries are never updated or deleted, actor_id is empty for
+-- mutations without authenticated user.
+CREATE TABLE audit_entry (
+    id UUID NOT NULL DEFAULT UUID(),
+    actor_id VARCHAR(36) NOT NULL DEFAULT '',
+    action VARCHAR(16) NOT NULL,
+    entity VARCHAR(32) NOT NULL,
+    entity_id VARCHAR(36) NOT NULL,
+    before_json LONGTEXT,
+    after_json LONGTEXT,
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    created_at DATETIME(6) NOT NULL,
+    PRIMARY KEY(id),
+    INDEX(actor_id),
+    INDEX(entity, entity_id),
+    INDEX(request_id),
+    INDEX(created_at)
+);
@@ -0,0 +1,9 @@
+-- NOTE: the version is incremented by every update, a stale version fails the
+-- update.
+ALTER TABLE user ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE team ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE vacation_request ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE vacation_resource ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE absence_type ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE team_rule ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE team_membership ADD COLUMN version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,58 @@
+package query
+
+import (
+	"time"
+
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+// Filter restricts the entries returned by a list. Unset fields match all
+// entries, fields not supported by a list are ignored.
+type Filter struct {
+	// UserID matches the entries of the given user.
+	UserID string
+	// TeamID matches the entries of users with a membership in the given team.
+	TeamID string
+	// DeputyID matches vacation requests, which assign the given user as
+	// deputy.
+	DeputyID string
+	// Status matches vacation requests with one of the given states.
+	Status []model.VacationRequestStatus
+	// From and To match entries, whose period overlaps both bounds.
+	From *time.Time
+	To   *time.Time
+}
+
+// MatchUser reports whether an entry of the given user is returned.
+func (f Filter) MatchUser(userID string) bool {
+	return f.UserID == "" || f.UserID == userID
+}
+
+// MatchDeputy reports whether a vacation request with the given deputy is
+// returned.
+func (f Filter) MatchDeputy(deputyID *string) bool {
+	return f.DeputyID == "" || (deputyID != nil && *deputyID == f.DeputyID)
+}
+
+// MatchStatus reports whether a vacation request with the given status is
+// returned.
+func (f Filter) MatchStatus(status model.VacationRequestStatus) bool {
+	if len(f.Status) == 0 {
+		return true
+	}
+	for _, s := range f.Status {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPeriod reports whether an entry with the given period is returned. A
+// zero to marks a period without end.
+func (f Filter) MatchPeriod(from, to time.Time) bool {
+	if f.From != nil && !to.IsZero() && to.Before(*f.From) {
+		return false
+	}
+	return f.To == nil || !from.After(*f.To)
+}
@@ -0,0 +1,196 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+// SortField is a field, which orders a list.
+type SortField string
+
+const (
+	SortCreatedAt SortField = "created_at"
+	SortName      SortField = "name"
+	SortFirstName SortField = "first_name"
+	SortLastName  SortField = "last_name"
+	SortEmail     SortField = "email"
+	SortFrom      SortField = "from"
+	SortTo        SortField = "to"
+)
+
+var (
+	// ErrUnknownSortField is returned, if a list can not be ordered by the
+	// requested field.
+	ErrUnknownSortField = errors.New("unknown sort field")
+	// ErrInvalidCursor is returned, if a cursor was not issued by NextCursor.
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// sortFields lists the fields, which order the lists of an entity.
+var sortFields = map[model.Entity][]SortField{
+	model.EntityUser:             {SortCreatedAt, SortFirstName, SortLastName, SortEmail},
+	model.EntityTeam:             {SortCreatedAt, SortName},
+	model.EntityVacation:         {SortCreatedAt, SortFrom, SortTo},
+	model.EntityVacationRequest:  {SortCreatedAt, SortFrom, SortTo},
+	model.EntityVacationResource: {SortCreatedAt, SortFrom},
+}
+
+// ParseSort parses the sort field of a list of the given entity. A leading
+// minus orders descending, e.g. -created_at.
+func ParseSort(entity model.Entity, raw string) (SortField, bool, error) {
+	desc := strings.HasPrefix(raw, "-")
+	field := SortField(strings.TrimPrefix(raw, "-"))
+	for _, f := range sortFields[entity] {
+		if f == field {
+			return field, desc, nil
+		}
+	}
+	return "", false, fmt.Errorf("%w: %s of %s", ErrUnknownSortField, field, entity)
+}
+
+// Cursor points to the last entry of a page. The next page starts behind the
+// cursor.
+type Cursor struct {
+	// Key is the value of the sort field of the entry.
+	Key string `json:"k"`
+	// ID breaks ties between entries with the same key.
+	ID string `json:"id"`
+}
+
+// String returns the opaque representation of the cursor, see ParseCursor.
+func (c *Cursor) String() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// ParseCursor parses a cursor returned by Cursor.String.
+func ParseCursor(raw string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err = json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// NextCursor returns the cursor of the given entry of a list ordered by the
+// given field.
+func NextCursor(entry interface{}, field SortField) (*Cursor, error) {
+	key, id, err := sortKey(entry, field)
+	if err != nil {
+		return nil, err
+	}
+	return &Cursor{Key: key, ID: id}, nil
+}
+
+// Apply orders the n entries returned by entry and returns the indices of the
+// entries of the requested page. Lists, which are not paged, keep their order.
+func (o Options) Apply(n int, entry func(i int) interface{}) ([]int, error) {
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	if !o.Paged() {
+		return idx, nil
+	}
+	field := o.SortField()
+	keys := make([]Cursor, n)
+	for i := range keys {
+		key, id, err := sortKey(entry(i), field)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = Cursor{Key: key, ID: id}
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		return o.before(keys[idx[a]], keys[idx[b]])
+	})
+	page := make([]int, 0, len(idx))
+	for _, i := range idx {
+		if o.Limit > 0 && len(page) == o.Limit {
+			break
+		}
+		if o.After == nil || o.before(*o.After, keys[i]) {
+			page = append(page, i)
+		}
+	}
+	return page, nil
+}
+
+// before reports whether a is ordered before b.
+func (o Options) before(a, b Cursor) bool {
+	if a.Key != b.Key {
+		return (a.Key < b.Key) != o.Desc
+	}
+	return a.ID != b.ID && (a.ID < b.ID) != o.Desc
+}
+
+// sortKey returns the value of the given sort field and the id of the given
+// entry. Timestamps are formatted, so that their order is kept.
+func sortKey(entry interface{}, field SortField) (string, string, error) {
+	switch e := entry.(type) {
+	case *model.User:
+		switch field {
+		case SortCreatedAt:
+			return formatTime(e.CreatedAt), e.ID, nil
+		case SortFirstName:
+			return e.FirstName, e.ID, nil
+		case SortLastName:
+			return e.LastName, e.ID, nil
+		case SortEmail:
+			return e.Email, e.ID, nil
+		}
+	case *model.Team:
+		switch field {
+		case SortCreatedAt:
+			return formatTime(e.CreatedAt), e.ID, nil
+		case SortName:
+			return e.Name, e.ID, nil
+		}
+	case *model.Vacation:
+		switch field {
+		case SortCreatedAt:
+			return formatTime(e.CreatedAt), e.ID, nil
+		case SortFrom:
+			return formatTime(&e.From), e.ID, nil
+		case SortTo:
+			return formatTime(&e.To), e.ID, nil
+		}
+	case *model.VacationRequest:
+		switch field {
+		case SortCreatedAt:
+			return formatTime(e.CreatedAt), e.ID, nil
+		case SortFrom:
+			return formatTime(&e.From), e.ID, nil
+		case SortTo:
+			return formatTime(&e.To), e.ID, nil
+		}
+	case *model.VacationResource:
+		switch field {
+		case SortCreatedAt:
+			return formatTime(e.CreatedAt), e.ID, nil
+		case SortFrom:
+			return formatTime(&e.From), e.ID, nil
+		}
+	}
+	return "", "", fmt.Errorf("%w: %s of %T", ErrUnknownSortField, field, entry)
+}
+
+// formatTime formats the given time in UTC, the result is also understood by
+// SQL databases. A nil time is formatted as empty string.
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02 15:04:05.000000")
+}
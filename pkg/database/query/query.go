@@ -0,0 +1,130 @@
+// Package query contains the options, which are accepted by the read methods
+// of the database.
+package query
+
+import (
+	"time"
+
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+// Options controls which entries are returned by a read.
+type Options struct {
+	// IncludeDeleted returns soft deleted entries next to the active ones.
+	IncludeDeleted bool
+	// Filter restricts the entries returned by a list.
+	Filter Filter
+	// Sort orders the entries returned by a list, see Paged.
+	Sort SortField
+	// Desc orders the entries descending.
+	Desc bool
+	// Limit caps the number of entries returned by a list, 0 is unlimited.
+	Limit int
+	// After skips all entries up to and including the given cursor.
+	After *Cursor
+}
+
+// Option modifies the Options of a read.
+type Option func(*Options)
+
+// IncludeDeleted returns an Option, which includes soft deleted entries, if
+// include is true.
+func IncludeDeleted(include bool) Option {
+	return func(o *Options) {
+		o.IncludeDeleted = include
+	}
+}
+
+// UserID returns an Option, which limits a list to the entries of the given
+// user.
+func UserID(userID string) Option {
+	return func(o *Options) {
+		o.Filter.UserID = userID
+	}
+}
+
+// TeamID returns an Option, which limits a list to the entries of the members
+// of the given team.
+func TeamID(teamID string) Option {
+	return func(o *Options) {
+		o.Filter.TeamID = teamID
+	}
+}
+
+// DeputyID returns an Option, which limits a list of vacation requests to the
+// requests assigning the given user as deputy.
+func DeputyID(deputyID string) Option {
+	return func(o *Options) {
+		o.Filter.DeputyID = deputyID
+	}
+}
+
+// Status returns an Option, which limits a list of vacation requests to the
+// given states.
+func Status(states ...model.VacationRequestStatus) Option {
+	return func(o *Options) {
+		o.Filter.Status = states
+	}
+}
+
+// Period returns an Option, which limits a list to the entries overlapping the
+// given period. A nil bound leaves the period open.
+func Period(from, to *time.Time) Option {
+	return func(o *Options) {
+		o.Filter.From = from
+		o.Filter.To = to
+	}
+}
+
+// SortBy returns an Option, which orders a list by the given field.
+func SortBy(field SortField, desc bool) Option {
+	return func(o *Options) {
+		o.Sort = field
+		o.Desc = desc
+	}
+}
+
+// Page returns an Option, which returns up to limit entries behind the given
+// cursor. A nil cursor starts at the first entry.
+func Page(limit int, after *Cursor) Option {
+	return func(o *Options) {
+		o.Limit = limit
+		o.After = after
+	}
+}
+
+// New returns the Options resulting from the given options. By default soft
+// deleted entries are excluded.
+func New(opts ...Option) Options {
+	o := Options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// With returns the given options followed by the given option, without
+// modifying the given options. Later options take precedence.
+func With(opts []Option, opt Option) []Option {
+	return append(opts[:len(opts):len(opts)], opt)
+}
+
+// Visible reports whether an entry with the given deletion time is returned.
+func (o Options) Visible(deletedAt *time.Time) bool {
+	return o.IncludeDeleted || deletedAt == nil
+}
+
+// Paged reports whether a list is ordered. Unordered lists keep the order of
+// the store.
+func (o Options) Paged() bool {
+	return o.Sort != "" || o.Limit > 0 || o.After != nil
+}
+
+// SortField returns the field, which orders a paged list. Lists are ordered by
+// creation time by default.
+func (o Options) SortField() SortField {
+	if o.Sort == "" {
+		return SortCreatedAt
+	}
+	return o.Sort
+}
@@ -0,0 +1,106 @@
+package query
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+func TestFilter_MatchPeriod(t *testing.T) {
+	from := time.Date(2022, time.April, 4, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, time.April, 8, 0, 0, 0, 0, time.UTC)
+	before := from.AddDate(0, 0, -1)
+	after := to.AddDate(0, 0, 1)
+	tt := []struct {
+		name   string
+		filter Filter
+		to     time.Time
+		want   bool
+	}{
+		{name: "empty", filter: Filter{}, to: to, want: true},
+		{name: "overlap", filter: Filter{From: &before, To: &from}, to: to, want: true},
+		{name: "inclusive bounds", filter: Filter{From: &to, To: &from}, to: to, want: true},
+		{name: "open end", filter: Filter{From: &after}, want: true},
+		{name: "ends before", filter: Filter{From: &after}, to: to},
+		{name: "starts after", filter: Filter{To: &before}, to: to},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.filter.MatchPeriod(from, tc.to); got != tc.want {
+				t.Fatalf("want: %t, got: %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	field, desc, err := ParseSort(model.EntityUser, "-last_name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if field != SortLastName || !desc {
+		t.Fatalf("expected descending %s, got: %s (desc: %t)", SortLastName, field, desc)
+	}
+	_, _, err = ParseSort(model.EntityTeam, "from")
+	if !errors.Is(err, ErrUnknownSortField) {
+		t.Fatalf("expected %v, got: %v", ErrUnknownSortField, err)
+	}
+}
+
+func TestParseCursor(t *testing.T) {
+	want := &Cursor{Key: "2022-04-05 08:57:32.000000", ID: "1ff63524-156f-466d-b287-4258811444dd"}
+	got, err := ParseCursor(want.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+	for _, raw := range []string{"not base64!", "e30"} {
+		if _, err = ParseCursor(raw); !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("expected %v for %q, got: %v", ErrInvalidCursor, raw, err)
+		}
+	}
+}
+
+func TestOptions_Apply(t *testing.T) {
+	teams := []*model.Team{
+		{ID: "a", Name: "Sales"},
+		{ID: "b", Name: "Development"},
+		{ID: "c", Name: "Marketing"},
+		{ID: "d", Name: "Development"},
+	}
+	entry := func(i int) interface{} { return teams[i] }
+	page := func(opts ...Option) []int {
+		t.Helper()
+		idx, err := New(opts...).Apply(len(teams), entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return idx
+	}
+	if diff := cmp.Diff([]int{0, 1, 2, 3}, page()); diff != "" {
+		t.Fatalf("expected store order (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int{1, 3, 2, 0}, page(SortBy(SortName, false))); diff != "" {
+		t.Fatalf("expected ascending order (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int{0, 2, 3}, page(SortBy(SortName, true), Page(3, nil))); diff != "" {
+		t.Fatalf("expected first descending page (-want +got):\n%s", diff)
+	}
+	next, err := NextCursor(teams[3], SortName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]int{1}, page(SortBy(SortName, true), Page(3, next))); diff != "" {
+		t.Fatalf("expected second descending page (-want +got):\n%s", diff)
+	}
+	_, err = New(SortBy(SortFrom, false)).Apply(len(teams), entry)
+	if !errors.Is(err, ErrUnknownSortField) {
+		t.Fatalf("expected %v, got: %v", ErrUnknownSortField, err)
+	}
+}
@@ -1,6 +1,11 @@
 package database
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/MninaTB/vacadm/pkg/model"
+)
 
 // RelationDB is implemented by any structure providing all RelationDB methods.
 type RelationDB interface {
@@ -10,6 +15,26 @@ type RelationDB interface {
 	IsTeamMember(ctx context.Context, teamID, userID string) (bool, error)
 	// IsTeamOwner verifies if the given userID refers to an owner of the teamID.
 	IsTeamOwner(ctx context.Context, teamID, userID string) (bool, error)
+	// TeamRole returns the role of userID within teamID, an empty role if the
+	// user is no member of the team.
+	TeamRole(ctx context.Context, teamID, userID string) (model.TeamRole, error)
+	// IsParentTeamOwner verifies if the given userID refers to an owner of the
+	// teamID or one of its parent teams.
+	IsParentTeamOwner(ctx context.Context, teamID, userID string) (bool, error)
+	// IsTeamParentUser verifies if the given parentID owns one of the teams of
+	// userID or one of their parent teams.
+	IsTeamParentUser(ctx context.Context, userID, parentID string) (bool, error)
+	// SubTeams returns the team of teamID and all of its sub-teams.
+	SubTeams(ctx context.Context, teamID string) ([]*model.Team, error)
+	// CanApprove verifies if the given approverID is allowed to approve the
+	// given approval step of a vacation-request of userID.
+	CanApprove(ctx context.Context, userID, approverID string, step model.ApprovalStep) (bool, error)
+	// Delegators returns the ids of all users, who delegated their approval
+	// rights to delegateID at the given time.
+	Delegators(ctx context.Context, delegateID string, at time.Time) ([]string, error)
+	// Delegates returns the ids of all users, who received the approval rights
+	// of delegatorID at the given time.
+	Delegates(ctx context.Context, delegatorID string, at time.Time) ([]string, error)
 }
 
 // NewRelationDB returns initialized RelationDB that matches
@@ -35,11 +60,15 @@ func (r *relationDB) IsParentUser(ctx context.Context, userID, parentID string)
 	if u.ParentID == nil {
 		return true, nil
 	}
+	// NOTE: visited protects against cycles in reporting lines, which were
+	// stored before updates were validated.
+	visited := map[string]bool{}
 	next := u
-	for next.ParentID != nil {
+	for next.ParentID != nil && !visited[*next.ParentID] {
 		if *next.ParentID == parentID {
 			return true, nil
 		}
+		visited[*next.ParentID] = true
 		parent, err := r.db.GetUserByID(ctx, *next.ParentID)
 		if err != nil {
 			return false, nil
@@ -49,23 +78,175 @@ func (r *relationDB) IsParentUser(ctx context.Context, userID, parentID string)
 	return false, nil
 }
 
-// IsTeamMember verifies if the given userID belongs to teamID.
+// IsTeamMember verifies if the given userID belongs to teamID through a team
+// membership.
 func (r *relationDB) IsTeamMember(ctx context.Context, teamID, userID string) (bool, error) {
-	u, err := r.db.GetUserByID(ctx, userID)
+	role, err := r.TeamRole(ctx, teamID, userID)
+	return role != "", err
+}
+
+// IsTeamOwner verifies if the given userID refers to an owner of the teamID.
+// Members with the owner role are owners as well.
+func (r *relationDB) IsTeamOwner(ctx context.Context, teamID, userID string) (bool, error) {
+	t, err := r.db.GetTeamByID(ctx, teamID)
+	if err != nil {
+		return false, nil
+	}
+	if t.OwnerID == userID {
+		return true, nil
+	}
+	role, err := r.TeamRole(ctx, teamID, userID)
+	return role == model.TeamRoleOwner, err
+}
+
+// TeamRole returns the role of userID within teamID, an empty role if the user
+// is no member of the team.
+func (r *relationDB) TeamRole(ctx context.Context, teamID, userID string) (model.TeamRole, error) {
+	memberships, err := r.db.ListUserTeamMemberships(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	for _, m := range memberships {
+		if m.TeamID == teamID {
+			return m.Role, nil
+		}
+	}
+	return "", nil
+}
+
+// IsParentTeamOwner verifies if the given userID refers to an owner of the
+// teamID or one of its parent teams. Parent is recursive in this case, this
+// grants owners of a department the same access as owners of its teams.
+func (r *relationDB) IsParentTeamOwner(ctx context.Context, teamID, userID string) (bool, error) {
+	visited := map[string]bool{}
+	next := &teamID
+	for next != nil && !visited[*next] {
+		visited[*next] = true
+		t, err := r.db.GetTeamByID(ctx, *next)
+		if err != nil {
+			return false, nil
+		}
+		isOwner, err := r.IsTeamOwner(ctx, t.ID, userID)
+		if err != nil || isOwner {
+			return isOwner, err
+		}
+		next = t.ParentID
+	}
+	return false, nil
+}
+
+// IsTeamParentUser verifies if the given parentID owns one of the teams of
+// userID or one of their parent teams. Along the team hierarchy this grants
+// the same access as IsParentUser along the user hierarchy.
+func (r *relationDB) IsTeamParentUser(ctx context.Context, userID, parentID string) (bool, error) {
+	memberships, err := r.db.ListUserTeamMemberships(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, m := range memberships {
+		isOwner, err := r.IsParentTeamOwner(ctx, m.TeamID, parentID)
+		if err != nil || isOwner {
+			return isOwner, err
+		}
+	}
+	return false, nil
+}
+
+// SubTeams returns the team of teamID and all of its sub-teams, parents are
+// listed before their sub-teams.
+func (r *relationDB) SubTeams(ctx context.Context, teamID string) ([]*model.Team, error) {
+	teams, err := r.db.ListTeams(ctx)
 	if err != nil {
+		return nil, err
+	}
+	return model.SubTeams(teams, teamID), nil
+}
+
+// CanApprove verifies if the given approverID is allowed to approve the
+// given approval step of a vacation-request of userID. Users can not approve
+// their own requests. Parent steps of users without parent are escalated to
+// admins and HR.
+func (r *relationDB) CanApprove(ctx context.Context, userID, approverID string, step model.ApprovalStep) (bool, error) {
+	if userID == approverID {
 		return false, nil
 	}
-	if u.TeamID == nil {
+	u, err := r.db.GetUserByID(ctx, userID)
+	if err != nil {
 		return false, nil
 	}
-	return *u.TeamID == teamID, nil
+	switch step.Role {
+	case model.ApproverParent:
+		if u.ParentID == nil {
+			return r.isEscalationApprover(ctx, approverID)
+		}
+		return r.IsParentUser(ctx, userID, approverID)
+	case model.ApproverManager:
+		return u.ParentID != nil && *u.ParentID == approverID, nil
+	case model.ApproverTeamOwner:
+		return r.isMembershipOwner(ctx, userID, approverID)
+	case model.ApproverTeam:
+		if step.TeamID == nil {
+			return false, nil
+		}
+		return r.IsTeamMember(ctx, *step.TeamID, approverID)
+	}
+	return false, nil
 }
 
-// IsTeamOwner verifies if the given userID refers to an owner of the teamID.
-func (r *relationDB) IsTeamOwner(ctx context.Context, teamID, userID string) (bool, error) {
-	t, err := r.db.GetTeamByID(ctx, teamID)
+// isMembershipOwner verifies if ownerID owns one of the teams, userID is a
+// member of.
+func (r *relationDB) isMembershipOwner(ctx context.Context, userID, ownerID string) (bool, error) {
+	memberships, err := r.db.ListUserTeamMemberships(ctx, userID)
 	if err != nil {
+		return false, err
+	}
+	for _, m := range memberships {
+		isOwner, err := r.IsTeamOwner(ctx, m.TeamID, ownerID)
+		if err != nil || isOwner {
+			return isOwner, err
+		}
+	}
+	return false, nil
+}
+
+// isEscalationApprover verifies if the given approverID refers to an active
+// admin or HR user, who approve in place of a missing parent.
+func (r *relationDB) isEscalationApprover(ctx context.Context, approverID string) (bool, error) {
+	approver, err := r.db.GetUserByID(ctx, approverID)
+	if err != nil || approver.DeletedAt != nil {
 		return false, nil
 	}
-	return t.OwnerID == userID, nil
+	return approver.Role == model.RoleAdmin || approver.Role == model.RoleHR, nil
+}
+
+// Delegators returns the ids of all users, who delegated their approval rights
+// to delegateID at the given time.
+func (r *relationDB) Delegators(ctx context.Context, delegateID string, at time.Time) ([]string, error) {
+	delegations, err := r.db.ListDelegations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var delegators []string
+	for _, d := range delegations {
+		if d.DelegateID == delegateID && d.Active(at) {
+			delegators = append(delegators, d.DelegatorID)
+		}
+	}
+	return delegators, nil
+}
+
+// Delegates returns the ids of all users, who received the approval rights of
+// delegatorID at the given time.
+func (r *relationDB) Delegates(ctx context.Context, delegatorID string, at time.Time) ([]string, error) {
+	delegations, err := r.db.ListDelegations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var delegates []string
+	for _, d := range delegations {
+		if d.DelegatorID == delegatorID && d.Active(at) {
+			delegates = append(delegates, d.DelegateID)
+		}
+	}
+	return delegates, nil
 }
@@ -0,0 +1,289 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/MninaTB/vacadm/pkg/database/inmemory"
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+func TestRelationDB_CanApprove(t *testing.T) {
+	ctx := context.Background()
+	db := inmemory.NewInmemoryDB()
+	mustUser := func(u *model.User) *model.User {
+		t.Helper()
+		u, err := db.CreateUser(ctx, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+	owner := mustUser(&model.User{Email: "owner@inform.de"})
+	team, err := db.CreateTeam(ctx, &model.Team{Name: "dev", OwnerID: owner.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hrTeam, err := db.CreateTeam(ctx, &model.Team{Name: "hr", OwnerID: owner.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager := mustUser(&model.User{Email: "manager@inform.de", ParentID: &owner.ID})
+	hr := mustUser(&model.User{Email: "hr@inform.de", ParentID: &owner.ID, TeamID: &hrTeam.ID})
+	user := mustUser(&model.User{Email: "user@inform.de", ParentID: &manager.ID, TeamID: &team.ID})
+	admin := mustUser(&model.User{Email: "admin@inform.de", ParentID: &owner.ID, Role: model.RoleAdmin})
+	hrManager := mustUser(&model.User{Email: "hr-manager@inform.de", ParentID: &owner.ID, Role: model.RoleHR})
+	root := mustUser(&model.User{Email: "root@inform.de", Role: model.RoleAdmin})
+	opsOwner := mustUser(&model.User{Email: "ops-owner@inform.de", ParentID: &owner.ID})
+	ops, err := db.CreateTeam(ctx, &model.Team{Name: "ops", OwnerID: opsOwner.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// NOTE: member and split joined their teams through memberships only.
+	member := mustUser(&model.User{Email: "member@inform.de", ParentID: &manager.ID})
+	split := mustUser(&model.User{Email: "split@inform.de", ParentID: &manager.ID})
+	for _, m := range []*model.TeamMembership{
+		{TeamID: ops.ID, UserID: member.ID},
+		{TeamID: team.ID, UserID: split.ID, Allocation: 50},
+		{TeamID: ops.ID, UserID: split.ID, Allocation: 50},
+	} {
+		if _, err := db.CreateTeamMembership(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tt := []struct {
+		name       string
+		userID     string
+		approverID string
+		step       model.ApprovalStep
+		want       bool
+	}{
+		{name: "parent", approverID: owner.ID, step: model.ApprovalStep{Role: model.ApproverParent}, want: true},
+		{name: "no parent", approverID: hr.ID, step: model.ApprovalStep{Role: model.ApproverParent}},
+		{name: "manager", approverID: manager.ID, step: model.ApprovalStep{Role: model.ApproverManager}, want: true},
+		{name: "indirect manager", approverID: owner.ID, step: model.ApprovalStep{Role: model.ApproverManager}},
+		{name: "team owner", approverID: owner.ID, step: model.ApprovalStep{Role: model.ApproverTeamOwner}, want: true},
+		{name: "no team owner", approverID: manager.ID, step: model.ApprovalStep{Role: model.ApproverTeamOwner}},
+		{name: "team owner of membership", userID: member.ID, approverID: opsOwner.ID, step: model.ApprovalStep{Role: model.ApproverTeamOwner}, want: true},
+		{name: "owner of foreign team", userID: member.ID, approverID: owner.ID, step: model.ApprovalStep{Role: model.ApproverTeamOwner}},
+		{name: "team owner of first team", userID: split.ID, approverID: owner.ID, step: model.ApprovalStep{Role: model.ApproverTeamOwner}, want: true},
+		{name: "team owner of second team", userID: split.ID, approverID: opsOwner.ID, step: model.ApprovalStep{Role: model.ApproverTeamOwner}, want: true},
+		{name: "team member", approverID: hr.ID, step: model.ApprovalStep{Role: model.ApproverTeam, TeamID: &hrTeam.ID}, want: true},
+		{name: "no team member", approverID: manager.ID, step: model.ApprovalStep{Role: model.ApproverTeam, TeamID: &hrTeam.ID}},
+		{name: "own request", approverID: user.ID, step: model.ApprovalStep{Role: model.ApproverTeam, TeamID: &team.ID}},
+		{name: "own request as parent", approverID: user.ID, step: model.ApprovalStep{Role: model.ApproverParent}},
+		{name: "root approves own request", userID: root.ID, approverID: root.ID, step: model.ApprovalStep{Role: model.ApproverParent}},
+		{name: "stranger approves root", userID: owner.ID, approverID: user.ID, step: model.ApprovalStep{Role: model.ApproverParent}},
+		{name: "admin approves root", userID: owner.ID, approverID: admin.ID, step: model.ApprovalStep{Role: model.ApproverParent}, want: true},
+		{name: "hr approves root", userID: owner.ID, approverID: hrManager.ID, step: model.ApprovalStep{Role: model.ApproverParent}, want: true},
+	}
+
+	r := NewRelationDB(db)
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			userID := tc.userID
+			if userID == "" {
+				userID = user.ID
+			}
+			got, err := r.CanApprove(ctx, userID, tc.approverID, tc.step)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Fatalf("want: %t, got: %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRelationDB_Delegations(t *testing.T) {
+	ctx := context.Background()
+	db := inmemory.NewInmemoryDB()
+	from := time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC)
+	delegations := []*model.Delegation{
+		{DelegatorID: "manager-id", DelegateID: "deputy-id", From: from, To: from.AddDate(0, 0, 13)},
+		{DelegatorID: "owner-id", DelegateID: "deputy-id", From: from.AddDate(0, 1, 0), To: from.AddDate(0, 1, 13)},
+	}
+	for _, d := range delegations {
+		if _, err := db.CreateDelegation(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tt := []struct {
+		name           string
+		at             time.Time
+		wantDelegators []string
+		wantDelegates  []string
+	}{
+		{name: "before", at: from.AddDate(0, 0, -1)},
+		{name: "manager absent", at: from.AddDate(0, 0, 5), wantDelegators: []string{"manager-id"}, wantDelegates: []string{"deputy-id"}},
+		{name: "owner absent", at: from.AddDate(0, 1, 5), wantDelegators: []string{"owner-id"}},
+	}
+
+	r := NewRelationDB(db)
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			delegators, err := r.Delegators(ctx, "deputy-id", tc.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(tc.wantDelegators, delegators) {
+				t.Fatal(cmp.Diff(tc.wantDelegators, delegators))
+			}
+			delegates, err := r.Delegates(ctx, "manager-id", tc.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(tc.wantDelegates, delegates) {
+				t.Fatal(cmp.Diff(tc.wantDelegates, delegates))
+			}
+		})
+	}
+}
+
+func TestRelationDB_TeamHierarchy(t *testing.T) {
+	ctx := context.Background()
+	db := inmemory.NewInmemoryDB()
+	mustUser := func(u *model.User) *model.User {
+		t.Helper()
+		u, err := db.CreateUser(ctx, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+	mustTeam := func(tm *model.Team) *model.Team {
+		t.Helper()
+		tm, err := db.CreateTeam(ctx, tm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+	head := mustUser(&model.User{Email: "head@inform.de"})
+	lead := mustUser(&model.User{Email: "lead@inform.de", ParentID: &head.ID})
+	department := mustTeam(&model.Team{Name: "dev", OwnerID: head.ID})
+	team := mustTeam(&model.Team{Name: "backend", OwnerID: lead.ID, ParentID: &department.ID})
+	other := mustTeam(&model.Team{Name: "sales", OwnerID: lead.ID})
+	user := mustUser(&model.User{Email: "user@inform.de", ParentID: &lead.ID, TeamID: &team.ID})
+
+	tt := []struct {
+		name    string
+		teamID  string
+		ownerID string
+		want    bool
+	}{
+		{name: "team owner", teamID: team.ID, ownerID: lead.ID, want: true},
+		{name: "department owner", teamID: team.ID, ownerID: head.ID, want: true},
+		{name: "owner of sub-team", teamID: department.ID, ownerID: lead.ID},
+		{name: "unrelated team", teamID: other.ID, ownerID: head.ID},
+		{name: "member", teamID: team.ID, ownerID: user.ID},
+	}
+
+	r := NewRelationDB(db)
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := r.IsParentTeamOwner(ctx, tc.teamID, tc.ownerID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Fatalf("want: %t, got: %t", tc.want, got)
+			}
+		})
+	}
+
+	isParent, err := r.IsTeamParentUser(ctx, user.ID, head.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isParent {
+		t.Fatal("department owner should be team parent of the user")
+	}
+	subTeams, err := r.SubTeams(ctx, department.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, s := range subTeams {
+		got = append(got, s.ID)
+	}
+	want := []string{department.ID, team.ID}
+	if !cmp.Equal(want, got) {
+		t.Fatal(cmp.Diff(want, got))
+	}
+}
+
+func TestRelationDB_TeamMemberships(t *testing.T) {
+	ctx := context.Background()
+	db := inmemory.NewInmemoryDB()
+	owner, err := db.CreateUser(ctx, &model.User{Email: "owner@inform.de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend, err := db.CreateTeam(ctx, &model.Team{Name: "backend", OwnerID: owner.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	frontend, err := db.CreateTeam(ctx, &model.Team{Name: "frontend", OwnerID: owner.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := db.CreateUser(ctx, &model.User{Email: "user@inform.de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	memberships := []*model.TeamMembership{
+		{TeamID: backend.ID, UserID: user.ID, Role: model.TeamRoleLead, Allocation: 60},
+		{TeamID: frontend.ID, UserID: user.ID, Role: model.TeamRoleOwner, Allocation: 40},
+	}
+	for _, m := range memberships {
+		if _, err := db.CreateTeamMembership(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tt := []struct {
+		name      string
+		teamID    string
+		userID    string
+		wantRole  model.TeamRole
+		wantOwner bool
+	}{
+		{name: "lead", teamID: backend.ID, userID: user.ID, wantRole: model.TeamRoleLead},
+		{name: "owner role", teamID: frontend.ID, userID: user.ID, wantRole: model.TeamRoleOwner, wantOwner: true},
+		{name: "owner of the team", teamID: backend.ID, userID: owner.ID, wantOwner: true},
+	}
+
+	r := NewRelationDB(db)
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			role, err := r.TeamRole(ctx, tc.teamID, tc.userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if role != tc.wantRole {
+				t.Fatalf("want: %q, got: %q", tc.wantRole, role)
+			}
+			isMember, err := r.IsTeamMember(ctx, tc.teamID, tc.userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if isMember != (tc.wantRole != "") {
+				t.Fatalf("unexpected membership: %t", isMember)
+			}
+			isOwner, err := r.IsTeamOwner(ctx, tc.teamID, tc.userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if isOwner != tc.wantOwner {
+				t.Fatalf("want: %t, got: %t", tc.wantOwner, isOwner)
+			}
+		})
+	}
+}
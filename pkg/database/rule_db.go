@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+// RuleDB is implemented by any structure providing all RuleDB methods.
+type RuleDB interface {
+	// Violations returns the violations of the rules of the team of the
+	// requesting user, if the given vacation-request gets approved.
+	Violations(ctx context.Context, vR *model.VacationRequest) ([]model.RuleViolation, error)
+}
+
+// NewRuleDB returns initialized RuleDB that matches the RuleDB interface.
+func NewRuleDB(db Database) RuleDB {
+	return &ruleDB{
+		db:       db,
+		calendar: NewCalendarDB(db),
+	}
+}
+
+type ruleDB struct {
+	db       Database
+	calendar CalendarDB
+}
+
+// Violations returns the violations of the rules of the team of the
+// requesting user, if the given vacation-request gets approved. Each rule is
+// reported once, on the first working day of the request it is violated.
+// Users without team do not violate any rule.
+func (r *ruleDB) Violations(ctx context.Context, vR *model.VacationRequest) ([]model.RuleViolation, error) {
+	user, err := r.db.GetUserByID(ctx, vR.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TeamID == nil {
+		return nil, nil
+	}
+	rules, err := r.db.ListTeamRules(ctx, *user.TeamID)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+	members, err := r.db.ListTeamUsers(ctx, *user.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	vacs, err := r.db.GetVacationsByTeamID(ctx, *user.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	cal, err := r.calendar.TeamCalendar(ctx, *user.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	var violations []model.RuleViolation
+	for _, rule := range rules {
+		for d := vR.From; !d.After(vR.To); d = d.AddDate(0, 0, 1) {
+			if !cal.IsWorkingDay(d) || !rule.Applies(d) {
+				continue
+			}
+			violated := rule.Kind == model.TeamRuleBlackout
+			if rule.Kind == model.TeamRuleMinStaffing {
+				present := float64(len(members)) - vR.Portion.Fraction(vR.Hours)
+				for _, vac := range vacs {
+					// NOTE: the vacation of an approved request is already
+					// part of the request itself.
+					if vR.VacationID != nil && vac.ID == *vR.VacationID {
+						continue
+					}
+					if !d.Before(vac.From) && !d.After(vac.To) {
+						present -= vac.Portion.Fraction(vac.Hours)
+					}
+				}
+				violated = present < float64(rule.MinPresent)
+			}
+			if violated {
+				violations = append(violations, model.RuleViolation{
+					RuleID:   rule.ID,
+					Name:     rule.Name,
+					Kind:     rule.Kind,
+					Blocking: rule.Blocking,
+					Day:      d,
+				})
+				break
+			}
+		}
+	}
+	return violations, nil
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/MninaTB/vacadm/pkg/database/inmemory"
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+func TestRuleDB_Violations(t *testing.T) {
+	ctx := context.Background()
+	db := inmemory.NewInmemoryDB()
+	mustUser := func(u *model.User) *model.User {
+		t.Helper()
+		u, err := db.CreateUser(ctx, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+	owner := mustUser(&model.User{Email: "owner@inform.de"})
+	team, err := db.CreateTeam(ctx, &model.Team{Name: "dev", OwnerID: owner.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := mustUser(&model.User{Email: "user@inform.de", ParentID: &owner.ID, TeamID: &team.ID})
+	colleague := mustUser(&model.User{Email: "colleague@inform.de", ParentID: &owner.ID, TeamID: &team.ID})
+	mustUser(&model.User{Email: "other@inform.de", ParentID: &owner.ID, TeamID: &team.ID})
+	loner := mustUser(&model.User{Email: "loner@inform.de", ParentID: &owner.ID})
+
+	// NOTE: monday, 7th to wednesday, 9th November 2022
+	_, err = db.CreateVacation(ctx, &model.Vacation{
+		UserID:     colleague.ID,
+		ApprovedBy: &owner.ID,
+		From:       time.Date(2022, time.November, 7, 0, 0, 0, 0, time.UTC),
+		To:         time.Date(2022, time.November, 9, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	freezeFrom := time.Date(2021, time.December, 1, 0, 0, 0, 0, time.UTC)
+	freezeTo := time.Date(2021, time.December, 15, 0, 0, 0, 0, time.UTC)
+	staffing, err := db.CreateTeamRule(ctx, &model.TeamRule{
+		TeamID: team.ID, Name: "two present", Kind: model.TeamRuleMinStaffing, MinPresent: 2, Blocking: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	freeze, err := db.CreateTeamRule(ctx, &model.TeamRule{
+		TeamID: team.ID, Name: "release freeze", Kind: model.TeamRuleBlackout,
+		From: &freezeFrom, To: &freezeTo, Yearly: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		name    string
+		request *model.VacationRequest
+		want    []model.RuleViolation
+	}{
+		{
+			name: "no violation",
+			request: &model.VacationRequest{
+				UserID: user.ID,
+				From:   time.Date(2022, time.November, 14, 0, 0, 0, 0, time.UTC),
+				To:     time.Date(2022, time.November, 18, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "understaffed",
+			request: &model.VacationRequest{
+				UserID: user.ID,
+				From:   time.Date(2022, time.November, 4, 0, 0, 0, 0, time.UTC),
+				To:     time.Date(2022, time.November, 8, 0, 0, 0, 0, time.UTC),
+			},
+			want: []model.RuleViolation{
+				{
+					RuleID: staffing.ID, Name: staffing.Name, Kind: model.TeamRuleMinStaffing, Blocking: true,
+					Day: time.Date(2022, time.November, 7, 0, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			name: "hourly absence on understaffed day",
+			request: &model.VacationRequest{
+				UserID:  user.ID,
+				From:    time.Date(2022, time.November, 7, 0, 0, 0, 0, time.UTC),
+				To:      time.Date(2022, time.November, 7, 0, 0, 0, 0, time.UTC),
+				Portion: model.PortionHours,
+				Hours:   1,
+			},
+			want: []model.RuleViolation{
+				{
+					RuleID: staffing.ID, Name: staffing.Name, Kind: model.TeamRuleMinStaffing, Blocking: true,
+					Day: time.Date(2022, time.November, 7, 0, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			name: "yearly release freeze",
+			request: &model.VacationRequest{
+				UserID: user.ID,
+				// NOTE: saturday, 10th to tuesday, 13th December 2022
+				From: time.Date(2022, time.December, 10, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2022, time.December, 13, 0, 0, 0, 0, time.UTC),
+			},
+			want: []model.RuleViolation{
+				{
+					RuleID: freeze.ID, Name: freeze.Name, Kind: model.TeamRuleBlackout,
+					Day: time.Date(2022, time.December, 12, 0, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			name: "user without team",
+			request: &model.VacationRequest{
+				UserID: loner.ID,
+				From:   time.Date(2022, time.December, 12, 0, 0, 0, 0, time.UTC),
+				To:     time.Date(2022, time.December, 12, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	r := NewRuleDB(db)
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := r.Violations(ctx, tc.request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("Violations() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
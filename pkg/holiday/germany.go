@@ -0,0 +1,78 @@
+package holiday
+
+import "time"
+
+var (
+	newYear            = Holiday{Name: "Neujahr", Rule: Fixed(time.January, 1)}
+	epiphany           = Holiday{Name: "Heilige Drei Könige", Rule: Fixed(time.January, 6)}
+	womensDay          = Holiday{Name: "Internationaler Frauentag", Rule: Fixed(time.March, 8)}
+	goodFriday         = Holiday{Name: "Karfreitag", Rule: EasterOffset(-2)}
+	easterSunday       = Holiday{Name: "Ostersonntag", Rule: EasterOffset(0)}
+	easterMonday       = Holiday{Name: "Ostermontag", Rule: EasterOffset(1)}
+	labourDay          = Holiday{Name: "Tag der Arbeit", Rule: Fixed(time.May, 1)}
+	ascensionDay       = Holiday{Name: "Christi Himmelfahrt", Rule: EasterOffset(39)}
+	whitSunday         = Holiday{Name: "Pfingstsonntag", Rule: EasterOffset(49)}
+	whitMonday         = Holiday{Name: "Pfingstmontag", Rule: EasterOffset(50)}
+	corpusChristi      = Holiday{Name: "Fronleichnam", Rule: EasterOffset(60)}
+	assumptionDay      = Holiday{Name: "Mariä Himmelfahrt", Rule: Fixed(time.August, 15)}
+	childrensDay       = Holiday{Name: "Weltkindertag", Rule: Since(2019, Fixed(time.September, 20))}
+	germanUnityDay     = Holiday{Name: "Tag der Deutschen Einheit", Rule: Fixed(time.October, 3)}
+	reformationDay     = Holiday{Name: "Reformationstag", Rule: Fixed(time.October, 31)}
+	allSaintsDay       = Holiday{Name: "Allerheiligen", Rule: Fixed(time.November, 1)}
+	repentanceDay      = Holiday{Name: "Buß- und Bettag", Rule: WeekdayBefore(time.Wednesday, time.November, 23)}
+	christmasDay       = Holiday{Name: "1. Weihnachtstag", Rule: Fixed(time.December, 25)}
+	secondChristmasDay = Holiday{Name: "2. Weihnachtstag", Rule: Fixed(time.December, 26)}
+)
+
+// sinceYear returns a copy of the given holiday, limited to the given year
+// and all following years.
+func sinceYear(year int, h Holiday) Holiday {
+	return Holiday{Name: h.Name, Rule: Since(year, h.Rule)}
+}
+
+// germanFederalHolidays are valid in all german federal states.
+func germanFederalHolidays() []Holiday {
+	return []Holiday{
+		newYear, goodFriday, easterMonday, labourDay, ascensionDay,
+		whitMonday, germanUnityDay, christmasDay, secondChristmasDay,
+	}
+}
+
+// germanCalendars returns the federal calendar "DE" and one calendar for each
+// german federal state, identified by their ISO 3166-2 code.
+func germanCalendars() []*Calendar {
+	states := []struct {
+		id       string
+		name     string
+		holidays []Holiday
+	}{
+		{"DE-BW", "Baden-Württemberg", []Holiday{epiphany, corpusChristi, allSaintsDay}},
+		{"DE-BY", "Bayern", []Holiday{epiphany, corpusChristi, allSaintsDay}},
+		{"DE-BE", "Berlin", []Holiday{sinceYear(2019, womensDay)}},
+		{"DE-BB", "Brandenburg", []Holiday{easterSunday, whitSunday, reformationDay}},
+		{"DE-HB", "Bremen", []Holiday{sinceYear(2018, reformationDay)}},
+		{"DE-HH", "Hamburg", []Holiday{sinceYear(2018, reformationDay)}},
+		{"DE-HE", "Hessen", []Holiday{corpusChristi}},
+		{"DE-MV", "Mecklenburg-Vorpommern", []Holiday{sinceYear(2023, womensDay), reformationDay}},
+		{"DE-NI", "Niedersachsen", []Holiday{sinceYear(2018, reformationDay)}},
+		{"DE-NW", "Nordrhein-Westfalen", []Holiday{corpusChristi, allSaintsDay}},
+		{"DE-RP", "Rheinland-Pfalz", []Holiday{corpusChristi, allSaintsDay}},
+		{"DE-SL", "Saarland", []Holiday{corpusChristi, assumptionDay, allSaintsDay}},
+		{"DE-SN", "Sachsen", []Holiday{reformationDay, repentanceDay}},
+		{"DE-ST", "Sachsen-Anhalt", []Holiday{epiphany, reformationDay}},
+		{"DE-SH", "Schleswig-Holstein", []Holiday{sinceYear(2018, reformationDay)}},
+		{"DE-TH", "Thüringen", []Holiday{childrensDay, reformationDay}},
+	}
+
+	calendars := []*Calendar{
+		{ID: "DE", Name: "Deutschland", Holidays: germanFederalHolidays()},
+	}
+	for _, s := range states {
+		calendars = append(calendars, &Calendar{
+			ID:       s.id,
+			Name:     s.name,
+			Holidays: append(germanFederalHolidays(), s.holidays...),
+		})
+	}
+	return calendars
+}
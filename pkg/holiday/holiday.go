@@ -0,0 +1,197 @@
+package holiday
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrUnknownCalendar is returned if no calendar is registered for a given id.
+var ErrUnknownCalendar = fmt.Errorf("unknown holiday calendar")
+
+// Rule calculates the date of a holiday in a given year.
+type Rule interface {
+	// Date returns the date of the holiday in the given year. If the holiday
+	// does not take place in the given year, false is returned.
+	Date(year int) (time.Time, bool)
+}
+
+// RuleFunc is an adapter to allow the use of ordinary functions as Rule.
+type RuleFunc func(year int) (time.Time, bool)
+
+// Date calls f(year).
+func (f RuleFunc) Date(year int) (time.Time, bool) {
+	return f(year)
+}
+
+// Fixed returns a Rule for a holiday which takes place on the same date
+// every year.
+func Fixed(month time.Month, day int) Rule {
+	return RuleFunc(func(year int) (time.Time, bool) {
+		return date(year, month, day), true
+	})
+}
+
+// EasterOffset returns a Rule for a holiday which takes place the given number
+// of days before (negative) or after (positive) Easter Sunday.
+func EasterOffset(days int) Rule {
+	return RuleFunc(func(year int) (time.Time, bool) {
+		return Easter(year).AddDate(0, 0, days), true
+	})
+}
+
+// WeekdayBefore returns a Rule for a holiday which takes place on the last
+// given weekday before the given date.
+func WeekdayBefore(weekday time.Weekday, month time.Month, day int) Rule {
+	return RuleFunc(func(year int) (time.Time, bool) {
+		d := date(year, month, day).AddDate(0, 0, -1)
+		for d.Weekday() != weekday {
+			d = d.AddDate(0, 0, -1)
+		}
+		return d, true
+	})
+}
+
+// Since limits the given Rule to the given year and all following years.
+func Since(first int, r Rule) Rule {
+	return RuleFunc(func(year int) (time.Time, bool) {
+		if year < first {
+			return time.Time{}, false
+		}
+		return r.Date(year)
+	})
+}
+
+// Easter returns the date of Easter Sunday in the given year, based on the
+// anonymous gregorian algorithm.
+func Easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return date(year, time.Month(month), day)
+}
+
+// Holiday is a named Rule.
+type Holiday struct {
+	Name string
+	Rule Rule
+}
+
+// Day is a holiday at a specific date.
+type Day struct {
+	Name string    `json:"name"`
+	Date time.Time `json:"date"`
+}
+
+// Calendar is a set of holidays, which are valid for one region.
+type Calendar struct {
+	ID       string
+	Name     string
+	Holidays []Holiday
+}
+
+// Days returns all holidays of the given year ordered by date.
+func (c *Calendar) Days(year int) []Day {
+	var days []Day
+	if c == nil {
+		return days
+	}
+	for _, h := range c.Holidays {
+		d, ok := h.Rule.Date(year)
+		if !ok {
+			continue
+		}
+		days = append(days, Day{Name: h.Name, Date: d})
+	}
+	sort.SliceStable(days, func(i, j int) bool {
+		return days[i].Date.Before(days[j].Date)
+	})
+	return days
+}
+
+// IsHoliday reports whether the given date is a holiday.
+func (c *Calendar) IsHoliday(t time.Time) bool {
+	if c == nil {
+		return false
+	}
+	d := dateOf(t)
+	for _, h := range c.Holidays {
+		hd, ok := h.Rule.Date(d.Year())
+		if ok && hd.Equal(d) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWorkingDay reports whether the given date is neither part of a weekend
+// nor a holiday. A nil Calendar only considers weekends.
+func (c *Calendar) IsWorkingDay(t time.Time) bool {
+	if wd := t.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return false
+	}
+	return !c.IsHoliday(t)
+}
+
+// WorkingDays returns the number of working days between from and to.
+// Both dates are inclusive, the time of day is ignored.
+func (c *Calendar) WorkingDays(from, to time.Time) float64 {
+	var days float64
+	for d, end := dateOf(from), dateOf(to); !d.After(end); d = d.AddDate(0, 0, 1) {
+		if c.IsWorkingDay(d) {
+			days++
+		}
+	}
+	return days
+}
+
+var registry = newRegistry(germanCalendars()...)
+
+func newRegistry(calendars ...*Calendar) map[string]*Calendar {
+	r := make(map[string]*Calendar, len(calendars))
+	for _, c := range calendars {
+		r[c.ID] = c
+	}
+	return r
+}
+
+// Lookup returns the registered calendar of the given id.
+func Lookup(id string) (*Calendar, error) {
+	c, ok := registry[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCalendar, id)
+	}
+	return c, nil
+}
+
+// Calendars returns all registered calendars ordered by id.
+func Calendars() []*Calendar {
+	list := make([]*Calendar, 0, len(registry))
+	for _, c := range registry {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// dateOf truncates the given time to its date in UTC.
+func dateOf(t time.Time) time.Time {
+	return date(t.Year(), t.Month(), t.Day())
+}
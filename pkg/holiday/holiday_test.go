@@ -0,0 +1,152 @@
+package holiday
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEaster(t *testing.T) {
+	tt := []struct {
+		year int
+		want time.Time
+	}{
+		{year: 2019, want: date(2019, time.April, 21)},
+		{year: 2022, want: date(2022, time.April, 17)},
+		{year: 2024, want: date(2024, time.March, 31)},
+		{year: 2038, want: date(2038, time.April, 25)},
+	}
+
+	for _, tc := range tt {
+		got := Easter(tc.year)
+		if !got.Equal(tc.want) {
+			t.Fatalf("year %d: want: %s, got: %s", tc.year, tc.want, got)
+		}
+	}
+}
+
+func TestCalendar_IsHoliday(t *testing.T) {
+	tt := []struct {
+		name       string
+		calendarID string
+		day        time.Time
+		want       bool
+	}{
+		{
+			name:       "good friday",
+			calendarID: "DE",
+			day:        date(2022, time.April, 15),
+			want:       true,
+		},
+		{
+			name:       "whit monday",
+			calendarID: "DE-HE",
+			day:        date(2022, time.June, 6),
+			want:       true,
+		},
+		{
+			name:       "corpus christi in bavaria",
+			calendarID: "DE-BY",
+			day:        date(2022, time.June, 16),
+			want:       true,
+		},
+		{
+			name:       "no corpus christi in berlin",
+			calendarID: "DE-BE",
+			day:        date(2022, time.June, 16),
+			want:       false,
+		},
+		{
+			name:       "repentance day in saxony",
+			calendarID: "DE-SN",
+			day:        date(2022, time.November, 16),
+			want:       true,
+		},
+		{
+			name:       "womens day in berlin before introduction",
+			calendarID: "DE-BE",
+			day:        date(2018, time.March, 8),
+			want:       false,
+		},
+		{
+			name:       "womens day in berlin",
+			calendarID: "DE-BE",
+			day:        date(2019, time.March, 8),
+			want:       true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := Lookup(tc.calendarID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.IsHoliday(tc.day); got != tc.want {
+				t.Fatalf("want: %t, got: %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCalendar_WorkingDays(t *testing.T) {
+	tt := []struct {
+		name       string
+		calendarID string
+		from       time.Time
+		to         time.Time
+		want       float64
+	}{
+		{
+			name: "weekends only",
+			from: date(2022, time.April, 11),
+			to:   date(2022, time.April, 24),
+			want: 10,
+		},
+		{
+			name:       "easter week",
+			calendarID: "DE",
+			from:       date(2022, time.April, 11),
+			to:         date(2022, time.April, 24),
+			want:       8,
+		},
+		{
+			name:       "single day",
+			calendarID: "DE",
+			from:       date(2022, time.April, 19),
+			to:         date(2022, time.April, 19),
+			want:       1,
+		},
+		{
+			name: "to before from",
+			from: date(2022, time.April, 19),
+			to:   date(2022, time.April, 18),
+			want: 0,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var c *Calendar
+			if tc.calendarID != "" {
+				var err error
+				c, err = Lookup(tc.calendarID)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := c.WorkingDays(tc.from, tc.to); got != tc.want {
+				t.Fatalf("want: %v, got: %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	if _, err := Lookup("does-not-exist"); err == nil {
+		t.Fatal("expected error")
+	}
+	// NOTE: federal calendar and 16 federal states
+	if got := len(Calendars()); got != 17 {
+		t.Fatalf("want: 17 calendars, got: %d", got)
+	}
+}
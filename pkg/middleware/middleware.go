@@ -1,11 +1,15 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
-	"github.com/MninaTB/vacadm/api/v1/util"
 	"github.com/MninaTB/vacadm/pkg/database"
 	jwt "github.com/MninaTB/vacadm/pkg/jwt"
+	"github.com/MninaTB/vacadm/pkg/policy"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
@@ -17,40 +21,38 @@ type Validator interface {
 	Valid(token string) (userID string, teamID string, err error)
 }
 
-func shallPass(r *http.Request, db database.RelationDB, rUserID, rTeamID string) (bool, error) {
-	userID, errUserID := util.UserIDFromRequest(r)
-	teamID, errTeamID := util.TeamIDFromRequest(r)
-
-	if errUserID == util.ErrDoesNotExistUserID && errTeamID == util.ErrDoesNotExistTeamID {
-		return true, nil
+// shallPass looks up the permission of the matched route and asks the engine,
+// whether the user of the token holds it on the resource in the URL. Reads of
+// soft deleted entries additionally require policy.ReadDeleted.
+func shallPass(r *http.Request, engine policy.Engine, rUserID string) (bool, error) {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return false, errors.New("no route matched")
 	}
-
-	isUser := userID == rUserID
-	isParent, err := db.IsParentUser(r.Context(), userID, rUserID)
+	path, err := route.GetPathTemplate()
 	if err != nil {
 		return false, err
 	}
-	if errUserID == nil && errTeamID == util.ErrDoesNotExistTeamID {
-		return isUser || isParent, nil
+	permission, ok := policy.Lookup(r.Method, path)
+	if !ok {
+		return false, fmt.Errorf("no policy for route %s %s", r.Method, path)
 	}
-	isMember, err := db.IsTeamMember(r.Context(), teamID, rTeamID)
-	if err != nil {
-		return false, err
+	allowed, err := engine.Allowed(r.Context(), rUserID, permission, mux.Vars(r)[permission.Resource()])
+	if err != nil || !allowed {
+		return allowed, err
 	}
-	isOwner, err := db.IsTeamOwner(r.Context(), teamID, rUserID)
-	if err != nil {
-		return false, err
-	}
-	if errUserID == util.ErrDoesNotExistUserID && errTeamID == nil {
-		return isMember || isOwner, nil
+	// NOTE: malformed values are rejected by the handlers.
+	if include, _ := strconv.ParseBool(r.URL.Query().Get("include_deleted")); include {
+		return engine.Allowed(r.Context(), rUserID, policy.ReadDeleted, "")
 	}
-
-	return (isUser || isParent) && (isMember || isOwner), nil
+	return true, nil
 }
 
 // Auth returns a mux.MiddlewareFunc that restricts user access based on the
-// carried bearer token.
-func Auth(v Validator, db database.RelationDB) mux.MiddlewareFunc {
+// carried bearer token and the permissions of the route, see policy.Routes.
+// The user of the token is passed as actor of the mutations, see
+// database.WithActor.
+func Auth(v Validator, engine policy.Engine) mux.MiddlewareFunc {
 	logger := logrus.WithField("component", "auth-middleware")
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -61,13 +63,13 @@ func Auth(v Validator, db database.RelationDB) mux.MiddlewareFunc {
 				w.WriteHeader(http.StatusForbidden)
 				return
 			}
-			userID, teamID, err := v.Valid(token)
+			userID, _, err := v.Valid(token)
 			if err != nil {
 				logger.Error(err)
 				w.WriteHeader(http.StatusForbidden)
 				return
 			}
-			allowed, err := shallPass(r, db, userID, teamID)
+			allowed, err := shallPass(r, engine, userID)
 			if err != nil {
 				logger.Error(err)
 				w.WriteHeader(http.StatusInternalServerError)
@@ -78,7 +80,26 @@ func Auth(v Validator, db database.RelationDB) mux.MiddlewareFunc {
 				w.WriteHeader(http.StatusForbidden)
 				return
 			}
-			h.ServeHTTP(w, r)
+			h.ServeHTTP(w, r.WithContext(database.WithActor(r.Context(), userID)))
+		})
+	}
+}
+
+// RequestIDHeader is the header carrying the id of a request.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID returns a mux.MiddlewareFunc that passes the id of the request,
+// see database.WithRequestID. The id is taken from the RequestIDHeader or
+// generated, if the header is missing, and returned in the response header.
+func RequestID() mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(RequestIDHeader)
+			if requestID == "" || len(requestID) > 64 {
+				requestID = uuid.NewString()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+			h.ServeHTTP(w, r.WithContext(database.WithRequestID(r.Context(), requestID)))
 		})
 	}
 }
@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+// Well-known ids of the absence types, which are provided by every store.
+const (
+	AbsenceTypeSickLeave     = "5b9c1c7e-0b1a-4c2e-9a51-000000000001"
+	AbsenceTypeSpecialLeave  = "5b9c1c7e-0b1a-4c2e-9a51-000000000002"
+	AbsenceTypeUnpaidLeave   = "5b9c1c7e-0b1a-4c2e-9a51-000000000003"
+	AbsenceTypeParentalLeave = "5b9c1c7e-0b1a-4c2e-9a51-000000000004"
+	AbsenceTypeTraining      = "5b9c1c7e-0b1a-4c2e-9a51-000000000005"
+)
+
+// AbsenceType represents the AbsenceType model. A VacationRequest or Vacation
+// without absence type is a regular vacation, which needs approval, deducts
+// from the VacationResource and is visible to teammates.
+type AbsenceType struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// RequiresApproval is false for absences, which are approved on creation,
+	// e.g. sick leave.
+	RequiresApproval bool `json:"requires_approval"`
+	// DeductsVacation is true for absences, which reduce the vacation balance.
+	DeductsVacation bool `json:"deducts_vacation"`
+	// VisibleToTeam is true for absences, which are listed to teammates.
+	VisibleToTeam bool       `json:"visible_to_team"`
+	CreatedAt     *time.Time `json:"created_at"`
+	DeletedAt     *time.Time `json:"deleted_at"`
+	UpdatedAt     *time.Time `json:"updated_at"`
+	// Version is incremented by every update, see ErrVersionConflict.
+	Version int `json:"version"`
+}
+
+// DefaultAbsenceTypes returns the absence types, every store starts with.
+func DefaultAbsenceTypes() []*AbsenceType {
+	return []*AbsenceType{
+		{ID: AbsenceTypeSickLeave, Name: "Sick leave"},
+		{ID: AbsenceTypeSpecialLeave, Name: "Special leave", RequiresApproval: true, VisibleToTeam: true},
+		{ID: AbsenceTypeUnpaidLeave, Name: "Unpaid leave", RequiresApproval: true, VisibleToTeam: true},
+		{ID: AbsenceTypeParentalLeave, Name: "Parental leave", RequiresApproval: true, VisibleToTeam: true},
+		{ID: AbsenceTypeTraining, Name: "Training", RequiresApproval: true, VisibleToTeam: true},
+	}
+}
+
+// Copy returns a deep copy.
+func (a *AbsenceType) Copy() *AbsenceType {
+	var createdAt, deletedAt, updatedAt *time.Time
+	if a.CreatedAt != nil {
+		ct := time.Unix(0, a.CreatedAt.UnixNano())
+		createdAt = &ct
+	}
+	if a.DeletedAt != nil {
+		dt := time.Unix(0, a.DeletedAt.UnixNano())
+		deletedAt = &dt
+	}
+	if a.UpdatedAt != nil {
+		ut := time.Unix(0, a.UpdatedAt.UnixNano())
+		updatedAt = &ut
+	}
+	return &AbsenceType{
+		ID:               a.ID,
+		Name:             a.Name,
+		RequiresApproval: a.RequiresApproval,
+		DeductsVacation:  a.DeductsVacation,
+		VisibleToTeam:    a.VisibleToTeam,
+		CreatedAt:        createdAt,
+		DeletedAt:        deletedAt,
+		UpdatedAt:        updatedAt,
+		Version:          a.Version,
+	}
+}
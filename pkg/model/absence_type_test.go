@@ -0,0 +1,50 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAbsenceType_Copy(t *testing.T) {
+	now := time.Now()
+	tt := []struct {
+		name     string
+		original *AbsenceType
+	}{
+		{
+			name: "expected",
+			original: &AbsenceType{
+				ID:               "test-absence-type-id",
+				Name:             "test-absence-type-name",
+				RequiresApproval: true,
+				DeductsVacation:  true,
+				VisibleToTeam:    true,
+				CreatedAt:        func() *time.Time { tmp := now.Add(10 * time.Minute); return &tmp }(),
+				UpdatedAt:        func() *time.Time { tmp := now.Add(15 * time.Minute); return &tmp }(),
+				DeletedAt:        func() *time.Time { tmp := now.Add(30 * time.Minute); return &tmp }(),
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.original.Copy()
+			if !cmp.Equal(tc.original, got) {
+				t.Fatal(cmp.Diff(tc.original, got))
+			}
+			got.ID += "absence-type-id"
+			got.Name = "absence-type-name"
+			got.RequiresApproval = false
+			got.DeductsVacation = false
+			got.VisibleToTeam = false
+			got.CreatedAt = nil
+			got.UpdatedAt = nil
+			got.DeletedAt = nil
+			if cmp.Equal(tc.original, got) {
+				t.Fatal("copy should not be equal")
+			}
+		})
+	}
+}
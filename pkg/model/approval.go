@@ -0,0 +1,156 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidApprovalPolicy is returned if an approval policy can not be parsed.
+var ErrInvalidApprovalPolicy = errors.New("invalid approval policy")
+
+// ApproverRole describes who is allowed to approve an ApprovalStep.
+type ApproverRole string
+
+const (
+	// ApproverParent allows any parent of the requesting user, parent is
+	// recursive in this case. Users without parent are approved by admins and
+	// HR.
+	ApproverParent ApproverRole = "parent"
+	// ApproverManager allows the direct parent of the requesting user.
+	ApproverManager ApproverRole = "manager"
+	// ApproverTeamOwner allows the owner of any team, the requesting user is a
+	// member of.
+	ApproverTeamOwner ApproverRole = "team_owner"
+	// ApproverTeam allows any member of the team of the step, e.g. HR.
+	ApproverTeam ApproverRole = "team"
+)
+
+// ApprovalStep is a single step of the approval chain of a VacationRequest.
+type ApprovalStep struct {
+	Role ApproverRole `json:"role"`
+	// TeamID refers to the Team of the approvers of an ApproverTeam step.
+	TeamID *string `json:"team_id,omitempty"`
+	// ApprovedBy refers to the User, who approved the step.
+	ApprovedBy *string `json:"approved_by"`
+	// OnBehalfOf refers to the User, who delegated the approval to ApprovedBy.
+	OnBehalfOf *string    `json:"on_behalf_of"`
+	ApprovedAt *time.Time `json:"approved_at"`
+}
+
+// Approved reports whether the step is completed.
+func (a *ApprovalStep) Approved() bool {
+	return a.ApprovedBy != nil
+}
+
+// Copy returns a deep copy.
+func (a *ApprovalStep) Copy() *ApprovalStep {
+	var teamID, approvedBy, onBehalfOf *string
+	if a.TeamID != nil {
+		tID := *a.TeamID
+		teamID = &tID
+	}
+	if a.ApprovedBy != nil {
+		ab := *a.ApprovedBy
+		approvedBy = &ab
+	}
+	if a.OnBehalfOf != nil {
+		ob := *a.OnBehalfOf
+		onBehalfOf = &ob
+	}
+	var approvedAt *time.Time
+	if a.ApprovedAt != nil {
+		at := time.Unix(0, a.ApprovedAt.UnixNano())
+		approvedAt = &at
+	}
+	return &ApprovalStep{
+		Role:       a.Role,
+		TeamID:     teamID,
+		ApprovedBy: approvedBy,
+		OnBehalfOf: onBehalfOf,
+		ApprovedAt: approvedAt,
+	}
+}
+
+// ApprovalRule adds an approval step to the chain of a VacationRequest.
+type ApprovalRule struct {
+	Role ApproverRole
+	// TeamID refers to the Team of the approvers of an ApproverTeam rule.
+	TeamID string
+	// MinDays limits the rule to requests, which are longer than the given
+	// number of working days. 0 applies the rule to all requests.
+	MinDays float64
+}
+
+// ApprovalPolicy describes the ordered approval chain of vacation requests.
+type ApprovalPolicy []ApprovalRule
+
+// DefaultApprovalPolicy returns a policy, which requires the approval of any
+// parent of the requesting user.
+func DefaultApprovalPolicy() ApprovalPolicy {
+	return ApprovalPolicy{{Role: ApproverParent}}
+}
+
+// ParseApprovalPolicy reads a comma separated list of approval rules. A rule
+// consists of a role, the team of a team rule follows after a colon and the
+// minimum of working days after a greater-than sign, e.g.
+// "manager,team_owner,team:<hr-team-id>>10".
+func ParseApprovalPolicy(policy string) (ApprovalPolicy, error) {
+	var p ApprovalPolicy
+	for _, raw := range strings.Split(policy, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		var rule ApprovalRule
+		if i := strings.Index(raw, ">"); i >= 0 {
+			days, err := strconv.ParseFloat(raw[i+1:], 64)
+			if err != nil || days < 0 {
+				return nil, fmt.Errorf("%w: invalid days of rule %s", ErrInvalidApprovalPolicy, raw)
+			}
+			rule.MinDays = days
+			raw = raw[:i]
+		}
+		if i := strings.Index(raw, ":"); i >= 0 {
+			rule.TeamID = raw[i+1:]
+			raw = raw[:i]
+		}
+		rule.Role = ApproverRole(raw)
+		switch rule.Role {
+		case ApproverParent, ApproverManager, ApproverTeamOwner:
+			if rule.TeamID != "" {
+				return nil, fmt.Errorf("%w: team is only allowed for %s rules", ErrInvalidApprovalPolicy, ApproverTeam)
+			}
+		case ApproverTeam:
+			if rule.TeamID == "" {
+				return nil, fmt.Errorf("%w: missing team of %s rule", ErrInvalidApprovalPolicy, ApproverTeam)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unknown role %s", ErrInvalidApprovalPolicy, rule.Role)
+		}
+		p = append(p, rule)
+	}
+	if len(p) == 0 {
+		return nil, fmt.Errorf("%w: no rules", ErrInvalidApprovalPolicy)
+	}
+	return p, nil
+}
+
+// Steps returns the approval chain of a request of the given working days.
+func (p ApprovalPolicy) Steps(workingDays float64) []ApprovalStep {
+	steps := make([]ApprovalStep, 0, len(p))
+	for _, rule := range p {
+		if rule.MinDays > 0 && workingDays <= rule.MinDays {
+			continue
+		}
+		step := ApprovalStep{Role: rule.Role}
+		if rule.TeamID != "" {
+			teamID := rule.TeamID
+			step.TeamID = &teamID
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
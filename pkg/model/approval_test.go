@@ -0,0 +1,113 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseApprovalPolicy(t *testing.T) {
+	tt := []struct {
+		name    string
+		policy  string
+		want    ApprovalPolicy
+		wantErr bool
+	}{
+		{
+			name:   "parent",
+			policy: "parent",
+			want:   ApprovalPolicy{{Role: ApproverParent}},
+		},
+		{
+			name:   "manager and team owner",
+			policy: "manager, team_owner",
+			want:   ApprovalPolicy{{Role: ApproverManager}, {Role: ApproverTeamOwner}},
+		},
+		{
+			name:   "hr for long requests",
+			policy: "manager,team:hr-team-id>10",
+			want: ApprovalPolicy{
+				{Role: ApproverManager},
+				{Role: ApproverTeam, TeamID: "hr-team-id", MinDays: 10},
+			},
+		},
+		{
+			name:    "empty",
+			policy:  "",
+			wantErr: true,
+		},
+		{
+			name:    "unknown role",
+			policy:  "ceo",
+			wantErr: true,
+		},
+		{
+			name:    "team without id",
+			policy:  "team",
+			wantErr: true,
+		},
+		{
+			name:    "manager with team",
+			policy:  "manager:hr-team-id",
+			wantErr: true,
+		},
+		{
+			name:    "invalid days",
+			policy:  "team:hr-team-id>ten",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseApprovalPolicy(tc.policy)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("want error: %t, got: %v", tc.wantErr, err)
+			}
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidApprovalPolicy) {
+					t.Fatalf("want: %v, got: %v", ErrInvalidApprovalPolicy, err)
+				}
+				return
+			}
+			if !cmp.Equal(tc.want, got) {
+				t.Fatal(cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
+
+func TestApprovalPolicy_Steps(t *testing.T) {
+	hr := "hr-team-id"
+	policy := ApprovalPolicy{
+		{Role: ApproverManager},
+		{Role: ApproverTeamOwner},
+		{Role: ApproverTeam, TeamID: hr, MinDays: 10},
+	}
+	tt := []struct {
+		name        string
+		workingDays float64
+		want        []ApprovalStep
+	}{
+		{
+			name:        "short request",
+			workingDays: 10,
+			want:        []ApprovalStep{{Role: ApproverManager}, {Role: ApproverTeamOwner}},
+		},
+		{
+			name:        "long request",
+			workingDays: 10.5,
+			want:        []ApprovalStep{{Role: ApproverManager}, {Role: ApproverTeamOwner}, {Role: ApproverTeam, TeamID: &hr}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got := policy.Steps(tc.workingDays)
+			if !cmp.Equal(tc.want, got) {
+				t.Fatal(cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
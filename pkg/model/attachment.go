@@ -0,0 +1,144 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidAttachment is returned if an Attachment is incomplete.
+	ErrInvalidAttachment = errors.New("invalid attachment")
+	// ErrAttachmentTooLarge is returned if the content of an Attachment
+	// exceeds the size limit of the AttachmentPolicy.
+	ErrAttachmentTooLarge = errors.New("attachment too large")
+	// ErrUnsupportedContentType is returned if the content type of an
+	// Attachment is not part of the allowlist of the AttachmentPolicy.
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+)
+
+// AttachmentResource names the kind of record an Attachment belongs to.
+type AttachmentResource string
+
+const (
+	// AttachmentVacationRequest attaches a document to a VacationRequest.
+	AttachmentVacationRequest AttachmentResource = "vacation_request"
+	// AttachmentVacation attaches a document to a Vacation.
+	AttachmentVacation AttachmentResource = "vacation"
+)
+
+// Attachment represents the Attachment model. The content itself is kept in a
+// blob store by the ID of the attachment, e.g. a medical certificate of a sick
+// leave or the invitation of a special leave.
+type Attachment struct {
+	ID           string             `json:"id"`
+	ResourceKind AttachmentResource `json:"resource_kind"`
+	// ResourceID refers to the VacationRequest or Vacation, depending on
+	// ResourceKind.
+	ResourceID string `json:"resource_id"`
+	// UserID refers to the User, the resource belongs to.
+	UserID      string `json:"user_id"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	// Size of the content in bytes.
+	Size int64 `json:"size"`
+	// Checksum is the hex encoded SHA-256 sum of the content.
+	Checksum  string     `json:"checksum"`
+	CreatedAt *time.Time `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// Validate verifies that the attachment belongs to a known resource and
+// carries the metadata of its content.
+func (a *Attachment) Validate() error {
+	if a.ResourceKind != AttachmentVacationRequest && a.ResourceKind != AttachmentVacation {
+		return fmt.Errorf("%w: unknown resource %s", ErrInvalidAttachment, a.ResourceKind)
+	}
+	if a.ResourceID == "" || a.UserID == "" {
+		return fmt.Errorf("%w: missing resource or user", ErrInvalidAttachment)
+	}
+	if a.FileName == "" || a.ContentType == "" || a.Checksum == "" {
+		return fmt.Errorf("%w: missing file name, content type or checksum", ErrInvalidAttachment)
+	}
+	return nil
+}
+
+// Copy returns a deep copy.
+func (a *Attachment) Copy() *Attachment {
+	var createdAt, deletedAt, updatedAt *time.Time
+	if a.CreatedAt != nil {
+		ct := time.Unix(0, a.CreatedAt.UnixNano())
+		createdAt = &ct
+	}
+	if a.DeletedAt != nil {
+		dt := time.Unix(0, a.DeletedAt.UnixNano())
+		deletedAt = &dt
+	}
+	if a.UpdatedAt != nil {
+		ut := time.Unix(0, a.UpdatedAt.UnixNano())
+		updatedAt = &ut
+	}
+	return &Attachment{
+		ID:           a.ID,
+		ResourceKind: a.ResourceKind,
+		ResourceID:   a.ResourceID,
+		UserID:       a.UserID,
+		FileName:     a.FileName,
+		ContentType:  a.ContentType,
+		Size:         a.Size,
+		Checksum:     a.Checksum,
+		CreatedAt:    createdAt,
+		DeletedAt:    deletedAt,
+		UpdatedAt:    updatedAt,
+	}
+}
+
+// AttachmentPolicy limits the documents, which can be attached.
+type AttachmentPolicy struct {
+	// MaxSize is the maximum size of an attachment in bytes.
+	MaxSize int64
+	// ContentTypes is the allowlist of media types, e.g. application/pdf.
+	ContentTypes []string
+}
+
+// DefaultAttachmentContentTypes covers scanned and digital documents.
+const DefaultAttachmentContentTypes = "application/pdf,image/jpeg,image/png"
+
+// Check verifies the size and content type of an attachment against the
+// policy. Parameters of the content type, e.g. charset, are ignored.
+func (p AttachmentPolicy) Check(size int64, contentType string) error {
+	if size > p.MaxSize {
+		return fmt.Errorf("%w: %d of %d bytes", ErrAttachmentTooLarge, size, p.MaxSize)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+	}
+	for _, allowed := range p.ContentTypes {
+		if allowed == mediaType {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupportedContentType, mediaType)
+}
+
+// ParseContentTypes reads a comma separated list of media types, e.g.
+// "application/pdf,image/png".
+func ParseContentTypes(contentTypes string) ([]string, error) {
+	var result []string
+	for _, raw := range strings.Split(contentTypes, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		mediaType, params, err := mime.ParseMediaType(raw)
+		if err != nil || len(params) != 0 {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, raw)
+		}
+		result = append(result, mediaType)
+	}
+	return result, nil
+}
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAttachment_Validate(t *testing.T) {
+	valid := func() *Attachment {
+		return &Attachment{
+			ResourceKind: AttachmentVacationRequest,
+			ResourceID:   "request",
+			UserID:       "user",
+			FileName:     "certificate.pdf",
+			ContentType:  "application/pdf",
+			Checksum:     "checksum",
+		}
+	}
+	tt := []struct {
+		name       string
+		attachment func() *Attachment
+		wantErr    bool
+	}{
+		{name: "valid", attachment: valid},
+		{
+			name:       "unknown resource",
+			attachment: func() *Attachment { a := valid(); a.ResourceKind = "team"; return a },
+			wantErr:    true,
+		},
+		{
+			name:       "missing resource",
+			attachment: func() *Attachment { a := valid(); a.ResourceID = ""; return a },
+			wantErr:    true,
+		},
+		{
+			name:       "missing checksum",
+			attachment: func() *Attachment { a := valid(); a.Checksum = ""; return a },
+			wantErr:    true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.attachment().Validate()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidAttachment) {
+				t.Fatalf("expected %v, got: %v", ErrInvalidAttachment, err)
+			}
+		})
+	}
+}
+
+func TestAttachmentPolicy_Check(t *testing.T) {
+	policy := AttachmentPolicy{MaxSize: 1024, ContentTypes: []string{"application/pdf", "image/png"}}
+	tt := []struct {
+		name        string
+		size        int64
+		contentType string
+		wantErr     error
+	}{
+		{name: "allowed", size: 1024, contentType: "application/pdf"},
+		{name: "allowed with parameter", size: 10, contentType: "image/png; charset=utf-8"},
+		{name: "too large", size: 1025, contentType: "application/pdf", wantErr: ErrAttachmentTooLarge},
+		{name: "not allowed", size: 10, contentType: "text/html", wantErr: ErrUnsupportedContentType},
+		{name: "invalid", size: 10, contentType: "pdf/", wantErr: ErrUnsupportedContentType},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.Check(tc.size, tc.contentType)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected %v, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestParseContentTypes(t *testing.T) {
+	got, err := ParseContentTypes(DefaultAttachmentContentTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"application/pdf", "image/jpeg", "image/png"}
+	if !cmp.Equal(want, got) {
+		t.Fatal(cmp.Diff(want, got))
+	}
+	_, err = ParseContentTypes("application/pdf,text/plain; charset=utf-8")
+	if !errors.Is(err, ErrUnsupportedContentType) {
+		t.Fatalf("expected %v, got: %v", ErrUnsupportedContentType, err)
+	}
+}
+
+func TestAttachment_Copy(t *testing.T) {
+	now := time.Now()
+	original := &Attachment{
+		ID:           "test-attachment-id",
+		ResourceKind: AttachmentVacation,
+		ResourceID:   "test-vacation-id",
+		UserID:       "test-user-id",
+		FileName:     "invitation.pdf",
+		ContentType:  "application/pdf",
+		Size:         42,
+		Checksum:     "checksum",
+		CreatedAt:    &now,
+		UpdatedAt:    func() *time.Time { tmp := now.Add(15 * time.Minute); return &tmp }(),
+		DeletedAt:    func() *time.Time { tmp := now.Add(30 * time.Minute); return &tmp }(),
+	}
+	got := original.Copy()
+	if !cmp.Equal(original, got) {
+		t.Fatal(cmp.Diff(original, got))
+	}
+	got.ID = "changed"
+	got.Size = 0
+	*got.CreatedAt = now.Add(time.Minute)
+	got.DeletedAt = nil
+	if cmp.Equal(original, got) {
+		t.Fatal("copy should not be equal")
+	}
+	if !original.CreatedAt.Equal(now) {
+		t.Fatal("timestamp of the original should not be changed")
+	}
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrUnknownAuditAction is returned if an audit action is not known.
+var ErrUnknownAuditAction = errors.New("unknown audit action")
+
+// AuditAction names the kind of mutation recorded by an AuditEntry.
+type AuditAction string
+
+// Actions of the audit log.
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditApprove AuditAction = "approve"
+	AuditReject  AuditAction = "reject"
+	AuditRestore AuditAction = "restore"
+	AuditPurge   AuditAction = "purge"
+)
+
+// ParseAuditAction parses the given audit action.
+func ParseAuditAction(s string) (AuditAction, error) {
+	switch a := AuditAction(s); a {
+	case AuditCreate, AuditUpdate, AuditDelete, AuditApprove, AuditReject,
+		AuditRestore, AuditPurge:
+		return a, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownAuditAction, s)
+}
+
+// AuditEntry represents the AuditEntry model. Every mutation of an entry is
+// recorded by an AuditEntry, containing the entry before and after the
+// mutation.
+type AuditEntry struct {
+	ID string `json:"id"`
+	// ActorID is the user, who performed the mutation. Empty for mutations
+	// without authenticated user.
+	ActorID  string      `json:"actor_id"`
+	Action   AuditAction `json:"action"`
+	Entity   Entity      `json:"entity"`
+	EntityID string      `json:"entity_id"`
+	// Before is the JSON encoded entry before the mutation, null for creates.
+	Before json.RawMessage `json:"before"`
+	// After is the JSON encoded entry after the mutation, null for purges.
+	After     json.RawMessage `json:"after"`
+	RequestID string          `json:"request_id"`
+	CreatedAt *time.Time      `json:"created_at"`
+}
+
+// Copy returns a deep copy.
+func (a *AuditEntry) Copy() *AuditEntry {
+	var createdAt *time.Time
+	if a.CreatedAt != nil {
+		ct := time.Unix(0, a.CreatedAt.UnixNano())
+		createdAt = &ct
+	}
+	return &AuditEntry{
+		ID:        a.ID,
+		ActorID:   a.ActorID,
+		Action:    a.Action,
+		Entity:    a.Entity,
+		EntityID:  a.EntityID,
+		Before:    append(json.RawMessage(nil), a.Before...),
+		After:     append(json.RawMessage(nil), a.After...),
+		RequestID: a.RequestID,
+		CreatedAt: createdAt,
+	}
+}
+
+// AuditFilter restricts the listed audit entries, empty fields match every
+// entry.
+type AuditFilter struct {
+	ActorID   string
+	Action    AuditAction
+	Entity    Entity
+	EntityID  string
+	RequestID string
+	// From and To limit the creation time of the entries, both inclusive.
+	From *time.Time
+	To   *time.Time
+}
+
+// Match reports whether the given entry passes the filter.
+func (f AuditFilter) Match(a *AuditEntry) bool {
+	switch {
+	case f.ActorID != "" && a.ActorID != f.ActorID,
+		f.Action != "" && a.Action != f.Action,
+		f.Entity != "" && a.Entity != f.Entity,
+		f.EntityID != "" && a.EntityID != f.EntityID,
+		f.RequestID != "" && a.RequestID != f.RequestID:
+		return false
+	case a.CreatedAt == nil:
+		return f.From == nil && f.To == nil
+	case f.From != nil && a.CreatedAt.Before(*f.From),
+		f.To != nil && a.CreatedAt.After(*f.To):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuditFilter_Match(t *testing.T) {
+	createdAt := time.Date(2022, time.April, 5, 8, 0, 0, 0, time.UTC)
+	entry := &AuditEntry{
+		ActorID:   "actor",
+		Action:    AuditApprove,
+		Entity:    EntityVacationRequest,
+		EntityID:  "request",
+		RequestID: "id",
+		CreatedAt: &createdAt,
+	}
+	before := createdAt.Add(-time.Hour)
+	after := createdAt.Add(time.Hour)
+	tt := []struct {
+		name   string
+		filter AuditFilter
+		want   bool
+	}{
+		{name: "empty", filter: AuditFilter{}, want: true},
+		{name: "all fields", filter: AuditFilter{
+			ActorID: "actor", Action: AuditApprove, Entity: EntityVacationRequest,
+			EntityID: "request", RequestID: "id", From: &before, To: &after,
+		}, want: true},
+		{name: "inclusive period", filter: AuditFilter{From: &createdAt, To: &createdAt}, want: true},
+		{name: "other actor", filter: AuditFilter{ActorID: "other"}},
+		{name: "other action", filter: AuditFilter{Action: AuditUpdate}},
+		{name: "other entity", filter: AuditFilter{Entity: EntityVacation}},
+		{name: "other entity id", filter: AuditFilter{EntityID: "other"}},
+		{name: "other request", filter: AuditFilter{RequestID: "other"}},
+		{name: "created before", filter: AuditFilter{From: &after}},
+		{name: "created after", filter: AuditFilter{To: &before}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.filter.Match(entry); got != tc.want {
+				t.Fatalf("want: %t, got: %t", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SystemPrincipalID refers to the system as approver of automatically
+// approved requests.
+const SystemPrincipalID = "00000000-0000-0000-0000-000000000000"
+
+// ErrInvalidBlackoutPeriod is returned if a blackout period can not be parsed.
+var ErrInvalidBlackoutPeriod = errors.New("invalid blackout period")
+
+// BlackoutPeriod is a period, in which requests are not approved
+// automatically, e.g. the end of the fiscal year. From and To are both
+// inclusive.
+type BlackoutPeriod struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// Overlaps reports whether the period shares at least one day with the
+// given range.
+func (b BlackoutPeriod) Overlaps(from, to time.Time) bool {
+	return !from.After(b.To) && !to.Before(b.From)
+}
+
+// ParseBlackoutPeriods reads a comma separated list of periods, start and end
+// are separated by a colon, e.g. "2022-12-19:2023-01-06,2023-06-30:2023-06-30".
+func ParseBlackoutPeriods(periods string) ([]BlackoutPeriod, error) {
+	var result []BlackoutPeriod
+	for _, raw := range strings.Split(periods, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		bounds := strings.Split(raw, ":")
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidBlackoutPeriod, raw)
+		}
+		from, err := time.Parse("2006-01-02", bounds[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidBlackoutPeriod, raw)
+		}
+		to, err := time.Parse("2006-01-02", bounds[1])
+		if err != nil || to.Before(from) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidBlackoutPeriod, raw)
+		}
+		result = append(result, BlackoutPeriod{From: from, To: to})
+	}
+	return result, nil
+}
+
+// AutoApprovalPolicy describes low-risk vacation requests, which are approved
+// by the system without passing the approval chain. Only regular vacations
+// are approved automatically, absence types follow their own rules.
+type AutoApprovalPolicy struct {
+	// MaxDays is the maximum of working days of a request, 0 disables the
+	// auto-approval.
+	MaxDays float64
+	// MinNoticeDays is the minimum of days between submission and start of
+	// a request.
+	MinNoticeDays int
+	// MinTeamAvailability is the minimum share of team members, which are
+	// available on every working day of the request, e.g. 0.5. 0 disables
+	// the check.
+	MinTeamAvailability float64
+	// Blackouts are periods, in which no request is approved automatically.
+	Blackouts []BlackoutPeriod
+}
+
+// Enabled reports whether the policy approves any request.
+func (p AutoApprovalPolicy) Enabled() bool {
+	return p.MaxDays > 0
+}
+
+// Matches reports whether the given request of the given working days is
+// approved automatically, if submitted at the given time. teamAvailability is
+// the lowest share of available team members during the request, including
+// the requesting user.
+func (p AutoApprovalPolicy) Matches(vR *VacationRequest, workingDays, teamAvailability float64, at time.Time) bool {
+	if !p.Enabled() || vR.AbsenceTypeID != nil {
+		return false
+	}
+	if workingDays > p.MaxDays {
+		return false
+	}
+	today := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, vR.From.Location())
+	if vR.From.Before(today.AddDate(0, 0, p.MinNoticeDays)) {
+		return false
+	}
+	if teamAvailability < p.MinTeamAvailability {
+		return false
+	}
+	for _, b := range p.Blackouts {
+		if b.Overlaps(vR.From, vR.To) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,156 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseBlackoutPeriods(t *testing.T) {
+	tt := []struct {
+		name    string
+		periods string
+		want    []BlackoutPeriod
+		wantErr bool
+	}{
+		{
+			name:    "empty",
+			periods: "",
+		},
+		{
+			name:    "two periods",
+			periods: "2022-12-19:2023-01-06, 2023-06-30:2023-06-30",
+			want: []BlackoutPeriod{
+				{From: time.Date(2022, 12, 19, 0, 0, 0, 0, time.UTC), To: time.Date(2023, 1, 6, 0, 0, 0, 0, time.UTC)},
+				{From: time.Date(2023, 6, 30, 0, 0, 0, 0, time.UTC), To: time.Date(2023, 6, 30, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:    "missing end",
+			periods: "2022-12-19",
+			wantErr: true,
+		},
+		{
+			name:    "invalid date",
+			periods: "2022-12-19:2023-13-01",
+			wantErr: true,
+		},
+		{
+			name:    "end before start",
+			periods: "2023-01-06:2022-12-19",
+			wantErr: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseBlackoutPeriods(tc.periods)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ParseBlackoutPeriods() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAutoApprovalPolicy_Matches(t *testing.T) {
+	absenceTypeID := AbsenceTypeTraining
+	now := time.Date(2022, 6, 1, 15, 4, 0, 0, time.UTC)
+	policy := AutoApprovalPolicy{
+		MaxDays:             2,
+		MinNoticeDays:       7,
+		MinTeamAvailability: 0.5,
+		Blackouts: []BlackoutPeriod{
+			{From: time.Date(2022, 6, 27, 0, 0, 0, 0, time.UTC), To: time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	tt := []struct {
+		name         string
+		policy       AutoApprovalPolicy
+		request      *VacationRequest
+		days         float64
+		availability float64
+		want         bool
+	}{
+		{
+			name:   "matching",
+			policy: policy,
+			request: &VacationRequest{
+				From: time.Date(2022, 6, 8, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2022, 6, 9, 0, 0, 0, 0, time.UTC),
+			},
+			days:         2,
+			availability: 0.5,
+			want:         true,
+		},
+		{
+			name: "disabled",
+			request: &VacationRequest{
+				From: time.Date(2022, 6, 8, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2022, 6, 9, 0, 0, 0, 0, time.UTC),
+			},
+			days:         2,
+			availability: 1,
+		},
+		{
+			name:   "absence type",
+			policy: policy,
+			request: &VacationRequest{
+				AbsenceTypeID: &absenceTypeID,
+				From:          time.Date(2022, 6, 8, 0, 0, 0, 0, time.UTC),
+				To:            time.Date(2022, 6, 9, 0, 0, 0, 0, time.UTC),
+			},
+			days:         2,
+			availability: 1,
+		},
+		{
+			name:   "too long",
+			policy: policy,
+			request: &VacationRequest{
+				From: time.Date(2022, 6, 8, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2022, 6, 10, 0, 0, 0, 0, time.UTC),
+			},
+			days:         3,
+			availability: 1,
+		},
+		{
+			name:   "short notice",
+			policy: policy,
+			request: &VacationRequest{
+				From: time.Date(2022, 6, 7, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2022, 6, 7, 0, 0, 0, 0, time.UTC),
+			},
+			days:         1,
+			availability: 1,
+		},
+		{
+			name:   "low availability",
+			policy: policy,
+			request: &VacationRequest{
+				From: time.Date(2022, 6, 8, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2022, 6, 8, 0, 0, 0, 0, time.UTC),
+			},
+			days:         1,
+			availability: 0.4,
+		},
+		{
+			name:   "blackout",
+			policy: policy,
+			request: &VacationRequest{
+				From: time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC),
+			},
+			days:         1,
+			availability: 1,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.policy.Matches(tc.request, tc.days, tc.availability, now); got != tc.want {
+				t.Errorf("Matches() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
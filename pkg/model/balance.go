@@ -0,0 +1,19 @@
+package model
+
+// Balance represents the vacation balance of a user for one calendar year.
+// All values are measured in days.
+type Balance struct {
+	UserID      string  `json:"user_id"`
+	Year        int     `json:"year"`
+	Entitlement float64 `json:"entitlement"`
+	// Carried are the days carried over from the previous year.
+	Carried float64 `json:"carried"`
+	// CarriedTaken is the part of Taken, which is consumed from Carried.
+	CarriedTaken float64 `json:"carried_taken"`
+	// CarriedExpired are the carried days, which were not taken until their
+	// expiry date.
+	CarriedExpired float64 `json:"carried_expired"`
+	Taken          float64 `json:"taken"`
+	Pending        float64 `json:"pending"`
+	Remaining      float64 `json:"remaining"`
+}
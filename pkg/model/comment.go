@@ -0,0 +1,67 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxCommentLength is the maximum number of characters of a comment.
+const MaxCommentLength = 2000
+
+// ErrInvalidComment is returned if a Comment is incomplete or too long.
+var ErrInvalidComment = errors.New("invalid comment")
+
+// Comment represents the Comment model. Requesting users and approvers
+// discuss a VacationRequest by comments, e.g. to ask for a different period.
+type Comment struct {
+	ID                string     `json:"id"`
+	VacationRequestID string     `json:"vacation_request_id"`
+	AuthorID          string     `json:"author_id"`
+	Text              string     `json:"text"`
+	CreatedAt         *time.Time `json:"created_at"`
+	DeletedAt         *time.Time `json:"deleted_at"`
+	UpdatedAt         *time.Time `json:"updated_at"`
+}
+
+// Validate verifies that the comment belongs to a request, has an author and
+// a text of at most MaxCommentLength characters.
+func (c *Comment) Validate() error {
+	if c.VacationRequestID == "" || c.AuthorID == "" {
+		return fmt.Errorf("%w: missing vacation-request or author", ErrInvalidComment)
+	}
+	if strings.TrimSpace(c.Text) == "" {
+		return fmt.Errorf("%w: missing text", ErrInvalidComment)
+	}
+	if len([]rune(c.Text)) > MaxCommentLength {
+		return fmt.Errorf("%w: text exceeds %d characters", ErrInvalidComment, MaxCommentLength)
+	}
+	return nil
+}
+
+// Copy returns a deep copy.
+func (c *Comment) Copy() *Comment {
+	var createdAt, deletedAt, updatedAt *time.Time
+	if c.CreatedAt != nil {
+		ct := time.Unix(0, c.CreatedAt.UnixNano())
+		createdAt = &ct
+	}
+	if c.DeletedAt != nil {
+		dt := time.Unix(0, c.DeletedAt.UnixNano())
+		deletedAt = &dt
+	}
+	if c.UpdatedAt != nil {
+		ut := time.Unix(0, c.UpdatedAt.UnixNano())
+		updatedAt = &ut
+	}
+	return &Comment{
+		ID:                c.ID,
+		VacationRequestID: c.VacationRequestID,
+		AuthorID:          c.AuthorID,
+		Text:              c.Text,
+		CreatedAt:         createdAt,
+		DeletedAt:         deletedAt,
+		UpdatedAt:         updatedAt,
+	}
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestComment_Validate(t *testing.T) {
+	tt := []struct {
+		name    string
+		comment *Comment
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			comment: &Comment{VacationRequestID: "request", AuthorID: "author", Text: "can you move it one week?"},
+		},
+		{
+			name:    "missing author",
+			comment: &Comment{VacationRequestID: "request", Text: "can you move it one week?"},
+			wantErr: true,
+		},
+		{
+			name:    "blank text",
+			comment: &Comment{VacationRequestID: "request", AuthorID: "author", Text: " \n"},
+			wantErr: true,
+		},
+		{
+			name:    "text too long",
+			comment: &Comment{VacationRequestID: "request", AuthorID: "author", Text: strings.Repeat("a", MaxCommentLength+1)},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.comment.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidComment) {
+				t.Fatalf("expected %v, got: %v", ErrInvalidComment, err)
+			}
+		})
+	}
+}
+
+func TestComment_Copy(t *testing.T) {
+	now := time.Now()
+	original := &Comment{
+		ID:                "test-comment-id",
+		VacationRequestID: "test-request-id",
+		AuthorID:          "test-author-id",
+		Text:              "can you move it one week?",
+		CreatedAt:         &now,
+		UpdatedAt:         func() *time.Time { tmp := now.Add(15 * time.Minute); return &tmp }(),
+		DeletedAt:         func() *time.Time { tmp := now.Add(30 * time.Minute); return &tmp }(),
+	}
+	got := original.Copy()
+	if !cmp.Equal(original, got) {
+		t.Fatal(cmp.Diff(original, got))
+	}
+	got.ID = "changed"
+	got.Text = "changed"
+	*got.CreatedAt = now.Add(time.Minute)
+	got.DeletedAt = nil
+	if cmp.Equal(original, got) {
+		t.Fatal("copy should not be equal")
+	}
+	if !original.CreatedAt.Equal(now) {
+		t.Fatal("timestamp of the original should not be changed")
+	}
+}
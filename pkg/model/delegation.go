@@ -0,0 +1,76 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidDelegation is returned if a Delegation does not name two
+// different users or its period is invalid.
+var ErrInvalidDelegation = errors.New("invalid delegation")
+
+// Delegation represents the Delegation model. During its period the delegate
+// is allowed to decide about vacation requests on behalf of the delegator,
+// e.g. while a manager is on vacation.
+type Delegation struct {
+	ID string `json:"id"`
+	// DelegatorID refers to the User, who delegates the approval rights.
+	DelegatorID string `json:"delegator_id"`
+	// DelegateID refers to the User, who receives the approval rights.
+	DelegateID string `json:"delegate_id"`
+	// From and To are both inclusive.
+	From      time.Time  `json:"from"`
+	To        time.Time  `json:"to"`
+	CreatedAt *time.Time `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// Validate verifies that delegator and delegate are set and differ, and the
+// period does not end before it starts.
+func (d *Delegation) Validate() error {
+	if d.DelegatorID == "" || d.DelegateID == "" {
+		return fmt.Errorf("%w: missing delegator or delegate", ErrInvalidDelegation)
+	}
+	if d.DelegatorID == d.DelegateID {
+		return fmt.Errorf("%w: user can not delegate to itself", ErrInvalidDelegation)
+	}
+	if d.From.IsZero() || d.To.IsZero() || d.To.Before(d.From) {
+		return fmt.Errorf("%w: invalid period", ErrInvalidDelegation)
+	}
+	return nil
+}
+
+// Active reports whether the delegation is valid at the given time. The day
+// of To is included.
+func (d *Delegation) Active(at time.Time) bool {
+	return !at.Before(d.From) && at.Before(d.To.AddDate(0, 0, 1))
+}
+
+// Copy returns a deep copy.
+func (d *Delegation) Copy() *Delegation {
+	var createdAt, deletedAt, updatedAt *time.Time
+	if d.CreatedAt != nil {
+		ct := time.Unix(0, d.CreatedAt.UnixNano())
+		createdAt = &ct
+	}
+	if d.DeletedAt != nil {
+		dt := time.Unix(0, d.DeletedAt.UnixNano())
+		deletedAt = &dt
+	}
+	if d.UpdatedAt != nil {
+		ut := time.Unix(0, d.UpdatedAt.UnixNano())
+		updatedAt = &ut
+	}
+	return &Delegation{
+		ID:          d.ID,
+		DelegatorID: d.DelegatorID,
+		DelegateID:  d.DelegateID,
+		From:        d.From,
+		To:          d.To,
+		CreatedAt:   createdAt,
+		DeletedAt:   deletedAt,
+		UpdatedAt:   updatedAt,
+	}
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDelegation_Copy(t *testing.T) {
+	now := time.Now()
+	tt := []struct {
+		name     string
+		original *Delegation
+	}{
+		{
+			name: "expected",
+			original: &Delegation{
+				ID:          "test-delegation-id",
+				DelegatorID: "test-manager-id",
+				DelegateID:  "test-deputy-id",
+				From:        now.Add(time.Minute),
+				To:          now.Add(time.Hour),
+				CreatedAt:   func() *time.Time { tmp := now.Add(10 * time.Minute); return &tmp }(),
+				UpdatedAt:   func() *time.Time { tmp := now.Add(15 * time.Minute); return &tmp }(),
+				DeletedAt:   func() *time.Time { tmp := now.Add(30 * time.Minute); return &tmp }(),
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.original.Copy()
+			if !cmp.Equal(tc.original, got) {
+				t.Fatal(cmp.Diff(tc.original, got))
+			}
+			got.ID += "delegation-id"
+			got.DelegatorID = "manager-id-delegation"
+			got.DelegateID = "deputy-id-delegation"
+			got.From = time.Now()
+			got.To = time.Now()
+			got.CreatedAt = nil
+			got.UpdatedAt = nil
+			got.DeletedAt = nil
+			if cmp.Equal(tc.original, got) {
+				t.Fatal("copy should not be equal")
+			}
+		})
+	}
+}
+
+func TestDelegation_Active(t *testing.T) {
+	d := &Delegation{
+		From: time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2022, time.August, 14, 0, 0, 0, 0, time.UTC),
+	}
+	tt := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{name: "before", at: time.Date(2022, time.July, 31, 23, 59, 0, 0, time.UTC)},
+		{name: "first day", at: time.Date(2022, time.August, 1, 8, 0, 0, 0, time.UTC), want: true},
+		{name: "last day", at: time.Date(2022, time.August, 14, 18, 0, 0, 0, time.UTC), want: true},
+		{name: "after", at: time.Date(2022, time.August, 15, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := d.Active(tc.at); got != tc.want {
+				t.Fatalf("want: %t, got: %t", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnknownEntity is returned if an entity can not be restored or purged.
+	ErrUnknownEntity = errors.New("unknown entity")
+	// ErrEntityNotFound is returned if no entry of an entity exists with the
+	// given id.
+	ErrEntityNotFound = errors.New("entity not found")
+	// ErrNotDeleted is returned if an entry is restored or purged, which is
+	// not soft deleted.
+	ErrNotDeleted = errors.New("entity is not deleted")
+	// ErrRetentionPeriod is returned if an entry is purged, which was deleted
+	// within the retention period.
+	ErrRetentionPeriod = errors.New("entity is within the retention period")
+	// ErrEntityReferenced is returned if an entry is purged, which is still
+	// referenced by other entries.
+	ErrEntityReferenced = errors.New("entity is still referenced")
+	// ErrVersionConflict is returned if an entry is updated, which was changed
+	// since the given version was read.
+	ErrVersionConflict = errors.New("entity version conflict")
+)
+
+// Entity names a kind of entries, e.g. in the audit log. Except comments all
+// entities are soft deleted and can be restored or purged.
+type Entity string
+
+// Entities of the database.
+const (
+	EntityUser             Entity = "user"
+	EntityTeam             Entity = "team"
+	EntityVacation         Entity = "vacation"
+	EntityVacationRequest  Entity = "vacation-request"
+	EntityVacationResource Entity = "vacation-resource"
+	EntityAbsenceType      Entity = "absence-type"
+	EntityDelegation       Entity = "delegation"
+	EntityTeamRule         Entity = "team-rule"
+	EntityTeamMembership   Entity = "team-membership"
+	EntityAttachment       Entity = "attachment"
+	EntityComment          Entity = "comment"
+)
+
+// ParseEntity parses the given entity.
+func ParseEntity(s string) (Entity, error) {
+	switch e := Entity(s); e {
+	case EntityUser, EntityTeam, EntityVacation, EntityVacationRequest,
+		EntityVacationResource, EntityAbsenceType, EntityDelegation,
+		EntityTeamRule, EntityTeamMembership, EntityAttachment, EntityComment:
+		return e, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownEntity, s)
+}
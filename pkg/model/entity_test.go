@@ -0,0 +1,32 @@
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseEntity(t *testing.T) {
+	tt := []struct {
+		in      string
+		want    Entity
+		wantErr error
+	}{
+		{in: "user", want: EntityUser},
+		{in: "vacation-request", want: EntityVacationRequest},
+		{in: "team-membership", want: EntityTeamMembership},
+		{in: "comment", want: EntityComment},
+		{in: "holiday-calendar", wantErr: ErrUnknownEntity},
+		{in: "", wantErr: ErrUnknownEntity},
+	}
+	for _, tc := range tt {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := ParseEntity(tc.in)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error: %v, got: %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Fatalf("want: %q, got: %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrOverlappingAbsence is returned if a VacationRequest overlaps with
+// another request or Vacation of the same user.
+var ErrOverlappingAbsence = errors.New("overlapping absence")
+
+// OverlapError lists the records, which overlap with a VacationRequest.
+// It matches ErrOverlappingAbsence by errors.Is.
+type OverlapError struct {
+	VacationRequests []*VacationRequest `json:"vacation_requests"`
+	Vacations        []*Vacation        `json:"vacations"`
+}
+
+// Error implements the error interface.
+func (e *OverlapError) Error() string {
+	ids := make([]string, 0, len(e.VacationRequests)+len(e.Vacations))
+	for _, vR := range e.VacationRequests {
+		ids = append(ids, "vacation-request "+vR.ID)
+	}
+	for _, v := range e.Vacations {
+		ids = append(ids, "vacation "+v.ID)
+	}
+	return fmt.Sprintf("%s: %s", ErrOverlappingAbsence, strings.Join(ids, ", "))
+}
+
+// Is reports whether target is ErrOverlappingAbsence.
+func (e *OverlapError) Is(target error) bool {
+	return target == ErrOverlappingAbsence
+}
+
+// Blocking reports whether a request in status s reserves its period. Drafts
+// and closed requests do not block other requests.
+func (s VacationRequestStatus) Blocking() bool {
+	return s == StatusPending || s == StatusApproved
+}
+
+// CheckOverlaps returns an *OverlapError, if v overlaps with one of the given
+// blocking requests or vacations of the same user. The request itself, its
+// Vacation and deleted entries are ignored.
+func (v *VacationRequest) CheckOverlaps(requests []*VacationRequest, vacations []*Vacation) error {
+	var overlap OverlapError
+	for _, o := range requests {
+		if o.UserID != v.UserID || (v.ID != "" && o.ID == v.ID) || !o.Status.Blocking() || o.DeletedAt != nil {
+			continue
+		}
+		if absencesOverlap(v.From, v.To, v.Portion, v.Hours, o.From, o.To, o.Portion, o.Hours) {
+			overlap.VacationRequests = append(overlap.VacationRequests, o)
+		}
+	}
+	for _, o := range vacations {
+		if o.UserID != v.UserID || (v.VacationID != nil && o.ID == *v.VacationID) || o.DeletedAt != nil {
+			continue
+		}
+		if absencesOverlap(v.From, v.To, v.Portion, v.Hours, o.From, o.To, o.Portion, o.Hours) {
+			overlap.Vacations = append(overlap.Vacations, o)
+		}
+	}
+	if len(overlap.VacationRequests) == 0 && len(overlap.Vacations) == 0 {
+		return nil
+	}
+	return &overlap
+}
+
+// CheckOverlaps returns an *OverlapError, if v overlaps with one of the given
+// blocking requests or vacations of the same user. The vacation itself, the
+// request it got created from and deleted entries are ignored.
+func (v *Vacation) CheckOverlaps(requests []*VacationRequest, vacations []*Vacation) error {
+	probe := &VacationRequest{
+		UserID:     v.UserID,
+		From:       v.From,
+		To:         v.To,
+		Portion:    v.Portion,
+		Hours:      v.Hours,
+		VacationID: &v.ID,
+	}
+	for _, r := range requests {
+		if r.VacationID != nil && *r.VacationID == v.ID {
+			probe.ID = r.ID
+		}
+	}
+	return probe.CheckOverlaps(requests, vacations)
+}
+
+// absencesOverlap reports whether two absences share a day. Partial absences
+// of the same day only overlap, if they take more than the whole day together
+// or both take the same half of the day.
+func absencesOverlap(aFrom, aTo time.Time, aPortion Portion, aHours float64, bFrom, bTo time.Time, bPortion Portion, bHours float64) bool {
+	if dateOf(aFrom).After(dateOf(bTo)) || dateOf(bFrom).After(dateOf(aTo)) {
+		return false
+	}
+	aPartial := aPortion != "" && aPortion != PortionFullDay
+	bPartial := bPortion != "" && bPortion != PortionFullDay
+	if !aPartial || !bPartial {
+		return true
+	}
+	if aPortion == bPortion && aPortion != PortionHours {
+		return true
+	}
+	return aPortion.Fraction(aHours)+bPortion.Fraction(bHours) > 1
+}
+
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
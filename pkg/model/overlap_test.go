@@ -0,0 +1,175 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVacationRequest_CheckOverlaps(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2022, time.April, d, 0, 0, 0, 0, time.UTC)
+	}
+	vacationID := "vacation-id"
+	tt := []struct {
+		name          string
+		request       *VacationRequest
+		requests      []*VacationRequest
+		vacations     []*Vacation
+		wantRequests  int
+		wantVacations int
+	}{
+		{
+			name:    "no overlap",
+			request: &VacationRequest{ID: "a", UserID: "u", From: day(4), To: day(8)},
+			requests: []*VacationRequest{
+				{ID: "b", UserID: "u", Status: StatusPending, From: day(11), To: day(12)},
+			},
+			vacations: []*Vacation{
+				{ID: "c", UserID: "u", From: day(1), To: day(1)},
+			},
+		},
+		{
+			name:    "overlapping pending and approved requests",
+			request: &VacationRequest{ID: "a", UserID: "u", From: day(4), To: day(8)},
+			requests: []*VacationRequest{
+				{ID: "b", UserID: "u", Status: StatusPending, From: day(8), To: day(12)},
+				{ID: "c", UserID: "u", Status: StatusApproved, From: day(1), To: day(4)},
+			},
+			wantRequests: 2,
+		},
+		{
+			name:    "drafts, closed requests and itself do not block",
+			request: &VacationRequest{ID: "a", UserID: "u", Status: StatusPending, From: day(4), To: day(8)},
+			requests: []*VacationRequest{
+				{ID: "a", UserID: "u", Status: StatusPending, From: day(4), To: day(8)},
+				{ID: "b", UserID: "u", Status: StatusDraft, From: day(4), To: day(8)},
+				{ID: "c", UserID: "u", Status: StatusRejected, From: day(4), To: day(8)},
+				{ID: "d", UserID: "u", Status: StatusCancelled, From: day(4), To: day(8)},
+			},
+		},
+		{
+			name:    "other users do not block",
+			request: &VacationRequest{UserID: "u", From: day(4), To: day(8)},
+			requests: []*VacationRequest{
+				{ID: "b", UserID: "other", Status: StatusPending, From: day(4), To: day(8)},
+			},
+			vacations: []*Vacation{
+				{ID: "c", UserID: "other", From: day(4), To: day(8)},
+			},
+		},
+		{
+			name:    "overlapping vacation",
+			request: &VacationRequest{UserID: "u", From: day(4), To: day(8)},
+			vacations: []*Vacation{
+				{ID: "c", UserID: "u", From: day(6), To: day(6), Portion: PortionMorning},
+			},
+			wantVacations: 1,
+		},
+		{
+			name:    "own vacation",
+			request: &VacationRequest{ID: "a", UserID: "u", VacationID: &vacationID, From: day(4), To: day(8)},
+			vacations: []*Vacation{
+				{ID: vacationID, UserID: "u", From: day(4), To: day(8)},
+			},
+		},
+		{
+			name:    "morning and afternoon",
+			request: &VacationRequest{UserID: "u", From: day(4), To: day(4), Portion: PortionMorning},
+			requests: []*VacationRequest{
+				{ID: "b", UserID: "u", Status: StatusPending, From: day(4), To: day(4), Portion: PortionAfternoon},
+			},
+		},
+		{
+			name:    "two mornings",
+			request: &VacationRequest{UserID: "u", From: day(4), To: day(4), Portion: PortionMorning},
+			requests: []*VacationRequest{
+				{ID: "b", UserID: "u", Status: StatusPending, From: day(4), To: day(4), Portion: PortionMorning},
+			},
+			wantRequests: 1,
+		},
+		{
+			name:    "hours exceeding the day",
+			request: &VacationRequest{UserID: "u", From: day(4), To: day(4), Portion: PortionHours, Hours: 5},
+			vacations: []*Vacation{
+				{ID: "c", UserID: "u", From: day(4), To: day(4), Portion: PortionAfternoon},
+			},
+			wantVacations: 1,
+		},
+		{
+			name:    "hours within the day",
+			request: &VacationRequest{UserID: "u", From: day(4), To: day(4), Portion: PortionHours, Hours: 2},
+			vacations: []*Vacation{
+				{ID: "c", UserID: "u", From: day(4), To: day(4), Portion: PortionHours, Hours: 4},
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.request.CheckOverlaps(tc.requests, tc.vacations)
+			if tc.wantRequests == 0 && tc.wantVacations == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrOverlappingAbsence) {
+				t.Fatalf("expected %v, got: %v", ErrOverlappingAbsence, err)
+			}
+			var overlap *OverlapError
+			if !errors.As(err, &overlap) {
+				t.Fatalf("expected *OverlapError, got: %T", err)
+			}
+			if len(overlap.VacationRequests) != tc.wantRequests {
+				t.Errorf("invalid number of vacation-requests, want: %d, got: %d", tc.wantRequests, len(overlap.VacationRequests))
+			}
+			if len(overlap.Vacations) != tc.wantVacations {
+				t.Errorf("invalid number of vacations, want: %d, got: %d", tc.wantVacations, len(overlap.Vacations))
+			}
+		})
+	}
+}
+
+func TestVacation_CheckOverlaps(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2022, time.April, d, 0, 0, 0, 0, time.UTC)
+	}
+	vacationID := "vacation-id"
+	vacation := &Vacation{ID: vacationID, UserID: "u", From: day(4), To: day(8)}
+	tt := []struct {
+		name      string
+		requests  []*VacationRequest
+		vacations []*Vacation
+		wantErr   bool
+	}{
+		{
+			name: "itself and its approved request",
+			requests: []*VacationRequest{
+				{ID: "a", UserID: "u", Status: StatusApproved, VacationID: &vacationID, From: day(4), To: day(8)},
+			},
+			vacations: []*Vacation{vacation},
+		},
+		{
+			name: "overlapping pending request",
+			requests: []*VacationRequest{
+				{ID: "b", UserID: "u", Status: StatusPending, From: day(8), To: day(9)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "overlapping vacation",
+			vacations: []*Vacation{
+				{ID: "c", UserID: "u", From: day(1), To: day(4)},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := vacation.CheckOverlaps(tc.requests, tc.vacations)
+			if tc.wantErr != errors.Is(err, ErrOverlappingAbsence) {
+				t.Fatalf("expected overlap: %v, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
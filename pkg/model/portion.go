@@ -0,0 +1,77 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// HoursPerDay is the number of working hours of a full working day.
+const HoursPerDay = 8
+
+// ErrInvalidPortion is returned if the portion of an absence does not match
+// its period or hours.
+var ErrInvalidPortion = errors.New("invalid absence portion")
+
+// Portion describes which part of the working days of an absence is taken.
+type Portion string
+
+const (
+	// PortionFullDay marks an absence of whole working days.
+	PortionFullDay Portion = "full_day"
+	// PortionMorning marks an absence of the first half of a working day.
+	PortionMorning Portion = "morning"
+	// PortionAfternoon marks an absence of the second half of a working day.
+	PortionAfternoon Portion = "afternoon"
+	// PortionHours marks an absence of a number of hours of a working day.
+	PortionHours Portion = "hours"
+)
+
+// Fraction returns the part of a working day, which is covered by p.
+// The given hours are only taken into account for PortionHours.
+func (p Portion) Fraction(hours float64) float64 {
+	switch p {
+	case PortionMorning, PortionAfternoon:
+		return 0.5
+	case PortionHours:
+		return hours / HoursPerDay
+	default:
+		return 1
+	}
+}
+
+// validatePortion verifies the given portion and hours. Half-day and hourly
+// absences must start and end at the same day. If no portion is set, a full
+// day absence is assumed.
+func validatePortion(p *Portion, hours float64, from, to time.Time) error {
+	if *p == "" {
+		*p = PortionFullDay
+	}
+	switch *p {
+	case PortionFullDay:
+		if hours != 0 {
+			return fmt.Errorf("%w: hours are only allowed for %s", ErrInvalidPortion, PortionHours)
+		}
+		return nil
+	case PortionMorning, PortionAfternoon:
+		if hours != 0 {
+			return fmt.Errorf("%w: hours are only allowed for %s", ErrInvalidPortion, PortionHours)
+		}
+	case PortionHours:
+		if hours <= 0 || hours >= HoursPerDay {
+			return fmt.Errorf("%w: hours must be between 0 and %d", ErrInvalidPortion, HoursPerDay)
+		}
+	default:
+		return fmt.Errorf("%w: unknown portion %s", ErrInvalidPortion, *p)
+	}
+	if !sameDay(from, to) {
+		return fmt.Errorf("%w: %s absence must start and end at the same day", ErrInvalidPortion, *p)
+	}
+	return nil
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPortion_Fraction(t *testing.T) {
+	tt := []struct {
+		name    string
+		portion Portion
+		hours   float64
+		want    float64
+	}{
+		{name: "default", want: 1},
+		{name: "full day", portion: PortionFullDay, want: 1},
+		{name: "morning", portion: PortionMorning, want: 0.5},
+		{name: "afternoon", portion: PortionAfternoon, want: 0.5},
+		{name: "hours", portion: PortionHours, hours: 2, want: 0.25},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.portion.Fraction(tc.hours); got != tc.want {
+				t.Fatalf("want: %f, got: %f", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestVacation_Validate(t *testing.T) {
+	day := time.Date(2022, time.April, 8, 0, 0, 0, 0, time.UTC)
+	tt := []struct {
+		name          string
+		vacation      *Vacation
+		expectPortion Portion
+		wantErr       bool
+	}{
+		{
+			name:          "default full day",
+			vacation:      &Vacation{From: day, To: day.AddDate(0, 0, 2)},
+			expectPortion: PortionFullDay,
+		},
+		{
+			name:          "afternoon",
+			vacation:      &Vacation{From: day, To: day, Portion: PortionAfternoon},
+			expectPortion: PortionAfternoon,
+		},
+		{
+			name:     "half day over multiple days",
+			vacation: &Vacation{From: day, To: day.AddDate(0, 0, 1), Portion: PortionMorning},
+			wantErr:  true,
+		},
+		{
+			name:          "hours",
+			vacation:      &Vacation{From: day, To: day, Portion: PortionHours, Hours: 3.5},
+			expectPortion: PortionHours,
+		},
+		{
+			name:     "hours without hours",
+			vacation: &Vacation{From: day, To: day, Portion: PortionHours},
+			wantErr:  true,
+		},
+		{
+			name:     "hours exceed working day",
+			vacation: &Vacation{From: day, To: day, Portion: PortionHours, Hours: HoursPerDay},
+			wantErr:  true,
+		},
+		{
+			name:     "full day with hours",
+			vacation: &Vacation{From: day, To: day, Hours: 2},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown portion",
+			vacation: &Vacation{From: day, To: day, Portion: "evening"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.vacation.Validate()
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidPortion) {
+					t.Fatalf("want: %v, got: %v", ErrInvalidPortion, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.vacation.Portion != tc.expectPortion {
+				t.Fatalf("want: %s, got: %s", tc.expectPortion, tc.vacation.Portion)
+			}
+		})
+	}
+}
@@ -1,19 +1,48 @@
 package model
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrInvalidTeamParent is returned if the parent of a Team does not exist
+	// or is deleted.
+	ErrInvalidTeamParent = errors.New("invalid parent team")
+	// ErrTeamCycle is returned if a Team would become its own ancestor.
+	ErrTeamCycle = errors.New("team hierarchy contains a cycle")
+	// ErrTeamHasSubTeams is returned if a Team with sub-teams is deleted.
+	ErrTeamHasSubTeams = errors.New("team has sub-teams")
+)
 
 // Team represents the Team model.
 type Team struct {
-	ID        string     `json:"id"`
-	OwnerID   string     `json:"owner_id"`
-	Name      string     `json:"name"`
-	CreatedAt *time.Time `json:"created_at"`
-	DeletedAt *time.Time `json:"deleted_at"`
-	UpdatedAt *time.Time `json:"updated_at"`
+	ID      string `json:"id"`
+	OwnerID string `json:"owner_id"`
+	// ParentID refers to the parent team, e.g. the department of a team.
+	ParentID *string `json:"parent_id"`
+	Name     string  `json:"name"`
+	// HolidayCalendar refers to a holiday calendar id, e.g. "DE-BY".
+	HolidayCalendar *string    `json:"holiday_calendar"`
+	CreatedAt       *time.Time `json:"created_at"`
+	DeletedAt       *time.Time `json:"deleted_at"`
+	UpdatedAt       *time.Time `json:"updated_at"`
+	// Version is incremented by every update, see ErrVersionConflict.
+	Version int `json:"version"`
 }
 
 // Copy returns a deep copy.
 func (t *Team) Copy() *Team {
+	var parentID, holidayCalendar *string
+	if t.ParentID != nil {
+		pid := *t.ParentID
+		parentID = &pid
+	}
+	if t.HolidayCalendar != nil {
+		hc := *t.HolidayCalendar
+		holidayCalendar = &hc
+	}
 	var createdAt, deletedAt, updatedAt *time.Time
 	if t.CreatedAt != nil {
 		ct := time.Unix(0, t.CreatedAt.UnixNano())
@@ -28,11 +57,71 @@ func (t *Team) Copy() *Team {
 		updatedAt = &ct
 	}
 	return &Team{
-		ID:        t.ID,
-		OwnerID:   t.OwnerID,
-		Name:      t.Name,
-		CreatedAt: createdAt,
-		DeletedAt: deletedAt,
-		UpdatedAt: updatedAt,
+		ID:              t.ID,
+		OwnerID:         t.OwnerID,
+		ParentID:        parentID,
+		Name:            t.Name,
+		HolidayCalendar: holidayCalendar,
+		CreatedAt:       createdAt,
+		DeletedAt:       deletedAt,
+		UpdatedAt:       updatedAt,
+		Version:         t.Version,
+	}
+}
+
+// ValidateTeamParent verifies that parentID refers to one of the given teams
+// and that teamID is not an ancestor of parentID. An unset or empty parentID
+// makes the team a root team and is always valid.
+func ValidateTeamParent(teams []*Team, teamID string, parentID *string) error {
+	if parentID == nil || *parentID == "" {
+		return nil
+	}
+	byID := make(map[string]*Team, len(teams))
+	for _, t := range teams {
+		byID[t.ID] = t
+	}
+	if parent, ok := byID[*parentID]; !ok || parent.DeletedAt != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidTeamParent, *parentID)
+	}
+	// NOTE: visited protects against cycles, which already exist.
+	visited := map[string]bool{}
+	for next := parentID; next != nil && !visited[*next]; {
+		if *next == teamID {
+			return fmt.Errorf("%w: %s is a sub-team of %s", ErrTeamCycle, *parentID, teamID)
+		}
+		visited[*next] = true
+		parent, ok := byID[*next]
+		if !ok {
+			break
+		}
+		next = parent.ParentID
+	}
+	return nil
+}
+
+// SubTeams returns the team of teamID and all of its direct and indirect
+// sub-teams out of the given teams, parents are listed before their
+// sub-teams.
+func SubTeams(teams []*Team, teamID string) []*Team {
+	children := map[string][]*Team{}
+	var result []*Team
+	for _, t := range teams {
+		if t.ParentID != nil {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		}
+		if t.ID == teamID {
+			result = append(result, t)
+		}
+	}
+	visited := map[string]bool{teamID: true}
+	for i := 0; i < len(result); i++ {
+		for _, child := range children[result[i].ID] {
+			if visited[child.ID] {
+				continue
+			}
+			visited[child.ID] = true
+			result = append(result, child)
+		}
 	}
+	return result
 }
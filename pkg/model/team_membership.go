@@ -0,0 +1,130 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrInvalidMembership is returned if a TeamMembership is incomplete or
+	// of unknown role.
+	ErrInvalidMembership = errors.New("invalid team membership")
+	// ErrOverAllocated is returned if the allocations of all memberships of a
+	// user exceed 100 percent.
+	ErrOverAllocated = errors.New("allocation exceeds 100 percent")
+)
+
+// TeamRole describes the role of a user within a team.
+type TeamRole string
+
+const (
+	// TeamRoleMember is a regular member of a team.
+	TeamRoleMember TeamRole = "member"
+	// TeamRoleLead leads the daily work of a team.
+	TeamRoleLead TeamRole = "lead"
+	// TeamRoleOwner has the same rights as the owner of the team.
+	TeamRoleOwner TeamRole = "owner"
+)
+
+// Valid reports whether the role is known.
+func (r TeamRole) Valid() bool {
+	switch r {
+	case TeamRoleMember, TeamRoleLead, TeamRoleOwner:
+		return true
+	}
+	return false
+}
+
+// FullAllocation is the allocation of a user, who works for a single team.
+const FullAllocation = 100
+
+// TeamMembership represents the TeamMembership model, it links a User to a
+// Team. A user can be member of several teams, e.g. split across two teams,
+// the allocations of all memberships of a user must not exceed 100 percent.
+type TeamMembership struct {
+	ID     string   `json:"id"`
+	TeamID string   `json:"team_id"`
+	UserID string   `json:"user_id"`
+	Role   TeamRole `json:"role"`
+	// Allocation is the share of the working time of the user in percent,
+	// which belongs to the team.
+	Allocation int        `json:"allocation"`
+	CreatedAt  *time.Time `json:"created_at"`
+	DeletedAt  *time.Time `json:"deleted_at"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+	// Version is incremented by every update, see ErrVersionConflict.
+	Version int `json:"version"`
+}
+
+// Validate verifies that the membership is complete. An unset role defaults to
+// member, an unset allocation to a full allocation.
+func (m *TeamMembership) Validate() error {
+	if m.TeamID == "" || m.UserID == "" {
+		return fmt.Errorf("%w: missing team or user", ErrInvalidMembership)
+	}
+	if m.Role == "" {
+		m.Role = TeamRoleMember
+	}
+	if !m.Role.Valid() {
+		return fmt.Errorf("%w: unknown role %s", ErrInvalidMembership, m.Role)
+	}
+	if m.Allocation == 0 {
+		m.Allocation = FullAllocation
+	}
+	if m.Allocation < 0 || m.Allocation > FullAllocation {
+		return fmt.Errorf("%w: allocation must be between 1 and %d percent", ErrInvalidMembership, FullAllocation)
+	}
+	return nil
+}
+
+// Share returns the allocation as fraction of the working time.
+func (m *TeamMembership) Share() float64 {
+	return float64(m.Allocation) / FullAllocation
+}
+
+// CheckAllocation verifies that the given membership does not exceed the
+// allocation of its user, together with the other memberships of the user out
+// of the given memberships. The membership itself and other memberships of the
+// same team are ignored.
+func (m *TeamMembership) CheckAllocation(memberships []*TeamMembership) error {
+	total := m.Allocation
+	for _, other := range memberships {
+		if other.UserID != m.UserID || other.TeamID == m.TeamID || other.ID == m.ID || other.DeletedAt != nil {
+			continue
+		}
+		total += other.Allocation
+	}
+	if total > FullAllocation {
+		return fmt.Errorf("%w: user %s", ErrOverAllocated, m.UserID)
+	}
+	return nil
+}
+
+// Copy returns a deep copy.
+func (m *TeamMembership) Copy() *TeamMembership {
+	var createdAt, deletedAt, updatedAt *time.Time
+	if m.CreatedAt != nil {
+		ct := time.Unix(0, m.CreatedAt.UnixNano())
+		createdAt = &ct
+	}
+	if m.DeletedAt != nil {
+		dt := time.Unix(0, m.DeletedAt.UnixNano())
+		deletedAt = &dt
+	}
+	if m.UpdatedAt != nil {
+		ut := time.Unix(0, m.UpdatedAt.UnixNano())
+		updatedAt = &ut
+	}
+	return &TeamMembership{
+		ID:         m.ID,
+		TeamID:     m.TeamID,
+		UserID:     m.UserID,
+		Role:       m.Role,
+		Allocation: m.Allocation,
+		CreatedAt:  createdAt,
+		DeletedAt:  deletedAt,
+		UpdatedAt:  updatedAt,
+		Version:    m.Version,
+	}
+}
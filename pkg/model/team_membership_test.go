@@ -0,0 +1,123 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTeamMembership_Validate(t *testing.T) {
+	tt := []struct {
+		name       string
+		membership TeamMembership
+		wantRole   TeamRole
+		wantAlloc  int
+		wantErr    bool
+	}{
+		{
+			name:       "defaults",
+			membership: TeamMembership{TeamID: "team", UserID: "user"},
+			wantRole:   TeamRoleMember,
+			wantAlloc:  FullAllocation,
+		},
+		{
+			name:       "lead with half allocation",
+			membership: TeamMembership{TeamID: "team", UserID: "user", Role: TeamRoleLead, Allocation: 50},
+			wantRole:   TeamRoleLead,
+			wantAlloc:  50,
+		},
+		{
+			name:       "missing user",
+			membership: TeamMembership{TeamID: "team"},
+			wantErr:    true,
+		},
+		{
+			name:       "unknown role",
+			membership: TeamMembership{TeamID: "team", UserID: "user", Role: "admin"},
+			wantErr:    true,
+		},
+		{
+			name:       "allocation too high",
+			membership: TeamMembership{TeamID: "team", UserID: "user", Allocation: 120},
+			wantErr:    true,
+		},
+		{
+			name:       "negative allocation",
+			membership: TeamMembership{TeamID: "team", UserID: "user", Allocation: -10},
+			wantErr:    true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.membership.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrInvalidMembership) {
+					t.Fatalf("expected %v, got: %v", ErrInvalidMembership, err)
+				}
+				return
+			}
+			if tc.membership.Role != tc.wantRole || tc.membership.Allocation != tc.wantAlloc {
+				t.Fatalf("want: %s/%d, got: %s/%d", tc.wantRole, tc.wantAlloc, tc.membership.Role, tc.membership.Allocation)
+			}
+		})
+	}
+}
+
+func TestTeamMembership_CheckAllocation(t *testing.T) {
+	now := time.Now()
+	memberships := []*TeamMembership{
+		{ID: "backend", TeamID: "backend", UserID: "user", Allocation: 60},
+		{ID: "ops", TeamID: "ops", UserID: "user", Allocation: 20, DeletedAt: &now},
+		{ID: "other", TeamID: "frontend", UserID: "other", Allocation: 100},
+	}
+	tt := []struct {
+		name       string
+		membership *TeamMembership
+		wantErr    error
+	}{
+		{name: "fits", membership: &TeamMembership{TeamID: "frontend", UserID: "user", Allocation: 40}},
+		{name: "exceeds", membership: &TeamMembership{TeamID: "frontend", UserID: "user", Allocation: 50}, wantErr: ErrOverAllocated},
+		{name: "update itself", membership: &TeamMembership{ID: "backend", TeamID: "backend", UserID: "user", Allocation: 100}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.membership.CheckAllocation(memberships)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected %v, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestTeamMembership_Copy(t *testing.T) {
+	now := time.Now()
+	original := &TeamMembership{
+		ID:         "test-membership-id",
+		TeamID:     "test-team-id",
+		UserID:     "test-user-id",
+		Role:       TeamRoleLead,
+		Allocation: 50,
+		CreatedAt:  &now,
+		UpdatedAt:  func() *time.Time { tmp := now.Add(15 * time.Minute); return &tmp }(),
+		DeletedAt:  func() *time.Time { tmp := now.Add(30 * time.Minute); return &tmp }(),
+	}
+	got := original.Copy()
+	if !cmp.Equal(original, got) {
+		t.Fatal(cmp.Diff(original, got))
+	}
+	got.Role = TeamRoleOwner
+	got.Allocation = 100
+	*got.CreatedAt = now.Add(time.Minute)
+	got.DeletedAt = nil
+	if cmp.Equal(original, got) {
+		t.Fatal("copy should not be equal")
+	}
+	if !original.CreatedAt.Equal(now) {
+		t.Fatal("timestamp of the original should not be changed")
+	}
+}
@@ -0,0 +1,155 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidTeamRule is returned if a TeamRule is incomplete or of unknown
+// kind.
+var ErrInvalidTeamRule = errors.New("invalid team rule")
+
+// TeamRuleKind describes how a TeamRule is evaluated.
+type TeamRuleKind string
+
+const (
+	// TeamRuleMinStaffing requires a minimum of present team members on every
+	// working day.
+	TeamRuleMinStaffing TeamRuleKind = "min_staffing"
+	// TeamRuleBlackout forbids absences during a period, e.g. a release
+	// freeze.
+	TeamRuleBlackout TeamRuleKind = "blackout"
+)
+
+// TeamRule represents the TeamRule model. Vacation requests of team members,
+// which violate a rule, are rejected if the rule is blocking, otherwise they
+// are flagged for the approvers.
+type TeamRule struct {
+	ID     string       `json:"id"`
+	TeamID string       `json:"team_id"`
+	Name   string       `json:"name"`
+	Kind   TeamRuleKind `json:"kind"`
+	// Blocking rejects violating requests instead of flagging them.
+	Blocking bool `json:"blocking"`
+	// MinPresent is the minimum of present members of a min_staffing rule.
+	MinPresent int `json:"min_present"`
+	// From and To limit the rule to a period, both are inclusive. A
+	// min_staffing rule without period applies all the time.
+	From *time.Time `json:"from"`
+	To   *time.Time `json:"to"`
+	// Yearly repeats the period every year, e.g. a release freeze from 1st
+	// to 15th December.
+	Yearly    bool       `json:"yearly"`
+	CreatedAt *time.Time `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+	// Version is incremented by every update, see ErrVersionConflict.
+	Version int `json:"version"`
+}
+
+// Validate verifies that the rule is complete. A blackout rule requires a
+// period, a min_staffing rule at least one present member.
+func (t *TeamRule) Validate() error {
+	if t.TeamID == "" || t.Name == "" {
+		return fmt.Errorf("%w: missing team or name", ErrInvalidTeamRule)
+	}
+	if (t.From == nil) != (t.To == nil) {
+		return fmt.Errorf("%w: period requires from and to", ErrInvalidTeamRule)
+	}
+	if t.From != nil && t.To.Before(*t.From) {
+		return fmt.Errorf("%w: invalid period", ErrInvalidTeamRule)
+	}
+	if t.Yearly && t.From != nil && t.To.After(t.From.AddDate(1, 0, -1)) {
+		return fmt.Errorf("%w: yearly period must not exceed a year", ErrInvalidTeamRule)
+	}
+	switch t.Kind {
+	case TeamRuleMinStaffing:
+		if t.MinPresent <= 0 {
+			return fmt.Errorf("%w: %s rule requires min_present", ErrInvalidTeamRule, t.Kind)
+		}
+	case TeamRuleBlackout:
+		if t.From == nil {
+			return fmt.Errorf("%w: %s rule requires a period", ErrInvalidTeamRule, t.Kind)
+		}
+	default:
+		return fmt.Errorf("%w: unknown kind %s", ErrInvalidTeamRule, t.Kind)
+	}
+	return nil
+}
+
+// Applies reports whether the given day is part of the period of the rule.
+func (t *TeamRule) Applies(day time.Time) bool {
+	if t.From == nil {
+		return true
+	}
+	day = dateOf(day)
+	from, to := dateOf(*t.From), dateOf(*t.To)
+	if !t.Yearly {
+		return !day.Before(from) && !day.After(to)
+	}
+	// NOTE: the period may span the turn of the year, e.g. 20th December to
+	// 6th January, therefore the period of the previous year is checked too.
+	for _, years := range []int{day.Year() - from.Year() - 1, day.Year() - from.Year()} {
+		if !day.Before(from.AddDate(years, 0, 0)) && !day.After(to.AddDate(years, 0, 0)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Copy returns a deep copy.
+func (t *TeamRule) Copy() *TeamRule {
+	var from, to *time.Time
+	if t.From != nil {
+		f := time.Unix(0, t.From.UnixNano())
+		from = &f
+	}
+	if t.To != nil {
+		tt := time.Unix(0, t.To.UnixNano())
+		to = &tt
+	}
+	var createdAt, deletedAt, updatedAt *time.Time
+	if t.CreatedAt != nil {
+		ct := time.Unix(0, t.CreatedAt.UnixNano())
+		createdAt = &ct
+	}
+	if t.DeletedAt != nil {
+		dt := time.Unix(0, t.DeletedAt.UnixNano())
+		deletedAt = &dt
+	}
+	if t.UpdatedAt != nil {
+		ut := time.Unix(0, t.UpdatedAt.UnixNano())
+		updatedAt = &ut
+	}
+	return &TeamRule{
+		ID:         t.ID,
+		TeamID:     t.TeamID,
+		Name:       t.Name,
+		Kind:       t.Kind,
+		Blocking:   t.Blocking,
+		MinPresent: t.MinPresent,
+		From:       from,
+		To:         to,
+		Yearly:     t.Yearly,
+		CreatedAt:  createdAt,
+		DeletedAt:  deletedAt,
+		UpdatedAt:  updatedAt,
+		Version:    t.Version,
+	}
+}
+
+// RuleViolation names a TeamRule, which is violated by a VacationRequest.
+type RuleViolation struct {
+	RuleID   string       `json:"rule_id"`
+	Name     string       `json:"name"`
+	Kind     TeamRuleKind `json:"kind"`
+	Blocking bool         `json:"blocking"`
+	// Day is the first day of the request, on which the rule is violated.
+	Day time.Time `json:"day"`
+}
+
+// String returns a human readable description of the violation.
+func (r RuleViolation) String() string {
+	return fmt.Sprintf("team rule '%s' (%s) violated on %s", r.Name, r.Kind, r.Day.Format("2006-01-02"))
+}
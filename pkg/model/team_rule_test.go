@@ -0,0 +1,135 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTeamRule_Validate(t *testing.T) {
+	from := time.Date(2022, time.December, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, time.December, 15, 0, 0, 0, 0, time.UTC)
+	tooLong := from.AddDate(1, 0, 0)
+	tt := []struct {
+		name    string
+		rule    *TeamRule
+		wantErr bool
+	}{
+		{
+			name: "min staffing",
+			rule: &TeamRule{TeamID: "team", Name: "two present", Kind: TeamRuleMinStaffing, MinPresent: 2},
+		},
+		{
+			name: "blackout",
+			rule: &TeamRule{TeamID: "team", Name: "freeze", Kind: TeamRuleBlackout, From: &from, To: &to, Yearly: true},
+		},
+		{
+			name:    "missing name",
+			rule:    &TeamRule{TeamID: "team", Kind: TeamRuleMinStaffing, MinPresent: 2},
+			wantErr: true,
+		},
+		{
+			name:    "min staffing without members",
+			rule:    &TeamRule{TeamID: "team", Name: "nobody", Kind: TeamRuleMinStaffing},
+			wantErr: true,
+		},
+		{
+			name:    "blackout without period",
+			rule:    &TeamRule{TeamID: "team", Name: "freeze", Kind: TeamRuleBlackout},
+			wantErr: true,
+		},
+		{
+			name:    "period without end",
+			rule:    &TeamRule{TeamID: "team", Name: "freeze", Kind: TeamRuleBlackout, From: &from},
+			wantErr: true,
+		},
+		{
+			name:    "end before start",
+			rule:    &TeamRule{TeamID: "team", Name: "freeze", Kind: TeamRuleBlackout, From: &to, To: &from},
+			wantErr: true,
+		},
+		{
+			name:    "yearly period exceeds a year",
+			rule:    &TeamRule{TeamID: "team", Name: "freeze", Kind: TeamRuleBlackout, From: &from, To: &tooLong, Yearly: true},
+			wantErr: true,
+		},
+		{
+			name:    "unknown kind",
+			rule:    &TeamRule{TeamID: "team", Name: "freeze", Kind: "unknown"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.rule.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidTeamRule) {
+				t.Fatalf("expected %v, got: %v", ErrInvalidTeamRule, err)
+			}
+		})
+	}
+}
+
+func TestTeamRule_Applies(t *testing.T) {
+	from := time.Date(2021, time.December, 20, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, time.January, 6, 0, 0, 0, 0, time.UTC)
+	tt := []struct {
+		name string
+		rule *TeamRule
+		day  time.Time
+		want bool
+	}{
+		{name: "without period", rule: &TeamRule{}, day: time.Now(), want: true},
+		{name: "within period", rule: &TeamRule{From: &from, To: &to}, day: to, want: true},
+		{name: "after period", rule: &TeamRule{From: &from, To: &to}, day: to.AddDate(0, 0, 1)},
+		{name: "next year", rule: &TeamRule{From: &from, To: &to}, day: from.AddDate(1, 0, 0)},
+		{name: "yearly end of year", rule: &TeamRule{From: &from, To: &to, Yearly: true}, day: from.AddDate(1, 0, 0), want: true},
+		{name: "yearly start of year", rule: &TeamRule{From: &from, To: &to, Yearly: true}, day: to.AddDate(2, 0, 0), want: true},
+		{name: "yearly outside", rule: &TeamRule{From: &from, To: &to, Yearly: true}, day: to.AddDate(1, 0, 1)},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.rule.Applies(tc.day); got != tc.want {
+				t.Fatalf("want: %t, got: %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTeamRule_Copy(t *testing.T) {
+	now := time.Now()
+	original := &TeamRule{
+		ID:         "test-rule-id",
+		TeamID:     "test-team-id",
+		Name:       "release freeze",
+		Kind:       TeamRuleBlackout,
+		Blocking:   true,
+		MinPresent: 2,
+		From:       &now,
+		To:         func() *time.Time { tmp := now.Add(time.Hour); return &tmp }(),
+		Yearly:     true,
+		CreatedAt:  func() *time.Time { tmp := now.Add(10 * time.Minute); return &tmp }(),
+		UpdatedAt:  func() *time.Time { tmp := now.Add(15 * time.Minute); return &tmp }(),
+		DeletedAt:  func() *time.Time { tmp := now.Add(30 * time.Minute); return &tmp }(),
+	}
+	got := original.Copy()
+	if !cmp.Equal(original, got) {
+		t.Fatal(cmp.Diff(original, got))
+	}
+	got.ID = "changed"
+	got.Name = "changed"
+	got.Blocking = false
+	*got.From = now.Add(time.Minute)
+	got.To = nil
+	got.CreatedAt = nil
+	if cmp.Equal(original, got) {
+		t.Fatal("copy should not be equal")
+	}
+	if !original.From.Equal(now) {
+		t.Fatal("period of the original should not be changed")
+	}
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -16,12 +17,14 @@ func TestTeam_Copy(t *testing.T) {
 		{
 			name: "expected",
 			original: &Team{
-				ID:        "test-team-id",
-				OwnerID:   "test-owner-id",
-				Name:      "test-team-name",
-				CreatedAt: func() *time.Time { tmp := now.Add(10 * time.Minute); return &tmp }(),
-				UpdatedAt: func() *time.Time { tmp := now.Add(15 * time.Minute); return &tmp }(),
-				DeletedAt: func() *time.Time { tmp := now.Add(30 * time.Minute); return &tmp }(),
+				ID:              "test-team-id",
+				OwnerID:         "test-owner-id",
+				ParentID:        func() *string { str := "test-parent-id"; return &str }(),
+				Name:            "test-team-name",
+				HolidayCalendar: func() *string { str := "DE-BY"; return &str }(),
+				CreatedAt:       func() *time.Time { tmp := now.Add(10 * time.Minute); return &tmp }(),
+				UpdatedAt:       func() *time.Time { tmp := now.Add(15 * time.Minute); return &tmp }(),
+				DeletedAt:       func() *time.Time { tmp := now.Add(30 * time.Minute); return &tmp }(),
 			},
 		},
 	}
@@ -34,13 +37,78 @@ func TestTeam_Copy(t *testing.T) {
 			}
 			got.ID += "team-id"
 			got.OwnerID = "owner-id"
+			*got.ParentID = "parent-id"
 			got.Name = "team-name"
+			got.HolidayCalendar = nil
 			got.CreatedAt = nil
 			got.UpdatedAt = nil
 			got.DeletedAt = nil
 			if cmp.Equal(tc.original, got) {
 				t.Fatal("copy should not be equal")
 			}
+			if *tc.original.ParentID != "test-parent-id" {
+				t.Fatal("parent of the original should not be changed")
+			}
+		})
+	}
+}
+
+func teamHierarchy() []*Team {
+	str := func(s string) *string { return &s }
+	return []*Team{
+		{ID: "company"},
+		{ID: "sales", ParentID: str("company")},
+		{ID: "dev", ParentID: str("company")},
+		{ID: "backend", ParentID: str("dev")},
+		{ID: "frontend", ParentID: str("dev")},
+	}
+}
+
+func TestValidateTeamParent(t *testing.T) {
+	tt := []struct {
+		name     string
+		teamID   string
+		parentID *string
+		wantErr  error
+	}{
+		{name: "root", teamID: "dev"},
+		{name: "detach", teamID: "dev", parentID: func() *string { s := ""; return &s }()},
+		{name: "new team", parentID: func() *string { s := "backend"; return &s }()},
+		{name: "move", teamID: "backend", parentID: func() *string { s := "sales"; return &s }()},
+		{name: "unknown parent", teamID: "dev", parentID: func() *string { s := "hr"; return &s }(), wantErr: ErrInvalidTeamParent},
+		{name: "itself", teamID: "dev", parentID: func() *string { s := "dev"; return &s }(), wantErr: ErrTeamCycle},
+		{name: "sub-team", teamID: "company", parentID: func() *string { s := "backend"; return &s }(), wantErr: ErrTeamCycle},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateTeamParent(teamHierarchy(), tc.teamID, tc.parentID)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected %v, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSubTeams(t *testing.T) {
+	tt := []struct {
+		name   string
+		teamID string
+		want   []string
+	}{
+		{name: "company", teamID: "company", want: []string{"company", "sales", "dev", "backend", "frontend"}},
+		{name: "department", teamID: "dev", want: []string{"dev", "backend", "frontend"}},
+		{name: "team", teamID: "backend", want: []string{"backend"}},
+		{name: "unknown", teamID: "hr"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, team := range SubTeams(teamHierarchy(), tc.teamID) {
+				got = append(got, team.ID)
+			}
+			if !cmp.Equal(tc.want, got) {
+				t.Fatal(cmp.Diff(tc.want, got))
+			}
 		})
 	}
 }
@@ -1,23 +1,74 @@
 package model
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrInvalidRole is returned if a role is unknown.
+	ErrInvalidRole = errors.New("invalid role")
+	// ErrInvalidUserParent is returned if the parent of a User does not exist
+	// or is deleted.
+	ErrInvalidUserParent = errors.New("invalid parent user")
+	// ErrUserCycle is returned if a User would become its own manager.
+	ErrUserCycle = errors.New("reporting line contains a cycle")
+)
+
+// Role describes the organizational role of a user. A role grants permissions
+// independent of the relations between users and teams.
+type Role string
+
+const (
+	// RoleAdmin can manage all resources.
+	RoleAdmin Role = "admin"
+	// RoleHR can read all users, teams and absences and manage users.
+	RoleHR Role = "hr"
+	// RoleManager can create and read all teams.
+	RoleManager Role = "manager"
+	// RoleEmployee has access to its own resources and the resources of the
+	// users and teams it is responsible for.
+	RoleEmployee Role = "employee"
+)
+
+// ParseRole parses the given role, an empty role defaults to employee.
+func ParseRole(s string) (Role, error) {
+	if s == "" {
+		return RoleEmployee, nil
+	}
+	switch r := Role(s); r {
+	case RoleAdmin, RoleHR, RoleManager, RoleEmployee:
+		return r, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrInvalidRole, s)
+}
 
 // User represents the User model.
 type User struct {
-	ID        string     `json:"id"`
-	ParentID  *string    `json:"parent_id"`
-	TeamID    *string    `json:"team_id"`
-	FirstName string     `json:"first_name"`
-	LastName  string     `json:"last_name"`
-	Email     string     `json:"email"`
-	CreatedAt *time.Time `json:"created_at"`
-	DeletedAt *time.Time `json:"deleted_at"`
-	UpdatedAt *time.Time `json:"updated_at"`
+	ID       string  `json:"id"`
+	ParentID *string `json:"parent_id"`
+	// TeamID refers to the primary team of the user, e.g. for the holiday
+	// calendar. Memberships of all teams, including the primary team, are
+	// kept as TeamMembership.
+	TeamID *string `json:"team_id"`
+	// Role defaults to employee and can only be changed by an admin.
+	Role      Role   `json:"role"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	// HolidayCalendar refers to a holiday calendar id, e.g. "DE-BY".
+	HolidayCalendar *string    `json:"holiday_calendar"`
+	CreatedAt       *time.Time `json:"created_at"`
+	DeletedAt       *time.Time `json:"deleted_at"`
+	UpdatedAt       *time.Time `json:"updated_at"`
+	// Version is incremented by every update, see ErrVersionConflict.
+	Version int `json:"version"`
 }
 
 // Copy returns a deep copy.
 func (u *User) Copy() *User {
-	var parentID, teamID *string
+	var parentID, teamID, holidayCalendar *string
 	if u.ParentID != nil {
 		pID := *u.ParentID
 		parentID = &pID
@@ -26,6 +77,10 @@ func (u *User) Copy() *User {
 		tID := *u.TeamID
 		teamID = &tID
 	}
+	if u.HolidayCalendar != nil {
+		hc := *u.HolidayCalendar
+		holidayCalendar = &hc
+	}
 	var createdAt, deletedAt, updatedAt *time.Time
 	if u.CreatedAt != nil {
 		ct := time.Unix(0, u.CreatedAt.UnixNano())
@@ -40,14 +95,97 @@ func (u *User) Copy() *User {
 		updatedAt = &ct
 	}
 	return &User{
-		ID:        u.ID,
-		ParentID:  parentID,
-		TeamID:    teamID,
-		FirstName: u.FirstName,
-		LastName:  u.LastName,
-		Email:     u.Email,
-		CreatedAt: createdAt,
-		DeletedAt: deletedAt,
-		UpdatedAt: updatedAt,
+		ID:              u.ID,
+		ParentID:        parentID,
+		TeamID:          teamID,
+		Role:            u.Role,
+		FirstName:       u.FirstName,
+		LastName:        u.LastName,
+		Email:           u.Email,
+		HolidayCalendar: holidayCalendar,
+		CreatedAt:       createdAt,
+		DeletedAt:       deletedAt,
+		UpdatedAt:       updatedAt,
+		Version:         u.Version,
+	}
+}
+
+// ValidateUserParent verifies that parentID refers to one of the given users,
+// which is not deleted, and that userID is not a direct or indirect manager of
+// parentID. An unset parentID is always valid.
+func ValidateUserParent(users []*User, userID string, parentID *string) error {
+	if parentID == nil {
+		return nil
+	}
+	byID := make(map[string]*User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+	if parent, ok := byID[*parentID]; !ok || parent.DeletedAt != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidUserParent, *parentID)
+	}
+	// NOTE: visited protects against cycles, which already exist.
+	visited := map[string]bool{}
+	for next := parentID; next != nil && !visited[*next]; {
+		if *next == userID {
+			return fmt.Errorf("%w: %s reports to %s", ErrUserCycle, *parentID, userID)
+		}
+		visited[*next] = true
+		parent, ok := byID[*next]
+		if !ok {
+			break
+		}
+		next = parent.ParentID
+	}
+	return nil
+}
+
+// OrgNode is a user of the reporting tree together with its direct reports.
+type OrgNode struct {
+	*User
+	Reports []*OrgNode `json:"reports"`
+}
+
+// OrgChart returns the reporting tree of the given users. Deleted users are
+// skipped, users without an active parent become roots. If rootID is set, only
+// the subtree of rootID is returned, the chart is empty if rootID is unknown.
+func OrgChart(users []*User, rootID string) []*OrgNode {
+	active := make(map[string]bool, len(users))
+	for _, u := range users {
+		if u.DeletedAt == nil {
+			active[u.ID] = true
+		}
+	}
+	children := map[string][]*User{}
+	var roots []*User
+	for _, u := range users {
+		if !active[u.ID] {
+			continue
+		}
+		hasParent := u.ParentID != nil && active[*u.ParentID]
+		if hasParent {
+			children[*u.ParentID] = append(children[*u.ParentID], u)
+		}
+		if u.ID == rootID || (rootID == "" && !hasParent) {
+			roots = append(roots, u)
+		}
+	}
+	// NOTE: visited protects against cycles, which already exist.
+	visited := map[string]bool{}
+	var tree func(u *User) *OrgNode
+	tree = func(u *User) *OrgNode {
+		visited[u.ID] = true
+		node := &OrgNode{User: u, Reports: []*OrgNode{}}
+		for _, child := range children[u.ID] {
+			if !visited[child.ID] {
+				node.Reports = append(node.Reports, tree(child))
+			}
+		}
+		return node
+	}
+	chart := make([]*OrgNode, 0, len(roots))
+	for _, root := range roots {
+		chart = append(chart, tree(root))
 	}
+	return chart
 }
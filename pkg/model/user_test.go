@@ -1,6 +1,8 @@
 package model
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,15 +18,17 @@ func TestUser_Copy(t *testing.T) {
 		{
 			name: "expected",
 			original: &User{
-				ID:        "test-user-id",
-				ParentID:  func() *string { str := "test-parent-id"; return &str }(),
-				TeamID:    func() *string { str := "test-team-id"; return &str }(),
-				FirstName: "test-firstname",
-				LastName:  "test-lastname",
-				Email:     "test-email",
-				CreatedAt: func() *time.Time { tmp := now.Add(10 * time.Minute); return &tmp }(),
-				UpdatedAt: func() *time.Time { tmp := now.Add(15 * time.Minute); return &tmp }(),
-				DeletedAt: func() *time.Time { tmp := now.Add(30 * time.Minute); return &tmp }(),
+				ID:              "test-user-id",
+				ParentID:        func() *string { str := "test-parent-id"; return &str }(),
+				TeamID:          func() *string { str := "test-team-id"; return &str }(),
+				Role:            RoleHR,
+				FirstName:       "test-firstname",
+				LastName:        "test-lastname",
+				Email:           "test-email",
+				HolidayCalendar: func() *string { str := "DE-BY"; return &str }(),
+				CreatedAt:       func() *time.Time { tmp := now.Add(10 * time.Minute); return &tmp }(),
+				UpdatedAt:       func() *time.Time { tmp := now.Add(15 * time.Minute); return &tmp }(),
+				DeletedAt:       func() *time.Time { tmp := now.Add(30 * time.Minute); return &tmp }(),
 			},
 		},
 	}
@@ -38,9 +42,11 @@ func TestUser_Copy(t *testing.T) {
 			got.ID += "user-id"
 			got.ParentID = nil
 			got.TeamID = nil
+			got.Role = RoleAdmin
 			got.FirstName = "firstname"
 			got.LastName = "lastname"
 			got.Email = "email"
+			got.HolidayCalendar = nil
 			got.CreatedAt = nil
 			got.UpdatedAt = nil
 			got.DeletedAt = nil
@@ -50,3 +56,104 @@ func TestUser_Copy(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRole(t *testing.T) {
+	tt := []struct {
+		in      string
+		want    Role
+		wantErr bool
+	}{
+		{in: "", want: RoleEmployee},
+		{in: "admin", want: RoleAdmin},
+		{in: "hr", want: RoleHR},
+		{in: "manager", want: RoleManager},
+		{in: "employee", want: RoleEmployee},
+		{in: "owner", wantErr: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := ParseRole(tc.in)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("want: %q, got: %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func reportingLines() []*User {
+	ptr := func(s string) *string { return &s }
+	deletedAt := time.Now()
+	return []*User{
+		{ID: "ceo"},
+		{ID: "cto", ParentID: ptr("ceo")},
+		{ID: "dev", ParentID: ptr("cto")},
+		{ID: "cfo", ParentID: ptr("ceo")},
+		{ID: "left", ParentID: ptr("ceo"), DeletedAt: &deletedAt},
+		{ID: "intern", ParentID: ptr("left")},
+	}
+}
+
+func TestValidateUserParent(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+	tt := []struct {
+		name     string
+		userID   string
+		parentID *string
+		wantErr  error
+	}{
+		{name: "root", userID: "cto"},
+		{name: "new user", parentID: ptr("dev")},
+		{name: "move", userID: "dev", parentID: ptr("cfo")},
+		{name: "unknown parent", userID: "dev", parentID: ptr("hr"), wantErr: ErrInvalidUserParent},
+		{name: "deleted parent", userID: "dev", parentID: ptr("left"), wantErr: ErrInvalidUserParent},
+		{name: "itself", userID: "dev", parentID: ptr("dev"), wantErr: ErrUserCycle},
+		{name: "indirect report", userID: "ceo", parentID: ptr("dev"), wantErr: ErrUserCycle},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateUserParent(reportingLines(), tc.userID, tc.parentID)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected %v, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestOrgChart(t *testing.T) {
+	// NOTE: flatten renders a chart as "user(reports...)".
+	var flatten func(nodes []*OrgNode) string
+	flatten = func(nodes []*OrgNode) string {
+		var parts []string
+		for _, n := range nodes {
+			parts = append(parts, n.ID+"("+flatten(n.Reports)+")")
+		}
+		return strings.Join(parts, ",")
+	}
+	cycle := append(reportingLines(),
+		&User{ID: "a", ParentID: func() *string { s := "b"; return &s }()},
+		&User{ID: "b", ParentID: func() *string { s := "a"; return &s }()},
+	)
+	tt := []struct {
+		name   string
+		users  []*User
+		rootID string
+		want   string
+	}{
+		{name: "company", users: reportingLines(), want: "ceo(cto(dev()),cfo()),intern()"},
+		{name: "subtree", users: reportingLines(), rootID: "cto", want: "cto(dev())"},
+		{name: "deleted root", users: reportingLines(), rootID: "left"},
+		{name: "unknown root", users: reportingLines(), rootID: "hr"},
+		{name: "existing cycle", users: cycle, rootID: "a", want: "a(b())"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got := flatten(OrgChart(tc.users, tc.rootID))
+			if got != tc.want {
+				t.Fatalf("want: %s, got: %s", tc.want, got)
+			}
+		})
+	}
+}
@@ -4,22 +4,49 @@ import "time"
 
 // Vacation represents the Vacation model.
 type Vacation struct {
-	ID         string     `json:"id"`
-	UserID     string     `json:"user_id"`
-	ApprovedBy *string    `json:"approved_by"`
-	From       time.Time  `json:"from"`
-	To         time.Time  `json:"to"`
-	CreatedAt  *time.Time `json:"created_at"`
-	DeletedAt  *time.Time `json:"deleted_at"`
+	ID         string  `json:"id"`
+	UserID     string  `json:"user_id"`
+	ApprovedBy *string `json:"approved_by"`
+	// ApprovedOnBehalfOf refers to the User, who delegated the approval to
+	// ApprovedBy.
+	ApprovedOnBehalfOf *string `json:"approved_on_behalf_of"`
+	// AbsenceTypeID refers to an AbsenceType, nil marks a regular vacation.
+	AbsenceTypeID *string `json:"absence_type_id"`
+	// DeputyID refers to the User, who covers for the user.
+	DeputyID  *string    `json:"deputy_id"`
+	From      time.Time  `json:"from"`
+	To        time.Time  `json:"to"`
+	Portion   Portion    `json:"portion"`
+	Hours     float64    `json:"hours"`
+	CreatedAt *time.Time `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+// Validate verifies portion and hours of the vacation.
+// If no portion is set, a full day vacation is assumed.
+func (v *Vacation) Validate() error {
+	return validatePortion(&v.Portion, v.Hours, v.From, v.To)
 }
 
 // Copy returns a deep copy.
 func (v *Vacation) Copy() *Vacation {
-	var approvedBy *string
+	var approvedBy, approvedOnBehalfOf, absenceTypeID, deputyID *string
 	if v.ApprovedBy != nil {
 		apBy := *v.ApprovedBy
 		approvedBy = &apBy
 	}
+	if v.ApprovedOnBehalfOf != nil {
+		apOnBehalfOf := *v.ApprovedOnBehalfOf
+		approvedOnBehalfOf = &apOnBehalfOf
+	}
+	if v.AbsenceTypeID != nil {
+		atID := *v.AbsenceTypeID
+		absenceTypeID = &atID
+	}
+	if v.DeputyID != nil {
+		dID := *v.DeputyID
+		deputyID = &dID
+	}
 	var createdAt, deletedAt *time.Time
 	if v.CreatedAt != nil {
 		ct := time.Unix(0, v.CreatedAt.UnixNano())
@@ -30,12 +57,17 @@ func (v *Vacation) Copy() *Vacation {
 		deletedAt = &dt
 	}
 	return &Vacation{
-		ID:         v.ID,
-		UserID:     v.UserID,
-		ApprovedBy: approvedBy,
-		From:       v.From,
-		To:         v.To,
-		CreatedAt:  createdAt,
-		DeletedAt:  deletedAt,
+		ID:                 v.ID,
+		UserID:             v.UserID,
+		ApprovedBy:         approvedBy,
+		ApprovedOnBehalfOf: approvedOnBehalfOf,
+		AbsenceTypeID:      absenceTypeID,
+		DeputyID:           deputyID,
+		From:               v.From,
+		To:                 v.To,
+		Portion:            v.Portion,
+		Hours:              v.Hours,
+		CreatedAt:          createdAt,
+		DeletedAt:          deletedAt,
 	}
 }
@@ -1,20 +1,392 @@
 package model
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidStatusTransition is returned if a VacationRequest can not be
+// moved from its current status to the requested one.
+var ErrInvalidStatusTransition = errors.New("invalid vacation-request status transition")
+
+// ErrMissingRejectionReason is returned if a VacationRequest gets rejected
+// without a reason.
+var ErrMissingRejectionReason = errors.New("missing vacation-request rejection reason")
+
+// ErrInvalidDeputy is returned if the deputy of a VacationRequest is the
+// requesting user or can not cover for the requesting user.
+var ErrInvalidDeputy = errors.New("invalid deputy")
+
+// ErrDeputyNotAccepted is returned if a VacationRequest gets approved before
+// its deputy accepted the assignment.
+var ErrDeputyNotAccepted = errors.New("deputy did not accept the assignment")
+
+// DeputyStatus describes whether the deputy of a VacationRequest accepted the
+// assignment.
+type DeputyStatus string
+
+const (
+	// DeputyPending marks an assignment, which is not answered yet.
+	DeputyPending DeputyStatus = "pending"
+	// DeputyAccepted marks an assignment, which got accepted by the deputy.
+	DeputyAccepted DeputyStatus = "accepted"
+	// DeputyDeclined marks an assignment, which got declined by the deputy.
+	// The requesting user has to assign another deputy.
+	DeputyDeclined DeputyStatus = "declined"
+)
+
+// VacationRequestStatus describes the lifecycle state of a VacationRequest.
+type VacationRequestStatus string
+
+const (
+	// StatusDraft marks a request, which is not submitted yet.
+	StatusDraft VacationRequestStatus = "draft"
+	// StatusPending marks a submitted request, waiting for approval.
+	StatusPending VacationRequestStatus = "pending"
+	// StatusApproved marks an approved request, a Vacation exists.
+	StatusApproved VacationRequestStatus = "approved"
+	// StatusRejected marks a request, which got rejected by an approver.
+	StatusRejected VacationRequestStatus = "rejected"
+	// StatusWithdrawn marks a request, which got withdrawn by the requester
+	// before a decision was made.
+	StatusWithdrawn VacationRequestStatus = "withdrawn"
+	// StatusCancelled marks a request, which got cancelled after approval.
+	StatusCancelled VacationRequestStatus = "cancelled"
+)
+
+// statusTransitions lists all valid follow-up states of a status.
+var statusTransitions = map[VacationRequestStatus][]VacationRequestStatus{
+	StatusDraft:     {StatusPending, StatusWithdrawn},
+	StatusPending:   {StatusApproved, StatusRejected, StatusWithdrawn},
+	StatusApproved:  {StatusCancelled},
+	StatusRejected:  {},
+	StatusWithdrawn: {},
+	StatusCancelled: {},
+}
+
+// Valid reports whether s is a known status.
+func (s VacationRequestStatus) Valid() bool {
+	_, ok := statusTransitions[s]
+	return ok
+}
+
+// CanTransition reports whether a request in status s can be moved to next.
+func (s VacationRequestStatus) CanTransition(next VacationRequestStatus) bool {
+	for _, n := range statusTransitions[s] {
+		if n == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Editable reports whether the period of a request in status s can be changed.
+func (s VacationRequestStatus) Editable() bool {
+	return s == StatusDraft || s == StatusPending
+}
 
 // VacationRequest represents the VacationRequest model.
 type VacationRequest struct {
-	ID        string     `json:"id"`
-	UserID    string     `json:"user_id"`
-	To        time.Time  `json:"to"`
-	From      time.Time  `json:"from"`
-	CreatedAt *time.Time `json:"created_at"`
-	DeletedAt *time.Time `json:"deleted_at"`
-	UpdatedAt *time.Time `json:"updated_at"`
+	ID     string                `json:"id"`
+	UserID string                `json:"user_id"`
+	Status VacationRequestStatus `json:"status"`
+	// VacationID refers to the Vacation, which got created on approval.
+	VacationID *string `json:"vacation_id"`
+	// AbsenceTypeID refers to an AbsenceType, nil marks a regular vacation.
+	AbsenceTypeID *string `json:"absence_type_id"`
+	// RejectedBy refers to the User, who rejected the request.
+	RejectedBy *string `json:"rejected_by"`
+	// RejectedOnBehalfOf refers to the User, who delegated the decision to
+	// RejectedBy.
+	RejectedOnBehalfOf *string `json:"rejected_on_behalf_of"`
+	// RejectionReason is mandatory for rejected requests.
+	RejectionReason *string `json:"rejection_reason"`
+	// ApprovalSteps is the approval chain, which is recorded on submission.
+	// The request is approved once the last step is completed.
+	ApprovalSteps []ApprovalStep `json:"approval_steps"`
+	// RuleViolations lists the non-blocking team rules, which are violated
+	// by the request. They are recorded on submission as warning for the
+	// approvers.
+	RuleViolations []RuleViolation `json:"rule_violations"`
+	// DeputyID refers to the User, who covers for the requesting user.
+	DeputyID *string `json:"deputy_id"`
+	// DeputyStatus is set, if a deputy is assigned. The request can not be
+	// approved until the deputy accepted.
+	DeputyStatus DeputyStatus `json:"deputy_status"`
+	To           time.Time    `json:"to"`
+	From         time.Time    `json:"from"`
+	Portion      Portion      `json:"portion"`
+	Hours        float64      `json:"hours"`
+	CreatedAt    *time.Time   `json:"created_at"`
+	DeletedAt    *time.Time   `json:"deleted_at"`
+	UpdatedAt    *time.Time   `json:"updated_at"`
+	// Version is incremented by every update, see ErrVersionConflict.
+	Version int `json:"version"`
+}
+
+// DeputyConfirmed reports whether the request can be approved regarding its
+// deputy, either no deputy is assigned or the deputy accepted.
+func (v *VacationRequest) DeputyConfirmed() bool {
+	return v.DeputyID == nil || v.DeputyStatus == DeputyAccepted
+}
+
+// NextApprovalStep returns the index of the first approval step, which is not
+// completed yet. If all steps are completed, -1 is returned.
+func (v *VacationRequest) NextApprovalStep() int {
+	for i := range v.ApprovalSteps {
+		if !v.ApprovalSteps[i].Approved() {
+			return i
+		}
+	}
+	return -1
+}
+
+// Validate verifies that a new request starts either as draft or pending and
+// its portion matches the period. If no status is set, pending is assumed.
+func (v *VacationRequest) Validate() error {
+	if v.Status == "" {
+		v.Status = StatusPending
+	}
+	if v.Status != StatusDraft && v.Status != StatusPending {
+		return fmt.Errorf("%w: vacation-request can not be created as %s",
+			ErrInvalidStatusTransition, v.Status)
+	}
+	v.DeputyStatus = ""
+	if v.DeputyID != nil {
+		if *v.DeputyID == v.UserID {
+			return fmt.Errorf("%w: users can not cover for themselves", ErrInvalidDeputy)
+		}
+		v.DeputyStatus = DeputyPending
+	}
+	return validatePortion(&v.Portion, v.Hours, v.From, v.To)
+}
+
+// PeriodChanged reports whether applying u to v changes the period or the
+// portion of v.
+func (v *VacationRequest) PeriodChanged(u *VacationRequest) bool {
+	return (!u.From.IsZero() && !u.From.Equal(v.From)) ||
+		(!u.To.IsZero() && !u.To.Equal(v.To)) ||
+		(u.Portion != "" && u.Portion != v.Portion) ||
+		(u.Hours != 0 && u.Hours != v.Hours)
+}
+
+// Update applies all set fields of u to v. Status changes must follow the
+// vacation-request lifecycle, the period can only be changed as long as the
+// request is a draft or pending. Changing the period revokes all approvals
+// and restarts the deputy assignment. A rejection requires a reason.
+// Assigning another deputy restarts the assignment, the deputy answers by
+// DeputyStatus.
+func (v *VacationRequest) Update(u *VacationRequest) error {
+	periodChanged := v.PeriodChanged(u)
+	if periodChanged && !v.Status.Editable() {
+		return fmt.Errorf("%w: period of %s vacation-request can not be changed",
+			ErrInvalidStatusTransition, v.Status)
+	}
+	if u.Status != "" && u.Status != v.Status && !v.Status.CanTransition(u.Status) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, v.Status, u.Status)
+	}
+	if u.Status == StatusRejected && (u.RejectionReason == nil || strings.TrimSpace(*u.RejectionReason) == "") {
+		return ErrMissingRejectionReason
+	}
+	if u.ApprovalSteps != nil {
+		current := v
+		if periodChanged {
+			// NOTE: approvals of the previous period are revoked below.
+			current = &VacationRequest{Status: v.Status}
+		}
+		if err := current.validateApprovalSteps(u.ApprovalSteps); err != nil {
+			return err
+		}
+	}
+	deputyChanged := u.DeputyID != nil && (v.DeputyID == nil || *u.DeputyID != *v.DeputyID)
+	if err := v.validateDeputy(u, deputyChanged); err != nil {
+		return err
+	}
+	if !u.From.IsZero() {
+		v.From = u.From
+	}
+	if !u.To.IsZero() {
+		v.To = u.To
+	}
+	if u.Portion != "" {
+		v.Portion = u.Portion
+		v.Hours = u.Hours
+	} else if u.Hours != 0 {
+		v.Hours = u.Hours
+	}
+	if periodChanged {
+		if err := validatePortion(&v.Portion, v.Hours, v.From, v.To); err != nil {
+			return err
+		}
+		for i := range v.ApprovalSteps {
+			v.ApprovalSteps[i].ApprovedBy = nil
+			v.ApprovalSteps[i].OnBehalfOf = nil
+			v.ApprovalSteps[i].ApprovedAt = nil
+		}
+		if v.DeputyID != nil {
+			v.DeputyStatus = DeputyPending
+		}
+	}
+	if u.Status != "" {
+		v.Status = u.Status
+	}
+	if u.VacationID != nil {
+		vacationID := *u.VacationID
+		v.VacationID = &vacationID
+	}
+	if u.RejectedBy != nil {
+		rejectedBy := *u.RejectedBy
+		v.RejectedBy = &rejectedBy
+	}
+	if u.RejectedOnBehalfOf != nil {
+		rejectedOnBehalfOf := *u.RejectedOnBehalfOf
+		v.RejectedOnBehalfOf = &rejectedOnBehalfOf
+	}
+	if u.RejectionReason != nil {
+		reason := *u.RejectionReason
+		v.RejectionReason = &reason
+	}
+	if u.ApprovalSteps != nil {
+		v.ApprovalSteps = copyApprovalSteps(u.ApprovalSteps)
+	}
+	if u.RuleViolations != nil {
+		v.RuleViolations = copyRuleViolations(u.RuleViolations)
+	}
+	if deputyChanged {
+		deputyID := *u.DeputyID
+		v.DeputyID = &deputyID
+		v.DeputyStatus = DeputyPending
+	} else if u.DeputyStatus != "" {
+		v.DeputyStatus = u.DeputyStatus
+	}
+	return nil
+}
+
+// Approve moves v to approved. Unlike Update, approving an approved request
+// fails, this way a request is never approved twice.
+func (v *VacationRequest) Approve() error {
+	if !v.Status.CanTransition(StatusApproved) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, v.Status, StatusApproved)
+	}
+	v.Status = StatusApproved
+	return nil
+}
+
+// Cancel moves v to cancelled. Unlike Update, cancelling a cancelled request
+// fails, this way the vacation of a request is never removed twice.
+func (v *VacationRequest) Cancel() error {
+	if !v.Status.CanTransition(StatusCancelled) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, v.Status, StatusCancelled)
+	}
+	v.Status = StatusCancelled
+	return nil
+}
+
+// validateDeputy verifies that a deputy is only assigned or answered as long
+// as v is a draft or pending. A pending assignment can be accepted or
+// declined once.
+func (v *VacationRequest) validateDeputy(u *VacationRequest, deputyChanged bool) error {
+	if !deputyChanged && u.DeputyStatus == "" {
+		return nil
+	}
+	if !v.Status.Editable() {
+		return fmt.Errorf("%w: deputy of %s vacation-request can not be changed",
+			ErrInvalidStatusTransition, v.Status)
+	}
+	if deputyChanged {
+		if *u.DeputyID == v.UserID {
+			return fmt.Errorf("%w: users can not cover for themselves", ErrInvalidDeputy)
+		}
+		return nil
+	}
+	if v.DeputyID == nil {
+		return fmt.Errorf("%w: no deputy assigned", ErrInvalidDeputy)
+	}
+	if v.DeputyStatus != DeputyPending || (u.DeputyStatus != DeputyAccepted && u.DeputyStatus != DeputyDeclined) {
+		return fmt.Errorf("%w: deputy assignment %s to %s",
+			ErrInvalidStatusTransition, v.DeputyStatus, u.DeputyStatus)
+	}
+	return nil
+}
+
+// validateApprovalSteps verifies that the given steps do not revoke or change
+// completed steps of v. Steps can only be approved while v is pending.
+func (v *VacationRequest) validateApprovalSteps(steps []ApprovalStep) error {
+	for i, step := range steps {
+		var current *ApprovalStep
+		if i < len(v.ApprovalSteps) {
+			current = &v.ApprovalSteps[i]
+		}
+		if current != nil && current.Approved() {
+			if !step.Approved() || *step.ApprovedBy != *current.ApprovedBy {
+				return fmt.Errorf("%w: approval step %d is already approved",
+					ErrInvalidStatusTransition, i)
+			}
+			continue
+		}
+		if step.Approved() && v.Status != StatusPending {
+			return fmt.Errorf("%w: approval step of %s vacation-request can not be approved",
+				ErrInvalidStatusTransition, v.Status)
+		}
+	}
+	for i := len(steps); i < len(v.ApprovalSteps); i++ {
+		if v.ApprovalSteps[i].Approved() {
+			return fmt.Errorf("%w: approval step %d is already approved",
+				ErrInvalidStatusTransition, i)
+		}
+	}
+	return nil
+}
+
+func copyApprovalSteps(steps []ApprovalStep) []ApprovalStep {
+	if steps == nil {
+		return nil
+	}
+	c := make([]ApprovalStep, len(steps))
+	for i := range steps {
+		c[i] = *steps[i].Copy()
+	}
+	return c
+}
+
+func copyRuleViolations(violations []RuleViolation) []RuleViolation {
+	if violations == nil {
+		return nil
+	}
+	c := make([]RuleViolation, len(violations))
+	copy(c, violations)
+	return c
 }
 
 // Copy returns a deep copy.
 func (v *VacationRequest) Copy() *VacationRequest {
+	var vacationID, absenceTypeID, rejectedBy, rejectedOnBehalfOf, rejectionReason, deputyID *string
+	if v.VacationID != nil {
+		vID := *v.VacationID
+		vacationID = &vID
+	}
+	if v.AbsenceTypeID != nil {
+		atID := *v.AbsenceTypeID
+		absenceTypeID = &atID
+	}
+	if v.RejectedBy != nil {
+		rb := *v.RejectedBy
+		rejectedBy = &rb
+	}
+	if v.RejectedOnBehalfOf != nil {
+		rob := *v.RejectedOnBehalfOf
+		rejectedOnBehalfOf = &rob
+	}
+	if v.RejectionReason != nil {
+		rr := *v.RejectionReason
+		rejectionReason = &rr
+	}
+	if v.DeputyID != nil {
+		dID := *v.DeputyID
+		deputyID = &dID
+	}
 	var createdAt, deletedAt, updatedAt *time.Time
 	if v.CreatedAt != nil {
 		ct := time.Unix(0, v.CreatedAt.UnixNano())
@@ -29,12 +401,25 @@ func (v *VacationRequest) Copy() *VacationRequest {
 		updatedAt = &ut
 	}
 	return &VacationRequest{
-		ID:        v.ID,
-		UserID:    v.UserID,
-		From:      v.From,
-		To:        v.To,
-		CreatedAt: createdAt,
-		DeletedAt: deletedAt,
-		UpdatedAt: updatedAt,
+		ID:                 v.ID,
+		UserID:             v.UserID,
+		Status:             v.Status,
+		VacationID:         vacationID,
+		AbsenceTypeID:      absenceTypeID,
+		RejectedBy:         rejectedBy,
+		RejectedOnBehalfOf: rejectedOnBehalfOf,
+		RejectionReason:    rejectionReason,
+		ApprovalSteps:      copyApprovalSteps(v.ApprovalSteps),
+		RuleViolations:     copyRuleViolations(v.RuleViolations),
+		DeputyID:           deputyID,
+		DeputyStatus:       v.DeputyStatus,
+		From:               v.From,
+		To:                 v.To,
+		Portion:            v.Portion,
+		Hours:              v.Hours,
+		CreatedAt:          createdAt,
+		DeletedAt:          deletedAt,
+		UpdatedAt:          updatedAt,
+		Version:            v.Version,
 	}
 }
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -16,13 +17,30 @@ func TestVacationRequest_Copy(t *testing.T) {
 		{
 			name: "expected",
 			original: &VacationRequest{
-				ID:        "test-vacation-resource-id",
-				UserID:    "test-user-id",
-				From:      now.Add(time.Minute),
-				To:        now.Add(time.Hour),
-				CreatedAt: func() *time.Time { tmp := now.Add(10 * time.Minute); return &tmp }(),
-				UpdatedAt: func() *time.Time { tmp := now.Add(15 * time.Minute); return &tmp }(),
-				DeletedAt: func() *time.Time { tmp := now.Add(30 * time.Minute); return &tmp }(),
+				ID:                 "test-vacation-resource-id",
+				UserID:             "test-user-id",
+				Status:             StatusApproved,
+				VacationID:         func() *string { str := "test-vacation-id"; return &str }(),
+				RejectedBy:         func() *string { str := "test-parent-id"; return &str }(),
+				RejectedOnBehalfOf: func() *string { str := "test-delegator-id"; return &str }(),
+				RejectionReason:    func() *string { str := "team event"; return &str }(),
+				ApprovalSteps: []ApprovalStep{
+					{Role: ApproverManager, ApprovedBy: func() *string { str := "test-parent-id"; return &str }(), ApprovedAt: &now},
+					{Role: ApproverTeam, TeamID: func() *string { str := "test-team-id"; return &str }()},
+				},
+				RuleViolations: []RuleViolation{
+					{RuleID: "test-rule-id", Name: "release freeze", Kind: TeamRuleBlackout, Day: now},
+				},
+				DeputyID:      func() *string { str := "test-deputy-id"; return &str }(),
+				DeputyStatus:  DeputyAccepted,
+				From:          now.Add(time.Minute),
+				AbsenceTypeID: func() *string { str := "test-absence-type-id"; return &str }(),
+				Portion:       PortionHours,
+				Hours:         2,
+				To:            now.Add(time.Hour),
+				CreatedAt:     func() *time.Time { tmp := now.Add(10 * time.Minute); return &tmp }(),
+				UpdatedAt:     func() *time.Time { tmp := now.Add(15 * time.Minute); return &tmp }(),
+				DeletedAt:     func() *time.Time { tmp := now.Add(30 * time.Minute); return &tmp }(),
 			},
 		},
 	}
@@ -35,7 +53,20 @@ func TestVacationRequest_Copy(t *testing.T) {
 			}
 			got.ID += "vacation-request-id"
 			got.UserID = "user-id-request"
+			got.Status = StatusCancelled
+			got.VacationID = nil
+			got.RejectedBy = nil
+			got.RejectedOnBehalfOf = nil
+			got.RejectionReason = nil
+			got.ApprovalSteps[0].ApprovedBy = nil
+			got.ApprovalSteps[1].TeamID = nil
+			got.RuleViolations[0].Name = "changed"
+			got.DeputyID = nil
+			got.DeputyStatus = DeputyDeclined
 			got.From = time.Now()
+			got.AbsenceTypeID = nil
+			got.Portion = PortionFullDay
+			got.Hours = 0
 			got.To = time.Now()
 			got.CreatedAt = nil
 			got.UpdatedAt = nil
@@ -43,6 +74,214 @@ func TestVacationRequest_Copy(t *testing.T) {
 			if cmp.Equal(tc.original, got) {
 				t.Fatal("copy should not be equal")
 			}
+			if tc.original.ApprovalSteps[0].ApprovedBy == nil || tc.original.ApprovalSteps[1].TeamID == nil {
+				t.Fatal("approval steps of the original should not be changed")
+			}
+			if tc.original.RuleViolations[0].Name != "release freeze" {
+				t.Fatal("rule violations of the original should not be changed")
+			}
+		})
+	}
+}
+
+func TestVacationRequest_UpdateApprovalSteps(t *testing.T) {
+	approver := "test-parent-id"
+	other := "test-other-id"
+	tt := []struct {
+		name     string
+		status   VacationRequestStatus
+		original []ApprovalStep
+		update   []ApprovalStep
+		wantNext int
+		wantErr  bool
+	}{
+		{
+			name:     "record chain",
+			status:   StatusPending,
+			update:   []ApprovalStep{{Role: ApproverManager}, {Role: ApproverTeamOwner}},
+			wantNext: 0,
+		},
+		{
+			name:     "approve first step",
+			status:   StatusPending,
+			original: []ApprovalStep{{Role: ApproverManager}, {Role: ApproverTeamOwner}},
+			update:   []ApprovalStep{{Role: ApproverManager, ApprovedBy: &approver}, {Role: ApproverTeamOwner}},
+			wantNext: 1,
+		},
+		{
+			name:     "approve last step",
+			status:   StatusPending,
+			original: []ApprovalStep{{Role: ApproverManager, ApprovedBy: &approver}, {Role: ApproverTeamOwner}},
+			update:   []ApprovalStep{{Role: ApproverManager, ApprovedBy: &approver}, {Role: ApproverTeamOwner, ApprovedBy: &other}},
+			wantNext: -1,
+		},
+		{
+			name:     "approve step twice",
+			status:   StatusPending,
+			original: []ApprovalStep{{Role: ApproverManager, ApprovedBy: &approver}},
+			update:   []ApprovalStep{{Role: ApproverManager, ApprovedBy: &other}},
+			wantErr:  true,
+		},
+		{
+			name:     "revoke approved step",
+			status:   StatusPending,
+			original: []ApprovalStep{{Role: ApproverManager, ApprovedBy: &approver}},
+			update:   []ApprovalStep{},
+			wantErr:  true,
+		},
+		{
+			name:     "approve draft",
+			status:   StatusDraft,
+			original: []ApprovalStep{{Role: ApproverManager}},
+			update:   []ApprovalStep{{Role: ApproverManager, ApprovedBy: &approver}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			vr := &VacationRequest{Status: tc.status, ApprovalSteps: tc.original}
+			err := vr.Update(&VacationRequest{ApprovalSteps: tc.update})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("want error: %t, got: %v", tc.wantErr, err)
+			}
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidStatusTransition) {
+					t.Fatalf("want: %v, got: %v", ErrInvalidStatusTransition, err)
+				}
+				return
+			}
+			if got := vr.NextApprovalStep(); got != tc.wantNext {
+				t.Fatalf("want next step: %d, got: %d", tc.wantNext, got)
+			}
+		})
+	}
+}
+
+func TestVacationRequest_UpdateDeputy(t *testing.T) {
+	deputy := func(id string) *string { return &id }
+	tt := []struct {
+		name       string
+		current    *VacationRequest
+		update     *VacationRequest
+		wantErr    error
+		wantStatus DeputyStatus
+	}{
+		{
+			name:       "assign deputy",
+			current:    &VacationRequest{UserID: "user", Status: StatusPending},
+			update:     &VacationRequest{DeputyID: deputy("deputy")},
+			wantStatus: DeputyPending,
+		},
+		{
+			name:       "assign other deputy after decline",
+			current:    &VacationRequest{UserID: "user", Status: StatusPending, DeputyID: deputy("deputy"), DeputyStatus: DeputyDeclined},
+			update:     &VacationRequest{DeputyID: deputy("other")},
+			wantStatus: DeputyPending,
+		},
+		{
+			name:    "cover for itself",
+			current: &VacationRequest{UserID: "user", Status: StatusDraft},
+			update:  &VacationRequest{DeputyID: deputy("user")},
+			wantErr: ErrInvalidDeputy,
+		},
+		{
+			name:       "accept",
+			current:    &VacationRequest{UserID: "user", Status: StatusPending, DeputyID: deputy("deputy"), DeputyStatus: DeputyPending},
+			update:     &VacationRequest{DeputyStatus: DeputyAccepted},
+			wantStatus: DeputyAccepted,
+		},
+		{
+			name:    "accept twice",
+			current: &VacationRequest{UserID: "user", Status: StatusPending, DeputyID: deputy("deputy"), DeputyStatus: DeputyDeclined},
+			update:  &VacationRequest{DeputyStatus: DeputyAccepted},
+			wantErr: ErrInvalidStatusTransition,
+		},
+		{
+			name:    "accept without deputy",
+			current: &VacationRequest{UserID: "user", Status: StatusPending},
+			update:  &VacationRequest{DeputyStatus: DeputyAccepted},
+			wantErr: ErrInvalidDeputy,
+		},
+		{
+			name:    "assign deputy after approval",
+			current: &VacationRequest{UserID: "user", Status: StatusApproved},
+			update:  &VacationRequest{DeputyID: deputy("deputy")},
+			wantErr: ErrInvalidStatusTransition,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.current.Update(tc.update)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected %v, got: %v", tc.wantErr, err)
+			}
+			if err == nil && tc.current.DeputyStatus != tc.wantStatus {
+				t.Fatalf("want: %s, got: %s", tc.wantStatus, tc.current.DeputyStatus)
+			}
+		})
+	}
+}
+
+func TestVacationRequest_UpdatePeriod(t *testing.T) {
+	approver := "test-parent-id"
+	deputy := "test-deputy-id"
+	from := time.Date(2022, time.April, 4, 0, 0, 0, 0, time.UTC)
+	vr := &VacationRequest{
+		UserID:        "user",
+		Status:        StatusPending,
+		From:          from,
+		To:            from.AddDate(0, 0, 2),
+		ApprovalSteps: []ApprovalStep{{Role: ApproverManager, ApprovedBy: &approver}, {Role: ApproverTeamOwner}},
+		DeputyID:      &deputy,
+		DeputyStatus:  DeputyAccepted,
+	}
+	err := vr.Update(&VacationRequest{To: from.AddDate(0, 0, 4)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := vr.NextApprovalStep(); next != 0 {
+		t.Fatalf("expected approvals to be revoked, next step: %d", next)
+	}
+	if vr.DeputyStatus != DeputyPending {
+		t.Fatalf("expected deputy assignment to restart, got: %s", vr.DeputyStatus)
+	}
+	err = vr.Update(&VacationRequest{
+		From:          from.AddDate(0, 0, 1),
+		ApprovalSteps: []ApprovalStep{{Role: ApproverParent}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]ApprovalStep{{Role: ApproverParent}}, vr.ApprovalSteps); diff != "" {
+		t.Fatalf("expected new approval chain (-want +got):\n%s", diff)
+	}
+}
+
+func TestVacationRequestStatus_CanTransition(t *testing.T) {
+	tt := []struct {
+		name string
+		from VacationRequestStatus
+		to   VacationRequestStatus
+		want bool
+	}{
+		{name: "submit draft", from: StatusDraft, to: StatusPending, want: true},
+		{name: "approve draft", from: StatusDraft, to: StatusApproved, want: false},
+		{name: "approve pending", from: StatusPending, to: StatusApproved, want: true},
+		{name: "reject pending", from: StatusPending, to: StatusRejected, want: true},
+		{name: "withdraw pending", from: StatusPending, to: StatusWithdrawn, want: true},
+		{name: "approve approved", from: StatusApproved, to: StatusApproved, want: false},
+		{name: "cancel approved", from: StatusApproved, to: StatusCancelled, want: true},
+		{name: "withdraw approved", from: StatusApproved, to: StatusWithdrawn, want: false},
+		{name: "reopen rejected", from: StatusRejected, to: StatusPending, want: false},
+		{name: "unknown status", from: "unknown", to: StatusPending, want: false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.from.CanTransition(tc.to); got != tc.want {
+				t.Fatalf("want: %t, got: %t", tc.want, got)
+			}
 		})
 	}
 }
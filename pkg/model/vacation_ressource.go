@@ -1,17 +1,49 @@
 package model
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrOverlappingResource is returned if the period of a VacationResource
+// overlaps with another VacationResource of the same user.
+var ErrOverlappingResource = errors.New("overlapping vacation-resource")
 
 // VacationResource represents the VacationResource model.
 type VacationResource struct {
-	ID         string     `json:"id"`
-	UserID     string     `json:"user_id"`
-	YearlyDays int        `json:"yearly_days"`
-	From       time.Time  `json:"from"`
-	To         time.Time  `json:"to"`
-	CreatedAt  *time.Time `json:"created_at"`
-	DeletedAt  *time.Time `json:"deleted_at"`
-	UpdatedAt  *time.Time `json:"updated_at"`
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	YearlyDays int       `json:"yearly_days"`
+	From       time.Time `json:"from"`
+	// To is optional, a resource without end date is valid until further
+	// notice.
+	To time.Time `json:"to"`
+	// CarriedDays are the unused days of the previous year, which are carried
+	// over. A resource with carried days is a carry-over resource, its yearly
+	// days are ignored and the carried days expire at To.
+	CarriedDays float64    `json:"carried_days"`
+	CreatedAt   *time.Time `json:"created_at"`
+	DeletedAt   *time.Time `json:"deleted_at"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+	// Version is incremented by every update, see ErrVersionConflict.
+	Version int `json:"version"`
+}
+
+// IsCarryOver reports whether v holds carried days of the previous year.
+func (v *VacationResource) IsCarryOver() bool {
+	return v.CarriedDays > 0
+}
+
+// Overlaps reports whether v and o belong to the same user and share at least
+// one day. Both limits are inclusive. Carry-over resources only overlap with
+// other carry-over resources.
+func (v *VacationResource) Overlaps(o *VacationResource) bool {
+	if v.UserID != o.UserID || (v.ID != "" && v.ID == o.ID) || v.IsCarryOver() != o.IsCarryOver() {
+		return false
+	}
+	vEndsBefore := !v.To.IsZero() && v.To.Before(o.From)
+	oEndsBefore := !o.To.IsZero() && o.To.Before(v.From)
+	return !vEndsBefore && !oEndsBefore
 }
 
 // Copy returns a deep copy.
@@ -30,13 +62,15 @@ func (v *VacationResource) Copy() *VacationResource {
 		updatedAt = &ut
 	}
 	return &VacationResource{
-		ID:         v.ID,
-		UserID:     v.UserID,
-		YearlyDays: v.YearlyDays,
-		From:       v.From,
-		To:         v.To,
-		CreatedAt:  createdAt,
-		DeletedAt:  deletedAt,
-		UpdatedAt:  updatedAt,
+		ID:          v.ID,
+		UserID:      v.UserID,
+		YearlyDays:  v.YearlyDays,
+		From:        v.From,
+		To:          v.To,
+		CarriedDays: v.CarriedDays,
+		CreatedAt:   createdAt,
+		DeletedAt:   deletedAt,
+		UpdatedAt:   updatedAt,
+		Version:     v.Version,
 	}
 }
@@ -16,14 +16,15 @@ func TestVacationResource_Copy(t *testing.T) {
 		{
 			name: "expected",
 			original: &VacationResource{
-				ID:         "test-vacation-resource-id",
-				UserID:     "test-user-id",
-				YearlyDays: 10,
-				From:       now.Add(time.Minute),
-				To:         now.Add(time.Hour),
-				CreatedAt:  func() *time.Time { tmp := now.Add(10 * time.Minute); return &tmp }(),
-				UpdatedAt:  func() *time.Time { tmp := now.Add(15 * time.Minute); return &tmp }(),
-				DeletedAt:  func() *time.Time { tmp := now.Add(30 * time.Minute); return &tmp }(),
+				ID:          "test-vacation-resource-id",
+				UserID:      "test-user-id",
+				YearlyDays:  10,
+				From:        now.Add(time.Minute),
+				To:          now.Add(time.Hour),
+				CarriedDays: 2.5,
+				CreatedAt:   func() *time.Time { tmp := now.Add(10 * time.Minute); return &tmp }(),
+				UpdatedAt:   func() *time.Time { tmp := now.Add(15 * time.Minute); return &tmp }(),
+				DeletedAt:   func() *time.Time { tmp := now.Add(30 * time.Minute); return &tmp }(),
 			},
 		},
 	}
@@ -39,6 +40,7 @@ func TestVacationResource_Copy(t *testing.T) {
 			got.YearlyDays = 10
 			got.From = time.Now()
 			got.To = time.Now()
+			got.CarriedDays = 1
 			got.CreatedAt = nil
 			got.UpdatedAt = nil
 			got.DeletedAt = nil
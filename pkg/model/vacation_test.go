@@ -16,13 +16,18 @@ func TestVacation_Copy(t *testing.T) {
 		{
 			name: "expected",
 			original: &Vacation{
-				ID:         "test-vacation-id",
-				UserID:     "test-user-id",
-				ApprovedBy: func() *string { str := "test-approvedBy-id"; return &str }(),
-				From:       now.Add(time.Minute),
-				To:         now.Add(time.Hour),
-				CreatedAt:  func() *time.Time { tmp := now.Add(10 * time.Minute); return &tmp }(),
-				DeletedAt:  func() *time.Time { tmp := now.Add(30 * time.Minute); return &tmp }(),
+				ID:                 "test-vacation-id",
+				UserID:             "test-user-id",
+				ApprovedBy:         func() *string { str := "test-approvedBy-id"; return &str }(),
+				ApprovedOnBehalfOf: func() *string { str := "test-delegator-id"; return &str }(),
+				From:               now.Add(time.Minute),
+				AbsenceTypeID:      func() *string { str := "test-absence-type-id"; return &str }(),
+				DeputyID:           func() *string { str := "test-deputy-id"; return &str }(),
+				Portion:            PortionHours,
+				Hours:              2,
+				To:                 now.Add(time.Hour),
+				CreatedAt:          func() *time.Time { tmp := now.Add(10 * time.Minute); return &tmp }(),
+				DeletedAt:          func() *time.Time { tmp := now.Add(30 * time.Minute); return &tmp }(),
 			},
 		},
 	}
@@ -36,7 +41,12 @@ func TestVacation_Copy(t *testing.T) {
 			got.ID += "vacation-id"
 			got.UserID = "user-id-vacation"
 			got.ApprovedBy = nil
+			got.ApprovedOnBehalfOf = nil
 			got.From = time.Now()
+			got.AbsenceTypeID = nil
+			got.DeputyID = nil
+			got.Portion = PortionFullDay
+			got.Hours = 0
 			got.To = time.Now()
 			got.CreatedAt = nil
 			got.DeletedAt = nil
@@ -0,0 +1,208 @@
+package policy
+
+import (
+	"context"
+	"time"
+
+	"github.com/MninaTB/vacadm/pkg/database"
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+// Permission names an action on a kind of resource.
+type Permission string
+
+const (
+	// Authenticated is held by every user with a valid token.
+	Authenticated Permission = "authenticated"
+
+	// ReadUser allows to read a user and all resources below the user, e.g.
+	// vacations, requests and delegations.
+	ReadUser Permission = "user:read"
+	// WriteUser allows to create and change resources below a user, e.g.
+	// vacation requests.
+	WriteUser Permission = "user:write"
+	// ManageUser allows to delete a user and to manage its entitlements and
+	// vacations.
+	ManageUser Permission = "user:manage"
+	// CreateUser allows to create new users.
+	CreateUser Permission = "user:create"
+	// AssignRole allows to change the role of a user.
+	AssignRole Permission = "user:assign-role"
+	// Approve allows to decide about vacation requests in the name of the
+	// approver in the URL, the approval chain is verified by the handler.
+	Approve Permission = "vacation-request:approve"
+
+	// ReadTeam allows to read a team and all resources below the team, e.g.
+	// rules and memberships.
+	ReadTeam Permission = "team:read"
+	// ManageTeam allows to change or delete a team and to manage its rules
+	// and memberships.
+	ManageTeam Permission = "team:manage"
+	// ReadAbsences allows to read all absences of the members of a team,
+	// including absence types, which are hidden from the team.
+	ReadAbsences Permission = "team:read-absences"
+	// CreateTeam allows to create new teams.
+	CreateTeam Permission = "team:create"
+
+	// ManageAbsenceTypes allows to create, change and delete absence types.
+	ManageAbsenceTypes Permission = "absence-type:manage"
+
+	// ReadDeleted allows to include soft deleted entries in reads.
+	ReadDeleted Permission = "deleted:read"
+	// ManageDeleted allows to restore and purge soft deleted entries.
+	ManageDeleted Permission = "deleted:manage"
+
+	// ReadAudit allows to read the audit log.
+	ReadAudit Permission = "audit:read"
+)
+
+// Resource returns the name of the route variable, which identifies the
+// resource a permission is checked on. Global permissions return an empty
+// string.
+func (p Permission) Resource() string {
+	switch p {
+	case ReadUser, WriteUser, ManageUser:
+		return "userID"
+	case Approve:
+		return "parentID"
+	case ReadTeam, ManageTeam, ReadAbsences:
+		return "teamID"
+	}
+	return ""
+}
+
+// grants contains the permissions a role holds on all resources. Admins hold
+// all permissions, all roles hold the permissions derived from relations.
+var grants = map[model.Role][]Permission{
+	model.RoleHR: {
+		ReadUser, ManageUser, CreateUser,
+		ReadTeam, ReadAbsences,
+		ManageAbsenceTypes,
+	},
+	model.RoleManager: {
+		ReadTeam, CreateTeam,
+	},
+	model.RoleEmployee: {},
+}
+
+// Engine decides about the permissions of users.
+type Engine interface {
+	// Allowed reports whether the user holds the permission on the resource
+	// with the given id. The id is ignored for global permissions.
+	Allowed(ctx context.Context, userID string, p Permission, resourceID string) (bool, error)
+}
+
+// NewEngine returns an Engine, which combines the role of a user with its
+// relations to other users and teams.
+func NewEngine(store database.Database) Engine {
+	return &engine{
+		store:     store,
+		relations: database.NewRelationDB(store),
+	}
+}
+
+type engine struct {
+	store     database.Database
+	relations database.RelationDB
+}
+
+func (e *engine) Allowed(ctx context.Context, userID string, p Permission, resourceID string) (bool, error) {
+	if p == Authenticated {
+		return true, nil
+	}
+	user, err := e.store.GetUserByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if user.Role == model.RoleAdmin {
+		return true, nil
+	}
+	for _, granted := range grants[user.Role] {
+		if granted == p {
+			return true, nil
+		}
+	}
+	switch p {
+	case ReadUser:
+		if userID == resourceID {
+			return true, nil
+		}
+		return e.isParentOrDelegate(ctx, resourceID, userID)
+	case WriteUser:
+		if userID == resourceID {
+			return true, nil
+		}
+		return e.isParentOrTeamParent(ctx, resourceID, userID)
+	case ManageUser:
+		if userID == resourceID {
+			return false, nil
+		}
+		return e.isParentOrTeamParent(ctx, resourceID, userID)
+	case Approve:
+		return userID == resourceID, nil
+	case ReadTeam:
+		role, err := e.relations.TeamRole(ctx, resourceID, userID)
+		if err != nil || role != "" {
+			return role != "", err
+		}
+		return e.isTeamManager(ctx, resourceID, userID)
+	case ManageTeam, ReadAbsences:
+		return e.isTeamManager(ctx, resourceID, userID)
+	}
+	return false, nil
+}
+
+// isParentOrDelegate verifies if parentID is parent of userID or acts as active
+// delegate of a parent of userID. Delegates only take over approvals and read
+// access, they never write or manage the users of their delegators.
+func (e *engine) isParentOrDelegate(ctx context.Context, userID, parentID string) (bool, error) {
+	isParent, err := e.isParentOrTeamParent(ctx, userID, parentID)
+	if err != nil || isParent {
+		return isParent, err
+	}
+	delegators, err := e.relations.Delegators(ctx, parentID, time.Now())
+	if err != nil {
+		return false, err
+	}
+	for _, delegatorID := range delegators {
+		isParent, err := e.isParentOrTeamParent(ctx, userID, delegatorID)
+		if err != nil || isParent {
+			return isParent, err
+		}
+	}
+	return false, nil
+}
+
+// isParentOrTeamParent verifies if parentID is parent of userID along the user
+// hierarchy or owns the team of userID or one of its parent teams.
+func (e *engine) isParentOrTeamParent(ctx context.Context, userID, parentID string) (bool, error) {
+	isParent, err := e.isParent(ctx, userID, parentID)
+	if err != nil || isParent {
+		return isParent, err
+	}
+	return e.relations.IsTeamParentUser(ctx, userID, parentID)
+}
+
+// isParent verifies if parentID is parent of userID along the user hierarchy.
+// Unlike RelationDB.IsParentUser, users without parent have no parents.
+func (e *engine) isParent(ctx context.Context, userID, parentID string) (bool, error) {
+	user, err := e.store.GetUserByID(ctx, userID)
+	if err != nil || user.ParentID == nil {
+		return false, nil
+	}
+	return e.relations.IsParentUser(ctx, userID, parentID)
+}
+
+// isTeamManager verifies if userID owns the team or one of its parent teams,
+// or is parent of the team owner.
+func (e *engine) isTeamManager(ctx context.Context, teamID, userID string) (bool, error) {
+	isOwner, err := e.relations.IsParentTeamOwner(ctx, teamID, userID)
+	if err != nil || isOwner {
+		return isOwner, err
+	}
+	team, err := e.store.GetTeamByID(ctx, teamID)
+	if err != nil {
+		return false, err
+	}
+	return e.isParent(ctx, team.OwnerID, userID)
+}
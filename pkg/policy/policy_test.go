@@ -0,0 +1,151 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MninaTB/vacadm/pkg/database/inmemory"
+	"github.com/MninaTB/vacadm/pkg/model"
+)
+
+func TestEngine_Allowed(t *testing.T) {
+	ctx := context.Background()
+	db := inmemory.NewInmemoryDB()
+	mustUser := func(u *model.User) *model.User {
+		t.Helper()
+		u, err := db.CreateUser(ctx, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+	mustTeam := func(tm *model.Team) *model.Team {
+		t.Helper()
+		tm, err := db.CreateTeam(ctx, tm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+	admin := mustUser(&model.User{Email: "admin@inform.de", Role: model.RoleAdmin})
+	hr := mustUser(&model.User{Email: "hr@inform.de", Role: model.RoleHR})
+	manager := mustUser(&model.User{Email: "manager@inform.de", Role: model.RoleManager})
+	salesOwner := mustUser(&model.User{Email: "sales-owner@inform.de"})
+	backend := mustTeam(&model.Team{Name: "backend", OwnerID: manager.ID})
+	sales := mustTeam(&model.Team{Name: "sales", OwnerID: salesOwner.ID})
+	employee := mustUser(&model.User{Email: "employee@inform.de", ParentID: &manager.ID, TeamID: &backend.ID})
+	colleague := mustUser(&model.User{Email: "colleague@inform.de", ParentID: &manager.ID, TeamID: &backend.ID})
+	seller := mustUser(&model.User{Email: "seller@inform.de", ParentID: &salesOwner.ID, TeamID: &sales.ID})
+	now := time.Now()
+	_, err := db.CreateDelegation(ctx, &model.Delegation{
+		DelegatorID: salesOwner.ID,
+		DelegateID:  colleague.ID,
+		From:        now.AddDate(0, 0, -1),
+		To:          now.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type check struct {
+		name       string
+		userID     string
+		permission Permission
+		resourceID string
+		want       bool
+	}
+	tt := map[model.Role][]check{
+		model.RoleAdmin: {
+			{name: "read foreign user", userID: admin.ID, permission: ReadUser, resourceID: seller.ID, want: true},
+			{name: "manage foreign user", userID: admin.ID, permission: ManageUser, resourceID: employee.ID, want: true},
+			{name: "assign role", userID: admin.ID, permission: AssignRole, want: true},
+			{name: "manage foreign team", userID: admin.ID, permission: ManageTeam, resourceID: sales.ID, want: true},
+			{name: "manage absence types", userID: admin.ID, permission: ManageAbsenceTypes, want: true},
+			{name: "manage deleted", userID: admin.ID, permission: ManageDeleted, want: true},
+		},
+		model.RoleHR: {
+			{name: "read everyone", userID: hr.ID, permission: ReadUser, resourceID: seller.ID, want: true},
+			{name: "write foreign user", userID: hr.ID, permission: WriteUser, resourceID: seller.ID},
+			{name: "manage foreign user", userID: hr.ID, permission: ManageUser, resourceID: seller.ID, want: true},
+			{name: "create user", userID: hr.ID, permission: CreateUser, want: true},
+			{name: "assign role", userID: hr.ID, permission: AssignRole},
+			{name: "read absences of all teams", userID: hr.ID, permission: ReadAbsences, resourceID: sales.ID, want: true},
+			{name: "manage team", userID: hr.ID, permission: ManageTeam, resourceID: sales.ID},
+			{name: "create team", userID: hr.ID, permission: CreateTeam},
+			{name: "manage absence types", userID: hr.ID, permission: ManageAbsenceTypes, want: true},
+			{name: "read deleted", userID: hr.ID, permission: ReadDeleted},
+			{name: "read audit", userID: hr.ID, permission: ReadAudit},
+		},
+		model.RoleManager: {
+			{name: "read own employee", userID: manager.ID, permission: ReadUser, resourceID: employee.ID, want: true},
+			{name: "manage own employee", userID: manager.ID, permission: ManageUser, resourceID: employee.ID, want: true},
+			{name: "read foreign user", userID: manager.ID, permission: ReadUser, resourceID: seller.ID},
+			{name: "manage own team", userID: manager.ID, permission: ManageTeam, resourceID: backend.ID, want: true},
+			{name: "read absences of own team", userID: manager.ID, permission: ReadAbsences, resourceID: backend.ID, want: true},
+			{name: "read foreign team", userID: manager.ID, permission: ReadTeam, resourceID: sales.ID, want: true},
+			{name: "read absences of foreign team", userID: manager.ID, permission: ReadAbsences, resourceID: sales.ID},
+			{name: "manage foreign team", userID: manager.ID, permission: ManageTeam, resourceID: sales.ID},
+			{name: "create team", userID: manager.ID, permission: CreateTeam, want: true},
+			{name: "create user", userID: manager.ID, permission: CreateUser},
+		},
+		model.RoleEmployee: {
+			{name: "authenticated", userID: employee.ID, permission: Authenticated, want: true},
+			{name: "read itself", userID: employee.ID, permission: ReadUser, resourceID: employee.ID, want: true},
+			{name: "write itself", userID: employee.ID, permission: WriteUser, resourceID: employee.ID, want: true},
+			{name: "manage itself", userID: employee.ID, permission: ManageUser, resourceID: employee.ID},
+			{name: "read colleague", userID: employee.ID, permission: ReadUser, resourceID: colleague.ID},
+			{name: "read own team", userID: employee.ID, permission: ReadTeam, resourceID: backend.ID, want: true},
+			{name: "read absences of own team", userID: employee.ID, permission: ReadAbsences, resourceID: backend.ID},
+			{name: "manage own team", userID: employee.ID, permission: ManageTeam, resourceID: backend.ID},
+			{name: "read foreign team", userID: employee.ID, permission: ReadTeam, resourceID: sales.ID},
+			{name: "create team", userID: employee.ID, permission: CreateTeam},
+			{name: "approve as itself", userID: employee.ID, permission: Approve, resourceID: employee.ID, want: true},
+			{name: "approve as parent", userID: employee.ID, permission: Approve, resourceID: manager.ID},
+			{name: "team owner manages team", userID: salesOwner.ID, permission: ManageTeam, resourceID: sales.ID, want: true},
+			{name: "parent manages user", userID: salesOwner.ID, permission: ManageUser, resourceID: seller.ID, want: true},
+			{name: "delegate reads user", userID: colleague.ID, permission: ReadUser, resourceID: seller.ID, want: true},
+			{name: "delegate writes user", userID: colleague.ID, permission: WriteUser, resourceID: seller.ID},
+			{name: "delegate manages user", userID: colleague.ID, permission: ManageUser, resourceID: seller.ID},
+		},
+	}
+
+	e := NewEngine(db)
+	for role, checks := range tt {
+		t.Run(string(role), func(t *testing.T) {
+			for _, tc := range checks {
+				t.Run(tc.name, func(t *testing.T) {
+					got, err := e.Allowed(ctx, tc.userID, tc.permission, tc.resourceID)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if got != tc.want {
+						t.Fatalf("want: %t, got: %t", tc.want, got)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tt := []struct {
+		method string
+		path   string
+		want   Permission
+		wantOK bool
+	}{
+		{method: "GET", path: "/user/{userID}", want: ReadUser, wantOK: true},
+		{method: "DELETE", path: "/user/{userID}", want: ManageUser, wantOK: true},
+		{method: "PUT", path: "/team", want: CreateTeam, wantOK: true},
+		{method: "POST", path: "/user/{userID}"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			got, ok := Lookup(tc.method, tc.path)
+			if ok != tc.wantOK || got != tc.want {
+				t.Fatalf("want: %q/%t, got: %q/%t", tc.want, tc.wantOK, got, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package policy
+
+import "net/http"
+
+// Route maps a route of the v1 api to the permission, which is required to
+// call it.
+type Route struct {
+	Method string
+	// Path is the path template as registered on the router, without the
+	// version prefix.
+	Path       string
+	Permission Permission
+}
+
+// Routes contains the permissions of all routes of the v1 api. Routes, which
+// are not listed, are denied.
+var Routes = []Route{
+	{http.MethodPut, "/user", CreateUser},
+	{http.MethodGet, "/user", Authenticated},
+	{http.MethodGet, "/user/{userID}", ReadUser},
+	{http.MethodPatch, "/user/{userID}", WriteUser},
+	{http.MethodDelete, "/user/{userID}", ManageUser},
+	{http.MethodPut, "/user/{userID}/role", AssignRole},
+
+	{http.MethodGet, "/org", Authenticated},
+	{http.MethodGet, "/org/{userID}", Authenticated},
+
+	{http.MethodPut, "/team", CreateTeam},
+	{http.MethodGet, "/team", Authenticated},
+	{http.MethodGet, "/team/{teamID}", ReadTeam},
+	{http.MethodPatch, "/team/{teamID}", ManageTeam},
+	{http.MethodDelete, "/team/{teamID}", ManageTeam},
+	{http.MethodGet, "/team/{teamID}/list-users", ReadTeam},
+	// NOTE: the capacity handler decides per team, which absences are
+	// returned.
+	{http.MethodPost, "/team/list-vacation", Authenticated},
+
+	{http.MethodPut, "/team/{teamID}/rules", ManageTeam},
+	{http.MethodGet, "/team/{teamID}/rules", ReadTeam},
+	{http.MethodGet, "/team/{teamID}/rules/{teamRuleID}", ReadTeam},
+	{http.MethodPatch, "/team/{teamID}/rules/{teamRuleID}", ManageTeam},
+	{http.MethodDelete, "/team/{teamID}/rules/{teamRuleID}", ManageTeam},
+
+	{http.MethodPut, "/team/{teamID}/members", ManageTeam},
+	{http.MethodGet, "/team/{teamID}/members", ReadTeam},
+	{http.MethodPatch, "/team/{teamID}/members/{teamMembershipID}", ManageTeam},
+	{http.MethodDelete, "/team/{teamID}/members/{teamMembershipID}", ManageTeam},
+
+	{http.MethodPut, "/user/{userID}/delegation", WriteUser},
+	{http.MethodGet, "/user/{userID}/delegation", ReadUser},
+	{http.MethodGet, "/user/{userID}/delegation/{delegationID}", ReadUser},
+	{http.MethodDelete, "/user/{userID}/delegation/{delegationID}", WriteUser},
+
+	{http.MethodGet, "/user/{userID}/deputy", ReadUser},
+	{http.MethodPut, "/user/{userID}/deputy/{vacationRequestID}/accept", WriteUser},
+	{http.MethodPut, "/user/{userID}/deputy/{vacationRequestID}/decline", WriteUser},
+
+	{http.MethodGet, "/user/{userID}/vacation/balance", ReadUser},
+	{http.MethodGet, "/user/{userID}/vacation", ReadUser},
+	{http.MethodPut, "/user/{userID}/vacation/carry-over", ManageUser},
+
+	{http.MethodPut, "/user/{userID}/vacation/request", WriteUser},
+	{http.MethodGet, "/user/{userID}/vacation/request", ReadUser},
+	{http.MethodGet, "/user/{userID}/vacation/request/{vacationRequestID}", ReadUser},
+	{http.MethodPatch, "/user/{userID}/vacation/request/{vacationRequestID}", WriteUser},
+	{http.MethodDelete, "/user/{userID}/vacation/request/{vacationRequestID}", WriteUser},
+	{http.MethodPut, "/user/{userID}/vacation/request/{vacationRequestID}/approve/{parentID}", Approve},
+	{http.MethodPut, "/user/{userID}/vacation/request/{vacationRequestID}/reject/{parentID}", Approve},
+	{http.MethodPut, "/user/{userID}/vacation/request/{vacationRequestID}/submit", WriteUser},
+	{http.MethodPut, "/user/{userID}/vacation/request/{vacationRequestID}/withdraw", WriteUser},
+	{http.MethodPut, "/user/{userID}/vacation/request/{vacationRequestID}/cancel", WriteUser},
+	// NOTE: the comment handlers verify, that the user takes part in the
+	// vacation request.
+	{http.MethodPut, "/user/{userID}/vacation/request/{vacationRequestID}/comments", Authenticated},
+	{http.MethodGet, "/user/{userID}/vacation/request/{vacationRequestID}/comments", Authenticated},
+	{http.MethodPut, "/user/{userID}/vacation/request/{vacationRequestID}/attachments", WriteUser},
+	{http.MethodGet, "/user/{userID}/vacation/request/{vacationRequestID}/attachments", ReadUser},
+	{http.MethodGet, "/user/{userID}/vacation/request/{vacationRequestID}/attachments/{attachmentID}", ReadUser},
+	{http.MethodDelete, "/user/{userID}/vacation/request/{vacationRequestID}/attachments/{attachmentID}", WriteUser},
+
+	{http.MethodPut, "/user/{userID}/vacation/resource", ManageUser},
+	{http.MethodGet, "/user/{userID}/vacation/resource", ReadUser},
+	{http.MethodGet, "/user/{userID}/vacation/resource/{vacationResourceID}", ReadUser},
+	{http.MethodPatch, "/user/{userID}/vacation/resource/{vacationResourceID}", ManageUser},
+	{http.MethodDelete, "/user/{userID}/vacation/resource/{vacationResourceID}", ManageUser},
+
+	{http.MethodGet, "/user/{userID}/vacation/{vacationID}", ReadUser},
+	{http.MethodDelete, "/user/{userID}/vacation/{vacationID}", ManageUser},
+	{http.MethodPut, "/user/{userID}/vacation/{vacationID}/attachments", WriteUser},
+	{http.MethodGet, "/user/{userID}/vacation/{vacationID}/attachments", ReadUser},
+	{http.MethodGet, "/user/{userID}/vacation/{vacationID}/attachments/{attachmentID}", ReadUser},
+	{http.MethodDelete, "/user/{userID}/vacation/{vacationID}/attachments/{attachmentID}", WriteUser},
+
+	{http.MethodGet, "/holiday-calendar", Authenticated},
+	{http.MethodGet, "/holiday-calendar/{calendarID}", Authenticated},
+
+	{http.MethodPut, "/absence-type", ManageAbsenceTypes},
+	{http.MethodGet, "/absence-type", Authenticated},
+	{http.MethodGet, "/absence-type/{absenceTypeID}", Authenticated},
+	{http.MethodPatch, "/absence-type/{absenceTypeID}", ManageAbsenceTypes},
+	{http.MethodDelete, "/absence-type/{absenceTypeID}", ManageAbsenceTypes},
+
+	{http.MethodPut, "/admin/{entity}/{entityID}/restore", ManageDeleted},
+	{http.MethodDelete, "/admin/{entity}/{entityID}", ManageDeleted},
+
+	{http.MethodGet, "/audit", ReadAudit},
+}
+
+// Lookup returns the permission required to call the route with the given
+// method and path template.
+func Lookup(method, path string) (Permission, bool) {
+	for _, route := range Routes {
+		if route.Method == method && route.Path == path {
+			return route.Permission, true
+		}
+	}
+	return "", false
+}